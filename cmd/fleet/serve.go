@@ -24,6 +24,7 @@ import (
 	"github.com/kolide/fleet/server/health"
 	"github.com/kolide/fleet/server/kolide"
 	"github.com/kolide/fleet/server/launcher"
+	"github.com/kolide/fleet/server/logging"
 	"github.com/kolide/fleet/server/mail"
 	"github.com/kolide/fleet/server/pubsub"
 	"github.com/kolide/fleet/server/service"
@@ -111,6 +112,13 @@ the way that the Fleet server works.
 				}
 			}
 
+			if !kolide.HostIdentityStrategy(config.Osquery.HostIdentityStrategy).Valid() {
+				initFatal(
+					errors.Errorf("unknown strategy %q", config.Osquery.HostIdentityStrategy),
+					"setting osquery host identity strategy",
+				)
+			}
+
 			var ds kolide.Datastore
 			var err error
 			mailService := mail.NewService()
@@ -172,7 +180,17 @@ the way that the Fleet server works.
 
 			var resultStore kolide.QueryResultStore
 			redisPool := pubsub.NewRedisPool(config.Redis.Address, config.Redis.Password)
-			resultStore = pubsub.NewRedisQueryResults(redisPool)
+			switch config.Osquery.LiveQueryStore {
+			case "redis":
+				resultStore = pubsub.NewRedisQueryResults(redisPool)
+			case "mysql":
+				resultStore = pubsub.NewDatastoreQueryResults(ds, config.Osquery.LiveQueryPollInterval)
+			default:
+				initFatal(
+					errors.Errorf("unknown store %q", config.Osquery.LiveQueryStore),
+					"setting osquery live query store",
+				)
+			}
 			ssoSessionStore := sso.NewSessionStore(redisPool)
 
 			svc, err := service.NewService(ds, resultStore, logger, config, mailService, clock.C, ssoSessionStore)
@@ -180,15 +198,6 @@ the way that the Fleet server works.
 				initFatal(err, "initializing service")
 			}
 
-			go func() {
-				ticker := time.NewTicker(1 * time.Hour)
-				for {
-					ds.CleanupDistributedQueryCampaigns(time.Now())
-					ds.CleanupIncomingHosts(time.Now())
-					<-ticker.C
-				}
-			}()
-
 			fieldKeys := []string{"method", "error"}
 			requestCount := kitprometheus.NewCounterFrom(prometheus.CounterOpts{
 				Namespace: "api",
@@ -204,9 +213,30 @@ the way that the Fleet server works.
 			}, fieldKeys)
 
 			svcLogger := kitlog.With(logger, "component", "service")
+			activityLogger, err := logging.NewActivityLogger(config, logger)
+			if err != nil {
+				initFatal(err, "initializing activity logger")
+			}
+			svc = service.NewActivityService(svc, ds, activityLogger, svcLogger)
 			svc = service.NewLoggingService(svc, svcLogger)
 			svc = service.NewMetricsService(svc, requestCount, requestLatency)
 
+			go func() {
+				ticker := time.NewTicker(1 * time.Hour)
+				for {
+					ds.CleanupDistributedQueryCampaigns(time.Now())
+					ds.CleanupCampaignResults(time.Now().Add(-config.Osquery.CampaignResultRetention))
+					ds.CleanupIncomingHosts(time.Now())
+					if config.Osquery.LiveQueryStore == "mysql" {
+						pubsub.CleanupDatastoreQueryResults(ds, time.Now())
+					}
+					// Expiry is run through the service so that the
+					// removed hosts are logged.
+					svc.ExpireHosts(context.Background())
+					<-ticker.C
+				}
+			}()
+
 			httpLogger := kitlog.With(logger, "component", "http")
 
 			var apiHandler, frontendHandler http.Handler
@@ -22,16 +22,19 @@ type specGroup struct {
 	Queries      []*kolide.QuerySpec
 	Packs        []*kolide.PackSpec
 	Labels       []*kolide.LabelSpec
+	HostTags     []*kolide.HostTagSpec
 	Options      *kolide.OptionsSpec
 	AppConfig    *kolide.AppConfigPayload
 	EnrollSecret *kolide.EnrollSecretSpec
+	UserRoles    *kolide.UserRolesSpec
 }
 
 func specGroupFromBytes(b []byte) (*specGroup, error) {
 	specs := &specGroup{
 		Queries: []*kolide.QuerySpec{},
 		Packs:   []*kolide.PackSpec{},
-		Labels:  []*kolide.LabelSpec{},
+		Labels:   []*kolide.LabelSpec{},
+		HostTags: []*kolide.HostTagSpec{},
 	}
 
 	for _, spec := range strings.Split(string(b), "---") {
@@ -70,6 +73,13 @@ func specGroupFromBytes(b []byte) (*specGroup, error) {
 			}
 			specs.Labels = append(specs.Labels, labelSpec)
 
+		case "host_tags":
+			var hostTagSpec *kolide.HostTagSpec
+			if err := yaml.Unmarshal(s.Spec, &hostTagSpec); err != nil {
+				return nil, errors.Wrap(err, "unmarshaling host tags spec")
+			}
+			specs.HostTags = append(specs.HostTags, hostTagSpec)
+
 		case "options":
 			if specs.Options != nil {
 				return nil, errors.New("options defined twice in the same file")
@@ -103,6 +113,19 @@ func specGroupFromBytes(b []byte) (*specGroup, error) {
 			}
 			specs.EnrollSecret = enrollSecretSpec
 
+		case "user_roles":
+			var userRolesSpec *kolide.UserRolesSpec
+			if err := yaml.Unmarshal(s.Spec, &userRolesSpec); err != nil {
+				return nil, errors.Wrap(err, "unmarshaling user roles spec")
+			}
+			// Roles from several documents are merged
+			if specs.UserRoles == nil {
+				specs.UserRoles = &kolide.UserRolesSpec{Roles: map[string]kolide.Role{}}
+			}
+			for username, role := range userRolesSpec.Roles {
+				specs.UserRoles.Roles[username] = role
+			}
+
 		default:
 			return nil, errors.Errorf("unknown kind %q", s.Kind)
 		}
@@ -111,10 +134,17 @@ func specGroupFromBytes(b []byte) (*specGroup, error) {
 	return specs, nil
 }
 
+func printWarnings(warnings []string) {
+	for _, w := range warnings {
+		fmt.Printf("[!] warning: %s\n", w)
+	}
+}
+
 func applyCommand() cli.Command {
 	var (
 		flFilename string
 		flDebug    bool
+		flDryRun   bool
 	)
 	return cli.Command{
 		Name:      "apply",
@@ -136,6 +166,12 @@ func applyCommand() cli.Command {
 				Destination: &flDebug,
 				Usage:       "Whether or not to enable debug logging",
 			},
+			cli.BoolFlag{
+				Name:        "dry-run",
+				EnvVar:      "DRY_RUN",
+				Destination: &flDryRun,
+				Usage:       "Validate the queries, labels and packs without applying anything",
+			},
 		},
 		Action: func(c *cli.Context) error {
 			if flFilename == "" {
@@ -157,27 +193,57 @@ func applyCommand() cli.Command {
 				return err
 			}
 
+			if flDryRun {
+				validation, err := fleet.ValidateSpecs(specs.Queries, specs.Labels, specs.Packs)
+				if err != nil {
+					return errors.Wrap(err, "validating specs")
+				}
+				printWarnings(validation.Warnings)
+				for _, e := range validation.Errors {
+					fmt.Printf("[!] error: %s\n", e)
+				}
+				if len(validation.Errors) > 0 {
+					return errors.Errorf("validation found %d errors", len(validation.Errors))
+				}
+				fmt.Printf("[+] validated %d queries, %d labels and %d packs\n",
+					len(specs.Queries), len(specs.Labels), len(specs.Packs))
+				return nil
+			}
+
 			if len(specs.Queries) > 0 {
-				if err := fleet.ApplyQueries(specs.Queries); err != nil {
+				warnings, err := fleet.ApplyQueries(specs.Queries)
+				if err != nil {
 					return errors.Wrap(err, "applying queries")
 				}
+				printWarnings(warnings)
 				fmt.Printf("[+] applied %d queries\n", len(specs.Queries))
 			}
 
 			if len(specs.Labels) > 0 {
-				if err := fleet.ApplyLabels(specs.Labels); err != nil {
+				warnings, err := fleet.ApplyLabels(specs.Labels)
+				if err != nil {
 					return errors.Wrap(err, "applying labels")
 				}
+				printWarnings(warnings)
 				fmt.Printf("[+] applied %d labels\n", len(specs.Labels))
 			}
 
 			if len(specs.Packs) > 0 {
-				if err := fleet.ApplyPacks(specs.Packs); err != nil {
+				warnings, err := fleet.ApplyPacks(specs.Packs)
+				if err != nil {
 					return errors.Wrap(err, "applying packs")
 				}
+				printWarnings(warnings)
 				fmt.Printf("[+] applied %d packs\n", len(specs.Packs))
 			}
 
+			if len(specs.HostTags) > 0 {
+				if err := fleet.ApplyHostTags(specs.HostTags); err != nil {
+					return errors.Wrap(err, "applying host tags")
+				}
+				fmt.Printf("[+] applied %d host tags\n", len(specs.HostTags))
+			}
+
 			if specs.Options != nil {
 				if err := fleet.ApplyOptions(specs.Options); err != nil {
 					return errors.Wrap(err, "applying options")
@@ -201,6 +267,13 @@ func applyCommand() cli.Command {
 
 			}
 
+			if specs.UserRoles != nil {
+				if err := fleet.ApplyUserRolesSpec(specs.UserRoles); err != nil {
+					return errors.Wrap(err, "applying user roles")
+				}
+				fmt.Printf("[+] applied %d user roles\n", len(specs.UserRoles.Roles))
+			}
+
 			return nil
 		},
 	}
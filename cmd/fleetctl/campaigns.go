@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/kolide/fleet/server/service"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+func campaignsCommand() cli.Command {
+	return cli.Command{
+		Name:  "campaigns",
+		Usage: "Manage live query campaigns",
+		Subcommands: []cli.Command{
+			cancelCampaignCommand(),
+		},
+	}
+}
+
+func cancelCampaignCommand() cli.Command {
+	return cli.Command{
+		Name:      "cancel",
+		Usage:     "Stop sending a live query campaign to hosts",
+		UsageText: `fleetctl campaigns cancel <campaign id>`,
+		Flags: []cli.Flag{
+			configFlag(),
+			contextFlag(),
+		},
+		Action: func(c *cli.Context) error {
+			campaignID, err := strconv.ParseUint(c.Args().First(), 10, 32)
+			if err != nil || campaignID == 0 {
+				return errors.New("a campaign ID must be provided")
+			}
+
+			fleet, err := clientFromCLI(c)
+			if err != nil {
+				return err
+			}
+
+			if _, err := fleet.CancelCampaign(uint(campaignID)); err != nil {
+				switch err.(type) {
+				case service.NotFoundErr:
+					return errors.Errorf("campaign %d not found", campaignID)
+				}
+				return errors.Wrap(err, "could not cancel campaign")
+			}
+
+			fmt.Printf("[+] canceled campaign %d\n", campaignID)
+			return nil
+		},
+	}
+}
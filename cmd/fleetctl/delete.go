@@ -3,7 +3,10 @@ package main
 import (
 	"fmt"
 	"io/ioutil"
+	"strconv"
+	"strings"
 
+	"github.com/kolide/fleet/server/kolide"
 	"github.com/kolide/fleet/server/service"
 	"github.com/pkg/errors"
 	"github.com/urfave/cli"
@@ -18,6 +21,9 @@ func deleteCommand() cli.Command {
 		Name:      "delete",
 		Usage:     "Specify files to declaratively batch delete osquery configurations",
 		UsageText: `fleetctl delete [options]`,
+		Subcommands: []cli.Command{
+			deleteHostsCommand(),
+		},
 		Flags: []cli.Flag{
 			configFlag(),
 			contextFlag(),
@@ -95,3 +101,103 @@ func deleteCommand() cli.Command {
 		},
 	}
 }
+
+func deleteHostsCommand() cli.Command {
+	var (
+		flIDs, flLabel, flFilter string
+		flYes                    bool
+	)
+	return cli.Command{
+		Name:      "hosts",
+		Usage:     "Delete hosts in bulk by ID, label or filter",
+		UsageText: `fleetctl delete hosts [--ids <id,...> | --label <name> | --filter <expr>] [--yes]`,
+		Flags: []cli.Flag{
+			configFlag(),
+			contextFlag(),
+			cli.StringFlag{
+				Name:        "ids",
+				Value:       "",
+				Destination: &flIDs,
+				Usage:       "Comma separated IDs of the hosts to delete",
+			},
+			cli.StringFlag{
+				Name:        "label",
+				Value:       "",
+				Destination: &flLabel,
+				Usage:       "Name of the label whose hosts should be deleted",
+			},
+			cli.StringFlag{
+				Name:        "filter",
+				Value:       "",
+				Destination: &flFilter,
+				Usage:       "Host filter expression selecting the hosts to delete",
+			},
+			cli.BoolFlag{
+				Name:        "yes",
+				Destination: &flYes,
+				Usage:       "Delete without asking for confirmation",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			var ids []uint
+			if flIDs != "" {
+				for _, s := range strings.Split(flIDs, ",") {
+					id, err := strconv.ParseUint(strings.TrimSpace(s), 10, 64)
+					if err != nil {
+						return errors.Errorf("invalid host ID %q", s)
+					}
+					ids = append(ids, uint(id))
+				}
+			}
+			if flFilter != "" {
+				if _, err := kolide.ParseHostFilter(flFilter); err != nil {
+					return err
+				}
+			}
+
+			fleet, err := clientFromCLI(c)
+			if err != nil {
+				return err
+			}
+
+			var labelID *uint
+			if flLabel != "" {
+				label, err := fleet.GetLabel(flLabel)
+				if err != nil {
+					switch err.(type) {
+					case service.NotFoundErr:
+						return errors.Errorf("label %q doesn't exist", flLabel)
+					}
+					return err
+				}
+				labelID = &label.ID
+			}
+
+			result, err := fleet.DeleteHosts(ids, labelID, flFilter, "")
+			if err != nil {
+				return err
+			}
+
+			if result.ConfirmationRequired {
+				if !flYes {
+					fmt.Printf("About to delete %d hosts. Continue? [y/N] ", result.Matched)
+					var answer string
+					fmt.Scanln(&answer)
+					if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+						fmt.Println("[!] no hosts deleted")
+						return nil
+					}
+				}
+
+				// Delete exactly the previewed hosts
+				result, err = fleet.DeleteHosts(result.HostIDs, nil, "", result.Token)
+				if err != nil {
+					return err
+				}
+			}
+
+			fmt.Printf("[+] deleted %d of %d matching hosts\n", result.Deleted, result.Matched)
+			return nil
+		},
+	}
+}
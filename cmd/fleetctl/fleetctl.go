@@ -29,6 +29,9 @@ func main() {
 		logoutCommand(),
 		queryCommand(),
 		getCommand(),
+		hostsCommand(),
+		campaignsCommand(),
+		serviceAccountsCommand(),
 		cli.Command{
 			Name:  "config",
 			Usage: "Modify how and which Fleet server to connect to",
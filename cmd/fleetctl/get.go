@@ -4,9 +4,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ghodss/yaml"
 	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/service"
 	"github.com/olekukonko/tablewriter"
 	"github.com/pkg/errors"
 	"github.com/urfave/cli"
@@ -152,6 +156,24 @@ func printSecret(c *cli.Context, secret *kolide.EnrollSecretSpec) error {
 	return err
 }
 
+func printUserRoles(c *cli.Context, roles *kolide.UserRolesSpec) error {
+	spec := specGeneric{
+		Kind:    "user_roles",
+		Version: kolide.ApiVersion,
+		Spec:    roles,
+	}
+
+	var err error
+
+	if c.Bool(jsonFlagName) {
+		err = printJSON(spec)
+	} else {
+		err = printYaml(spec)
+	}
+
+	return err
+}
+
 func printHost(c *cli.Context, host *kolide.Host) error {
 	spec := specGeneric{
 		Kind:    "host",
@@ -187,6 +209,40 @@ func printConfig(c *cli.Context, config *kolide.AppConfigPayload) error {
 	return err
 }
 
+func printCampaign(c *cli.Context, campaign *kolide.DistributedQueryCampaignSummary) error {
+	spec := specGeneric{
+		Kind:    "campaign",
+		Version: kolide.ApiVersion,
+		Spec:    campaign,
+	}
+	var err error
+
+	if c.Bool(jsonFlagName) {
+		err = printJSON(spec)
+	} else {
+		err = printYaml(spec)
+	}
+
+	return err
+}
+
+func printActivity(c *cli.Context, activity *kolide.Activity) error {
+	spec := specGeneric{
+		Kind:    "activity",
+		Version: kolide.ApiVersion,
+		Spec:    activity,
+	}
+	var err error
+
+	if c.Bool(jsonFlagName) {
+		err = printJSON(spec)
+	} else {
+		err = printYaml(spec)
+	}
+
+	return err
+}
+
 func getCommand() cli.Command {
 	return cli.Command{
 		Name:  "get",
@@ -198,7 +254,12 @@ func getCommand() cli.Command {
 			getOptionsCommand(),
 			getHostsCommand(),
 			getEnrollSecretCommand(),
+			getUserRolesCommand(),
 			getAppConfigCommand(),
+			getCampaignsCommand(),
+			getCampaignResultsCommand(),
+			getCampaignHostsCommand(),
+			getActivitiesCommand(),
 		},
 	}
 }
@@ -527,6 +588,38 @@ func getEnrollSecretCommand() cli.Command {
 	}
 }
 
+func getUserRolesCommand() cli.Command {
+	return cli.Command{
+		Name:    "user_roles",
+		Aliases: []string{"user-roles"},
+		Usage:   "Retrieve the roles of the Fleet users",
+		Flags: []cli.Flag{
+			jsonFlag(),
+			yamlFlag(),
+			configFlag(),
+			contextFlag(),
+		},
+		Action: func(c *cli.Context) error {
+			fleet, err := clientFromCLI(c)
+			if err != nil {
+				return err
+			}
+
+			roles, err := fleet.GetUserRolesSpec()
+			if err != nil {
+				return err
+			}
+
+			err = printUserRoles(c, roles)
+			if err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+}
+
 func getAppConfigCommand() cli.Command {
 	return cli.Command{
 		Name:  "config",
@@ -564,18 +657,49 @@ func getHostsCommand() cli.Command {
 		Aliases: []string{"host", "h"},
 		Usage:   "List information about one or more hosts",
 		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "filter",
+				Value: "",
+				Usage: `Only list hosts matching the filter (eg. "platform:darwin status:online osquery_version<4.2 label:Production")`,
+			},
+			cli.StringFlag{
+				Name:  "format",
+				Value: "",
+				Usage: "Export the hosts in the given format (csv or ndjson) rather than listing them",
+			},
+			cli.StringFlag{
+				Name:  "columns",
+				Value: "",
+				Usage: "Comma separated columns to include in the export (defaults to the server's column set)",
+			},
+			cli.StringFlag{
+				Name:  "output",
+				Value: "",
+				Usage: "File to write the export to (required with --format)",
+			},
 			jsonFlag(),
 			yamlFlag(),
 			configFlag(),
 			contextFlag(),
 		},
 		Action: func(c *cli.Context) error {
+			filter := c.String("filter")
+			// Validate the filter locally for a more helpful error
+			// before contacting the server
+			if _, err := kolide.ParseHostFilter(filter); err != nil {
+				return err
+			}
+
 			fleet, err := clientFromCLI(c)
 			if err != nil {
 				return err
 			}
 
-			hosts, err := fleet.GetHosts()
+			if format := c.String("format"); format != "" {
+				return exportHosts(fleet, format, c.String("columns"), filter, c.String("output"))
+			}
+
+			hosts, err := fleet.GetHosts(filter)
 			if err != nil {
 				return errors.Wrap(err, "could not list hosts")
 			}
@@ -616,3 +740,372 @@ func getHostsCommand() cli.Command {
 		},
 	}
 }
+
+// exportHosts streams the host export to the output file. The file is removed
+// if the export fails so that a truncated export is not mistaken for a
+// complete one.
+func exportHosts(fleet *service.Client, format, columns, filter, output string) error {
+	if output == "" {
+		return errors.New("--output must be specified with --format")
+	}
+	var exportColumns []string
+	if columns != "" {
+		exportColumns = strings.Split(columns, ",")
+	}
+
+	f, err := os.Create(output)
+	if err != nil {
+		return errors.Wrap(err, "create export file")
+	}
+	err = fleet.ExportHosts(format, exportColumns, filter, f)
+	if closeErr := f.Close(); err == nil && closeErr != nil {
+		err = errors.Wrap(closeErr, "close export file")
+	}
+	if err != nil {
+		os.Remove(output)
+		return errors.Wrap(err, "could not export hosts")
+	}
+
+	fmt.Printf("[+] exported hosts to %s\n", output)
+	return nil
+}
+
+func getCampaignsCommand() cli.Command {
+	return cli.Command{
+		Name:    "campaigns",
+		Aliases: []string{"campaign"},
+		Usage:   "List the live query campaigns that have been run, most recent first",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "status",
+				Value: "",
+				Usage: "Only list campaigns with the given status (waiting, running or complete)",
+			},
+			cli.UintFlag{
+				Name:  "user-id",
+				Value: 0,
+				Usage: "Only list campaigns run by the user with the given ID",
+			},
+			cli.UintFlag{
+				Name:  "limit",
+				Value: 100,
+				Usage: "Maximum number of campaigns to list",
+			},
+			jsonFlag(),
+			yamlFlag(),
+			configFlag(),
+			contextFlag(),
+		},
+		Action: func(c *cli.Context) error {
+			var filter kolide.DistributedQueryCampaignFilter
+			if name := c.String("status"); name != "" {
+				status, err := kolide.ParseDistributedQueryStatus(name)
+				if err != nil {
+					return err
+				}
+				filter.Status = &status
+			}
+			if userID := c.Uint("user-id"); userID != 0 {
+				filter.UserID = &userID
+			}
+
+			fleet, err := clientFromCLI(c)
+			if err != nil {
+				return err
+			}
+
+			campaigns, err := fleet.GetCampaigns(filter, 0, c.Uint("limit"))
+			if err != nil {
+				return errors.Wrap(err, "could not list campaigns")
+			}
+
+			if len(campaigns) == 0 {
+				fmt.Println("no campaigns found")
+				return nil
+			}
+
+			if c.Bool(jsonFlagName) || c.Bool(yamlFlagName) {
+				for _, campaign := range campaigns {
+					if err := printCampaign(c, campaign); err != nil {
+						return errors.Wrap(err, "unable to print campaign")
+					}
+				}
+				return nil
+			}
+
+			// Default to printing as a table
+			data := [][]string{}
+
+			for _, campaign := range campaigns {
+				var targets []string
+				targets = append(targets, campaign.Hosts...)
+				for _, label := range campaign.Labels {
+					targets = append(targets, "label:"+label)
+				}
+				for _, tag := range campaign.Tags {
+					targets = append(targets, "tag:"+tag)
+				}
+				status := campaign.Status.String()
+				if campaign.Deferred {
+					status += " (deferred)"
+				}
+				data = append(data, []string{
+					strconv.FormatUint(uint64(campaign.ID), 10),
+					campaign.CreatedAt.Format(time.RFC3339),
+					campaign.Username,
+					campaign.Query,
+					strings.Join(targets, ", "),
+					status,
+					fmt.Sprintf("%d/%d", campaign.ReceivedResults, campaign.ExpectedResults),
+				})
+			}
+
+			table := defaultTable()
+			table.SetHeader([]string{"id", "created", "user", "query", "targets", "status", "results"})
+			table.AppendBulk(data)
+			table.Render()
+
+			return nil
+		},
+	}
+}
+
+// campaignResultsPageSize is the number of results retrieved per request by
+// fleetctl get campaign-results.
+const campaignResultsPageSize = 100
+
+func getCampaignResultsCommand() cli.Command {
+	return cli.Command{
+		Name:      "campaign-results",
+		Aliases:   []string{"campaign_results"},
+		Usage:     "Retrieve the stored results of a live query run with --persist",
+		UsageText: `fleetctl get campaign-results <campaign id>`,
+		Flags: []cli.Flag{
+			configFlag(),
+			contextFlag(),
+		},
+		Action: func(c *cli.Context) error {
+			campaignID, err := strconv.ParseUint(c.Args().First(), 10, 32)
+			if err != nil || campaignID == 0 {
+				return errors.New("a campaign ID must be provided")
+			}
+
+			fleet, err := clientFromCLI(c)
+			if err != nil {
+				return err
+			}
+
+			enc := json.NewEncoder(os.Stdout)
+			for page := uint(0); ; page++ {
+				results, truncated, err := fleet.GetCampaignResults(uint(campaignID), page, campaignResultsPageSize)
+				if err != nil {
+					switch err.(type) {
+					case service.NotFoundErr:
+						return errors.Errorf("campaign %d not found", campaignID)
+					}
+					return errors.Wrap(err, "could not get campaign results")
+				}
+
+				for _, result := range results {
+					out := resultOutput{result.HostName, result.Rows, result.Error}
+					if err := enc.Encode(out); err != nil {
+						return errors.Wrap(err, "write result")
+					}
+				}
+
+				if len(results) < campaignResultsPageSize {
+					if truncated {
+						fmt.Fprintf(os.Stderr, "Warning: campaign %d reached the stored rows limit, results of some hosts were not stored\n", campaignID)
+					}
+					return nil
+				}
+			}
+		},
+	}
+}
+
+func getCampaignHostsCommand() cli.Command {
+	return cli.Command{
+		Name:      "campaign-hosts",
+		Aliases:   []string{"campaign_hosts"},
+		Usage:     "List the targeted hosts of a live query campaign and the state of the query on each",
+		UsageText: `fleetctl get campaign-hosts <campaign id>`,
+		Flags: []cli.Flag{
+			configFlag(),
+			contextFlag(),
+		},
+		Action: func(c *cli.Context) error {
+			campaignID, err := strconv.ParseUint(c.Args().First(), 10, 32)
+			if err != nil || campaignID == 0 {
+				return errors.New("a campaign ID must be provided")
+			}
+
+			fleet, err := clientFromCLI(c)
+			if err != nil {
+				return err
+			}
+
+			data := [][]string{}
+			for page := uint(0); ; page++ {
+				hosts, err := fleet.GetCampaignHosts(uint(campaignID), page, campaignResultsPageSize)
+				if err != nil {
+					switch err.(type) {
+					case service.NotFoundErr:
+						return errors.Errorf("campaign %d not found", campaignID)
+					}
+					return errors.Wrap(err, "could not get campaign hosts")
+				}
+
+				for _, host := range hosts {
+					duration := ""
+					if host.ExecutionDuration > 0 {
+						duration = host.ExecutionDuration.String()
+					}
+					data = append(data, []string{
+						host.HostName,
+						host.Status.String(),
+						host.Error,
+						duration,
+					})
+				}
+
+				if len(hosts) < campaignResultsPageSize {
+					break
+				}
+			}
+
+			if len(data) == 0 {
+				fmt.Println("no hosts found")
+				return nil
+			}
+
+			table := defaultTable()
+			table.SetHeader([]string{"hostname", "status", "error", "duration"})
+			table.AppendBulk(data)
+			table.Render()
+
+			return nil
+		},
+	}
+}
+
+func getActivitiesCommand() cli.Command {
+	return cli.Command{
+		Name:    "activities",
+		Aliases: []string{"activity"},
+		Usage:   "List the administrative actions performed in Fleet, most recent first",
+		Flags: []cli.Flag{
+			cli.UintFlag{
+				Name:  "actor-id",
+				Value: 0,
+				Usage: "Only list activities performed by the user with the given ID",
+			},
+			cli.StringFlag{
+				Name:  "type",
+				Value: "",
+				Usage: "Only list activities of the given type (eg. deleted_host)",
+			},
+			cli.StringFlag{
+				Name:  "target-type",
+				Value: "",
+				Usage: "Only list activities on objects of the given type (eg. pack)",
+			},
+			cli.UintFlag{
+				Name:  "target-id",
+				Value: 0,
+				Usage: "Only list activities on the object with the given ID",
+			},
+			cli.DurationFlag{
+				Name:  "since",
+				Value: 0,
+				Usage: "Only list activities performed within the given duration (eg. 24h)",
+			},
+			cli.UintFlag{
+				Name:  "limit",
+				Value: 100,
+				Usage: "Maximum number of activities to list",
+			},
+			jsonFlag(),
+			yamlFlag(),
+			configFlag(),
+			contextFlag(),
+		},
+		Action: func(c *cli.Context) error {
+			var filter kolide.ActivityFilter
+			if actorID := c.Uint("actor-id"); actorID != 0 {
+				filter.ActorID = &actorID
+			}
+			if activityType := c.String("type"); activityType != "" {
+				filter.Type = &activityType
+			}
+			if targetType := c.String("target-type"); targetType != "" {
+				filter.TargetType = &targetType
+			}
+			if targetID := c.Uint("target-id"); targetID != 0 {
+				filter.TargetID = &targetID
+			}
+			if since := c.Duration("since"); since != 0 {
+				t := time.Now().Add(-since)
+				filter.Since = &t
+			}
+
+			fleet, err := clientFromCLI(c)
+			if err != nil {
+				return err
+			}
+
+			activities, err := fleet.GetActivities(filter, 0, c.Uint("limit"))
+			if err != nil {
+				return errors.Wrap(err, "could not list activities")
+			}
+
+			if len(activities) == 0 {
+				fmt.Println("no activities found")
+				return nil
+			}
+
+			if c.Bool(jsonFlagName) || c.Bool(yamlFlagName) {
+				for _, activity := range activities {
+					if err := printActivity(c, activity); err != nil {
+						return errors.Wrap(err, "unable to print activity")
+					}
+				}
+				return nil
+			}
+
+			// Default to printing as a table
+			data := [][]string{}
+
+			for _, activity := range activities {
+				target := activity.TargetType
+				if activity.TargetName != "" {
+					target += " " + activity.TargetName
+				} else if activity.TargetID != nil {
+					target += " " + strconv.FormatUint(uint64(*activity.TargetID), 10)
+				}
+				actor := activity.ActorName
+				if activity.ActorID == nil {
+					actor = "fleet"
+				}
+				details := ""
+				if activity.Details != nil {
+					details = string(*activity.Details)
+				}
+				data = append(data, []string{
+					activity.CreatedAt.Format(time.RFC3339),
+					actor,
+					activity.Type,
+					target,
+					details,
+				})
+			}
+
+			table := defaultTable()
+			table.SetHeader([]string{"time", "actor", "type", "target", "details"})
+			table.AppendBulk(data)
+			table.Render()
+
+			return nil
+		},
+	}
+}
@@ -76,7 +76,7 @@ func (c *goqueryClient) ScheduleQuery(uuid, query string) (string, error) {
 		return "", errors.New("could not lookup host")
 	}
 
-	res, err := c.client.LiveQuery(query, []string{}, []string{hostname})
+	res, err := c.client.LiveQuery(query, []string{}, []string{hostname}, nil, kolide.CampaignOptions{})
 	if err != nil {
 		return "", err
 	}
@@ -94,6 +94,9 @@ func (c *goqueryClient) ScheduleQuery(uuid, query string) (string, error) {
 			// Print an error
 		case err := <-res.Errors():
 			c.queries[queryName] = activeQuery{status: "error: " + err.Error()}
+
+		case <-res.Done():
+			c.queries[queryName] = activeQuery{status: "error: stream closed by server"}
 		}
 	}()
 
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/kolide/fleet/server/service"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+func hostsCommand() cli.Command {
+	return cli.Command{
+		Name:  "hosts",
+		Usage: "Manage Fleet hosts",
+		Subcommands: []cli.Command{
+			mergeHostsCommand(),
+		},
+	}
+}
+
+func mergeHostsCommand() cli.Command {
+	var (
+		flSurvivor  uint
+		flDuplicate uint
+	)
+	return cli.Command{
+		Name:      "merge",
+		Usage:     "Fold a duplicate host into the surviving host",
+		UsageText: `fleetctl hosts merge --survivor <id> --duplicate <id>`,
+		Flags: []cli.Flag{
+			cli.UintFlag{
+				Name:        "survivor",
+				Destination: &flSurvivor,
+				Usage:       "ID of the host to keep",
+			},
+			cli.UintFlag{
+				Name:        "duplicate",
+				Destination: &flDuplicate,
+				Usage:       "ID of the duplicate host to merge and delete",
+			},
+			configFlag(),
+			contextFlag(),
+		},
+		Action: func(c *cli.Context) error {
+			if flSurvivor == 0 || flDuplicate == 0 {
+				return errors.New("--survivor and --duplicate must be specified")
+			}
+
+			fleet, err := clientFromCLI(c)
+			if err != nil {
+				return err
+			}
+
+			host, err := fleet.MergeHosts(flSurvivor, flDuplicate)
+			if err != nil {
+				switch err.(type) {
+				case service.NotFoundErr:
+					return errors.New("survivor or duplicate host not found")
+				}
+				return errors.Wrap(err, "could not merge hosts")
+			}
+
+			fmt.Printf("[+] merged host %d into host %d (%s)\n", flDuplicate, host.ID, host.HostName)
+			return nil
+		},
+	}
+}
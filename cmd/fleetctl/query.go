@@ -1,27 +1,32 @@
 package main
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"strings"
 	"time"
 
 	"github.com/briandowns/spinner"
+	"github.com/kolide/fleet/server/kolide"
 	"github.com/urfave/cli"
 )
 
 type resultOutput struct {
 	HostIdentifier string              `json:"host"`
 	Rows           []map[string]string `json:"rows"`
+	Error          *string             `json:"error,omitempty"`
 }
 
 func queryCommand() cli.Command {
 	var (
-		flHosts, flLabels, flQuery, flQueryName string
-		flDebug, flQuiet, flExit                bool
-		flTimeout                               time.Duration
+		flHosts, flLabels, flTags, flQuery, flQueryName string
+		flFormat, flGroupBy, flOutput                   string
+		flDebug, flQuiet, flExit, flPersist, flDefer    bool
+		flFlatten, flCount                              bool
+		flTTLDays                                       uint
+		flTimeout                                       time.Duration
 	)
 	return cli.Command{
 		Name:      "query",
@@ -44,6 +49,13 @@ func queryCommand() cli.Command {
 				Destination: &flLabels,
 				Usage:       "Comma separated label names to target",
 			},
+			cli.StringFlag{
+				Name:        "tags",
+				EnvVar:      "TAGS",
+				Value:       "",
+				Destination: &flTags,
+				Usage:       "Comma separated host tags (key=value) to target",
+			},
 			cli.BoolFlag{
 				Name:        "quiet",
 				EnvVar:      "QUIET",
@@ -70,6 +82,24 @@ func queryCommand() cli.Command {
 				Destination: &flQueryName,
 				Usage:       "Name of saved query to run",
 			},
+			cli.BoolFlag{
+				Name:        "persist",
+				EnvVar:      "PERSIST",
+				Destination: &flPersist,
+				Usage:       "Store the results on the server (retrieve them later with fleetctl get campaign-results)",
+			},
+			cli.BoolFlag{
+				Name:        "defer",
+				EnvVar:      "DEFER",
+				Destination: &flDefer,
+				Usage:       "Keep running the query on targeted hosts as they check in, after this command exits (implies --persist)",
+			},
+			cli.UintFlag{
+				Name:        "ttl-days",
+				EnvVar:      "TTL_DAYS",
+				Destination: &flTTLDays,
+				Usage:       "Number of days a deferred query waits for hosts to check in (defaults to the server setting)",
+			},
 			cli.BoolFlag{
 				Name:        "debug",
 				EnvVar:      "DEBUG",
@@ -82,6 +112,39 @@ func queryCommand() cli.Command {
 				Destination: &flTimeout,
 				Usage:       "How long to run query before exiting (10s, 1h, etc.)",
 			},
+			cli.StringFlag{
+				Name:        "format",
+				EnvVar:      "FORMAT",
+				Value:       queryFormatJSONL,
+				Destination: &flFormat,
+				Usage:       "Output format (jsonl, table or csv). Table and csv output one line per row",
+			},
+			cli.BoolFlag{
+				Name:        "flatten",
+				EnvVar:      "FLATTEN",
+				Destination: &flFlatten,
+				Usage:       "Output one line per row, with the hostname in the host column (_host if the query returns a host column)",
+			},
+			cli.StringFlag{
+				Name:        "group-by",
+				EnvVar:      "GROUP_BY",
+				Value:       "",
+				Destination: &flGroupBy,
+				Usage:       "Comma separated columns to group rows by when counting them (host groups by hostname)",
+			},
+			cli.BoolFlag{
+				Name:        "count",
+				EnvVar:      "COUNT",
+				Destination: &flCount,
+				Usage:       "Output the number of rows returned by all hosts (per group with --group-by) when the query stops",
+			},
+			cli.StringFlag{
+				Name:        "output",
+				EnvVar:      "OUTPUT",
+				Value:       "",
+				Destination: &flOutput,
+				Usage:       "File to write the results to, instead of stdout",
+			},
 		},
 		Action: func(c *cli.Context) error {
 			fleet, err := clientFromCLI(c)
@@ -89,8 +152,8 @@ func queryCommand() cli.Command {
 				return err
 			}
 
-			if flHosts == "" && flLabels == "" {
-				return errors.New("No hosts or labels targeted")
+			if flHosts == "" && flLabels == "" && flTags == "" {
+				return errors.New("No hosts, labels or tags targeted")
 			}
 
 			if flQuery != "" && flQueryName != "" {
@@ -111,11 +174,54 @@ func queryCommand() cli.Command {
 
 			hosts := strings.Split(flHosts, ",")
 			labels := strings.Split(flLabels, ",")
+			var tags []string
+			if flTags != "" {
+				tags = strings.Split(flTags, ",")
+			}
+
+			if flTTLDays > 0 && !flDefer {
+				return errors.New("--ttl-days can only be used with --defer")
+			}
+
+			var groupBy []string
+			if flGroupBy != "" {
+				groupBy = strings.Split(flGroupBy, ",")
+			}
+
+			out := os.Stdout
+			if flOutput != "" {
+				f, err := os.Create(flOutput)
+				if err != nil {
+					return fmt.Errorf("creating output file: %s", err)
+				}
+				defer f.Close()
+				out = f
+			}
+
+			output, err := newQueryResultWriter(out, os.Stderr, flFormat, flFlatten, groupBy, flCount)
+			if err != nil {
+				return err
+			}
 
-			res, err := fleet.LiveQuery(flQuery, labels, hosts)
+			opts := kolide.CampaignOptions{
+				PersistResults: flPersist,
+				Deferred:       flDefer,
+				TTLDays:        flTTLDays,
+			}
+			res, err := fleet.LiveQuery(flQuery, labels, hosts, tags, opts)
 			if err != nil {
 				return err
 			}
+			if !flQuiet {
+				for _, w := range res.Warnings() {
+					fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+				}
+				if flDefer {
+					fmt.Fprintf(os.Stderr, "Deferred campaign %d will run as hosts check in (retrieve results with fleetctl get campaign-results %d)\n", res.CampaignID(), res.CampaignID())
+				} else if flPersist {
+					fmt.Fprintf(os.Stderr, "Persisting results of campaign %d\n", res.CampaignID())
+				}
+			}
 
 			tick := time.NewTicker(100 * time.Millisecond)
 			defer tick.Stop()
@@ -138,13 +244,18 @@ func queryCommand() cli.Command {
 				timeoutChan = make(chan time.Time)
 			}
 
+			// Stop on interrupt, so that the results aggregated by the
+			// output (e.g. with --count) are still written
+			interrupt := make(chan os.Signal, 1)
+			signal.Notify(interrupt, os.Interrupt)
+			defer signal.Stop(interrupt)
+
 			for {
 				select {
 				// Print a result
 				case hostResult := <-res.Results():
-					out := resultOutput{hostResult.Host.HostName, hostResult.Rows}
 					s.Stop()
-					if err := json.NewEncoder(os.Stdout).Encode(out); err != nil {
+					if err := output.WriteResult(hostResult); err != nil {
 						fmt.Fprintf(os.Stderr, "Error writing output: %s\n", err)
 					}
 					s.Start()
@@ -172,7 +283,8 @@ func queryCommand() cli.Command {
 					}
 
 					if responded >= online && flExit {
-						return nil
+						s.Stop()
+						return output.Close()
 					}
 
 					msg := fmt.Sprintf(" %.f%% responded (%.f%% online) | %d/%d targeted hosts (%d/%d online)", percentTotal, percentOnline, responded, total, responded, online)
@@ -184,7 +296,7 @@ func queryCommand() cli.Command {
 						if !flQuiet {
 							fmt.Fprintln(os.Stderr, msg)
 						}
-						return nil
+						return output.Close()
 					}
 
 				// Check for timeout expiring
@@ -193,7 +305,22 @@ func queryCommand() cli.Command {
 					if !flQuiet {
 						fmt.Fprintln(os.Stderr, s.Suffix+"\nStopped by timeout")
 					}
-					return nil
+					return output.Close()
+
+				case <-interrupt:
+					s.Stop()
+					if !flQuiet {
+						fmt.Fprintln(os.Stderr, s.Suffix+"\nStopped by interrupt")
+					}
+					return output.Close()
+
+				// No more results once the server closed the stream
+				case <-res.Done():
+					s.Stop()
+					if !flQuiet {
+						fmt.Fprintln(os.Stderr, s.Suffix+"\nStream closed by server")
+					}
+					return output.Close()
 				}
 			}
 		},
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+)
+
+const (
+	queryFormatJSONL = "jsonl"
+	queryFormatTable = "table"
+	queryFormatCSV   = "csv"
+
+	hostColumn  = "host"
+	countColumn = "count"
+)
+
+// queryResultWriter writes the results of a live query as they are received
+// from hosts.
+type queryResultWriter interface {
+	WriteResult(res kolide.DistributedQueryResult) error
+	// Close writes any buffered output, it must be called once the query
+	// has stopped.
+	Close() error
+}
+
+// newQueryResultWriter returns the writer for the provided output options.
+// Host errors are written to errOut when the results are flattened or
+// aggregated.
+func newQueryResultWriter(out, errOut io.Writer, format string, flatten bool, groupBy []string, count bool) (queryResultWriter, error) {
+	if len(groupBy) > 0 && !count {
+		return nil, errors.New("--group-by must be used with --count")
+	}
+
+	if format == queryFormatJSONL && !flatten && !count {
+		return &hostResultWriter{enc: json.NewEncoder(out)}, nil
+	}
+
+	if count {
+		columns := append(append([]string{}, groupBy...), countColumn)
+		rows, err := newRowWriter(out, format, columns)
+		if err != nil {
+			return nil, err
+		}
+		return &aggregateResultWriter{
+			rows:    rows,
+			errOut:  errOut,
+			groupBy: groupBy,
+			counts:  map[string]*rowGroup{},
+		}, nil
+	}
+
+	rows, err := newRowWriter(out, format, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &flatResultWriter{rows: rows, errOut: errOut}, nil
+}
+
+// hostResultWriter writes a JSON object per host.
+type hostResultWriter struct {
+	enc *json.Encoder
+}
+
+func (w *hostResultWriter) WriteResult(res kolide.DistributedQueryResult) error {
+	return w.enc.Encode(resultOutput{res.Host.HostName, res.Rows, res.Error})
+}
+
+func (w *hostResultWriter) Close() error {
+	return nil
+}
+
+// flatResultWriter writes a line per result row, with the hostname in the
+// host column (see flatHostColumn).
+type flatResultWriter struct {
+	rows   rowWriter
+	errOut io.Writer
+}
+
+func (w *flatResultWriter) WriteResult(res kolide.DistributedQueryResult) error {
+	if res.Error != nil {
+		fmt.Fprintf(w.errOut, "Error on host %s: %s\n", res.Host.HostName, *res.Error)
+	}
+	for _, row := range res.Rows {
+		flat := make(map[string]string, len(row)+1)
+		for k, v := range row {
+			flat[k] = v
+		}
+		flat[flatHostColumn(row)] = res.Host.HostName
+		if err := w.rows.WriteRow(flat); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *flatResultWriter) Close() error {
+	return w.rows.Close()
+}
+
+// flatHostColumn returns the column the hostname is added to in a flattened
+// row. It is prefixed with underscores when the row already has a host
+// column, so that the result values are kept.
+func flatHostColumn(row map[string]string) string {
+	col := hostColumn
+	for {
+		if _, ok := row[col]; !ok {
+			return col
+		}
+		col = "_" + col
+	}
+}
+
+type rowGroup struct {
+	values []string
+	count  int
+}
+
+// aggregateResultWriter counts the result rows of all hosts, grouped by the
+// values of the groupBy columns. The counts are written when it is closed.
+type aggregateResultWriter struct {
+	rows    rowWriter
+	errOut  io.Writer
+	groupBy []string
+	counts  map[string]*rowGroup
+}
+
+func (w *aggregateResultWriter) WriteResult(res kolide.DistributedQueryResult) error {
+	if res.Error != nil {
+		fmt.Fprintf(w.errOut, "Error on host %s: %s\n", res.Host.HostName, *res.Error)
+	}
+	for _, row := range res.Rows {
+		values := make([]string, len(w.groupBy))
+		for i, col := range w.groupBy {
+			if col == hostColumn {
+				values[i] = res.Host.HostName
+			} else {
+				values[i] = row[col]
+			}
+		}
+		// Null bytes cannot appear in osquery results, so they separate
+		// the values unambiguously
+		key := strings.Join(values, "\x00")
+		group, ok := w.counts[key]
+		if !ok {
+			group = &rowGroup{values: values}
+			w.counts[key] = group
+		}
+		group.count++
+	}
+	return nil
+}
+
+func (w *aggregateResultWriter) Close() error {
+	var groups []*rowGroup
+	for _, group := range w.counts {
+		groups = append(groups, group)
+	}
+	// Most frequent groups first
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].count != groups[j].count {
+			return groups[i].count > groups[j].count
+		}
+		return strings.Join(groups[i].values, "\x00") < strings.Join(groups[j].values, "\x00")
+	})
+	if len(w.groupBy) == 0 && len(groups) == 0 {
+		groups = append(groups, &rowGroup{})
+	}
+
+	for _, group := range groups {
+		row := map[string]string{countColumn: strconv.Itoa(group.count)}
+		for i, col := range w.groupBy {
+			row[col] = group.values[i]
+		}
+		if err := w.rows.WriteRow(row); err != nil {
+			return err
+		}
+	}
+	return w.rows.Close()
+}
+
+// rowWriter writes rows in one of the query output formats.
+type rowWriter interface {
+	WriteRow(row map[string]string) error
+	Close() error
+}
+
+// newRowWriter returns a rowWriter for the format. When columns is nil, they
+// are determined from the rows written.
+func newRowWriter(out io.Writer, format string, columns []string) (rowWriter, error) {
+	switch format {
+	case queryFormatJSONL:
+		return &jsonRowWriter{enc: json.NewEncoder(out)}, nil
+	case queryFormatCSV:
+		return &csvRowWriter{w: csv.NewWriter(out), columns: columns}, nil
+	case queryFormatTable:
+		return &tableRowWriter{out: out, columns: columns}, nil
+	default:
+		return nil, errors.Errorf("unknown format %q, must be one of %s, %s or %s",
+			format, queryFormatJSONL, queryFormatTable, queryFormatCSV)
+	}
+}
+
+// rowColumns returns the columns of the rows, sorted with the host column
+// first.
+func rowColumns(rows ...map[string]string) []string {
+	seen := map[string]bool{}
+	var columns []string
+	for _, row := range rows {
+		for col := range row {
+			if !seen[col] {
+				seen[col] = true
+				columns = append(columns, col)
+			}
+		}
+	}
+	sort.Slice(columns, func(i, j int) bool {
+		if columns[i] == hostColumn || columns[j] == hostColumn {
+			return columns[i] == hostColumn
+		}
+		return columns[i] < columns[j]
+	})
+	return columns
+}
+
+type jsonRowWriter struct {
+	enc *json.Encoder
+}
+
+func (w *jsonRowWriter) WriteRow(row map[string]string) error {
+	return w.enc.Encode(row)
+}
+
+func (w *jsonRowWriter) Close() error {
+	return nil
+}
+
+// csvRowWriter streams rows as CSV. The header is written with the first row,
+// and columns that are not in the header are dropped from later rows.
+type csvRowWriter struct {
+	w       *csv.Writer
+	columns []string
+	started bool
+}
+
+func (w *csvRowWriter) WriteRow(row map[string]string) error {
+	if !w.started {
+		if w.columns == nil {
+			w.columns = rowColumns(row)
+		}
+		if err := w.w.Write(w.columns); err != nil {
+			return err
+		}
+		w.started = true
+	}
+
+	record := make([]string, len(w.columns))
+	for i, col := range w.columns {
+		record[i] = row[col]
+	}
+	if err := w.w.Write(record); err != nil {
+		return err
+	}
+	// Flush each row so that results are visible as they stream
+	w.w.Flush()
+	return w.w.Error()
+}
+
+func (w *csvRowWriter) Close() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// tableRowWriter buffers the rows, so that the table can be rendered with the
+// columns of every row once the query has stopped.
+type tableRowWriter struct {
+	out     io.Writer
+	columns []string
+	rows    []map[string]string
+}
+
+func (w *tableRowWriter) WriteRow(row map[string]string) error {
+	w.rows = append(w.rows, row)
+	return nil
+}
+
+func (w *tableRowWriter) Close() error {
+	columns := w.columns
+	if columns == nil {
+		columns = rowColumns(w.rows...)
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+
+	table := tablewriter.NewWriter(w.out)
+	table.SetAutoFormatHeaders(false)
+	table.SetHeader(columns)
+	for _, row := range w.rows {
+		record := make([]string, len(columns))
+		for i, col := range columns {
+			record[i] = row[col]
+		}
+		table.Append(record)
+	}
+	table.Render()
+	return nil
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryResultWriter(t *testing.T) {
+	failed := "no such table: foo"
+	results := []kolide.DistributedQueryResult{
+		{
+			Host: kolide.Host{HostName: "foo.local"},
+			Rows: []map[string]string{
+				{"name": "osqueryd", "version": "4.3.0"},
+				{"name": "fleet", "version": "2.6.0"},
+			},
+		},
+		{
+			Host:  kolide.Host{HostName: "bar.local"},
+			Rows:  []map[string]string{},
+			Error: &failed,
+		},
+		{
+			Host: kolide.Host{HostName: "baz.local"},
+			Rows: []map[string]string{
+				{"name": "osqueryd", "version": "4.3.0"},
+			},
+		},
+	}
+
+	var writerTests = []struct {
+		name    string
+		format  string
+		flatten bool
+		groupBy []string
+		count   bool
+		results []kolide.DistributedQueryResult
+		wantOut string
+		wantErr string
+		// Errors are only written separately when the results are
+		// flattened or aggregated
+		wantErrOut string
+	}{
+		{
+			name:   "jsonl",
+			format: queryFormatJSONL,
+			wantOut: `{"host":"foo.local","rows":[{"name":"osqueryd","version":"4.3.0"},{"name":"fleet","version":"2.6.0"}]}
+{"host":"bar.local","rows":[],"error":"no such table: foo"}
+{"host":"baz.local","rows":[{"name":"osqueryd","version":"4.3.0"}]}
+`,
+		},
+		{
+			name:    "jsonl flatten",
+			format:  queryFormatJSONL,
+			flatten: true,
+			wantOut: `{"host":"foo.local","name":"osqueryd","version":"4.3.0"}
+{"host":"foo.local","name":"fleet","version":"2.6.0"}
+{"host":"baz.local","name":"osqueryd","version":"4.3.0"}
+`,
+			wantErrOut: "Error on host bar.local: no such table: foo\n",
+		},
+		{
+			name:   "csv",
+			format: queryFormatCSV,
+			wantOut: `host,name,version
+foo.local,osqueryd,4.3.0
+foo.local,fleet,2.6.0
+baz.local,osqueryd,4.3.0
+`,
+			wantErrOut: "Error on host bar.local: no such table: foo\n",
+		},
+		{
+			name:   "table",
+			format: queryFormatTable,
+			wantOut: `+-----------+----------+---------+
+|   host    |   name   | version |
++-----------+----------+---------+
+| foo.local | osqueryd | 4.3.0   |
+| foo.local | fleet    | 2.6.0   |
+| baz.local | osqueryd | 4.3.0   |
++-----------+----------+---------+
+`,
+			wantErrOut: "Error on host bar.local: no such table: foo\n",
+		},
+		{
+			name:    "flatten keeps host result column",
+			format:  queryFormatJSONL,
+			flatten: true,
+			results: []kolide.DistributedQueryResult{
+				{
+					Host: kolide.Host{HostName: "foo.local"},
+					Rows: []map[string]string{{"host": "db.internal", "port": "5432"}},
+				},
+			},
+			wantOut: `{"_host":"foo.local","host":"db.internal","port":"5432"}
+`,
+		},
+		{
+			name:   "count",
+			format: queryFormatCSV,
+			count:  true,
+			wantOut: `count
+3
+`,
+			wantErrOut: "Error on host bar.local: no such table: foo\n",
+		},
+		{
+			name:    "count without results",
+			format:  queryFormatCSV,
+			count:   true,
+			results: []kolide.DistributedQueryResult{},
+			wantOut: `count
+0
+`,
+		},
+		{
+			name:    "count group by",
+			format:  queryFormatJSONL,
+			count:   true,
+			groupBy: []string{"name", "version"},
+			wantOut: `{"count":"2","name":"osqueryd","version":"4.3.0"}
+{"count":"1","name":"fleet","version":"2.6.0"}
+`,
+			wantErrOut: "Error on host bar.local: no such table: foo\n",
+		},
+		{
+			name:    "count group by host",
+			format:  queryFormatTable,
+			count:   true,
+			groupBy: []string{"host"},
+			wantOut: `+-----------+-------+
+|   host    | count |
++-----------+-------+
+| foo.local |     2 |
+| baz.local |     1 |
++-----------+-------+
+`,
+			wantErrOut: "Error on host bar.local: no such table: foo\n",
+		},
+		{
+			name:    "group by without count",
+			format:  queryFormatJSONL,
+			groupBy: []string{"name"},
+			wantErr: "--group-by must be used with --count",
+		},
+		{
+			name:    "unknown format",
+			format:  "xml",
+			wantErr: `unknown format "xml", must be one of jsonl, table or csv`,
+		},
+	}
+
+	for _, tt := range writerTests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out, errOut bytes.Buffer
+			w, err := newQueryResultWriter(&out, &errOut, tt.format, tt.flatten, tt.groupBy, tt.count)
+			if tt.wantErr != "" {
+				require.NotNil(t, err)
+				assert.Equal(t, tt.wantErr, err.Error())
+				return
+			}
+			require.Nil(t, err)
+
+			res := tt.results
+			if res == nil {
+				res = results
+			}
+			for _, r := range res {
+				require.Nil(t, w.WriteResult(r))
+			}
+			require.Nil(t, w.Close())
+
+			assert.Equal(t, tt.wantOut, out.String())
+			assert.Equal(t, tt.wantErrOut, errOut.String())
+		})
+	}
+}
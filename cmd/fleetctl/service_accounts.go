@@ -0,0 +1,260 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/service"
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+func serviceAccountsCommand() cli.Command {
+	return cli.Command{
+		Name:  "service_accounts",
+		Usage: "Manage service accounts and their API tokens",
+		Subcommands: []cli.Command{
+			createServiceAccountCommand(),
+			createAPITokenCommand(),
+			listAPITokensCommand(),
+			deleteAPITokenCommand(),
+		},
+	}
+}
+
+func createServiceAccountCommand() cli.Command {
+	var (
+		flUsername string
+		flName     string
+		flEmail    string
+		flRole     string
+	)
+	return cli.Command{
+		Name:      "create",
+		Usage:     "Create a service account",
+		UsageText: `fleetctl service_accounts create --username <username> --email <email> [--role <role>]`,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:        "username",
+				Destination: &flUsername,
+				Usage:       "Username of the service account",
+			},
+			cli.StringFlag{
+				Name:        "name",
+				Destination: &flName,
+				Usage:       "Full name of the service account",
+			},
+			cli.StringFlag{
+				Name:        "email",
+				Destination: &flEmail,
+				Usage:       "Email of the team responsible for the service account",
+			},
+			cli.StringFlag{
+				Name:        "role",
+				Value:       string(kolide.RoleObserver),
+				Destination: &flRole,
+				Usage:       "Role of the service account (observer, maintainer or admin)",
+			},
+			configFlag(),
+			contextFlag(),
+		},
+		Action: func(c *cli.Context) error {
+			if flUsername == "" || flEmail == "" {
+				return errors.New("--username and --email must be specified")
+			}
+
+			fleet, err := clientFromCLI(c)
+			if err != nil {
+				return err
+			}
+
+			role := kolide.Role(flRole)
+			user, err := fleet.CreateServiceAccount(kolide.ServiceAccountPayload{
+				Username: flUsername,
+				Name:     flName,
+				Email:    flEmail,
+				Role:     &role,
+			})
+			if err != nil {
+				return errors.Wrap(err, "could not create service account")
+			}
+
+			fmt.Printf("[+] created service account %s with ID %d\n", user.Username, user.ID)
+			return nil
+		},
+	}
+}
+
+func createAPITokenCommand() cli.Command {
+	var (
+		flUserID  uint
+		flName    string
+		flRole    string
+		flExpires time.Duration
+	)
+	return cli.Command{
+		Name:      "create_token",
+		Usage:     "Create an API token for a service account",
+		UsageText: `fleetctl service_accounts create_token --user-id <id> --name <name> [--role <role>] [--expires <duration>]`,
+		Flags: []cli.Flag{
+			cli.UintFlag{
+				Name:        "user-id",
+				Destination: &flUserID,
+				Usage:       "ID of the service account",
+			},
+			cli.StringFlag{
+				Name:        "name",
+				Destination: &flName,
+				Usage:       "Name of the token, unique for the service account",
+			},
+			cli.StringFlag{
+				Name:        "role",
+				Destination: &flRole,
+				Usage:       "Role of the token, which defaults to the role of the service account",
+			},
+			cli.DurationFlag{
+				Name:        "expires",
+				Destination: &flExpires,
+				Usage:       "Duration after which the token expires (default: never)",
+			},
+			configFlag(),
+			contextFlag(),
+		},
+		Action: func(c *cli.Context) error {
+			if flUserID == 0 || flName == "" {
+				return errors.New("--user-id and --name must be specified")
+			}
+
+			fleet, err := clientFromCLI(c)
+			if err != nil {
+				return err
+			}
+
+			payload := kolide.APITokenPayload{Name: flName}
+			if flRole != "" {
+				role := kolide.Role(flRole)
+				payload.Role = &role
+			}
+			if flExpires != 0 {
+				expiresAt := time.Now().Add(flExpires)
+				payload.ExpiresAt = &expiresAt
+			}
+			token, _, err := fleet.CreateAPIToken(flUserID, payload)
+			if err != nil {
+				switch err.(type) {
+				case service.NotFoundErr:
+					return errors.Errorf("service account %d not found", flUserID)
+				}
+				return errors.Wrap(err, "could not create api token")
+			}
+
+			fmt.Println("[+] created api token, it will not be shown again:")
+			fmt.Println(token)
+			return nil
+		},
+	}
+}
+
+func listAPITokensCommand() cli.Command {
+	var flUserID uint
+	return cli.Command{
+		Name:      "list_tokens",
+		Usage:     "List the API tokens of a service account",
+		UsageText: `fleetctl service_accounts list_tokens --user-id <id>`,
+		Flags: []cli.Flag{
+			cli.UintFlag{
+				Name:        "user-id",
+				Destination: &flUserID,
+				Usage:       "ID of the service account",
+			},
+			configFlag(),
+			contextFlag(),
+		},
+		Action: func(c *cli.Context) error {
+			if flUserID == 0 {
+				return errors.New("--user-id must be specified")
+			}
+
+			fleet, err := clientFromCLI(c)
+			if err != nil {
+				return err
+			}
+
+			tokens, err := fleet.ListAPITokens(flUserID)
+			if err != nil {
+				return errors.Wrap(err, "could not list api tokens")
+			}
+
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader([]string{"id", "name", "role", "expires", "last used"})
+			for _, t := range tokens {
+				table.Append([]string{
+					fmt.Sprint(t.ID),
+					t.Name,
+					string(t.Role),
+					formatOptionalTime(t.ExpiresAt),
+					formatOptionalTime(t.LastUsedAt),
+				})
+			}
+			table.Render()
+			return nil
+		},
+	}
+}
+
+func deleteAPITokenCommand() cli.Command {
+	var (
+		flUserID uint
+		flID     uint
+	)
+	return cli.Command{
+		Name:      "delete_token",
+		Usage:     "Revoke an API token of a service account",
+		UsageText: `fleetctl service_accounts delete_token --user-id <id> --id <token id>`,
+		Flags: []cli.Flag{
+			cli.UintFlag{
+				Name:        "user-id",
+				Destination: &flUserID,
+				Usage:       "ID of the service account",
+			},
+			cli.UintFlag{
+				Name:        "id",
+				Destination: &flID,
+				Usage:       "ID of the token",
+			},
+			configFlag(),
+			contextFlag(),
+		},
+		Action: func(c *cli.Context) error {
+			if flUserID == 0 || flID == 0 {
+				return errors.New("--user-id and --id must be specified")
+			}
+
+			fleet, err := clientFromCLI(c)
+			if err != nil {
+				return err
+			}
+
+			if err := fleet.DeleteAPIToken(flUserID, flID); err != nil {
+				switch err.(type) {
+				case service.NotFoundErr:
+					return errors.Errorf("api token %d not found", flID)
+				}
+				return errors.Wrap(err, "could not delete api token")
+			}
+
+			fmt.Printf("[+] deleted api token %d\n", flID)
+			return nil
+		},
+	}
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return "never"
+	}
+	return t.Format(time.RFC3339)
+}
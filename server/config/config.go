@@ -75,21 +75,31 @@ type SessionConfig struct {
 
 // OsqueryConfig defines configs related to osquery
 type OsqueryConfig struct {
-	NodeKeySize          int           `yaml:"node_key_size"`
-	StatusLogPlugin      string        `yaml:"status_log_plugin"`
-	ResultLogPlugin      string        `yaml:"result_log_plugin"`
-	LabelUpdateInterval  time.Duration `yaml:"label_update_interval"`
-	DetailUpdateInterval time.Duration `yaml:"detail_update_interval"`
-	StatusLogFile        string        `yaml:"status_log_file"`
-	ResultLogFile        string        `yaml:"result_log_file"`
-	EnableLogRotation    bool          `yaml:"enable_log_rotation"`
+	NodeKeySize             int           `yaml:"node_key_size"`
+	StatusLogPlugin         string        `yaml:"status_log_plugin"`
+	ResultLogPlugin         string        `yaml:"result_log_plugin"`
+	LabelUpdateInterval     time.Duration `yaml:"label_update_interval"`
+	DetailUpdateInterval    time.Duration `yaml:"detail_update_interval"`
+	StatusLogFile           string        `yaml:"status_log_file"`
+	ResultLogFile           string        `yaml:"result_log_file"`
+	EnableLogRotation       bool          `yaml:"enable_log_rotation"`
+	HostIdentityStrategy    string        `yaml:"host_identity_strategy"`
+	StatusLogRetention      int           `yaml:"status_log_retention"`
+	CampaignResultRows      int           `yaml:"campaign_result_rows"`
+	DeferredCampaignTTL     time.Duration `yaml:"deferred_campaign_ttl"`
+	PersistedCampaignTTL    time.Duration `yaml:"persisted_campaign_ttl"`
+	CampaignResultRetention time.Duration `yaml:"campaign_result_retention"`
+	LiveQueryLogPlugin      string        `yaml:"live_query_log_plugin"`
+	LiveQueryStore          string        `yaml:"live_query_store"`
+	LiveQueryPollInterval   time.Duration `yaml:"live_query_poll_interval"`
 }
 
 // LoggingConfig defines configs related to logging
 type LoggingConfig struct {
-	Debug         bool
-	JSON          bool
-	DisableBanner bool `yaml:"disable_banner"`
+	Debug             bool
+	JSON              bool
+	DisableBanner     bool   `yaml:"disable_banner"`
+	ActivityLogPlugin string `yaml:"activity_log_plugin"`
 }
 
 // FirehoseConfig defines configs for the AWS Firehose logging plugin
@@ -99,19 +109,25 @@ type FirehoseConfig struct {
 	SecretAccessKey string `yaml:"secret_access_key"`
 	StatusStream    string `yaml:"status_stream"`
 	ResultStream    string `yaml:"result_stream"`
+	LiveQueryStream string `yaml:"live_query_stream"`
+	ActivityStream  string `yaml:"activity_stream"`
 }
 
 // PubSubConfig defines configs the for Google PubSub logging plugin
 type PubSubConfig struct {
-	Project     string
-	StatusTopic string `yaml:"status_topic"`
-	ResultTopic string `yaml:"result_topic"`
+	Project        string
+	StatusTopic    string `yaml:"status_topic"`
+	ResultTopic    string `yaml:"result_topic"`
+	LiveQueryTopic string `yaml:"live_query_topic"`
+	ActivityTopic  string `yaml:"activity_topic"`
 }
 
 // FilesystemConfig defines configs for the Filesystem logging plugin
 type FilesystemConfig struct {
 	StatusLogFile     string `yaml:"status_log_file"`
 	ResultLogFile     string `yaml:"result_log_file"`
+	LiveQueryLogFile  string `yaml:"live_query_log_file"`
+	ActivityLogFile   string `yaml:"activity_log_file"`
 	EnableLogRotation bool   `yaml:"enable_log_rotation"`
 }
 
@@ -220,6 +236,24 @@ func (man Manager) addConfigs() {
 		"(DEPRECATED: Use filesystem.result_log_file) Path for osqueryd result logs")
 	man.addConfigBool("osquery.enable_log_rotation", false,
 		"(DEPRECATED: Use filesystem.enable_log_rotation) Enable automatic rotation for osquery log files")
+	man.addConfigString("osquery.host_identity_strategy", "osquery_host_id",
+		"Strategy used to match re-enrolling hosts to existing hosts (osquery_host_id, uuid, serial, hostname_serial)")
+	man.addConfigInt("osquery.status_log_retention", 100,
+		"Number of warning and error status logs kept per host (0 to disable)")
+	man.addConfigInt("osquery.campaign_result_rows", 10000,
+		"Maximum number of result rows stored for a live query campaign with persisted results")
+	man.addConfigDuration("osquery.deferred_campaign_ttl", 7*24*time.Hour,
+		"Default time a deferred live query campaign waits for offline hosts")
+	man.addConfigDuration("osquery.persisted_campaign_ttl", 1*time.Hour,
+		"Time a live query campaign with persisted results keeps collecting results from hosts")
+	man.addConfigDuration("osquery.campaign_result_retention", 30*24*time.Hour,
+		"Time the stored results of a live query campaign are kept after the campaign ends")
+	man.addConfigString("osquery.live_query_log_plugin", "",
+		"Log plugin to also write live query results to (result to use the result log plugin, empty to disable)")
+	man.addConfigString("osquery.live_query_store", "redis",
+		"Store used to pass live query results between Fleet instances (redis, mysql)")
+	man.addConfigDuration("osquery.live_query_poll_interval", 1*time.Second,
+		"Interval at which live query results are read from the mysql live query store")
 
 	// Logging
 	man.addConfigBool("logging.debug", false,
@@ -228,6 +262,8 @@ func (man Manager) addConfigs() {
 		"Log in JSON format")
 	man.addConfigBool("logging.disable_banner", false,
 		"Disable startup banner")
+	man.addConfigString("logging.activity_log_plugin", "",
+		"Log plugin to also write the activity log to (filesystem, firehose, pubsub, empty to disable)")
 
 	// Firehose
 	man.addConfigString("firehose.region", "", "AWS Region to use")
@@ -237,17 +273,27 @@ func (man Manager) addConfigs() {
 		"Firehose stream name for status logs")
 	man.addConfigString("firehose.result_stream", "",
 		"Firehose stream name for result logs")
+	man.addConfigString("firehose.live_query_stream", "",
+		"Firehose stream name for live query results")
+	man.addConfigString("firehose.activity_stream", "",
+		"Firehose stream name for the activity log")
 
 	// PubSub
 	man.addConfigString("pubsub.project", "", "Google Cloud Project to use")
 	man.addConfigString("pubsub.status_topic", "", "PubSub topic for status logs")
 	man.addConfigString("pubsub.result_topic", "", "PubSub topic for result logs")
+	man.addConfigString("pubsub.live_query_topic", "", "PubSub topic for live query results")
+	man.addConfigString("pubsub.activity_topic", "", "PubSub topic for the activity log")
 
 	// Filesystem
 	man.addConfigString("filesystem.status_log_file", "/tmp/osquery_status",
 		"Log file path to use for status logs")
 	man.addConfigString("filesystem.result_log_file", "/tmp/osquery_result",
 		"Log file path to use for result logs")
+	man.addConfigString("filesystem.live_query_log_file", "/tmp/osquery_live_query",
+		"Log file path to use for live query results")
+	man.addConfigString("filesystem.activity_log_file", "/tmp/fleet_activity",
+		"Log file path to use for the activity log")
 	man.addConfigBool("filesystem.enable_log_rotation", false,
 		"Enable automatic rotation for osquery log files")
 }
@@ -298,19 +344,29 @@ func (man Manager) LoadConfig() KolideConfig {
 			Duration: man.getConfigDuration("session.duration"),
 		},
 		Osquery: OsqueryConfig{
-			NodeKeySize:          man.getConfigInt("osquery.node_key_size"),
-			StatusLogPlugin:      man.getConfigString("osquery.status_log_plugin"),
-			ResultLogPlugin:      man.getConfigString("osquery.result_log_plugin"),
-			StatusLogFile:        man.getConfigString("osquery.status_log_file"),
-			ResultLogFile:        man.getConfigString("osquery.result_log_file"),
-			LabelUpdateInterval:  man.getConfigDuration("osquery.label_update_interval"),
-			DetailUpdateInterval: man.getConfigDuration("osquery.detail_update_interval"),
-			EnableLogRotation:    man.getConfigBool("osquery.enable_log_rotation"),
+			NodeKeySize:             man.getConfigInt("osquery.node_key_size"),
+			StatusLogPlugin:         man.getConfigString("osquery.status_log_plugin"),
+			ResultLogPlugin:         man.getConfigString("osquery.result_log_plugin"),
+			StatusLogFile:           man.getConfigString("osquery.status_log_file"),
+			ResultLogFile:           man.getConfigString("osquery.result_log_file"),
+			LabelUpdateInterval:     man.getConfigDuration("osquery.label_update_interval"),
+			DetailUpdateInterval:    man.getConfigDuration("osquery.detail_update_interval"),
+			EnableLogRotation:       man.getConfigBool("osquery.enable_log_rotation"),
+			HostIdentityStrategy:    man.getConfigString("osquery.host_identity_strategy"),
+			StatusLogRetention:      man.getConfigInt("osquery.status_log_retention"),
+			CampaignResultRows:      man.getConfigInt("osquery.campaign_result_rows"),
+			DeferredCampaignTTL:     man.getConfigDuration("osquery.deferred_campaign_ttl"),
+			PersistedCampaignTTL:    man.getConfigDuration("osquery.persisted_campaign_ttl"),
+			CampaignResultRetention: man.getConfigDuration("osquery.campaign_result_retention"),
+			LiveQueryLogPlugin:      man.getConfigString("osquery.live_query_log_plugin"),
+			LiveQueryStore:          man.getConfigString("osquery.live_query_store"),
+			LiveQueryPollInterval:   man.getConfigDuration("osquery.live_query_poll_interval"),
 		},
 		Logging: LoggingConfig{
-			Debug:         man.getConfigBool("logging.debug"),
-			JSON:          man.getConfigBool("logging.json"),
-			DisableBanner: man.getConfigBool("logging.disable_banner"),
+			Debug:             man.getConfigBool("logging.debug"),
+			JSON:              man.getConfigBool("logging.json"),
+			DisableBanner:     man.getConfigBool("logging.disable_banner"),
+			ActivityLogPlugin: man.getConfigString("logging.activity_log_plugin"),
 		},
 		Firehose: FirehoseConfig{
 			Region:          man.getConfigString("firehose.region"),
@@ -318,15 +374,21 @@ func (man Manager) LoadConfig() KolideConfig {
 			SecretAccessKey: man.getConfigString("firehose.secret_access_key"),
 			StatusStream:    man.getConfigString("firehose.status_stream"),
 			ResultStream:    man.getConfigString("firehose.result_stream"),
+			LiveQueryStream: man.getConfigString("firehose.live_query_stream"),
+			ActivityStream:  man.getConfigString("firehose.activity_stream"),
 		},
 		PubSub: PubSubConfig{
-			Project:     man.getConfigString("pubsub.project"),
-			StatusTopic: man.getConfigString("pubsub.status_topic"),
-			ResultTopic: man.getConfigString("pubsub.result_topic"),
+			Project:        man.getConfigString("pubsub.project"),
+			StatusTopic:    man.getConfigString("pubsub.status_topic"),
+			ResultTopic:    man.getConfigString("pubsub.result_topic"),
+			LiveQueryTopic: man.getConfigString("pubsub.live_query_topic"),
+			ActivityTopic:  man.getConfigString("pubsub.activity_topic"),
 		},
 		Filesystem: FilesystemConfig{
 			StatusLogFile:     man.getConfigString("filesystem.status_log_file"),
 			ResultLogFile:     man.getConfigString("filesystem.result_log_file"),
+			LiveQueryLogFile:  man.getConfigString("filesystem.live_query_log_file"),
+			ActivityLogFile:   man.getConfigString("filesystem.activity_log_file"),
 			EnableLogRotation: man.getConfigBool("filesystem.enable_log_rotation"),
 		},
 	}
@@ -546,11 +608,18 @@ func TestConfig() KolideConfig {
 			Duration: 24 * 90 * time.Hour,
 		},
 		Osquery: OsqueryConfig{
-			NodeKeySize:          24,
-			StatusLogPlugin:      "filesystem",
-			ResultLogPlugin:      "filesystem",
-			LabelUpdateInterval:  1 * time.Hour,
-			DetailUpdateInterval: 1 * time.Hour,
+			NodeKeySize:             24,
+			StatusLogPlugin:         "filesystem",
+			ResultLogPlugin:         "filesystem",
+			LabelUpdateInterval:     1 * time.Hour,
+			DetailUpdateInterval:    1 * time.Hour,
+			StatusLogRetention:      100,
+			CampaignResultRows:      10000,
+			DeferredCampaignTTL:     7 * 24 * time.Hour,
+			PersistedCampaignTTL:    1 * time.Hour,
+			CampaignResultRetention: 30 * 24 * time.Hour,
+			LiveQueryStore:          "redis",
+			LiveQueryPollInterval:   1 * time.Second,
 		},
 		Logging: LoggingConfig{
 			Debug:         true,
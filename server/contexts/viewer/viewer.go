@@ -28,6 +28,9 @@ func FromContext(ctx context.Context) (Viewer, bool) {
 type Viewer struct {
 	User    *kolide.User
 	Session *kolide.Session
+	// Token is set instead of Session when a service account authenticates
+	// with an API token.
+	Token *kolide.APIToken
 }
 
 // UserID is a helper that enables quick access to the user ID of the current
@@ -79,6 +82,9 @@ func (v Viewer) IsLoggedIn() bool {
 			return false
 		}
 	}
+	if v.Token != nil {
+		return v.Token.ID != 0
+	}
 	if v.Session != nil {
 		// Without having access to a service to call GetInfoAboutSession(id),
 		// we can't synchronously check the database here.
@@ -101,10 +107,20 @@ func (v Viewer) CanPerformActions() bool {
 // CanPerformAdminActions indicates whether or not the current user can perform
 // administrative actions.
 func (v Viewer) CanPerformAdminActions() bool {
-	if v.User != nil {
-		return v.CanPerformActions() && v.User.Admin
+	return v.HasRole(kolide.RoleAdmin)
+}
+
+// HasRole returns a bool indicating whether the current user can perform
+// actions and has at least the permissions of the given role. Requests
+// authenticated with an API token are also limited to the role of the token.
+func (v Viewer) HasRole(role kolide.Role) bool {
+	if v.User == nil || !v.CanPerformActions() || !v.User.EffectiveRole().Includes(role) {
+		return false
 	}
-	return false
+	if v.Token != nil {
+		return v.Token.Role.Includes(role)
+	}
+	return true
 }
 
 // CanPerformReadActionOnUser returns a bool indicating the current user's
@@ -210,3 +210,31 @@ func TestCanPerformPasswordReset(t *testing.T) {
 	assert.Equal(t, true, needsPasswordResetAdminViewer.CanPerformPasswordReset())
 
 }
+
+func TestAPITokenViewer(t *testing.T) {
+	serviceAccount := &kolide.User{
+		ID:       48,
+		Name:     "Service Account",
+		Username: "automation",
+		Role:     kolide.RoleMaintainer,
+		Enabled:  true,
+		APIOnly:  true,
+	}
+	tokenViewer := Viewer{
+		User:  serviceAccount,
+		Token: &kolide.APIToken{ID: 1, UserID: 48, Role: kolide.RoleObserver},
+	}
+	assert.True(t, tokenViewer.IsLoggedIn())
+	assert.True(t, tokenViewer.CanPerformActions())
+	assert.True(t, tokenViewer.HasRole(kolide.RoleObserver))
+	// The token limits the role of the service account
+	assert.False(t, tokenViewer.HasRole(kolide.RoleMaintainer))
+
+	tokenViewer.Token.Role = kolide.RoleAdmin
+	assert.True(t, tokenViewer.HasRole(kolide.RoleMaintainer))
+	// but does not grant more than the role of the service account
+	assert.False(t, tokenViewer.HasRole(kolide.RoleAdmin))
+
+	serviceAccount.Enabled = false
+	assert.False(t, tokenViewer.IsLoggedIn())
+}
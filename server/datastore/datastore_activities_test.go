@@ -0,0 +1,67 @@
+package datastore
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testActivities(t *testing.T, ds kolide.Datastore) {
+	user := test.NewUser(t, ds, "Admin", "admin", "admin@kolide.co", true)
+
+	details := json.RawMessage(`{"name":{"old":"foo","new":"bar"}}`)
+	packID := uint(7)
+	modified, err := ds.NewActivity(&kolide.Activity{
+		ActorID:    &user.ID,
+		ActorName:  user.Username,
+		Type:       "modified_pack",
+		TargetType: "pack",
+		TargetID:   &packID,
+		TargetName: "bar",
+		Details:    &details,
+	})
+	require.Nil(t, err)
+	assert.NotZero(t, modified.ID)
+
+	expired, err := ds.NewActivity(&kolide.Activity{Type: "expired_hosts", TargetType: "host"})
+	require.Nil(t, err)
+
+	activities, err := ds.ListActivities(kolide.ActivityFilter{}, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, activities, 2)
+	// Most recent first
+	assert.Equal(t, expired.ID, activities[0].ID)
+	assert.Nil(t, activities[0].ActorID)
+	assert.Nil(t, activities[0].Details)
+	assert.Equal(t, modified.ID, activities[1].ID)
+	require.NotNil(t, activities[1].Details)
+	assert.JSONEq(t, string(details), string(*activities[1].Details))
+
+	activities, err = ds.ListActivities(kolide.ActivityFilter{ActorID: &user.ID}, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, activities, 1)
+	assert.Equal(t, "modified_pack", activities[0].Type)
+	assert.Equal(t, "admin", activities[0].ActorName)
+
+	targetType := "pack"
+	activities, err = ds.ListActivities(kolide.ActivityFilter{TargetType: &targetType, TargetID: &packID}, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, activities, 1)
+	assert.Equal(t, modified.ID, activities[0].ID)
+
+	activityType := "expired_hosts"
+	activities, err = ds.ListActivities(kolide.ActivityFilter{Type: &activityType}, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, activities, 1)
+	assert.Equal(t, expired.ID, activities[0].ID)
+
+	since := time.Now().Add(time.Hour)
+	activities, err = ds.ListActivities(kolide.ActivityFilter{Since: &since}, kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, activities, 0)
+}
@@ -0,0 +1,67 @@
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testAPITokens(t *testing.T, ds kolide.Datastore) {
+	bot := test.NewUser(t, ds, "Bot", "bot", "bot@kolide.co", false)
+	other := test.NewUser(t, ds, "Other", "other", "other@kolide.co", false)
+
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	token, err := ds.NewAPIToken(&kolide.APIToken{
+		UserID:    bot.ID,
+		Name:      "ci",
+		Hash:      kolide.HashAPIToken("fleet_ci"),
+		Role:      kolide.RoleObserver,
+		ExpiresAt: &expiresAt,
+	})
+	require.Nil(t, err)
+	assert.NotZero(t, token.ID)
+
+	// Names are unique per user
+	_, err = ds.NewAPIToken(&kolide.APIToken{UserID: bot.ID, Name: "ci", Hash: kolide.HashAPIToken("fleet_other")})
+	require.NotNil(t, err)
+	_, err = ds.NewAPIToken(&kolide.APIToken{UserID: other.ID, Name: "ci", Hash: kolide.HashAPIToken("fleet_other")})
+	require.Nil(t, err)
+
+	found, err := ds.APITokenByHash(kolide.HashAPIToken("fleet_ci"))
+	require.Nil(t, err)
+	assert.Equal(t, token.ID, found.ID)
+	assert.Equal(t, bot.ID, found.UserID)
+	assert.Equal(t, kolide.RoleObserver, found.Role)
+	require.NotNil(t, found.ExpiresAt)
+	assert.Equal(t, expiresAt, found.ExpiresAt.UTC())
+	assert.Nil(t, found.LastUsedAt)
+
+	_, err = ds.APITokenByHash(kolide.HashAPIToken("fleet_unknown"))
+	require.NotNil(t, err)
+	_, ok := err.(kolide.NotFoundError)
+	assert.True(t, ok)
+
+	usedAt := time.Now().UTC().Truncate(time.Second)
+	require.Nil(t, ds.MarkAPITokenUsed(token.ID, usedAt))
+	found, err = ds.APITokenByHash(kolide.HashAPIToken("fleet_ci"))
+	require.Nil(t, err)
+	require.NotNil(t, found.LastUsedAt)
+	assert.Equal(t, usedAt, found.LastUsedAt.UTC())
+
+	tokens, err := ds.ListAPITokensForUser(bot.ID)
+	require.Nil(t, err)
+	require.Len(t, tokens, 1)
+	assert.Equal(t, "ci", tokens[0].Name)
+
+	// Tokens can only be deleted by their user
+	err = ds.DeleteAPIToken(other.ID, token.ID)
+	require.NotNil(t, err)
+	require.Nil(t, ds.DeleteAPIToken(bot.ID, token.ID))
+	tokens, err = ds.ListAPITokensForUser(bot.ID)
+	require.Nil(t, err)
+	assert.Empty(t, tokens)
+}
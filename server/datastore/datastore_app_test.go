@@ -43,6 +43,10 @@ func testOrgInfo(t *testing.T, ds kolide.Datastore) {
 	info2.MetadataURL = "https://idp.com/metadata.xml"
 	info2.IssuerURI = "https://idp.issuer.com"
 	info2.IDPName = "My IDP"
+	info2.EnableJITProvisioning = true
+	info2.JITGroupAttribute = "groups"
+	info2.JITAdminGroups = kolide.StringList{"fleet-admins"}
+	info2.JITUserGroups = kolide.StringList{"fleet-users", "fleet-observers"}
 
 	err = ds.SaveAppConfig(info2)
 	require.Nil(t, err)
@@ -13,7 +13,7 @@ import (
 )
 
 func checkTargets(t *testing.T, ds kolide.Datastore, campaignID uint, expectedHostIDs []uint, expectedLabelIDs []uint) {
-	hostIDs, labelIDs, err := ds.DistributedQueryCampaignTargetIDs(campaignID)
+	hostIDs, labelIDs, _, err := ds.DistributedQueryCampaignTargetIDs(campaignID)
 	require.Nil(t, err)
 
 	sortutil.Asc(expectedHostIDs)
@@ -162,3 +162,441 @@ func testCleanupDistributedQueryCampaigns(t *testing.T, ds kolide.Datastore) {
 	}
 
 }
+
+func testCampaignResults(t *testing.T, ds kolide.Datastore) {
+	user := test.NewUser(t, ds, "Zach", "zwass", "zwass@kolide.co", true)
+	query := test.NewQuery(t, ds, "test", "select * from time", user.ID, false)
+	campaign := test.NewCampaign(t, ds, query.ID, kolide.QueryRunning, time.Now())
+
+	failed := "failed"
+	results := []*kolide.CampaignResult{
+		{HostID: 1, HostName: "foo.local", Rows: []map[string]string{{"hour": "1"}, {"hour": "2"}}},
+		{HostID: 2, HostName: "bar.local", Rows: []map[string]string{}, Error: &failed},
+		// Cut to the row limit
+		{HostID: 3, HostName: "baz.local", Rows: []map[string]string{{"hour": "3"}, {"hour": "4"}}},
+		// Over the row limit
+		{HostID: 4, HostName: "qux.local", Rows: []map[string]string{{"hour": "5"}}},
+	}
+	for i, result := range results {
+		result.DistributedQueryCampaignID = campaign.ID
+		saved, err := ds.SaveCampaignResult(result, 3)
+		require.Nil(t, err)
+		assert.Equal(t, i < 3, saved)
+	}
+	assert.False(t, results[0].Truncated)
+	assert.True(t, results[2].Truncated)
+
+	stored, err := ds.ListCampaignResults(campaign.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, stored, 3)
+	assert.Equal(t, "foo.local", stored[0].HostName)
+	assert.Equal(t, results[0].Rows, stored[0].Rows)
+	assert.Equal(t, uint(2), stored[0].RowCount)
+	assert.False(t, stored[0].Truncated)
+	require.NotNil(t, stored[1].Error)
+	assert.Equal(t, "failed", *stored[1].Error)
+	assert.Empty(t, stored[1].Rows)
+	assert.Equal(t, []map[string]string{{"hour": "3"}}, stored[2].Rows)
+	assert.Equal(t, uint(1), stored[2].RowCount)
+	assert.True(t, stored[2].Truncated)
+
+	stored, err = ds.ListCampaignResults(campaign.ID, kolide.ListOptions{Page: 1, PerPage: 2})
+	require.Nil(t, err)
+	require.Len(t, stored, 1)
+	assert.Equal(t, uint(3), stored[0].HostID)
+
+	// The truncation is recorded on the campaign, and kept when a stale
+	// copy of the campaign is saved
+	require.Nil(t, ds.MarkCampaignResultsTruncated(campaign.ID))
+	retrieved, err := ds.DistributedQueryCampaign(campaign.ID)
+	require.Nil(t, err)
+	assert.True(t, retrieved.ResultsTruncated)
+	require.Nil(t, ds.SaveDistributedQueryCampaign(campaign))
+	retrieved, err = ds.DistributedQueryCampaign(campaign.ID)
+	require.Nil(t, err)
+	assert.True(t, retrieved.ResultsTruncated)
+}
+
+func testCleanupCampaignResults(t *testing.T, ds kolide.Datastore) {
+	user := test.NewUser(t, ds, "Zach", "zwass", "zwass@kolide.co", true)
+	query := test.NewQuery(t, ds, "test", "select * from time", user.ID, false)
+	now := time.Now().UTC().Truncate(time.Second)
+	endedAt := now.Add(-2 * time.Hour)
+	expiresAt := now.Add(time.Hour)
+
+	ended, err := ds.NewDistributedQueryCampaign(&kolide.DistributedQueryCampaign{
+		QueryID:        query.ID,
+		UserID:         user.ID,
+		Status:         kolide.QueryComplete,
+		PersistResults: true,
+		ExpiresAt:      &endedAt,
+	})
+	require.Nil(t, err)
+	running, err := ds.NewDistributedQueryCampaign(&kolide.DistributedQueryCampaign{
+		QueryID:        query.ID,
+		UserID:         user.ID,
+		Status:         kolide.QueryRunning,
+		PersistResults: true,
+		ExpiresAt:      &expiresAt,
+	})
+	require.Nil(t, err)
+
+	for _, campaign := range []*kolide.DistributedQueryCampaign{ended, running} {
+		saved, err := ds.SaveCampaignResult(&kolide.CampaignResult{
+			DistributedQueryCampaignID: campaign.ID,
+			HostID:                     1,
+			HostName:                   "foo.local",
+			Rows:                       []map[string]string{{"hour": "1"}},
+		}, 10)
+		require.Nil(t, err)
+		require.True(t, saved)
+	}
+
+	// Results are kept until the retention window after the campaign
+	// ended has passed
+	deleted, err := ds.CleanupCampaignResults(now.Add(-3 * time.Hour))
+	require.Nil(t, err)
+	assert.Equal(t, uint(0), deleted)
+
+	deleted, err = ds.CleanupCampaignResults(now.Add(-time.Hour))
+	require.Nil(t, err)
+	assert.Equal(t, uint(1), deleted)
+
+	stored, err := ds.ListCampaignResults(ended.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.Empty(t, stored)
+	stored, err = ds.ListCampaignResults(running.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, stored, 1)
+}
+
+func testPersistedDistributedQueryCampaignExpiry(t *testing.T, ds kolide.Datastore) {
+	user := test.NewUser(t, ds, "Zach", "zwass", "zwass@kolide.co", true)
+	query := test.NewQuery(t, ds, "test", "select * from time", user.ID, false)
+	now := time.Now().UTC().Truncate(time.Second)
+	expiresAt := now.Add(time.Hour)
+
+	persisted, err := ds.NewDistributedQueryCampaign(&kolide.DistributedQueryCampaign{
+		QueryID:        query.ID,
+		UserID:         user.ID,
+		Status:         kolide.QueryRunning,
+		PersistResults: true,
+		ExpiresAt:      &expiresAt,
+	})
+	require.Nil(t, err)
+
+	expired, _, err := ds.CleanupDistributedQueryCampaigns(now.Add(30 * time.Minute))
+	require.Nil(t, err)
+	assert.Equal(t, uint(0), expired)
+
+	// Campaigns with persisted results are completed once they expire,
+	// rather than after a day
+	expired, _, err = ds.CleanupDistributedQueryCampaigns(now.Add(2 * time.Hour))
+	require.Nil(t, err)
+	assert.Equal(t, uint(1), expired)
+	retrieved, err := ds.DistributedQueryCampaign(persisted.ID)
+	require.Nil(t, err)
+	assert.Equal(t, kolide.QueryComplete, retrieved.Status)
+}
+
+func testListDistributedQueryCampaigns(t *testing.T, ds kolide.Datastore) {
+	zach := test.NewUser(t, ds, "Zach", "zwass", "zwass@kolide.co", true)
+	mike := test.NewUser(t, ds, "Mike", "mike", "mike@kolide.co", false)
+	q1 := test.NewQuery(t, ds, "q1", "select * from time", zach.ID, false)
+	q2 := test.NewQuery(t, ds, "q2", "select * from users", mike.ID, false)
+
+	newCampaign := func(queryID, userID uint, status kolide.DistributedQueryStatus) *kolide.DistributedQueryCampaign {
+		campaign, err := ds.NewDistributedQueryCampaign(&kolide.DistributedQueryCampaign{
+			QueryID:         queryID,
+			UserID:          userID,
+			Status:          status,
+			ExpectedResults: 2,
+		})
+		require.Nil(t, err)
+		return campaign
+	}
+	c1 := newCampaign(q1.ID, zach.ID, kolide.QueryComplete)
+	c2 := newCampaign(q2.ID, mike.ID, kolide.QueryRunning)
+
+	h1 := test.NewHost(t, ds, "foo.local", "192.168.1.10", "1", "1", time.Now())
+	h2 := test.NewHost(t, ds, "bar.local", "192.168.1.11", "2", "2", time.Now())
+	l1 := kolide.LabelSpec{ID: 1, Name: "label foo", Query: "query foo"}
+	require.Nil(t, ds.ApplyLabelSpecs([]*kolide.LabelSpec{&l1}))
+	require.Nil(t, ds.SetHostTags(h1.ID, map[string]string{"env": "prod"}))
+	tagIDs, err := ds.TagIDsByName([]string{"env=prod"})
+	require.Nil(t, err)
+	require.Len(t, tagIDs, 1)
+
+	test.AddHostToCampaign(t, ds, c1.ID, h1.ID)
+	test.AddLabelToCampaign(t, ds, c1.ID, l1.ID)
+	test.AddHostToCampaign(t, ds, c2.ID, h2.ID)
+	_, err = ds.NewDistributedQueryCampaignTarget(&kolide.DistributedQueryCampaignTarget{
+		Type:                       kolide.TargetTag,
+		DistributedQueryCampaignID: c2.ID,
+		TargetID:                   tagIDs[0],
+	})
+	require.Nil(t, err)
+
+	for _, hostID := range []uint{h1.ID, h2.ID} {
+		_, err := ds.NewDistributedQueryExecution(&kolide.DistributedQueryExecution{
+			HostID:                     hostID,
+			DistributedQueryCampaignID: c2.ID,
+			Status:                     kolide.ExecutionSucceeded,
+		})
+		require.Nil(t, err)
+	}
+	// Saving a copy loaded before the results were received must not
+	// reset the count.
+	require.Nil(t, ds.SaveDistributedQueryCampaign(c2))
+
+	campaigns, err := ds.ListDistributedQueryCampaigns(kolide.ListOptions{}, kolide.DistributedQueryCampaignFilter{})
+	require.Nil(t, err)
+	require.Len(t, campaigns, 2)
+
+	assert.Equal(t, c2.ID, campaigns[0].ID)
+	assert.Equal(t, "select * from users", campaigns[0].Query)
+	assert.Equal(t, "mike", campaigns[0].Username)
+	assert.Equal(t, kolide.QueryRunning, campaigns[0].Status)
+	assert.Equal(t, uint(2), campaigns[0].ExpectedResults)
+	assert.Equal(t, uint(2), campaigns[0].ReceivedResults)
+	assert.Equal(t, []string{"bar.local"}, campaigns[0].Hosts)
+	assert.Equal(t, []string{}, campaigns[0].Labels)
+	assert.Equal(t, []string{"env=prod"}, campaigns[0].Tags)
+
+	assert.Equal(t, c1.ID, campaigns[1].ID)
+	assert.Equal(t, "zwass", campaigns[1].Username)
+	assert.Equal(t, uint(0), campaigns[1].ReceivedResults)
+	assert.Equal(t, []string{"foo.local"}, campaigns[1].Hosts)
+	assert.Equal(t, []string{"label foo"}, campaigns[1].Labels)
+
+	campaigns, err = ds.ListDistributedQueryCampaigns(kolide.ListOptions{}, kolide.DistributedQueryCampaignFilter{UserID: &zach.ID})
+	require.Nil(t, err)
+	require.Len(t, campaigns, 1)
+	assert.Equal(t, c1.ID, campaigns[0].ID)
+
+	running := kolide.QueryRunning
+	campaigns, err = ds.ListDistributedQueryCampaigns(kolide.ListOptions{}, kolide.DistributedQueryCampaignFilter{Status: &running})
+	require.Nil(t, err)
+	require.Len(t, campaigns, 1)
+	assert.Equal(t, c2.ID, campaigns[0].ID)
+
+	campaigns, err = ds.ListDistributedQueryCampaigns(kolide.ListOptions{Page: 1, PerPage: 1}, kolide.DistributedQueryCampaignFilter{})
+	require.Nil(t, err)
+	require.Len(t, campaigns, 1)
+	assert.Equal(t, c1.ID, campaigns[0].ID)
+}
+
+func testDeferredDistributedQueryCampaigns(t *testing.T, ds kolide.Datastore) {
+	user := test.NewUser(t, ds, "Zach", "zwass", "zwass@kolide.co", true)
+	query := test.NewQuery(t, ds, "test", "select * from time", user.ID, false)
+	now := time.Now().UTC().Truncate(time.Second)
+	expiresAt := now.Add(7 * 24 * time.Hour)
+
+	deferred, err := ds.NewDistributedQueryCampaign(&kolide.DistributedQueryCampaign{
+		QueryID:        query.ID,
+		UserID:         user.ID,
+		Status:         kolide.QueryRunning,
+		PersistResults: true,
+		Deferred:       true,
+		ExpiresAt:      &expiresAt,
+	})
+	require.Nil(t, err)
+	live := test.NewCampaign(t, ds, query.ID, kolide.QueryRunning, now)
+
+	h1 := test.NewHost(t, ds, "foo.local", "192.168.1.10", "1", "1", now)
+	test.AddHostToCampaign(t, ds, deferred.ID, h1.ID)
+	test.AddHostToCampaign(t, ds, live.ID, h1.ID)
+
+	queries, err := ds.DistributedQueriesForHost(h1)
+	require.Nil(t, err)
+	assert.Len(t, queries, 2)
+
+	// Only the query of the deferred campaign is not sent again once
+	// requested
+	require.Nil(t, ds.MarkDistributedQueriesRequested(h1.ID, []uint{deferred.ID, live.ID}, now))
+	queries, err = ds.DistributedQueriesForHost(h1)
+	require.Nil(t, err)
+	assert.Equal(t, map[uint]string{live.ID: "select * from time"}, queries)
+
+	// Marking again keeps the first request time
+	require.Nil(t, ds.MarkDistributedQueriesRequested(h1.ID, []uint{deferred.ID, live.ID}, now.Add(time.Minute)))
+
+	// The result replaces the requested execution, and its duration is
+	// measured from the request
+	exec, err := ds.NewDistributedQueryExecution(&kolide.DistributedQueryExecution{
+		HostID:                     h1.ID,
+		DistributedQueryCampaignID: deferred.ID,
+		Status:                     kolide.ExecutionSucceeded,
+		ReceivedAt:                 now.Add(90 * time.Second),
+	})
+	require.Nil(t, err)
+	assert.Equal(t, 90*time.Second, exec.ExecutionDuration)
+	_, err = ds.NewDistributedQueryExecution(&kolide.DistributedQueryExecution{
+		HostID:                     h1.ID,
+		DistributedQueryCampaignID: live.ID,
+		Status:                     kolide.ExecutionSucceeded,
+		ReceivedAt:                 now.Add(2 * time.Second),
+	})
+	require.Nil(t, err)
+	queries, err = ds.DistributedQueriesForHost(h1)
+	require.Nil(t, err)
+	assert.Empty(t, queries)
+
+	campaignHosts, err := ds.ListDistributedQueryCampaignHosts(live.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, campaignHosts, 1)
+	assert.Equal(t, kolide.ExecutionSucceeded, campaignHosts[0].Status)
+	assert.Equal(t, 2*time.Second, campaignHosts[0].ExecutionDuration)
+
+	_, err = ds.NewDistributedQueryExecution(&kolide.DistributedQueryExecution{
+		HostID:                     h1.ID,
+		DistributedQueryCampaignID: deferred.ID,
+		Status:                     kolide.ExecutionSucceeded,
+	})
+	require.NotNil(t, err)
+
+	retrieved, err := ds.DistributedQueryCampaign(deferred.ID)
+	require.Nil(t, err)
+	assert.True(t, retrieved.Deferred)
+	require.NotNil(t, retrieved.ExpiresAt)
+	assert.Equal(t, expiresAt, retrieved.ExpiresAt.UTC())
+	assert.Equal(t, uint(1), retrieved.ReceivedResults)
+
+	// Deferred campaigns are not expired after a day, only once they
+	// reach their expiry
+	expired, _, err := ds.CleanupDistributedQueryCampaigns(now.Add(2 * 24 * time.Hour))
+	require.Nil(t, err)
+	assert.Equal(t, uint(1), expired)
+	retrieved, err = ds.DistributedQueryCampaign(deferred.ID)
+	require.Nil(t, err)
+	assert.Equal(t, kolide.QueryRunning, retrieved.Status)
+
+	campaignHosts, err = ds.ListDistributedQueryCampaignHosts(deferred.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	// The per host state of running deferred campaigns is kept
+	require.Len(t, campaignHosts, 1)
+	assert.Equal(t, kolide.ExecutionSucceeded, campaignHosts[0].Status)
+
+	expired, deleted, err := ds.CleanupDistributedQueryCampaigns(now.Add(8 * 24 * time.Hour))
+	require.Nil(t, err)
+	assert.Equal(t, uint(1), expired)
+	// and deleted once they are past their expiry
+	assert.Equal(t, uint(1), deleted)
+	retrieved, err = ds.DistributedQueryCampaign(deferred.ID)
+	require.Nil(t, err)
+	assert.Equal(t, kolide.QueryComplete, retrieved.Status)
+}
+
+func testListDistributedQueryCampaignHosts(t *testing.T, ds kolide.Datastore) {
+	user := test.NewUser(t, ds, "Zach", "zwass", "zwass@kolide.co", true)
+	query := test.NewQuery(t, ds, "test", "select * from time", user.ID, false)
+	campaign := test.NewCampaign(t, ds, query.ID, kolide.QueryRunning, time.Now())
+
+	h1 := test.NewHost(t, ds, "a.local", "192.168.1.10", "1", "1", time.Now())
+	h2 := test.NewHost(t, ds, "b.local", "192.168.1.11", "2", "2", time.Now())
+	h3 := test.NewHost(t, ds, "c.local", "192.168.1.12", "3", "3", time.Now())
+	h4 := test.NewHost(t, ds, "d.local", "192.168.1.13", "4", "4", time.Now())
+	test.NewHost(t, ds, "untargeted.local", "192.168.1.14", "5", "5", time.Now())
+
+	l1 := kolide.LabelSpec{ID: 1, Name: "label foo", Query: "query foo"}
+	require.Nil(t, ds.ApplyLabelSpecs([]*kolide.LabelSpec{&l1}))
+	require.Nil(t, ds.RecordLabelQueryExecutions(h2, map[uint]bool{l1.ID: true}, time.Now()))
+	require.Nil(t, ds.SetHostTags(h3.ID, map[string]string{"env": "prod"}))
+	tagIDs, err := ds.TagIDsByName([]string{"env=prod"})
+	require.Nil(t, err)
+	require.Len(t, tagIDs, 1)
+
+	test.AddHostToCampaign(t, ds, campaign.ID, h1.ID)
+	test.AddLabelToCampaign(t, ds, campaign.ID, l1.ID)
+	_, err = ds.NewDistributedQueryCampaignTarget(&kolide.DistributedQueryCampaignTarget{
+		Type:                       kolide.TargetTag,
+		DistributedQueryCampaignID: campaign.ID,
+		TargetID:                   tagIDs[0],
+	})
+	require.Nil(t, err)
+
+	_, err = ds.NewDistributedQueryExecution(&kolide.DistributedQueryExecution{
+		HostID:                     h1.ID,
+		DistributedQueryCampaignID: campaign.ID,
+		Status:                     kolide.ExecutionFailed,
+		Error:                      "no such table: foo",
+		ExecutionDuration:          2 * time.Second,
+	})
+	require.Nil(t, err)
+	// A host that returned results is listed even if it is no longer
+	// targeted
+	_, err = ds.NewDistributedQueryExecution(&kolide.DistributedQueryExecution{
+		HostID:                     h4.ID,
+		DistributedQueryCampaignID: campaign.ID,
+		Status:                     kolide.ExecutionSucceeded,
+	})
+	require.Nil(t, err)
+
+	hosts, err := ds.ListDistributedQueryCampaignHosts(campaign.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, hosts, 4)
+	assert.Equal(t, &kolide.DistributedQueryCampaignHost{
+		HostID:            h1.ID,
+		HostName:          "a.local",
+		Status:            kolide.ExecutionFailed,
+		Error:             "no such table: foo",
+		ExecutionDuration: 2 * time.Second,
+	}, hosts[0])
+	assert.Equal(t, h2.ID, hosts[1].HostID)
+	assert.Equal(t, kolide.ExecutionWaiting, hosts[1].Status)
+	assert.Equal(t, h3.ID, hosts[2].HostID)
+	assert.Equal(t, kolide.ExecutionWaiting, hosts[2].Status)
+	assert.Equal(t, h4.ID, hosts[3].HostID)
+	assert.Equal(t, kolide.ExecutionSucceeded, hosts[3].Status)
+
+	hosts, err = ds.ListDistributedQueryCampaignHosts(campaign.ID, kolide.ListOptions{Page: 1, PerPage: 3})
+	require.Nil(t, err)
+	require.Len(t, hosts, 1)
+	assert.Equal(t, h4.ID, hosts[0].HostID)
+}
+
+func testDistributedQueryCampaignViewers(t *testing.T, ds kolide.Datastore) {
+	user := test.NewUser(t, ds, "Zach", "zwass", "zwass@kolide.co", true)
+	query := test.NewQuery(t, ds, "test", "select * from time", user.ID, false)
+	now := time.Now()
+	campaign := test.NewCampaign(t, ds, query.ID, kolide.QueryRunning, now)
+	unviewed := test.NewCampaign(t, ds, query.ID, kolide.QueryRunning, now)
+
+	v1, err := ds.NewDistributedQueryCampaignViewer(campaign.ID)
+	require.Nil(t, err)
+	v2, err := ds.NewDistributedQueryCampaignViewer(campaign.ID)
+	require.Nil(t, err)
+	v3, err := ds.NewDistributedQueryCampaignViewer(campaign.ID)
+	require.Nil(t, err)
+	require.Nil(t, ds.TouchDistributedQueryCampaignViewer(v1))
+
+	remaining, err := ds.DeleteDistributedQueryCampaignViewer(v1, now.Add(-time.Hour))
+	require.Nil(t, err)
+	assert.Equal(t, uint(2), remaining)
+	// Viewers that timed out are not counted
+	remaining, err = ds.DeleteDistributedQueryCampaignViewer(v2, now.Add(time.Hour))
+	require.Nil(t, err)
+	assert.Equal(t, uint(0), remaining)
+	_, err = ds.DeleteDistributedQueryCampaignViewer(v2, now)
+	assert.True(t, kolide.IsNotFound(err))
+	assert.True(t, kolide.IsNotFound(ds.TouchDistributedQueryCampaignViewer(v2)))
+
+	_, err = ds.NewDistributedQueryCampaignViewer(campaign.ID + 100)
+	assert.True(t, kolide.IsNotFound(err))
+
+	// The campaign is completed by the cleanup once its last viewer timed
+	// out, and the viewer is deleted
+	expired, _, err := ds.CleanupDistributedQueryCampaigns(now.Add(-time.Hour))
+	require.Nil(t, err)
+	assert.Equal(t, uint(0), expired)
+	expired, _, err = ds.CleanupDistributedQueryCampaigns(now.Add(time.Hour))
+	require.Nil(t, err)
+	assert.Equal(t, uint(1), expired)
+	retrieved, err := ds.DistributedQueryCampaign(campaign.ID)
+	require.Nil(t, err)
+	assert.Equal(t, kolide.QueryComplete, retrieved.Status)
+	retrieved, err = ds.DistributedQueryCampaign(unviewed.ID)
+	require.Nil(t, err)
+	assert.Equal(t, kolide.QueryRunning, retrieved.Status)
+	assert.True(t, kolide.IsNotFound(ds.TouchDistributedQueryCampaignViewer(v3)))
+}
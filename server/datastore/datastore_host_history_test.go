@@ -0,0 +1,73 @@
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testHostHistory(t *testing.T, ds kolide.Datastore) {
+	host, err := ds.NewHost(&kolide.Host{
+		DetailUpdateTime: time.Now(),
+		SeenTime:         time.Now(),
+		OsqueryHostID:    "1",
+		NodeKey:          "1",
+		UUID:             "1",
+		HostName:         "foo.local",
+		OSVersion:        "Mac OS X 10.14.6",
+		OsqueryVersion:   "4.1.2",
+	})
+	require.Nil(t, err)
+
+	host.NetworkInterfaces = []*kolide.NetworkInterface{
+		{Interface: "en0", IPAddress: "192.168.1.2"},
+	}
+	require.Nil(t, ds.SaveHost(host))
+
+	// Populating the primary IP for the first time is not a change
+	history, err := ds.ListHostHistory(host.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, history, 0)
+
+	host.OSVersion = "Mac OS X 10.15.4"
+	host.OsqueryVersion = "4.2.0"
+	require.Nil(t, ds.SaveHost(host))
+
+	host.NetworkInterfaces = []*kolide.NetworkInterface{
+		{Interface: "en1", IPAddress: "10.0.0.5"},
+	}
+	require.Nil(t, ds.SaveHost(host))
+
+	// Saving without network interfaces does not record a primary IP change
+	host.NetworkInterfaces = nil
+	host.HostName = "bar.local"
+	require.Nil(t, ds.SaveHost(host))
+
+	history, err = ds.ListHostHistory(host.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, history, 4)
+
+	changes := map[string][2]string{}
+	for _, h := range history {
+		assert.Equal(t, host.ID, h.HostID)
+		assert.False(t, h.CreatedAt.IsZero())
+		changes[h.Field] = [2]string{h.OldValue, h.NewValue}
+	}
+	assert.Equal(t, map[string][2]string{
+		kolide.HostHistoryOSVersion:      {"Mac OS X 10.14.6", "Mac OS X 10.15.4"},
+		kolide.HostHistoryOsqueryVersion: {"4.1.2", "4.2.0"},
+		kolide.HostHistoryPrimaryIP:      {"192.168.1.2", "10.0.0.5"},
+		kolide.HostHistoryHostName:       {"foo.local", "bar.local"},
+	}, changes)
+
+	// Most recent first
+	assert.Equal(t, kolide.HostHistoryHostName, history[0].Field)
+
+	history, err = ds.ListHostHistory(host.ID, kolide.ListOptions{PerPage: 1, Page: 1})
+	require.Nil(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, kolide.HostHistoryPrimaryIP, history[0].Field)
+}
@@ -0,0 +1,73 @@
+package datastore
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testHostStatusLogs(t *testing.T, ds kolide.Datastore) {
+	var hosts []*kolide.Host
+	for i := 0; i < 2; i++ {
+		host, err := ds.NewHost(&kolide.Host{
+			DetailUpdateTime: time.Now(),
+			SeenTime:         time.Now(),
+			OsqueryHostID:    fmt.Sprint(i),
+			NodeKey:          fmt.Sprint(i),
+			UUID:             fmt.Sprint(i),
+			HostName:         fmt.Sprintf("foo%d.local", i),
+		})
+		require.Nil(t, err)
+		hosts = append(hosts, host)
+	}
+
+	queryName := "pack/it/users"
+	now := time.Now().UTC().Truncate(time.Second)
+	newLog := func(i int) *kolide.HostStatusLog {
+		return &kolide.HostStatusLog{
+			Severity:  kolide.StatusLogError,
+			Filename:  "scheduler.cpp",
+			Line:      93,
+			Message:   fmt.Sprintf("Error executing scheduled query %s: error %d", queryName, i),
+			QueryName: &queryName,
+			CreatedAt: now.Add(time.Duration(i) * time.Second),
+		}
+	}
+
+	// Only the most recent logs are kept
+	for i := 0; i < 5; i++ {
+		err := ds.SaveHostStatusLogs(hosts[0].ID, []*kolide.HostStatusLog{newLog(i)}, 3)
+		require.Nil(t, err)
+	}
+	err := ds.SaveHostStatusLogs(hosts[1].ID, []*kolide.HostStatusLog{
+		newLog(5),
+		{Severity: kolide.StatusLogWarning, Message: "warning!", CreatedAt: now},
+	}, 3)
+	require.Nil(t, err)
+
+	logs, err := ds.ListHostStatusLogs(hosts[0].ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, logs, 3)
+	assert.Contains(t, logs[0].Message, "error 4")
+	assert.Contains(t, logs[2].Message, "error 2")
+	assert.Equal(t, now.Add(4*time.Second), logs[0].CreatedAt.UTC())
+
+	summaries, err := ds.ListScheduledQueryErrors()
+	require.Nil(t, err)
+	require.Len(t, summaries, 1)
+	assert.Equal(t, queryName, summaries[0].QueryName)
+	assert.Equal(t, uint(4), summaries[0].Count)
+	assert.Equal(t, uint(2), summaries[0].HostCount)
+	assert.Contains(t, summaries[0].LastMessage, "error 5")
+
+	// Logs are deleted with the host
+	require.Nil(t, ds.DeleteHost(hosts[1].ID))
+	summaries, err = ds.ListScheduledQueryErrors()
+	require.Nil(t, err)
+	require.Len(t, summaries, 1)
+	assert.Equal(t, uint(3), summaries[0].Count)
+}
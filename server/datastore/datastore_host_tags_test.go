@@ -0,0 +1,73 @@
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testHostTags(t *testing.T, ds kolide.Datastore) {
+	var hosts []*kolide.Host
+	for _, id := range []string{"1", "2", "3"} {
+		h, err := ds.NewHost(&kolide.Host{
+			DetailUpdateTime: time.Now(),
+			SeenTime:         time.Now(),
+			OsqueryHostID:    id,
+			NodeKey:          id,
+			UUID:             id,
+			HostName:         "host" + id,
+		})
+		require.Nil(t, err)
+		hosts = append(hosts, h)
+	}
+	h1, h2, h3 := hosts[0], hosts[1], hosts[2]
+
+	tags, err := ds.ListTagsForHost(h1.ID)
+	require.Nil(t, err)
+	assert.Empty(t, tags)
+
+	require.Nil(t, ds.SetHostTags(h1.ID, map[string]string{"env": "prod", "owner": "infra"}))
+	require.Nil(t, ds.SetHostTags(h2.ID, map[string]string{"env": "prod"}))
+	require.Nil(t, ds.SetHostTags(h3.ID, map[string]string{"env": "dev", "owner": "infra"}))
+
+	tags, err = ds.ListTagsForHost(h1.ID)
+	require.Nil(t, err)
+	assert.Equal(t, map[string]string{"env": "prod", "owner": "infra"}, tags)
+
+	// Setting tags replaces the existing tags
+	require.Nil(t, ds.SetHostTags(h1.ID, map[string]string{"env": "prod", "team": ""}))
+	tags, err = ds.ListTagsForHost(h1.ID)
+	require.Nil(t, err)
+	assert.Equal(t, map[string]string{"env": "prod", "team": ""}, tags)
+
+	ids, err := ds.TagIDsByName([]string{"env=prod", "owner=infra", "env=unknown"})
+	require.Nil(t, err)
+	assert.Len(t, ids, 2)
+
+	_, err = ds.TagIDsByName([]string{"env"})
+	assert.NotNil(t, err)
+
+	prodIDs, err := ds.TagIDsByName([]string{"env=prod"})
+	require.Nil(t, err)
+	metrics, err := ds.CountHostsInTargets(nil, nil, prodIDs, time.Now())
+	require.Nil(t, err)
+	assert.Equal(t, uint(2), metrics.TotalHosts)
+
+	metrics, err = ds.CountHostsInTargets([]uint{h3.ID}, nil, prodIDs, time.Now())
+	require.Nil(t, err)
+	assert.Equal(t, uint(3), metrics.TotalHosts)
+
+	// Merging keeps the survivor's value for tags set on both hosts
+	require.Nil(t, ds.MergeHosts(h2.ID, h3.ID))
+	tags, err = ds.ListTagsForHost(h2.ID)
+	require.Nil(t, err)
+	assert.Equal(t, map[string]string{"env": "prod", "owner": "infra"}, tags)
+
+	require.Nil(t, ds.SetHostTags(h2.ID, nil))
+	tags, err = ds.ListTagsForHost(h2.ID)
+	require.Nil(t, err)
+	assert.Empty(t, tags)
+}
@@ -204,7 +204,7 @@ func testListHost(t *testing.T, ds kolide.Datastore) {
 	err = ds.SaveHost(hosts[3])
 	require.Nil(t, err)
 
-	hosts2, err := ds.ListHosts(kolide.ListOptions{})
+	hosts2, err := ds.ListHosts(kolide.HostListOptions{})
 	require.Nil(t, err)
 	assert.Equal(t, len(hosts), len(hosts2))
 
@@ -215,7 +215,7 @@ func testListHost(t *testing.T, ds kolide.Datastore) {
 	assert.Equal(t, "en2", hosts2[3].NetworkInterfaces[0].Interface)
 
 	// Test with logic for only a few hosts
-	hosts2, err = ds.ListHosts(kolide.ListOptions{PerPage: 4, Page: 0})
+	hosts2, err = ds.ListHosts(kolide.HostListOptions{ListOptions: kolide.ListOptions{PerPage: 4, Page: 0}})
 	require.Nil(t, err)
 	assert.Equal(t, 4, len(hosts2))
 
@@ -227,11 +227,11 @@ func testListHost(t *testing.T, ds kolide.Datastore) {
 
 	err = ds.DeleteHost(hosts[0].ID)
 	require.Nil(t, err)
-	hosts2, err = ds.ListHosts(kolide.ListOptions{})
+	hosts2, err = ds.ListHosts(kolide.HostListOptions{})
 	require.Nil(t, err)
 	assert.Equal(t, len(hosts)-1, len(hosts2))
 
-	hosts, err = ds.ListHosts(kolide.ListOptions{})
+	hosts, err = ds.ListHosts(kolide.HostListOptions{})
 	require.Nil(t, err)
 	require.Equal(t, len(hosts2), len(hosts))
 	hosts[0].NetworkInterfaces = []*kolide.NetworkInterface{
@@ -247,7 +247,7 @@ func testListHost(t *testing.T, ds kolide.Datastore) {
 
 	err = ds.SaveHost(hosts[0])
 	require.Nil(t, err)
-	hosts2, err = ds.ListHosts(kolide.ListOptions{})
+	hosts2, err = ds.ListHosts(kolide.HostListOptions{})
 	require.Nil(t, err)
 	require.Equal(t, hosts[0].ID, hosts2[0].ID)
 	assert.Equal(t, len(hosts[0].NetworkInterfaces), len(hosts2[0].NetworkInterfaces))
@@ -842,3 +842,288 @@ func testHostAdditional(t *testing.T, ds kolide.Datastore) {
 	require.Nil(t, err)
 	assert.Equal(t, additional, *h.Additional)
 }
+
+func testListHostsFilter(t *testing.T, ds kolide.Datastore) {
+	hosts := []*kolide.Host{}
+	for i, attrs := range []struct {
+		hostname, platform, osqueryVersion string
+		memory                             int
+	}{
+		{"web1.example.com", "darwin", "4.2.0", 8 << 30},
+		{"web2.example.com", "ubuntu", "3.4.0", 16 << 30},
+		{"db1.example.com", "ubuntu", "4.10.1", 32 << 30},
+	} {
+		host, err := ds.NewHost(&kolide.Host{
+			DetailUpdateTime: time.Now(),
+			SeenTime:         time.Now(),
+			OsqueryHostID:    strconv.Itoa(i),
+			NodeKey:          strconv.Itoa(i),
+			UUID:             strconv.Itoa(i),
+			HostName:         attrs.hostname,
+			Platform:         attrs.platform,
+			OsqueryVersion:   attrs.osqueryVersion,
+			PhysicalMemory:   attrs.memory,
+		})
+		require.Nil(t, err)
+		hosts = append(hosts, host)
+	}
+
+	label, err := ds.NewLabel(&kolide.Label{Name: "Production", Query: "select 1"})
+	require.Nil(t, err)
+	require.Nil(t, ds.RecordLabelQueryExecutions(hosts[2], map[uint]bool{label.ID: true}, time.Now()))
+
+	var testCases = []struct {
+		filter   string
+		expected []uint
+	}{
+		{"", []uint{hosts[0].ID, hosts[1].ID, hosts[2].ID}},
+		{"platform:ubuntu", []uint{hosts[1].ID, hosts[2].ID}},
+		{"-platform:ubuntu", []uint{hosts[0].ID}},
+		{"hostname:web*", []uint{hosts[0].ID, hosts[1].ID}},
+		{"hostname:web* platform:ubuntu", []uint{hosts[1].ID}},
+		{"osquery_version<4.2", []uint{hosts[1].ID}},
+		{"osquery_version>=4.2", []uint{hosts[0].ID, hosts[2].ID}},
+		{"osquery_version>4.9", []uint{hosts[2].ID}},
+		{"memory>8GB", []uint{hosts[1].ID, hosts[2].ID}},
+		{`label:"Production"`, []uint{hosts[2].ID}},
+		{"-label:Production", []uint{hosts[0].ID, hosts[1].ID}},
+		{"hostname:nomatch", []uint{}},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.filter, func(t *testing.T) {
+			filter, err := kolide.ParseHostFilter(tt.filter)
+			require.Nil(t, err)
+			listed, err := ds.ListHosts(kolide.HostListOptions{Filter: filter})
+			require.Nil(t, err)
+			ids := []uint{}
+			for _, h := range listed {
+				ids = append(ids, h.ID)
+			}
+			assert.ElementsMatch(t, tt.expected, ids)
+		})
+	}
+}
+
+func testHostIdentityAndMerge(t *testing.T, ds kolide.Datastore) {
+	original, err := ds.EnrollHost("original", "key1", "default")
+	require.Nil(t, err)
+	original.UUID = "uuid1"
+	original.HostName = "foo.local"
+	original.HardwareSerial = "serial1"
+	require.Nil(t, ds.SaveHost(original))
+
+	_, err = ds.FindHostByIdentity(kolide.HostIdentityUUID, kolide.HostIdentity{UUID: "uuid2"})
+	assert.True(t, kolide.IsNotFound(err))
+	_, err = ds.FindHostByIdentity(kolide.HostIdentitySerial, kolide.HostIdentity{})
+	assert.True(t, kolide.IsNotFound(err))
+	_, err = ds.FindHostByIdentity(kolide.HostIdentityHostnameSerial,
+		kolide.HostIdentity{HostName: "bar.local", HardwareSerial: "serial1"})
+	assert.True(t, kolide.IsNotFound(err))
+
+	found, err := ds.FindHostByIdentity(kolide.HostIdentityUUID, kolide.HostIdentity{UUID: "uuid1"})
+	require.Nil(t, err)
+	assert.Equal(t, original.ID, found.ID)
+	found, err = ds.FindHostByIdentity(kolide.HostIdentityHostnameSerial,
+		kolide.HostIdentity{HostName: "foo.local", HardwareSerial: "serial1"})
+	require.Nil(t, err)
+	assert.Equal(t, original.ID, found.ID)
+
+	// Placeholder serials are not matched, the UUID is used instead
+	whitebox, err := ds.EnrollHost("whitebox", "key5", "default")
+	require.Nil(t, err)
+	whitebox.UUID = "uuid3"
+	whitebox.HostName = "whitebox.local"
+	whitebox.HardwareSerial = "To Be Filled By O.E.M."
+	require.Nil(t, ds.SaveHost(whitebox))
+	for _, strategy := range []kolide.HostIdentityStrategy{kolide.HostIdentitySerial, kolide.HostIdentityHostnameSerial} {
+		_, err = ds.FindHostByIdentity(strategy,
+			kolide.HostIdentity{UUID: "uuid4", HostName: "whitebox.local", HardwareSerial: "To Be Filled By O.E.M."})
+		assert.True(t, kolide.IsNotFound(err))
+		_, err = ds.FindHostByIdentity(strategy,
+			kolide.HostIdentity{HostName: "whitebox.local", HardwareSerial: "to be filled by o.e.m."})
+		assert.True(t, kolide.IsNotFound(err))
+		found, err = ds.FindHostByIdentity(strategy,
+			kolide.HostIdentity{UUID: "uuid3", HostName: "whitebox.local", HardwareSerial: "To Be Filled By O.E.M."})
+		require.Nil(t, err)
+		assert.Equal(t, whitebox.ID, found.ID)
+	}
+	require.Nil(t, ds.DeleteHost(whitebox.ID))
+
+	// The machine was enrolled again as a duplicate before re-enrolling
+	// with the same identifier
+	duplicate, err := ds.EnrollHost("reimaged", "key2", "default")
+	require.Nil(t, err)
+
+	label, err := ds.NewLabel(&kolide.Label{Name: "label", Query: "select 1"})
+	require.Nil(t, err)
+	require.Nil(t, ds.RecordLabelQueryExecutions(duplicate, map[uint]bool{label.ID: true}, time.Now()))
+
+	host, err := ds.ReenrollHost(original.ID, "reimaged", "key3", "default")
+	require.Nil(t, err)
+	assert.Equal(t, original.ID, host.ID)
+	assert.Equal(t, "reimaged", host.OsqueryHostID)
+	assert.Equal(t, "key3", host.NodeKey)
+
+	_, err = ds.Host(duplicate.ID)
+	assert.NotNil(t, err)
+	labels, err := ds.ListLabelsForHost(original.ID)
+	require.Nil(t, err)
+	require.Len(t, labels, 1)
+	assert.Equal(t, label.ID, labels[0].ID)
+
+	authed, err := ds.AuthenticateHost("key3")
+	require.Nil(t, err)
+	assert.Equal(t, original.ID, authed.ID)
+
+	// Merging explicitly
+	orphan, err := ds.EnrollHost("orphan", "key4", "default")
+	require.Nil(t, err)
+	orphan.HostName = "orphan.local"
+	require.Nil(t, ds.SaveHost(orphan))
+	orphan.HostName = "renamed.local"
+	require.Nil(t, ds.SaveHost(orphan))
+
+	// The software and campaign state of the duplicate is moved
+	require.Nil(t, ds.SaveHostSoftware(orphan.ID, []kolide.Software{
+		{Name: "osquery", Version: "4.3.0", Source: "deb_packages"},
+	}))
+	user := test.NewUser(t, ds, "Zach", "zwass", "zwass@kolide.co", true)
+	query := test.NewQuery(t, ds, "test", "select * from time", user.ID, false)
+	campaign := test.NewCampaign(t, ds, query.ID, kolide.QueryRunning, time.Now())
+	test.AddHostToCampaign(t, ds, campaign.ID, orphan.ID)
+	_, err = ds.NewDistributedQueryExecution(&kolide.DistributedQueryExecution{
+		HostID:                     orphan.ID,
+		DistributedQueryCampaignID: campaign.ID,
+		Status:                     kolide.ExecutionSucceeded,
+	})
+	require.Nil(t, err)
+	_, err = ds.SaveCampaignResult(&kolide.CampaignResult{
+		DistributedQueryCampaignID: campaign.ID,
+		HostID:                     orphan.ID,
+		HostName:                   orphan.HostName,
+	}, 100)
+	require.Nil(t, err)
+
+	require.Nil(t, ds.MergeHosts(original.ID, orphan.ID))
+	_, err = ds.Host(orphan.ID)
+	assert.NotNil(t, err)
+	history, err := ds.ListHostHistory(original.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, "renamed.local", history[0].NewValue)
+
+	software, err := ds.ListSoftwareForHost(original.ID)
+	require.Nil(t, err)
+	require.Len(t, software, 1)
+	assert.Equal(t, "osquery", software[0].Name)
+	hostIDs, _, _, err := ds.DistributedQueryCampaignTargetIDs(campaign.ID)
+	require.Nil(t, err)
+	assert.Equal(t, []uint{original.ID}, hostIDs)
+	campaignHosts, err := ds.ListDistributedQueryCampaignHosts(campaign.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, campaignHosts, 1)
+	assert.Equal(t, original.ID, campaignHosts[0].HostID)
+	assert.Equal(t, kolide.ExecutionSucceeded, campaignHosts[0].Status)
+	results, err := ds.ListCampaignResults(campaign.ID, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, original.ID, results[0].HostID)
+
+	assert.NotNil(t, ds.MergeHosts(original.ID, original.ID))
+	assert.NotNil(t, ds.MergeHosts(original.ID, orphan.ID))
+}
+
+func testExpireHosts(t *testing.T, ds kolide.Datastore) {
+	now := time.Now().UTC().Truncate(time.Second)
+	window := 10 * 24 * time.Hour
+
+	var hosts []*kolide.Host
+	for i, seen := range []time.Time{
+		now,
+		now.Add(-window + time.Hour),
+		now.Add(-window - time.Hour),
+		now.Add(-2 * window),
+	} {
+		id := strconv.Itoa(i)
+		h, err := ds.NewHost(&kolide.Host{
+			DetailUpdateTime: now,
+			SeenTime:         seen,
+			OsqueryHostID:    id,
+			NodeKey:          id,
+			UUID:             id,
+			HostName:         "host" + id,
+		})
+		require.Nil(t, err)
+		hosts = append(hosts, h)
+	}
+
+	expired, err := ds.ListExpiredHosts(now, window)
+	require.Nil(t, err)
+	require.Len(t, expired, 2)
+	assert.Equal(t, hosts[2].ID, expired[0].ID)
+	assert.Equal(t, hosts[3].ID, expired[1].ID)
+
+	ids, err := ds.ExpireHosts(now, window)
+	require.Nil(t, err)
+	assert.ElementsMatch(t, []uint{hosts[2].ID, hosts[3].ID}, ids)
+
+	_, err = ds.Host(hosts[2].ID)
+	assert.NotNil(t, err)
+	_, err = ds.Host(hosts[1].ID)
+	assert.Nil(t, err)
+
+	ids, err = ds.ExpireHosts(now, window)
+	require.Nil(t, err)
+	assert.Empty(t, ids)
+}
+
+func testDeleteHosts(t *testing.T, ds kolide.Datastore) {
+	var ids []uint
+	for i := 0; i < 3; i++ {
+		id := strconv.Itoa(i)
+		h, err := ds.NewHost(&kolide.Host{
+			DetailUpdateTime: time.Now(),
+			SeenTime:         time.Now(),
+			OsqueryHostID:    id,
+			NodeKey:          id,
+			UUID:             id,
+			HostName:         "host" + id,
+		})
+		require.Nil(t, err)
+		ids = append(ids, h.ID)
+	}
+
+	existing, err := ds.ExistingHostIDs([]uint{9999, ids[2], ids[0]})
+	require.Nil(t, err)
+	assert.Equal(t, []uint{ids[0], ids[2]}, existing)
+
+	deleted, err := ds.DeleteHosts(nil)
+	require.Nil(t, err)
+	assert.Equal(t, uint(0), deleted)
+
+	// A rolled back delete leaves the hosts in place
+	tx, err := ds.Begin()
+	require.Nil(t, err)
+	deleted, err = ds.DeleteHosts(ids[:2], kolide.HasTransaction(tx))
+	require.Nil(t, err)
+	assert.Equal(t, uint(2), deleted)
+	require.Nil(t, tx.Rollback())
+
+	if ds.Name() != "inmem" {
+		hosts, err := ds.ListHosts(kolide.HostListOptions{})
+		require.Nil(t, err)
+		assert.Len(t, hosts, 3)
+	}
+
+	tx, err = ds.Begin()
+	require.Nil(t, err)
+	deleted, err = ds.DeleteHosts([]uint{ids[0], ids[1], 9999}, kolide.HasTransaction(tx))
+	require.Nil(t, err)
+	assert.Equal(t, uint(2), deleted)
+	require.Nil(t, tx.Commit())
+
+	hosts, err := ds.ListHosts(kolide.HostListOptions{})
+	require.Nil(t, err)
+	require.Len(t, hosts, 1)
+	assert.Equal(t, ids[2], hosts[0].ID)
+}
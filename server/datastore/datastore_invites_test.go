@@ -16,6 +16,7 @@ func testCreateInvite(t *testing.T, ds kolide.Datastore) {
 		Email: "user@foo.com",
 		Name:  "user",
 		Token: "some_user",
+		Role:  kolide.RoleObserver,
 	}
 
 	invite, err := ds.NewInvite(invite)
@@ -25,6 +26,7 @@ func testCreateInvite(t *testing.T, ds kolide.Datastore) {
 	require.Nil(t, err)
 	assert.Equal(t, invite.ID, verify.ID)
 	assert.Equal(t, invite.Email, verify.Email)
+	assert.Equal(t, kolide.RoleObserver, verify.Role)
 }
 
 func setupTestInvites(t *testing.T, ds kolide.Datastore) {
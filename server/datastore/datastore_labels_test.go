@@ -137,6 +137,16 @@ func testLabels(t *testing.T, db kolide.Datastore) {
 	labels, err = db.ListLabelsForHost(hosts[0].ID)
 	assert.Nil(t, err)
 	assert.Empty(t, labels)
+
+	// Labels are loaded for several hosts at once
+	hostLabels, err := db.ListLabelsForHosts([]uint{host.ID, hosts[0].ID})
+	assert.Nil(t, err)
+	assert.Len(t, hostLabels, 1)
+	if assert.Len(t, hostLabels[host.ID], 2) {
+		labelNames := []string{hostLabels[host.ID][0].Name, hostLabels[host.ID][1].Name}
+		sort.Strings(labelNames)
+		assert.Equal(t, []string{"label2", "label3"}, labelNames)
+	}
 }
 
 func testManagingLabelsOnPacks(t *testing.T, ds kolide.Datastore) {
@@ -492,3 +502,62 @@ func testSaveLabel(t *testing.T, db kolide.Datastore) {
 	assert.Equal(t, label.Name, saved.Name)
 	assert.Equal(t, label.Description, saved.Description)
 }
+
+func testListLabelExecutionsForHost(t *testing.T, db kolide.Datastore) {
+	host, err := db.NewHost(&kolide.Host{
+		DetailUpdateTime: time.Now(),
+		SeenTime:         time.Now(),
+		OsqueryHostID:    "1",
+		NodeKey:          "1",
+		UUID:             "1",
+		HostName:         "foo.local",
+		Platform:         "darwin",
+	})
+	require.Nil(t, err)
+
+	specs := []*kolide.LabelSpec{
+		{ID: 1, Name: "all", Query: "select 1"},
+		{ID: 2, Name: "darwin", Query: "select 1", Platform: "darwin"},
+		{ID: 3, Name: "failing", Query: "select * from foo"},
+		{ID: 4, Name: "pending", Query: "select 1"},
+		{ID: 5, Name: "ubuntu", Query: "select 1", Platform: "ubuntu"},
+	}
+	err = db.ApplyLabelSpecs(specs)
+	require.Nil(t, err)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	err = db.RecordLabelQueryExecutions(host, map[uint]bool{1: true, 2: false, 3: true}, now)
+	require.Nil(t, err)
+	err = db.RecordLabelQueryErrors(host, map[uint]string{3: "no such table: foo"}, now)
+	require.Nil(t, err)
+
+	executions, err := db.ListLabelExecutionsForHost(host)
+	require.Nil(t, err)
+	require.Len(t, executions, 4)
+
+	assert.Equal(t, "all", executions[0].LabelName)
+	assert.True(t, executions[0].Matches)
+	assert.Nil(t, executions[0].Error)
+	require.NotNil(t, executions[0].UpdatedAt)
+	assert.Equal(t, now, executions[0].UpdatedAt.UTC())
+
+	assert.Equal(t, "darwin", executions[1].LabelName)
+	assert.False(t, executions[1].Matches)
+	assert.Nil(t, executions[1].Error)
+
+	assert.Equal(t, "failing", executions[2].LabelName)
+	assert.False(t, executions[2].Matches)
+	require.NotNil(t, executions[2].Error)
+	assert.Equal(t, "no such table: foo", *executions[2].Error)
+
+	assert.Equal(t, "pending", executions[3].LabelName)
+	assert.Nil(t, executions[3].UpdatedAt)
+
+	// A successful execution clears the error
+	err = db.RecordLabelQueryExecutions(host, map[uint]bool{3: true}, now)
+	require.Nil(t, err)
+	executions, err = db.ListLabelExecutionsForHost(host)
+	require.Nil(t, err)
+	assert.True(t, executions[2].Matches)
+	assert.Nil(t, executions[2].Error)
+}
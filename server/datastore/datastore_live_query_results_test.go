@@ -0,0 +1,140 @@
+package datastore
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLiveQueryResults(t *testing.T, ds kolide.Datastore) {
+	// Results are not stored without readers
+	saved, err := ds.NewLiveQueryResult(1, []byte(`{"host":1}`), time.Now().Add(-time.Hour))
+	require.Nil(t, err)
+	assert.False(t, saved)
+
+	reader1, err := ds.NewLiveQueryReader(1)
+	require.Nil(t, err)
+	reader2, err := ds.NewLiveQueryReader(1)
+	require.Nil(t, err)
+	otherReader, err := ds.NewLiveQueryReader(2)
+	require.Nil(t, err)
+
+	// Nor when the readers are inactive
+	saved, err = ds.NewLiveQueryResult(1, []byte(`{"host":1}`), time.Now().Add(time.Hour))
+	require.Nil(t, err)
+	assert.False(t, saved)
+
+	for _, res := range []string{`{"host":1}`, `{"host":2}`, `{"host":3}`} {
+		saved, err = ds.NewLiveQueryResult(1, []byte(res), time.Now().Add(-time.Hour))
+		require.Nil(t, err)
+		assert.True(t, saved)
+	}
+	saved, err = ds.NewLiveQueryResult(2, []byte(`{"host":1}`), time.Now().Add(-time.Hour))
+	require.Nil(t, err)
+	assert.True(t, saved)
+
+	// Results are listed in order after the cursor
+	results, err := ds.ListLiveQueryResults(1, 0, 2)
+	require.Nil(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, []byte(`{"host":1}`), results[0].Result)
+	assert.Equal(t, []byte(`{"host":2}`), results[1].Result)
+	results, err = ds.ListLiveQueryResults(1, results[1].Seq, 2)
+	require.Nil(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, []byte(`{"host":3}`), results[0].Result)
+	results, err = ds.ListLiveQueryResults(1, results[0].Seq, 2)
+	require.Nil(t, err)
+	assert.Empty(t, results)
+
+	require.Nil(t, ds.TouchLiveQueryReader(reader1))
+	assert.True(t, kolide.IsNotFound(ds.TouchLiveQueryReader(otherReader+100)))
+
+	// Results are kept until the last reader of the campaign is deleted
+	require.Nil(t, ds.DeleteLiveQueryReader(reader1))
+	results, err = ds.ListLiveQueryResults(1, 0, 10)
+	require.Nil(t, err)
+	assert.Len(t, results, 3)
+	require.Nil(t, ds.DeleteLiveQueryReader(reader2))
+	results, err = ds.ListLiveQueryResults(1, 0, 10)
+	require.Nil(t, err)
+	assert.Empty(t, results)
+	assert.True(t, kolide.IsNotFound(ds.DeleteLiveQueryReader(reader2)))
+
+	// Cleanup keeps active readers and recent results
+	require.Nil(t, ds.CleanupLiveQueryResults(time.Now().Add(-time.Hour), time.Now().Add(-time.Hour)))
+	results, err = ds.ListLiveQueryResults(2, 0, 10)
+	require.Nil(t, err)
+	assert.Len(t, results, 1)
+
+	// Then removes expired results
+	require.Nil(t, ds.CleanupLiveQueryResults(time.Now().Add(-time.Hour), time.Now().Add(time.Hour)))
+	results, err = ds.ListLiveQueryResults(2, 0, 10)
+	require.Nil(t, err)
+	assert.Empty(t, results)
+	require.Nil(t, ds.TouchLiveQueryReader(otherReader))
+
+	// And inactive readers, with their results
+	saved, err = ds.NewLiveQueryResult(2, []byte(`{"host":2}`), time.Now().Add(-time.Hour))
+	require.Nil(t, err)
+	assert.True(t, saved)
+	require.Nil(t, ds.CleanupLiveQueryResults(time.Now().Add(time.Hour), time.Now().Add(-time.Hour)))
+	results, err = ds.ListLiveQueryResults(2, 0, 10)
+	require.Nil(t, err)
+	assert.Empty(t, results)
+	assert.True(t, kolide.IsNotFound(ds.TouchLiveQueryReader(otherReader)))
+}
+
+func testLiveQueryResultsConcurrentWriters(t *testing.T, ds kolide.Datastore) {
+	reader, err := ds.NewLiveQueryReader(3)
+	require.Nil(t, err)
+	defer ds.DeleteLiveQueryReader(reader)
+
+	// Writers commit their results in an arbitrary order, while a reader
+	// pages through them with a cursor. No result may be skipped.
+	const writers, perWriter = 8, 25
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				saved, err := ds.NewLiveQueryResult(3, []byte(fmt.Sprintf(`{"host":"%d-%d"}`, w, i)), time.Now().Add(-time.Hour))
+				assert.Nil(t, err)
+				assert.True(t, saved)
+			}
+		}(w)
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	read := map[string]bool{}
+	var cursor uint
+	for finished := false; ; {
+		select {
+		case <-done:
+			finished = true
+		default:
+		}
+		results, err := ds.ListLiveQueryResults(3, cursor, 10)
+		require.Nil(t, err)
+		for _, res := range results {
+			assert.Equal(t, cursor+1, res.Seq)
+			cursor = res.Seq
+			read[string(res.Result)] = true
+		}
+		// Read once more after the writers finished
+		if finished && len(results) == 0 {
+			break
+		}
+	}
+	assert.Len(t, read, writers*perWriter)
+}
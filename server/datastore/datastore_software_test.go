@@ -0,0 +1,85 @@
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSaveHostSoftware(t *testing.T, ds kolide.Datastore) {
+	host1, err := ds.NewHost(&kolide.Host{
+		DetailUpdateTime: time.Now(),
+		SeenTime:         time.Now(),
+		OsqueryHostID:    "1",
+		NodeKey:          "1",
+		UUID:             "1",
+		HostName:         "foo.local",
+	})
+	require.Nil(t, err)
+	host2, err := ds.NewHost(&kolide.Host{
+		DetailUpdateTime: time.Now(),
+		SeenTime:         time.Now(),
+		OsqueryHostID:    "2",
+		NodeKey:          "2",
+		UUID:             "2",
+		HostName:         "bar.local",
+	})
+	require.Nil(t, err)
+
+	software1 := []kolide.Software{
+		{Name: "openssl", Version: "1.0.2k", Source: "rpm_packages"},
+		{Name: "bash", Version: "4.2.46", Source: "rpm_packages"},
+	}
+	software2 := []kolide.Software{
+		{Name: "openssl", Version: "1.0.2k", Source: "rpm_packages"},
+		{Name: "openssl", Version: "1.1.1d", Source: "deb_packages"},
+	}
+	require.Nil(t, ds.SaveHostSoftware(host1.ID, software1))
+	require.Nil(t, ds.SaveHostSoftware(host2.ID, software2))
+
+	software, err := ds.ListSoftwareForHost(host1.ID)
+	require.Nil(t, err)
+	require.Len(t, software, 2)
+	assert.Equal(t, "bash", software[0].Name)
+	assert.Equal(t, "openssl", software[1].Name)
+	assert.Equal(t, "1.0.2k", software[1].Version)
+
+	found, err := ds.ListSoftware("openssl", "1.0.2", kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, "1.0.2k", found[0].Version)
+	assert.ElementsMatch(t, []kolide.SoftwareHost{
+		{ID: host1.ID, HostName: "foo.local"},
+		{ID: host2.ID, HostName: "bar.local"},
+	}, found[0].Hosts)
+
+	found, err = ds.ListSoftware("openssl", "", kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, found, 2)
+
+	// Wildcards in the version are matched literally
+	found, err = ds.ListSoftware("openssl", "1.%", kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, found, 0)
+
+	// Replacing the software of a host removes software it no longer
+	// reports
+	require.Nil(t, ds.SaveHostSoftware(host1.ID, software1[1:]))
+	found, err = ds.ListSoftware("openssl", "1.0.2", kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, []kolide.SoftwareHost{{ID: host2.ID, HostName: "bar.local"}}, found[0].Hosts)
+
+	// Software no longer installed on any host is not listed
+	require.Nil(t, ds.SaveHostSoftware(host2.ID, nil))
+	found, err = ds.ListSoftware("openssl", "", kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, found, 0)
+
+	software, err = ds.ListSoftwareForHost(host2.ID)
+	require.Nil(t, err)
+	assert.Len(t, software, 0)
+}
@@ -97,4 +97,24 @@ var testFunctions = [...]func(*testing.T, kolide.Datastore){
 	testLabelIDsByName,
 	testListLabelsForPack,
 	testHostAdditional,
+	testSaveHostSoftware,
+	testListHostsFilter,
+	testHostHistory,
+	testHostIdentityAndMerge,
+	testHostTags,
+	testExpireHosts,
+	testDeleteHosts,
+	testListLabelExecutionsForHost,
+	testHostStatusLogs,
+	testCampaignResults,
+	testCleanupCampaignResults,
+	testListDistributedQueryCampaigns,
+	testDeferredDistributedQueryCampaigns,
+	testPersistedDistributedQueryCampaignExpiry,
+	testListDistributedQueryCampaignHosts,
+	testDistributedQueryCampaignViewers,
+	testLiveQueryResults,
+	testLiveQueryResultsConcurrentWriters,
+	testAPITokens,
+	testActivities,
 }
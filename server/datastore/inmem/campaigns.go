@@ -33,33 +33,91 @@ func (d *Datastore) SaveDistributedQueryCampaign(camp *kolide.DistributedQueryCa
 	d.mtx.Lock()
 	defer d.mtx.Unlock()
 
-	if _, ok := d.distributedQueryCampaigns[camp.ID]; !ok {
+	existing, ok := d.distributedQueryCampaigns[camp.ID]
+	if !ok {
 		return notFound("DistributedQueryCampaign").WithID(camp.ID)
 	}
 
-	d.distributedQueryCampaigns[camp.ID] = *camp
+	// Received results are only counted by NewDistributedQueryExecution.
+	saved := *camp
+	saved.ReceivedResults = existing.ReceivedResults
+	d.distributedQueryCampaigns[camp.ID] = saved
 	return nil
 }
 
-func (d *Datastore) DistributedQueryCampaignTargetIDs(id uint) (hostIDs []uint, labelIDs []uint, err error) {
+// campaignViewer is a result stream following a campaign.
+type campaignViewer struct {
+	campaignID uint
+	updatedAt  time.Time
+}
+
+func (d *Datastore) NewDistributedQueryCampaignViewer(campaignID uint) (uint, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if _, ok := d.distributedQueryCampaigns[campaignID]; !ok {
+		return 0, notFound("DistributedQueryCampaign").WithID(campaignID)
+	}
+	viewer := campaignViewer{campaignID: campaignID, updatedAt: time.Now()}
+	id := d.nextID(viewer)
+	d.distributedQueryCampaignViewers[id] = viewer
+	return id, nil
+}
+
+func (d *Datastore) TouchDistributedQueryCampaignViewer(id uint) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	viewer, ok := d.distributedQueryCampaignViewers[id]
+	if !ok {
+		return notFound("DistributedQueryCampaignViewer").WithID(id)
+	}
+	viewer.updatedAt = time.Now()
+	d.distributedQueryCampaignViewers[id] = viewer
+	return nil
+}
+
+func (d *Datastore) DeleteDistributedQueryCampaignViewer(id uint, activeSince time.Time) (uint, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	viewer, ok := d.distributedQueryCampaignViewers[id]
+	if !ok {
+		return 0, notFound("DistributedQueryCampaignViewer").WithID(id)
+	}
+	delete(d.distributedQueryCampaignViewers, id)
+
+	var remaining uint
+	for _, other := range d.distributedQueryCampaignViewers {
+		if other.campaignID == viewer.campaignID && !other.updatedAt.Before(activeSince) {
+			remaining++
+		}
+	}
+	return remaining, nil
+}
+
+func (d *Datastore) DistributedQueryCampaignTargetIDs(id uint) (hostIDs []uint, labelIDs []uint, tagIDs []uint, err error) {
 	d.mtx.Lock()
 	defer d.mtx.Unlock()
 
 	hostIDs = []uint{}
 	labelIDs = []uint{}
+	tagIDs = []uint{}
 	for _, target := range d.distributedQueryCampaignTargets {
 		if target.DistributedQueryCampaignID == id {
 			if target.Type == kolide.TargetHost {
 				hostIDs = append(hostIDs, target.TargetID)
 			} else if target.Type == kolide.TargetLabel {
 				labelIDs = append(labelIDs, target.TargetID)
+			} else if target.Type == kolide.TargetTag {
+				tagIDs = append(tagIDs, target.TargetID)
 			} else {
-				return []uint{}, []uint{}, fmt.Errorf("invalid target type: %d", target.Type)
+				return []uint{}, []uint{}, []uint{}, fmt.Errorf("invalid target type: %d", target.Type)
 			}
 		}
 	}
 
-	return hostIDs, labelIDs, nil
+	return hostIDs, labelIDs, tagIDs, nil
 }
 
 func (d *Datastore) NewDistributedQueryCampaignTarget(target *kolide.DistributedQueryCampaignTarget) (*kolide.DistributedQueryCampaignTarget, error) {
@@ -76,27 +134,101 @@ func (d *Datastore) NewDistributedQueryExecution(exec *kolide.DistributedQueryEx
 	d.mtx.Lock()
 	defer d.mtx.Unlock()
 
+	exec.ID = 0
 	for _, e := range d.distributedQueryExecutions {
 		if exec.HostID == e.HostID && exec.DistributedQueryCampaignID == e.DistributedQueryCampaignID {
-			fmt.Printf("%+v -- %+v\n", exec, d.distributedQueryExecutions)
-			return exec, alreadyExists("DistributedQueryExecution", exec.HostID)
+			if e.Status != kolide.ExecutionRequested {
+				fmt.Printf("%+v -- %+v\n", exec, d.distributedQueryExecutions)
+				return exec, alreadyExists("DistributedQueryExecution", exec.HostID)
+			}
+			// Replace the execution recorded when the query was sent
+			exec.ID = e.ID
+			exec.RequestedAt = e.RequestedAt
+			if exec.ExecutionDuration == 0 && exec.RequestedAt != nil && exec.ReceivedAt.After(*exec.RequestedAt) {
+				exec.ExecutionDuration = exec.ReceivedAt.Sub(*exec.RequestedAt)
+			}
 		}
 	}
 
-	exec.ID = d.nextID(exec)
+	if exec.ID == 0 {
+		exec.ID = d.nextID(exec)
+	}
 	d.distributedQueryExecutions[exec.ID] = *exec
 
+	if campaign, ok := d.distributedQueryCampaigns[exec.DistributedQueryCampaignID]; ok {
+		campaign.ReceivedResults++
+		d.distributedQueryCampaigns[campaign.ID] = campaign
+	}
+
 	return exec, nil
 }
 
+func (d *Datastore) MarkDistributedQueriesRequested(hostID uint, campaignIDs []uint, now time.Time) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	for _, id := range campaignIDs {
+		if _, ok := d.distributedQueryCampaigns[id]; !ok {
+			continue
+		}
+		requested := false
+		for _, e := range d.distributedQueryExecutions {
+			if e.HostID == hostID && e.DistributedQueryCampaignID == id {
+				requested = true
+				break
+			}
+		}
+		if requested {
+			continue
+		}
+		exec := kolide.DistributedQueryExecution{
+			HostID:                     hostID,
+			DistributedQueryCampaignID: id,
+			Status:                     kolide.ExecutionRequested,
+			RequestedAt:                &now,
+		}
+		exec.ID = d.nextID(exec)
+		d.distributedQueryExecutions[exec.ID] = exec
+	}
+
+	return nil
+}
+
 func (d *Datastore) CleanupDistributedQueryCampaigns(now time.Time) (expired uint, deleted uint, err error) {
 	d.mtx.Lock()
 	defer d.mtx.Unlock()
 
-	// First expire old waiting and running campaigns
+	// Find the campaigns only followed by viewers that timed out, and
+	// delete these viewers
+	activeSince := now.Add(-kolide.CampaignViewerTimeout)
+	activeViewers := map[uint]bool{}
+	for _, v := range d.distributedQueryCampaignViewers {
+		activeViewers[v.campaignID] = activeViewers[v.campaignID] || !v.updatedAt.Before(activeSince)
+	}
+	for id, v := range d.distributedQueryCampaignViewers {
+		if v.updatedAt.Before(activeSince) {
+			delete(d.distributedQueryCampaignViewers, id)
+		}
+	}
+
+	// First expire old waiting and running campaigns, and running
+	// campaigns that are only followed by viewers that timed out
 	for id, c := range d.distributedQueryCampaigns {
+		active, hasViewers := activeViewers[id]
+		if c.ExpiresAt != nil {
+			if c.Status != kolide.QueryComplete && c.ExpiresAt.Before(now) {
+				c.Status = kolide.QueryComplete
+				d.distributedQueryCampaigns[id] = c
+				expired++
+				continue
+			}
+			if c.Deferred {
+				continue
+			}
+		}
 		if (c.Status == kolide.QueryWaiting && c.CreatedAt.Before(now.Add(-1*time.Minute))) ||
-			(c.Status == kolide.QueryRunning && c.CreatedAt.Before(now.Add(-24*time.Hour))) {
+			(c.Status == kolide.QueryRunning && c.ExpiresAt == nil && c.CreatedAt.Before(now.Add(-24*time.Hour))) ||
+			(c.Status == kolide.QueryRunning && c.ExpiresAt == nil && hasViewers && !active) {
 			c.Status = kolide.QueryComplete
 			d.distributedQueryCampaigns[id] = c
 			expired++
@@ -106,7 +238,7 @@ func (d *Datastore) CleanupDistributedQueryCampaigns(now time.Time) (expired uin
 	// Now delete executions for expired campaigns
 	for id, e := range d.distributedQueryExecutions {
 		c, ok := d.distributedQueryCampaigns[e.DistributedQueryCampaignID]
-		if !ok || c.Status == kolide.QueryComplete {
+		if !ok || (c.Status == kolide.QueryComplete && (!c.Deferred || (c.ExpiresAt != nil && c.ExpiresAt.Before(now)))) {
 			delete(d.distributedQueryExecutions, id)
 			deleted++
 		}
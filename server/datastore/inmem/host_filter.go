@@ -0,0 +1,135 @@
+package inmem
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+// hostMatchesFilter returns whether the host matches all of the terms in the
+// filter. The caller must hold d.mtx.
+func (d *Datastore) hostMatchesFilter(host *kolide.Host, filter *kolide.HostFilter, now time.Time) bool {
+	if filter == nil {
+		return true
+	}
+	for _, term := range filter.Terms {
+		if d.hostMatchesTerm(host, term, now) == term.Negate {
+			return false
+		}
+	}
+	return true
+}
+
+func (d *Datastore) hostMatchesTerm(host *kolide.Host, term kolide.HostFilterTerm, now time.Time) bool {
+	switch term.Field {
+	case "hostname":
+		return matchHostFilterString(host.HostName, term.Value)
+	case "computer_name":
+		return matchHostFilterString(host.ComputerName, term.Value)
+	case "uuid":
+		return matchHostFilterString(host.UUID, term.Value)
+	case "platform":
+		return matchHostFilterString(host.Platform, term.Value)
+	case "os_version":
+		return matchHostFilterString(host.OSVersion, term.Value)
+	case "hardware_serial":
+		return matchHostFilterString(host.HardwareSerial, term.Value)
+	case "hardware_vendor":
+		return matchHostFilterString(host.HardwareVendor, term.Value)
+	case "hardware_model":
+		return matchHostFilterString(host.HardwareModel, term.Value)
+	case "osquery_version":
+		return compareHostFilter(compareVersions(host.OsqueryVersion, term.Version), term.Op)
+	case "memory":
+		return compareHostFilter(compareInts(int64(host.PhysicalMemory), term.Number), term.Op)
+	case "cpu_physical_cores":
+		return compareHostFilter(compareInts(int64(host.CPUPhysicalCores), term.Number), term.Op)
+	case "cpu_logical_cores":
+		return compareHostFilter(compareInts(int64(host.CPULogicalCores), term.Number), term.Op)
+	case "status":
+		if term.Value == "new" {
+			return host.IsNew(now)
+		}
+		return host.Status(now) == term.Value
+	case "label":
+		for _, lqe := range d.labelQueryExecutions {
+			if lqe.HostID != host.ID || !lqe.Matches {
+				continue
+			}
+			label, ok := d.labels[lqe.LabelID]
+			if ok && matchHostFilterString(label.Name, term.Value) {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// matchHostFilterString matches the value against the pattern, in which "*"
+// matches any sequence of characters. Matching is case-insensitive to mirror
+// the default MySQL collation.
+func matchHostFilterString(value, pattern string) bool {
+	value, pattern = strings.ToLower(value), strings.ToLower(pattern)
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return value == pattern
+	}
+
+	if !strings.HasPrefix(value, parts[0]) {
+		return false
+	}
+	value = value[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(value, part)
+		if i < 0 {
+			return false
+		}
+		value = value[i+len(part):]
+	}
+	return strings.HasSuffix(value, parts[len(parts)-1])
+}
+
+// compareVersions compares the dotted version string with the parsed version,
+// returning -1, 0 or 1. Missing or non-numeric components are treated as 0.
+func compareVersions(version string, other [3]int64) int {
+	parts := strings.SplitN(version, ".", 3)
+	for i := 0; i < 3; i++ {
+		var n int64
+		if i < len(parts) {
+			n, _ = strconv.ParseInt(parts[i], 10, 64)
+		}
+		if c := compareInts(n, other[i]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareHostFilter(cmp int, op kolide.HostFilterOp) bool {
+	switch op {
+	case kolide.HostFilterEqual:
+		return cmp == 0
+	case kolide.HostFilterLess:
+		return cmp < 0
+	case kolide.HostFilterLessOrEqual:
+		return cmp <= 0
+	case kolide.HostFilterGreater:
+		return cmp > 0
+	case kolide.HostFilterGreaterOrEqual:
+		return cmp >= 0
+	}
+	return false
+}
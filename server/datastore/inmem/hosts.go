@@ -56,6 +56,35 @@ func (d *Datastore) DeleteHost(hid uint) error {
 	return nil
 }
 
+func (d *Datastore) DeleteHosts(ids []uint, opts ...kolide.OptionalArg) (uint, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	var deleted uint
+	for _, id := range ids {
+		if _, ok := d.hosts[id]; ok {
+			delete(d.hosts, id)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+func (d *Datastore) ExistingHostIDs(ids []uint) ([]uint, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	var existing []uint
+	for _, id := range ids {
+		if _, ok := d.hosts[id]; ok {
+			existing = append(existing, id)
+		}
+	}
+	sort.Slice(existing, func(i, j int) bool { return existing[i] < existing[j] })
+	return existing, nil
+}
+
 func (d *Datastore) Host(id uint) (*kolide.Host, error) {
 	d.mtx.Lock()
 	defer d.mtx.Unlock()
@@ -68,7 +97,7 @@ func (d *Datastore) Host(id uint) (*kolide.Host, error) {
 	return host, nil
 }
 
-func (d *Datastore) ListHosts(opt kolide.ListOptions) ([]*kolide.Host, error) {
+func (d *Datastore) ListHosts(opt kolide.HostListOptions) ([]*kolide.Host, error) {
 	d.mtx.Lock()
 	defer d.mtx.Unlock()
 
@@ -79,9 +108,14 @@ func (d *Datastore) ListHosts(opt kolide.ListOptions) ([]*kolide.Host, error) {
 	}
 	sort.Ints(keys)
 
+	now := time.Now()
 	hosts := []*kolide.Host{}
 	for _, k := range keys {
-		hosts = append(hosts, d.hosts[uint(k)])
+		host := d.hosts[uint(k)]
+		if !d.hostMatchesFilter(host, opt.Filter, now) {
+			continue
+		}
+		hosts = append(hosts, host)
 	}
 
 	// Apply ordering
@@ -101,13 +135,13 @@ func (d *Datastore) ListHosts(opt kolide.ListOptions) ([]*kolide.Host, error) {
 			"mac":                "PrimaryMAC",
 			"ip":                 "PrimaryIP",
 		}
-		if err := sortResults(hosts, opt, fields); err != nil {
+		if err := sortResults(hosts, opt.ListOptions, fields); err != nil {
 			return nil, err
 		}
 	}
 
 	// Apply limit/offset
-	low, high := d.getLimitOffsetSliceBounds(opt, len(hosts))
+	low, high := d.getLimitOffsetSliceBounds(opt.ListOptions, len(hosts))
 	hosts = hosts[low:high]
 
 	return hosts, nil
@@ -136,6 +170,40 @@ func (d *Datastore) GenerateHostStatusStatistics(now time.Time) (online, offline
 	return online, offline, mia, new, nil
 }
 
+func (d *Datastore) ListExpiredHosts(now time.Time, window time.Duration) ([]*kolide.Host, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	cutoff := now.Add(-window)
+	hosts := []*kolide.Host{}
+	for _, host := range d.hosts {
+		if host.SeenTime.Before(cutoff) {
+			hosts = append(hosts, host)
+		}
+	}
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].ID < hosts[j].ID })
+
+	return hosts, nil
+}
+
+func (d *Datastore) ExpireHosts(now time.Time, window time.Duration) ([]uint, error) {
+	hosts, err := d.ListExpiredHosts(now, window)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	ids := make([]uint, 0, len(hosts))
+	for _, host := range hosts {
+		delete(d.hosts, host.ID)
+		ids = append(ids, host.ID)
+	}
+
+	return ids, nil
+}
+
 func (d *Datastore) EnrollHost(osQueryHostID, nodeKey, secretName string) (*kolide.Host, error) {
 	d.mtx.Lock()
 	defer d.mtx.Unlock()
@@ -257,7 +325,8 @@ func (d *Datastore) DistributedQueriesForHost(host *kolide.Host) (map[uint]strin
 			if campaign.ID == target.DistributedQueryCampaignID &&
 				((target.Type == kolide.TargetHost && target.TargetID == host.ID) ||
 					(target.Type == kolide.TargetLabel && hostLabels[target.TargetID])) &&
-				(hostExecutions[campaign.ID] == kolide.ExecutionWaiting) {
+				(hostExecutions[campaign.ID] == kolide.ExecutionWaiting ||
+					(hostExecutions[campaign.ID] == kolide.ExecutionRequested && !campaign.Deferred)) {
 				queries[campaign.ID] = d.queries[campaign.QueryID].Query
 			}
 		}
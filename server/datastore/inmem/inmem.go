@@ -32,6 +32,7 @@ type Datastore struct {
 	distributedQueryExecutions      map[uint]kolide.DistributedQueryExecution
 	distributedQueryCampaigns       map[uint]kolide.DistributedQueryCampaign
 	distributedQueryCampaignTargets map[uint]kolide.DistributedQueryCampaignTarget
+	distributedQueryCampaignViewers map[uint]campaignViewer
 	options                         map[uint]*kolide.Option
 	decorators                      map[uint]*kolide.Decorator
 	filePaths                       map[uint]*kolide.FIMSection
@@ -105,6 +106,7 @@ func (d *Datastore) MigrateTables() error {
 	d.distributedQueryExecutions = make(map[uint]kolide.DistributedQueryExecution)
 	d.distributedQueryCampaigns = make(map[uint]kolide.DistributedQueryCampaign)
 	d.distributedQueryCampaignTargets = make(map[uint]kolide.DistributedQueryCampaignTarget)
+	d.distributedQueryCampaignViewers = make(map[uint]campaignViewer)
 	d.options = make(map[uint]*kolide.Option)
 	d.decorators = make(map[uint]*kolide.Decorator)
 	d.filePaths = make(map[uint]*kolide.FIMSection)
@@ -45,6 +45,27 @@ func (d *Datastore) ListLabelsForHost(hid uint) ([]kolide.Label, error) {
 	return resLabels, nil
 }
 
+func (d *Datastore) ListLabelsForHosts(hids []uint) (map[uint][]kolide.Label, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	wanted := map[uint]bool{}
+	for _, hid := range hids {
+		wanted[hid] = true
+	}
+
+	labels := map[uint][]kolide.Label{}
+	for _, lqe := range d.labelQueryExecutions {
+		if wanted[lqe.HostID] && lqe.Matches {
+			if label := d.labels[lqe.LabelID]; label != nil {
+				labels[lqe.HostID] = append(labels[lqe.HostID], *label)
+			}
+		}
+	}
+
+	return labels, nil
+}
+
 func (d *Datastore) LabelQueriesForHost(host *kolide.Host, cutoff time.Time) (map[string]string, error) {
 	d.mtx.Lock()
 	defer d.mtx.Unlock()
@@ -68,42 +89,98 @@ func (d *Datastore) LabelQueriesForHost(host *kolide.Host, cutoff time.Time) (ma
 }
 
 func (d *Datastore) RecordLabelQueryExecutions(host *kolide.Host, results map[uint]bool, t time.Time) error {
-	// Record executions
 	for labelID, matches := range results {
-		label, ok := d.labels[labelID]
-		if !ok {
-			return notFound("Label").WithID(labelID)
-		}
-
-		updated := false
-		d.mtx.Lock()
-		for _, lqe := range d.labelQueryExecutions {
-			if lqe.LabelID == label.ID && lqe.HostID == host.ID {
-				// Update existing execution values
-				lqe.UpdatedAt = t
-				lqe.Matches = matches
-				updated = true
-				break
-			}
+		if err := d.recordLabelQueryExecution(host, labelID, matches, nil, t); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		if !updated {
-			// Create new execution
-			lqe := kolide.LabelQueryExecution{
-				HostID:    host.ID,
-				LabelID:   label.ID,
-				UpdatedAt: t,
-				Matches:   matches,
-			}
-			lqe.ID = d.nextID(lqe)
-			d.labelQueryExecutions[lqe.ID] = &lqe
+func (d *Datastore) RecordLabelQueryErrors(host *kolide.Host, errs map[uint]string, t time.Time) error {
+	for labelID, msg := range errs {
+		msg := msg
+		if err := d.recordLabelQueryExecution(host, labelID, false, &msg, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Datastore) recordLabelQueryExecution(host *kolide.Host, labelID uint, matches bool, errMsg *string, t time.Time) error {
+	label, ok := d.labels[labelID]
+	if !ok {
+		return notFound("Label").WithID(labelID)
+	}
+
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	for _, lqe := range d.labelQueryExecutions {
+		if lqe.LabelID == label.ID && lqe.HostID == host.ID {
+			// Update existing execution values
+			lqe.UpdatedAt = t
+			lqe.Matches = matches
+			lqe.Error = errMsg
+			return nil
 		}
-		d.mtx.Unlock()
 	}
 
+	// Create new execution
+	lqe := kolide.LabelQueryExecution{
+		HostID:    host.ID,
+		LabelID:   label.ID,
+		UpdatedAt: t,
+		Matches:   matches,
+		Error:     errMsg,
+	}
+	lqe.ID = d.nextID(lqe)
+	d.labelQueryExecutions[lqe.ID] = &lqe
 	return nil
 }
 
+func (d *Datastore) ListLabelExecutionsForHost(host *kolide.Host) ([]*kolide.HostLabelExecution, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	executed := map[uint]*kolide.LabelQueryExecution{}
+	for _, lqe := range d.labelQueryExecutions {
+		if lqe.HostID == host.ID {
+			executed[lqe.LabelID] = lqe
+		}
+	}
+
+	// We need to sort by keys to provide reliable ordering
+	keys := []int{}
+	for k := range d.labels {
+		keys = append(keys, int(k))
+	}
+	sort.Ints(keys)
+
+	executions := []*kolide.HostLabelExecution{}
+	for _, k := range keys {
+		label := d.labels[uint(k)]
+		lqe := executed[label.ID]
+		applies := label.Platform == "" || strings.Contains(label.Platform, host.Platform)
+		if label.Deleted || (!applies && lqe == nil) {
+			continue
+		}
+		execution := &kolide.HostLabelExecution{
+			LabelID:   label.ID,
+			LabelName: label.Name,
+			LabelType: label.LabelType,
+		}
+		if lqe != nil {
+			updatedAt := lqe.UpdatedAt
+			execution.Matches = lqe.Matches
+			execution.UpdatedAt = &updatedAt
+			execution.Error = lqe.Error
+		}
+		executions = append(executions, execution)
+	}
+
+	return executions, nil
+}
+
 func (d *Datastore) Label(lid uint) (*kolide.Label, error) {
 	d.mtx.Lock()
 	label, ok := d.labels[lid]
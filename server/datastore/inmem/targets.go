@@ -6,7 +6,7 @@ import (
 	"github.com/kolide/fleet/server/kolide"
 )
 
-func (d *Datastore) CountHostsInTargets(hostIDs, labelIDs []uint, now time.Time) (kolide.TargetMetrics, error) {
+func (d *Datastore) CountHostsInTargets(hostIDs, labelIDs, tagIDs []uint, now time.Time) (kolide.TargetMetrics, error) {
 	// noop
 	return kolide.TargetMetrics{}, nil
 }
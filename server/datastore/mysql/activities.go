@@ -0,0 +1,74 @@
+package mysql
+
+import (
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+func (d *Datastore) NewActivity(activity *kolide.Activity) (*kolide.Activity, error) {
+	sqlStatement := `
+		INSERT INTO activities (
+			actor_id,
+			actor_name,
+			activity_type,
+			target_type,
+			target_id,
+			target_name,
+			details
+		) VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	result, err := d.db.Exec(sqlStatement,
+		activity.ActorID,
+		activity.ActorName,
+		activity.Type,
+		activity.TargetType,
+		activity.TargetID,
+		activity.TargetName,
+		activity.Details,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "insert activity")
+	}
+
+	id, _ := result.LastInsertId()
+	activity.ID = uint(id)
+	activity.CreatedAt = d.clock.Now()
+	return activity, nil
+}
+
+func (d *Datastore) ListActivities(filter kolide.ActivityFilter, opt kolide.ListOptions) ([]*kolide.Activity, error) {
+	sqlStatement := `
+		SELECT * FROM activities WHERE TRUE
+	`
+	args := []interface{}{}
+	if filter.ActorID != nil {
+		sqlStatement += " AND actor_id = ?"
+		args = append(args, *filter.ActorID)
+	}
+	if filter.Type != nil {
+		sqlStatement += " AND activity_type = ?"
+		args = append(args, *filter.Type)
+	}
+	if filter.TargetType != nil {
+		sqlStatement += " AND target_type = ?"
+		args = append(args, *filter.TargetType)
+	}
+	if filter.TargetID != nil {
+		sqlStatement += " AND target_id = ?"
+		args = append(args, *filter.TargetID)
+	}
+	if filter.Since != nil {
+		sqlStatement += " AND created_at >= ?"
+		args = append(args, *filter.Since)
+	}
+	if opt.OrderKey == "" {
+		sqlStatement += " ORDER BY created_at DESC, id DESC"
+	}
+	sqlStatement = appendListOptionsToSQL(sqlStatement, opt)
+
+	activities := []*kolide.Activity{}
+	if err := d.db.Select(&activities, sqlStatement, args...); err != nil {
+		return nil, errors.Wrap(err, "list activities")
+	}
+	return activities, nil
+}
@@ -0,0 +1,77 @@
+package mysql
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+func (d *Datastore) NewAPIToken(token *kolide.APIToken) (*kolide.APIToken, error) {
+	sqlStatement := `
+		INSERT INTO api_tokens (
+			user_id,
+			name,
+			hash,
+			role,
+			expires_at
+		) VALUES (?, ?, ?, ?, ?)
+	`
+	result, err := d.db.Exec(sqlStatement, token.UserID, token.Name, token.Hash, token.Role, token.ExpiresAt)
+	if isDuplicate(err) {
+		return nil, alreadyExists("APIToken", 0)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "insert api token")
+	}
+
+	id, _ := result.LastInsertId()
+	token.ID = uint(id)
+	token.CreatedAt = d.clock.Now()
+	return token, nil
+}
+
+func (d *Datastore) APITokenByHash(hash []byte) (*kolide.APIToken, error) {
+	token := &kolide.APIToken{}
+	err := d.db.Get(token, "SELECT * FROM api_tokens WHERE hash = ?", hash)
+	if err == sql.ErrNoRows {
+		return nil, notFound("APIToken")
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "select api token by hash")
+	}
+	return token, nil
+}
+
+func (d *Datastore) ListAPITokensForUser(userID uint) ([]*kolide.APIToken, error) {
+	tokens := []*kolide.APIToken{}
+	err := d.db.Select(&tokens, "SELECT * FROM api_tokens WHERE user_id = ? ORDER BY name", userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "select api tokens for user")
+	}
+	return tokens, nil
+}
+
+func (d *Datastore) DeleteAPIToken(userID, id uint) error {
+	result, err := d.db.Exec("DELETE FROM api_tokens WHERE user_id = ? AND id = ?", userID, id)
+	if err != nil {
+		return errors.Wrap(err, "delete api token")
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "rows affected deleting api token")
+	}
+	if rows == 0 {
+		return notFound("APIToken").WithID(id)
+	}
+	return nil
+}
+
+func (d *Datastore) MarkAPITokenUsed(id uint, usedAt time.Time) error {
+	_, err := d.db.Exec("UPDATE api_tokens SET last_used_at = ? WHERE id = ?", usedAt, id)
+	if err != nil {
+		return errors.Wrap(err, "mark api token used")
+	}
+	return nil
+}
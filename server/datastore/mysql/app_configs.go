@@ -1,10 +1,6 @@
 package mysql
 
 import (
-	"fmt"
-
-	"github.com/VividCortex/mysqlerr"
-	"github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
 	"github.com/kolide/fleet/server/kolide"
 	"github.com/pkg/errors"
@@ -28,64 +24,7 @@ func (d *Datastore) AppConfig() (*kolide.AppConfig, error) {
 	return info, nil
 }
 
-func (d *Datastore) isEventSchedulerEnabled() (bool, error) {
-	rows, err := d.db.Query("SELECT @@event_scheduler")
-	if err != nil {
-		return false, err
-	}
-	if !rows.Next() {
-		return false, errors.New("Error detecting MySQL event scheduler status.")
-	}
-	var value string
-	if err := rows.Scan(&value); err != nil {
-		return false, err
-	}
-
-	return value == "ON", nil
-}
-
-func (d *Datastore) ManageHostExpiryEvent(hostExpiryEnabled bool, hostExpiryWindow int) error {
-	var err error
-	hostExpiryConfig := struct {
-		Window int `db:"host_expiry_window"`
-	}{}
-	if err = d.db.Get(&hostExpiryConfig, "SELECT host_expiry_window from app_configs LIMIT 1"); err != nil {
-		return errors.Wrap(err, "get expiry window setting")
-	}
-
-	shouldUpdateWindow := hostExpiryEnabled && hostExpiryConfig.Window != hostExpiryWindow
-
-	if !hostExpiryEnabled || shouldUpdateWindow {
-		if _, err := d.db.Exec("DROP EVENT IF EXISTS host_expiry"); err != nil {
-			if driverErr, ok := err.(*mysql.MySQLError); !ok || driverErr.Number != mysqlerr.ER_DBACCESS_DENIED_ERROR {
-				return errors.Wrap(err, "drop existing host_expiry event")
-			}
-		}
-	}
-
-	if shouldUpdateWindow {
-		sql := fmt.Sprintf("CREATE EVENT IF NOT EXISTS host_expiry ON SCHEDULE EVERY 1 HOUR ON COMPLETION PRESERVE DO DELETE FROM hosts WHERE seen_time < DATE_SUB(NOW(), INTERVAL %d DAY)", hostExpiryWindow)
-		if _, err := d.db.Exec(sql); err != nil {
-			return errors.Wrap(err, "create new host_expiry event")
-		}
-	}
-	return nil
-}
-
 func (d *Datastore) SaveAppConfig(info *kolide.AppConfig) error {
-	eventSchedulerEnabled, err := d.isEventSchedulerEnabled()
-	if err != nil {
-		return err
-	}
-
-	if !eventSchedulerEnabled && info.HostExpiryEnabled {
-		return errors.New("MySQL Event Scheduler must be enabled to configure Host Expiry.")
-	}
-
-	if err := d.ManageHostExpiryEvent(info.HostExpiryEnabled, info.HostExpiryWindow); err != nil {
-		return err
-	}
-
 	// Note that we hard code the ID column to 1, insuring that, if no rows
 	// exist, a row will be created with INSERT, if a row does exist the key
 	// will be violate uniqueness constraint and an UPDATE will occur
@@ -114,6 +53,15 @@ func (d *Datastore) SaveAppConfig(info *kolide.AppConfig) error {
       metadata_url,
       idp_name,
       enable_sso,
+      sso_provider,
+      oidc_issuer_url,
+      oidc_client_id,
+      oidc_client_secret,
+      oidc_user_claim,
+      enable_jit_provisioning,
+      jit_group_attribute,
+      jit_admin_groups,
+      jit_user_groups,
       fim_interval,
       fim_file_accesses,
       host_expiry_enabled,
@@ -121,7 +69,7 @@ func (d *Datastore) SaveAppConfig(info *kolide.AppConfig) error {
       live_query_disabled,
       additional_queries
     )
-    VALUES( 1, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ? )
+    VALUES( 1, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ? )
     ON DUPLICATE KEY UPDATE
       org_name = VALUES(org_name),
       org_logo_url = VALUES(org_logo_url),
@@ -145,6 +93,15 @@ func (d *Datastore) SaveAppConfig(info *kolide.AppConfig) error {
       metadata_url = VALUES(metadata_url),
       idp_name = VALUES(idp_name),
       enable_sso = VALUES(enable_sso),
+      sso_provider = VALUES(sso_provider),
+      oidc_issuer_url = VALUES(oidc_issuer_url),
+      oidc_client_id = VALUES(oidc_client_id),
+      oidc_client_secret = VALUES(oidc_client_secret),
+      oidc_user_claim = VALUES(oidc_user_claim),
+      enable_jit_provisioning = VALUES(enable_jit_provisioning),
+      jit_group_attribute = VALUES(jit_group_attribute),
+      jit_admin_groups = VALUES(jit_admin_groups),
+      jit_user_groups = VALUES(jit_user_groups),
       fim_interval = VALUES(fim_interval),
       fim_file_accesses = VALUES(fim_file_accesses),
       host_expiry_enabled = VALUES(host_expiry_enabled),
@@ -153,7 +110,7 @@ func (d *Datastore) SaveAppConfig(info *kolide.AppConfig) error {
       additional_queries = VALUES(additional_queries)
     `
 
-	_, err = d.db.Exec(insertStatement,
+	_, err := d.db.Exec(insertStatement,
 		info.OrgName,
 		info.OrgLogoURL,
 		info.KolideServerURL,
@@ -176,6 +133,15 @@ func (d *Datastore) SaveAppConfig(info *kolide.AppConfig) error {
 		info.MetadataURL,
 		info.IDPName,
 		info.EnableSSO,
+		info.SSOProvider,
+		info.OIDCIssuerURL,
+		info.OIDCClientID,
+		info.OIDCClientSecret,
+		info.OIDCUserClaim,
+		info.EnableJITProvisioning,
+		info.JITGroupAttribute,
+		info.JITAdminGroups,
+		info.JITUserGroups,
 		info.FIMInterval,
 		info.FIMFileAccesses,
 		info.HostExpiryEnabled,
@@ -0,0 +1,160 @@
+package mysql
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// campaignResultRow is a distributed_query_campaign_results row, with the
+// result rows stored as JSON.
+type campaignResultRow struct {
+	kolide.CampaignResult
+	RowsJSON string `db:"rows_json"`
+}
+
+func (d *Datastore) SaveCampaignResult(result *kolide.CampaignResult, maxRows uint) (bool, error) {
+	saved := false
+	rows := result.Rows
+	err := d.withRetryTxx(func(tx *sqlx.Tx) error {
+		result.Rows, result.Truncated = rows, false
+
+		// Locking the campaign serializes the results saved for it, so
+		// that the rows limit is never exceeded.
+		var campaignID uint
+		err := tx.Get(&campaignID,
+			"SELECT id FROM distributed_query_campaigns WHERE id = ? FOR UPDATE",
+			result.DistributedQueryCampaignID,
+		)
+		if err != nil {
+			return errors.Wrap(err, "lock campaign")
+		}
+
+		var stored uint
+		err = tx.Get(&stored, `
+			SELECT COALESCE(SUM(row_count), 0)
+			FROM distributed_query_campaign_results
+			WHERE distributed_query_campaign_id = ?
+		`, campaignID)
+		if err != nil {
+			return errors.Wrap(err, "count stored campaign result rows")
+		}
+		if stored >= maxRows {
+			return nil
+		}
+		if remaining := maxRows - stored; uint(len(result.Rows)) > remaining {
+			result.Rows = result.Rows[:remaining]
+			result.Truncated = true
+		}
+
+		rowsJSON, err := json.Marshal(result.Rows)
+		if err != nil {
+			return errors.Wrap(err, "marshal campaign result rows")
+		}
+		result.RowCount = uint(len(result.Rows))
+
+		sqlStatement := `
+			INSERT INTO distributed_query_campaign_results (
+				distributed_query_campaign_id,
+				host_id,
+				host_name,
+				rows_json,
+				row_count,
+				truncated,
+				error,
+				created_at
+			)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		`
+		res, err := tx.Exec(sqlStatement,
+			campaignID, result.HostID, result.HostName, rowsJSON,
+			result.RowCount, result.Truncated, result.Error, d.clock.Now(),
+		)
+		if err != nil {
+			return errors.Wrap(err, "insert campaign result")
+		}
+		id, _ := res.LastInsertId()
+		result.ID = uint(id)
+		saved = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return saved, nil
+}
+
+func (d *Datastore) MarkCampaignResultsTruncated(campaignID uint) error {
+	sqlStatement := `
+		UPDATE distributed_query_campaigns SET results_truncated = TRUE
+		WHERE id = ?
+	`
+	if _, err := d.db.Exec(sqlStatement, campaignID); err != nil {
+		return errors.Wrap(err, "mark campaign results truncated")
+	}
+	return nil
+}
+
+func (d *Datastore) ListCampaignResults(campaignID uint, opt kolide.ListOptions) ([]*kolide.CampaignResult, error) {
+	sqlStatement := `
+		SELECT
+			id,
+			distributed_query_campaign_id,
+			host_id,
+			host_name,
+			rows_json,
+			row_count,
+			truncated,
+			error,
+			created_at
+		FROM distributed_query_campaign_results
+		WHERE distributed_query_campaign_id = ?
+	`
+	if opt.OrderKey == "" {
+		opt.OrderKey = "id"
+	}
+	sqlStatement = appendListOptionsToSQL(sqlStatement, opt)
+
+	rows := []campaignResultRow{}
+	if err := d.db.Select(&rows, sqlStatement, campaignID); err != nil {
+		return nil, errors.Wrapf(err, "list results for campaign %d", campaignID)
+	}
+
+	results := make([]*kolide.CampaignResult, 0, len(rows))
+	for i := range rows {
+		result := rows[i].CampaignResult
+		if err := json.Unmarshal([]byte(rows[i].RowsJSON), &result.Rows); err != nil {
+			return nil, errors.Wrapf(err, "unmarshal rows of campaign result %d", result.ID)
+		}
+		results = append(results, &result)
+	}
+
+	return results, nil
+}
+
+func (d *Datastore) CleanupCampaignResults(before time.Time) (uint, error) {
+	// Campaigns are soft deleted, so their results are not removed by the
+	// foreign key cascade. Campaigns with persisted results always have an
+	// expiry time, the update time covers campaigns completed otherwise.
+	sqlStatement := `
+		DELETE dqcr
+		FROM distributed_query_campaign_results dqcr
+		JOIN distributed_query_campaigns dqc
+		ON dqcr.distributed_query_campaign_id = dqc.id
+		WHERE dqc.deleted
+		OR (dqc.status = ? AND COALESCE(dqc.expires_at, dqc.updated_at) < ?)
+	`
+	res, err := d.db.Exec(sqlStatement, kolide.QueryComplete, before)
+	if err != nil {
+		return 0, errors.Wrap(err, "deleting campaign results")
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "rows affected deleting campaign results")
+	}
+	return uint(deleted), nil
+}
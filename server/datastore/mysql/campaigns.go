@@ -1,9 +1,11 @@
 package mysql
 
 import (
+	"database/sql"
 	"fmt"
 	"time"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/kolide/fleet/server/kolide"
 	"github.com/pkg/errors"
 )
@@ -14,11 +16,16 @@ func (d *Datastore) NewDistributedQueryCampaign(camp *kolide.DistributedQueryCam
 		INSERT INTO distributed_query_campaigns (
 			query_id,
 			status,
-			user_id
+			user_id,
+			persist_results,
+			expected_results,
+			deferred,
+			expires_at
 		)
-		VALUES(?,?,?)
+		VALUES(?,?,?,?,?,?,?)
 	`
-	result, err := d.db.Exec(sqlStatement, camp.QueryID, camp.Status, camp.UserID)
+	result, err := d.db.Exec(sqlStatement, camp.QueryID, camp.Status, camp.UserID, camp.PersistResults,
+		camp.ExpectedResults, camp.Deferred, camp.ExpiresAt)
 	if err != nil {
 		return nil, errors.Wrap(err, "inserting distributed query campaign")
 	}
@@ -29,11 +36,14 @@ func (d *Datastore) NewDistributedQueryCampaign(camp *kolide.DistributedQueryCam
 }
 
 func (d *Datastore) DistributedQueryCampaign(id uint) (*kolide.DistributedQueryCampaign, error) {
-	sql := `
+	sqlStatement := `
 		SELECT * FROM distributed_query_campaigns WHERE id = ? AND NOT deleted
 	`
 	campaign := &kolide.DistributedQueryCampaign{}
-	if err := d.db.Get(campaign, sql, id); err != nil {
+	if err := d.db.Get(campaign, sqlStatement, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFound("DistributedQueryCampaign").WithID(id)
+		}
 		return nil, errors.Wrap(err, "selecting distributed query campaign")
 	}
 
@@ -41,15 +51,22 @@ func (d *Datastore) DistributedQueryCampaign(id uint) (*kolide.DistributedQueryC
 }
 
 func (d *Datastore) SaveDistributedQueryCampaign(camp *kolide.DistributedQueryCampaign) error {
+	// received_results is only updated by NewDistributedQueryExecution, so
+	// that saving a stale copy of the campaign does not lose it.
 	sqlStatement := `
 		UPDATE distributed_query_campaigns SET
 			query_id = ?,
 			status = ?,
-			user_id = ?
+			user_id = ?,
+			persist_results = ?,
+			expected_results = ?,
+			deferred = ?,
+			expires_at = ?
 		WHERE id = ?
 		AND NOT deleted
 	`
-	result, err := d.db.Exec(sqlStatement, camp.QueryID, camp.Status, camp.UserID, camp.ID)
+	result, err := d.db.Exec(sqlStatement, camp.QueryID, camp.Status, camp.UserID, camp.PersistResults,
+		camp.ExpectedResults, camp.Deferred, camp.ExpiresAt, camp.ID)
 	if err != nil {
 		return errors.Wrap(err, "updating distributed query campaign")
 	}
@@ -64,29 +81,99 @@ func (d *Datastore) SaveDistributedQueryCampaign(camp *kolide.DistributedQueryCa
 	return nil
 }
 
-func (d *Datastore) DistributedQueryCampaignTargetIDs(id uint) (hostIDs []uint, labelIDs []uint, err error) {
+func (d *Datastore) NewDistributedQueryCampaignViewer(campaignID uint) (uint, error) {
+	sqlStatement := `
+		INSERT INTO distributed_query_campaign_viewers (campaign_id, updated_at)
+		SELECT id, ?
+		FROM distributed_query_campaigns
+		WHERE id = ? AND NOT deleted
+	`
+	result, err := d.db.Exec(sqlStatement, d.clock.Now(), campaignID)
+	if err != nil {
+		return 0, errors.Wrap(err, "insert distributed query campaign viewer")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "rows affected inserting distributed query campaign viewer")
+	}
+	if rowsAffected == 0 {
+		return 0, notFound("DistributedQueryCampaign").WithID(campaignID)
+	}
+	id, _ := result.LastInsertId()
+	return uint(id), nil
+}
+
+func (d *Datastore) TouchDistributedQueryCampaignViewer(id uint) error {
+	sqlStatement := `
+		UPDATE distributed_query_campaign_viewers
+		SET updated_at = ?
+		WHERE id = ?
+	`
+	result, err := d.db.Exec(sqlStatement, d.clock.Now(), id)
+	if err != nil {
+		return errors.Wrap(err, "touch distributed query campaign viewer")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "rows affected touching distributed query campaign viewer")
+	}
+	if rowsAffected == 0 {
+		return notFound("DistributedQueryCampaignViewer").WithID(id)
+	}
+	return nil
+}
+
+func (d *Datastore) DeleteDistributedQueryCampaignViewer(id uint, activeSince time.Time) (uint, error) {
+	var campaignID uint
+	err := d.db.Get(&campaignID, "SELECT campaign_id FROM distributed_query_campaign_viewers WHERE id = ?", id)
+	if err == sql.ErrNoRows {
+		return 0, notFound("DistributedQueryCampaignViewer").WithID(id)
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "select distributed query campaign viewer")
+	}
+
+	if _, err := d.db.Exec("DELETE FROM distributed_query_campaign_viewers WHERE id = ?", id); err != nil {
+		return 0, errors.Wrap(err, "delete distributed query campaign viewer")
+	}
+
+	var remaining uint
+	sqlStatement := `
+		SELECT COUNT(*) FROM distributed_query_campaign_viewers
+		WHERE campaign_id = ? AND updated_at >= ?
+	`
+	if err := d.db.Get(&remaining, sqlStatement, campaignID, activeSince); err != nil {
+		return 0, errors.Wrap(err, "count distributed query campaign viewers")
+	}
+	return remaining, nil
+}
+
+func (d *Datastore) DistributedQueryCampaignTargetIDs(id uint) (hostIDs []uint, labelIDs []uint, tagIDs []uint, err error) {
 	sqlStatement := `
 		SELECT * FROM distributed_query_campaign_targets WHERE distributed_query_campaign_id = ?
 	`
 	targets := []kolide.DistributedQueryCampaignTarget{}
 
 	if err = d.db.Select(&targets, sqlStatement, id); err != nil {
-		return nil, nil, errors.Wrap(err, "selecting distributed campaign target")
+		return nil, nil, nil, errors.Wrap(err, "selecting distributed campaign target")
 	}
 
 	hostIDs = []uint{}
 	labelIDs = []uint{}
+	tagIDs = []uint{}
 	for _, target := range targets {
 		if target.Type == kolide.TargetHost {
 			hostIDs = append(hostIDs, target.TargetID)
 		} else if target.Type == kolide.TargetLabel {
 			labelIDs = append(labelIDs, target.TargetID)
+		} else if target.Type == kolide.TargetTag {
+			tagIDs = append(tagIDs, target.TargetID)
 		} else {
-			return []uint{}, []uint{}, fmt.Errorf("invalid target type: %d", target.Type)
+			return []uint{}, []uint{}, []uint{}, fmt.Errorf("invalid target type: %d", target.Type)
 		}
 	}
 
-	return hostIDs, labelIDs, nil
+	return hostIDs, labelIDs, tagIDs, nil
 }
 
 func (d *Datastore) NewDistributedQueryCampaignTarget(target *kolide.DistributedQueryCampaignTarget) (*kolide.DistributedQueryCampaignTarget, error) {
@@ -108,39 +195,143 @@ func (d *Datastore) NewDistributedQueryCampaignTarget(target *kolide.Distributed
 	return target, nil
 }
 
-func (d *Datastore) NewDistributedQueryExecution(exec *kolide.DistributedQueryExecution) (*kolide.DistributedQueryExecution, error) {
+func (d *Datastore) NewDistributedQueryExecution(exec *kolide.DistributedQueryExecution) (_ *kolide.DistributedQueryExecution, err error) {
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return nil, errors.Wrap(err, "begin NewDistributedQueryExecution transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			rbErr := tx.Rollback()
+			if rbErr != nil && rbErr != sql.ErrTxDone {
+				panic(fmt.Sprintf("got err '%s' rolling back after err '%s'", rbErr, err))
+			}
+		}
+	}()
+
+	// An execution recorded when the query was sent to the host is
+	// replaced by the result.
+	var existing struct {
+		ID          uint                                   `db:"id"`
+		Status      kolide.DistributedQueryExecutionStatus `db:"status"`
+		RequestedAt *time.Time                             `db:"requested_at"`
+	}
 	sqlStatement := `
-		INSERT INTO distributed_query_executions (
+		SELECT id, status, requested_at FROM distributed_query_executions
+		WHERE host_id = ? AND distributed_query_campaign_id = ?
+		FOR UPDATE
+	`
+	err = tx.Get(&existing, sqlStatement, exec.HostID, exec.DistributedQueryCampaignID)
+	switch {
+	case err == sql.ErrNoRows:
+		sqlStatement = `
+			INSERT INTO distributed_query_executions (
+				host_id,
+				distributed_query_campaign_id,
+				status,
+				error,
+				execution_duration
+			) VALUES (?,?,?,?,?)
+		`
+		result, err := tx.Exec(sqlStatement, exec.HostID, exec.DistributedQueryCampaignID,
+			exec.Status, exec.Error, exec.ExecutionDuration)
+		if err != nil {
+			return nil, errors.Wrap(err, "insert distributed campaign execution")
+		}
+		id, _ := result.LastInsertId()
+		exec.ID = uint(id)
+
+	case err != nil:
+		return nil, errors.Wrap(err, "select distributed campaign execution")
+
+	case existing.Status == kolide.ExecutionRequested:
+		exec.RequestedAt = existing.RequestedAt
+		if exec.ExecutionDuration == 0 && exec.RequestedAt != nil && exec.ReceivedAt.After(*exec.RequestedAt) {
+			exec.ExecutionDuration = exec.ReceivedAt.Sub(*exec.RequestedAt)
+		}
+		sqlStatement = `
+			UPDATE distributed_query_executions
+			SET status = ?, error = ?, execution_duration = ?
+			WHERE id = ?
+		`
+		if _, err = tx.Exec(sqlStatement, exec.Status, exec.Error, exec.ExecutionDuration, existing.ID); err != nil {
+			return nil, errors.Wrap(err, "update distributed campaign execution")
+		}
+		exec.ID = existing.ID
+
+	default:
+		return nil, alreadyExists("DistributedQueryExecution", existing.ID)
+	}
+
+	sqlStatement = `
+		UPDATE distributed_query_campaigns
+		SET received_results = received_results + 1
+		WHERE id = ?
+	`
+	if _, err = tx.Exec(sqlStatement, exec.DistributedQueryCampaignID); err != nil {
+		return nil, errors.Wrap(err, "count distributed campaign result")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "commit NewDistributedQueryExecution transaction")
+	}
+
+	return exec, nil
+}
+
+func (d *Datastore) MarkDistributedQueriesRequested(hostID uint, campaignIDs []uint, now time.Time) error {
+	if len(campaignIDs) == 0 {
+		return nil
+	}
+
+	sqlStatement := `
+		INSERT IGNORE INTO distributed_query_executions (
 			host_id,
 			distributed_query_campaign_id,
 			status,
-			error,
-			execution_duration
-		) VALUES (?,?,?,?,?)
+			requested_at
+		)
+		SELECT ?, id, ?, ?
+		FROM distributed_query_campaigns
+		WHERE id IN (?)
 	`
-	result, err := d.db.Exec(sqlStatement, exec.HostID, exec.DistributedQueryCampaignID,
-		exec.Status, exec.Error, exec.ExecutionDuration)
+	query, args, err := sqlx.In(sqlStatement, hostID, kolide.ExecutionRequested, now, campaignIDs)
 	if err != nil {
-		return nil, errors.Wrap(err, "insert distributed campaign target")
+		return errors.Wrap(err, "building query marking distributed queries requested")
+	}
+	if _, err := d.db.Exec(d.db.Rebind(query), args...); err != nil {
+		return errors.Wrap(err, "mark distributed queries requested")
 	}
 
-	id, _ := result.LastInsertId()
-	exec.ID = uint(id)
-
-	return exec, nil
+	return nil
 }
 
 func (d *Datastore) CleanupDistributedQueryCampaigns(now time.Time) (expired uint, deleted uint, err error) {
-	// First expire old waiting and running campaigns
+	// First expire old waiting and running campaigns, and running
+	// campaigns that are only followed by viewers that timed out
 	sqlStatement := `
-		UPDATE distributed_query_campaigns
+		UPDATE distributed_query_campaigns dqc
 		SET status = ?
-		WHERE (status = ? AND created_at < ?)
-		OR (status = ? AND created_at < ?)
+		WHERE (NOT deferred AND status = ? AND created_at < ?)
+		OR (expires_at IS NULL AND status = ? AND created_at < ?)
+		OR (expires_at IS NOT NULL AND status != ? AND expires_at < ?)
+		OR (expires_at IS NULL AND status = ?
+			AND EXISTS (
+				SELECT 1 FROM distributed_query_campaign_viewers v
+				WHERE v.campaign_id = dqc.id
+			)
+			AND NOT EXISTS (
+				SELECT 1 FROM distributed_query_campaign_viewers v
+				WHERE v.campaign_id = dqc.id AND v.updated_at >= ?
+			))
 	`
+	activeSince := now.Add(-kolide.CampaignViewerTimeout)
 	result, err := d.db.Exec(sqlStatement, kolide.QueryComplete,
 		kolide.QueryWaiting, now.Add(-1*time.Minute),
-		kolide.QueryRunning, now.Add(-24*time.Hour))
+		kolide.QueryRunning, now.Add(-24*time.Hour),
+		kolide.QueryComplete, now,
+		kolide.QueryRunning, activeSince)
 	if err != nil {
 		return expired, deleted, errors.Wrap(err, "updating distributed query campaign")
 	}
@@ -151,15 +342,22 @@ func (d *Datastore) CleanupDistributedQueryCampaigns(now time.Time) (expired uin
 	}
 	expired = uint(exp)
 
-	// Now delete executions for expired campaigns
+	_, err = d.db.Exec("DELETE FROM distributed_query_campaign_viewers WHERE updated_at < ?", activeSince)
+	if err != nil {
+		return expired, deleted, errors.Wrap(err, "deleting inactive distributed query campaign viewers")
+	}
+
+	// Now delete executions for expired campaigns. Deferred campaigns keep
+	// their per host state until they are past their expiry.
 	sqlStatement = `
 		DELETE dqe
 		FROM distributed_query_executions dqe
 		JOIN distributed_query_campaigns dqc
 		ON dqe.distributed_query_campaign_id = dqc.id
 		WHERE dqc.status = ?
+		AND (NOT dqc.deferred OR dqc.expires_at < ?)
 	`
-	result, err = d.db.Exec(sqlStatement, kolide.QueryComplete)
+	result, err = d.db.Exec(sqlStatement, kolide.QueryComplete, now)
 	if err != nil {
 		return expired, deleted, errors.Wrap(err, "deleting distributed campaign executions")
 	}
@@ -172,3 +370,150 @@ func (d *Datastore) CleanupDistributedQueryCampaigns(now time.Time) (expired uin
 
 	return expired, deleted, nil
 }
+
+func (d *Datastore) ListDistributedQueryCampaigns(opt kolide.ListOptions, filter kolide.DistributedQueryCampaignFilter) ([]*kolide.DistributedQueryCampaignSummary, error) {
+	sqlStatement := `
+		SELECT
+			dqc.id,
+			dqc.created_at,
+			dqc.query_id,
+			COALESCE(q.query, '') AS query,
+			dqc.user_id,
+			COALESCE(u.username, '') AS username,
+			dqc.status,
+			dqc.persist_results,
+			dqc.deferred,
+			dqc.expires_at,
+			dqc.expected_results,
+			dqc.received_results
+		FROM distributed_query_campaigns dqc
+		LEFT JOIN queries q ON (q.id = dqc.query_id)
+		LEFT JOIN users u ON (u.id = dqc.user_id)
+		WHERE NOT dqc.deleted
+	`
+	args := []interface{}{}
+	if filter.UserID != nil {
+		sqlStatement += " AND dqc.user_id = ?"
+		args = append(args, *filter.UserID)
+	}
+	if filter.Status != nil {
+		sqlStatement += " AND dqc.status = ?"
+		args = append(args, *filter.Status)
+	}
+	if opt.OrderKey == "" {
+		sqlStatement += " ORDER BY dqc.created_at DESC, dqc.id DESC"
+	}
+	sqlStatement = appendListOptionsToSQL(sqlStatement, opt)
+
+	campaigns := []*kolide.DistributedQueryCampaignSummary{}
+	if err := d.db.Select(&campaigns, sqlStatement, args...); err != nil {
+		return nil, errors.Wrap(err, "list distributed query campaigns")
+	}
+	if len(campaigns) == 0 {
+		return campaigns, nil
+	}
+
+	if err := d.loadCampaignTargetNames(campaigns); err != nil {
+		return nil, err
+	}
+
+	return campaigns, nil
+}
+
+// loadCampaignTargetNames fills in the names of the targets of the campaigns.
+// Targets that no longer exist are listed by their ID.
+func (d *Datastore) loadCampaignTargetNames(campaigns []*kolide.DistributedQueryCampaignSummary) error {
+	byID := make(map[uint]*kolide.DistributedQueryCampaignSummary, len(campaigns))
+	ids := make([]uint, 0, len(campaigns))
+	for _, campaign := range campaigns {
+		campaign.Hosts = []string{}
+		campaign.Labels = []string{}
+		campaign.Tags = []string{}
+		byID[campaign.ID] = campaign
+		ids = append(ids, campaign.ID)
+	}
+
+	sqlStatement := "" +
+		"SELECT dqct.distributed_query_campaign_id, dqct.type, " +
+		"COALESCE(h.host_name, l.name, CONCAT(t.`key`, '=', t.value), CAST(dqct.target_id AS CHAR)) AS name " +
+		"FROM distributed_query_campaign_targets dqct " +
+		"LEFT JOIN hosts h ON (dqct.type = ? AND h.id = dqct.target_id) " +
+		"LEFT JOIN labels l ON (dqct.type = ? AND l.id = dqct.target_id) " +
+		"LEFT JOIN tags t ON (dqct.type = ? AND t.id = dqct.target_id) " +
+		"WHERE dqct.distributed_query_campaign_id IN (?) " +
+		"ORDER BY dqct.id"
+	query, args, err := sqlx.In(sqlStatement, kolide.TargetHost, kolide.TargetLabel, kolide.TargetTag, ids)
+	if err != nil {
+		return errors.Wrap(err, "building query listing campaign targets")
+	}
+
+	var targets []struct {
+		CampaignID uint              `db:"distributed_query_campaign_id"`
+		Type       kolide.TargetType `db:"type"`
+		Name       string            `db:"name"`
+	}
+	if err := d.db.Select(&targets, d.db.Rebind(query), args...); err != nil {
+		return errors.Wrap(err, "list campaign targets")
+	}
+
+	for _, target := range targets {
+		campaign := byID[target.CampaignID]
+		switch target.Type {
+		case kolide.TargetHost:
+			campaign.Hosts = append(campaign.Hosts, target.Name)
+		case kolide.TargetLabel:
+			campaign.Labels = append(campaign.Labels, target.Name)
+		case kolide.TargetTag:
+			campaign.Tags = append(campaign.Tags, target.Name)
+		}
+	}
+
+	return nil
+}
+
+func (d *Datastore) ListDistributedQueryCampaignHosts(campaignID uint, opt kolide.ListOptions) ([]*kolide.DistributedQueryCampaignHost, error) {
+	sqlStatement := `
+		SELECT
+			h.id AS host_id,
+			h.host_name,
+			COALESCE(dqe.status, ?) AS status,
+			COALESCE(dqe.error, '') AS error,
+			COALESCE(dqe.execution_duration, 0) AS execution_duration
+		FROM hosts h
+		LEFT JOIN distributed_query_executions dqe
+			ON (dqe.host_id = h.id AND dqe.distributed_query_campaign_id = ?)
+		WHERE NOT h.deleted AND (dqe.id IS NOT NULL OR h.id IN (
+			SELECT dqct.target_id
+			FROM distributed_query_campaign_targets dqct
+			WHERE dqct.distributed_query_campaign_id = ? AND dqct.type = ?
+			UNION
+			SELECT lqe.host_id
+			FROM distributed_query_campaign_targets dqct
+			JOIN label_query_executions lqe
+				ON (lqe.label_id = dqct.target_id AND lqe.matches)
+			WHERE dqct.distributed_query_campaign_id = ? AND dqct.type = ?
+			UNION
+			SELECT ht.host_id
+			FROM distributed_query_campaign_targets dqct
+			JOIN host_tags ht
+				ON (ht.tag_id = dqct.target_id)
+			WHERE dqct.distributed_query_campaign_id = ? AND dqct.type = ?
+		))
+	`
+	if opt.OrderKey == "" {
+		sqlStatement += " ORDER BY h.host_name, h.id"
+	}
+	sqlStatement = appendListOptionsToSQL(sqlStatement, opt)
+
+	hosts := []*kolide.DistributedQueryCampaignHost{}
+	err := d.db.Select(&hosts, sqlStatement, kolide.ExecutionWaiting, campaignID,
+		campaignID, kolide.TargetHost,
+		campaignID, kolide.TargetLabel,
+		campaignID, kolide.TargetTag,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "list distributed query campaign hosts")
+	}
+
+	return hosts, nil
+}
@@ -0,0 +1,135 @@
+package mysql
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// hostFilterColumns maps the host filter fields to the columns of the hosts
+// table. Fields not in this map (status and label) are handled specially.
+var hostFilterColumns = map[string]string{
+	"hostname":           "host_name",
+	"computer_name":      "computer_name",
+	"uuid":               "uuid",
+	"platform":           "platform",
+	"os_version":         "os_version",
+	"hardware_serial":    "hardware_serial",
+	"hardware_vendor":    "hardware_vendor",
+	"hardware_model":     "hardware_model",
+	"osquery_version":    "osquery_version",
+	"memory":             "physical_memory",
+	"cpu_physical_cores": "cpu_physical_cores",
+	"cpu_logical_cores":  "cpu_logical_cores",
+}
+
+// hostFilterToSQL translates the filter into a SQL condition on the hosts
+// table, along with the arguments to be bound to its placeholders. The
+// condition is always wrapped in parentheses so that it can be appended to an
+// existing WHERE clause. A nil filter results in a condition matching all
+// hosts.
+func hostFilterToSQL(filter *kolide.HostFilter, now time.Time) (string, []interface{}, error) {
+	if filter == nil || len(filter.Terms) == 0 {
+		return "(TRUE)", nil, nil
+	}
+
+	conditions := make([]string, 0, len(filter.Terms))
+	args := []interface{}{}
+	for _, term := range filter.Terms {
+		cond, termArgs, err := hostFilterTermToSQL(term, now)
+		if err != nil {
+			return "", nil, err
+		}
+		if term.Negate {
+			cond = "NOT " + cond
+		}
+		conditions = append(conditions, cond)
+		args = append(args, termArgs...)
+	}
+
+	return "(" + strings.Join(conditions, " AND ") + ")", args, nil
+}
+
+func hostFilterTermToSQL(term kolide.HostFilterTerm, now time.Time) (string, []interface{}, error) {
+	kind, ok := kolide.HostFilterFields[term.Field]
+	if !ok {
+		return "", nil, errors.Errorf("unknown host filter field %q", term.Field)
+	}
+
+	switch kind {
+	case kolide.HostFilterString:
+		column := hostFilterColumns[term.Field]
+		cond, arg := stringMatchSQL(column, term)
+		return cond, []interface{}{arg}, nil
+
+	case kolide.HostFilterNumber:
+		column := hostFilterColumns[term.Field]
+		return fmt.Sprintf("(%s %s ?)", column, term.Op), []interface{}{term.Number}, nil
+
+	case kolide.HostFilterVersion:
+		// Compare the major, minor and patch components numerically.
+		// Missing components are treated as 0 by padding the version.
+		column := hostFilterColumns[term.Field]
+		parts := make([]string, 3)
+		for i := range parts {
+			parts[i] = fmt.Sprintf(
+				"CAST(SUBSTRING_INDEX(SUBSTRING_INDEX(CONCAT(%s, '.0.0'), '.', %d), '.', -1) AS UNSIGNED)",
+				column, i+1,
+			)
+		}
+		cond := fmt.Sprintf("((%s) %s (?, ?, ?))", strings.Join(parts, ", "), term.Op)
+		return cond, []interface{}{term.Version[0], term.Version[1], term.Version[2]}, nil
+
+	case kolide.HostFilterStatus:
+		// The logic here should remain synchronized with host.Status and
+		// GenerateHostStatusStatistics
+		switch term.Value {
+		case kolide.StatusMIA:
+			return "(DATE_ADD(seen_time, INTERVAL 30 DAY) <= ?)", []interface{}{now}, nil
+		case kolide.StatusOffline:
+			cond := fmt.Sprintf(
+				"(DATE_ADD(seen_time, INTERVAL LEAST(distributed_interval, config_tls_refresh) + %d SECOND) <= ? AND DATE_ADD(seen_time, INTERVAL 30 DAY) >= ?)",
+				kolide.OnlineIntervalBuffer,
+			)
+			return cond, []interface{}{now, now}, nil
+		case kolide.StatusOnline:
+			cond := fmt.Sprintf(
+				"(DATE_ADD(seen_time, INTERVAL LEAST(distributed_interval, config_tls_refresh) + %d SECOND) > ?)",
+				kolide.OnlineIntervalBuffer,
+			)
+			return cond, []interface{}{now}, nil
+		case "new":
+			return "(DATE_ADD(created_at, INTERVAL 1 DAY) >= ?)", []interface{}{now}, nil
+		}
+		return "", nil, errors.Errorf("unknown host status %q", term.Value)
+
+	case kolide.HostFilterLabel:
+		nameCond, arg := stringMatchSQL("l.name", term)
+		cond := `(id IN (
+			SELECT lqe.host_id
+			FROM label_query_executions lqe
+			JOIN labels l ON (lqe.label_id = l.id)
+			WHERE lqe.matches AND NOT l.deleted AND ` + nameCond + `
+		))`
+		return cond, []interface{}{arg}, nil
+	}
+
+	return "", nil, errors.Errorf("unsupported host filter field %q", term.Field)
+}
+
+// stringMatchSQL returns the condition matching the column against the term
+// value, using LIKE if the value contains wildcards.
+func stringMatchSQL(column string, term kolide.HostFilterTerm) (string, interface{}) {
+	if !term.HasWildcard() {
+		return fmt.Sprintf("(%s = ?)", column), term.Value
+	}
+
+	parts := strings.Split(term.Value, "*")
+	for i, part := range parts {
+		parts[i] = escapeLike(part)
+	}
+	return fmt.Sprintf("(%s LIKE ?)", column), strings.Join(parts, "%")
+}
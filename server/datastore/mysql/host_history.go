@@ -0,0 +1,55 @@
+package mysql
+
+import (
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// recordHostHistory inserts a host_history row for each change of the
+// tracked attributes since the host was loaded or last saved.
+func (d *Datastore) recordHostHistory(tx *sqlx.Tx, host *kolide.Host) error {
+	changes := host.HistoryChanges()
+	if len(changes) == 0 {
+		return nil
+	}
+
+	now := d.clock.Now()
+	values := []string{}
+	args := []interface{}{}
+	for _, c := range changes {
+		values = append(values, "(?,?,?,?,?)")
+		args = append(args, host.ID, c.Field, c.OldValue, c.NewValue, now)
+	}
+
+	sqlStatement := `
+		INSERT INTO host_history (host_id, field, old_value, new_value, created_at)
+		VALUES ` + strings.Join(values, ",")
+	if _, err := tx.Exec(sqlStatement, args...); err != nil {
+		return errors.Wrap(err, "insert host history")
+	}
+
+	return nil
+}
+
+func (d *Datastore) ListHostHistory(hostID uint, opt kolide.ListOptions) ([]*kolide.HostHistory, error) {
+	sqlStatement := `
+		SELECT id, host_id, field, old_value, new_value, created_at
+		FROM host_history
+		WHERE host_id = ?
+	`
+	if opt.OrderKey == "" {
+		opt.OrderKey = "id"
+		opt.OrderDirection = kolide.OrderDescending
+	}
+	sqlStatement = appendListOptionsToSQL(sqlStatement, opt)
+
+	history := []*kolide.HostHistory{}
+	if err := d.db.Select(&history, sqlStatement, hostID); err != nil {
+		return nil, errors.Wrapf(err, "list history for host %d", hostID)
+	}
+
+	return history, nil
+}
@@ -0,0 +1,97 @@
+package mysql
+
+import (
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+func (d *Datastore) SaveHostStatusLogs(hostID uint, logs []*kolide.HostStatusLog, keep uint) error {
+	if len(logs) == 0 || keep == 0 {
+		return nil
+	}
+
+	return d.withRetryTxx(func(tx *sqlx.Tx) error {
+		values := []string{}
+		args := []interface{}{}
+		for _, log := range logs {
+			values = append(values, "(?,?,?,?,?,?,?)")
+			args = append(args, hostID, log.Severity, log.Filename, log.Line, log.Message, log.QueryName, log.CreatedAt)
+		}
+		sqlStatement := `
+			INSERT INTO host_status_logs (host_id, severity, filename, line, message, query_name, created_at)
+			VALUES ` + strings.Join(values, ",")
+		if _, err := tx.Exec(sqlStatement, args...); err != nil {
+			return errors.Wrap(err, "insert host status logs")
+		}
+
+		// Delete everything older than the keep-th most recent log. The
+		// subquery is wrapped in a derived table as MySQL does not allow
+		// LIMIT in a subquery on the table being deleted from.
+		sqlStatement = `
+			DELETE FROM host_status_logs
+			WHERE host_id = ? AND id < (
+				SELECT id FROM (
+					SELECT id FROM host_status_logs
+					WHERE host_id = ?
+					ORDER BY id DESC
+					LIMIT 1 OFFSET ?
+				) AS oldest_kept
+			)
+		`
+		if _, err := tx.Exec(sqlStatement, hostID, hostID, int(keep)-1); err != nil {
+			return errors.Wrap(err, "trim host status logs")
+		}
+
+		return nil
+	})
+}
+
+func (d *Datastore) ListHostStatusLogs(hostID uint, opt kolide.ListOptions) ([]*kolide.HostStatusLog, error) {
+	sqlStatement := `
+		SELECT id, host_id, severity, filename, line, message, query_name, created_at
+		FROM host_status_logs
+		WHERE host_id = ?
+	`
+	if opt.OrderKey == "" {
+		opt.OrderKey = "id"
+		opt.OrderDirection = kolide.OrderDescending
+	}
+	sqlStatement = appendListOptionsToSQL(sqlStatement, opt)
+
+	logs := []*kolide.HostStatusLog{}
+	if err := d.db.Select(&logs, sqlStatement, hostID); err != nil {
+		return nil, errors.Wrapf(err, "list status logs for host %d", hostID)
+	}
+
+	return logs, nil
+}
+
+func (d *Datastore) ListScheduledQueryErrors() ([]*kolide.ScheduledQueryErrors, error) {
+	sqlStatement := `
+		SELECT
+			l.query_name,
+			COUNT(*) AS count,
+			COUNT(DISTINCT l.host_id) AS host_count,
+			MAX(l.created_at) AS last_seen,
+			(
+				SELECT message FROM host_status_logs
+				WHERE query_name = l.query_name
+				ORDER BY created_at DESC, id DESC
+				LIMIT 1
+			) AS last_message
+		FROM host_status_logs l
+		WHERE l.query_name IS NOT NULL
+		GROUP BY l.query_name
+		ORDER BY count DESC, l.query_name
+	`
+
+	summaries := []*kolide.ScheduledQueryErrors{}
+	if err := d.db.Select(&summaries, sqlStatement); err != nil {
+		return nil, errors.Wrap(err, "list scheduled query errors")
+	}
+
+	return summaries, nil
+}
@@ -0,0 +1,90 @@
+package mysql
+
+import (
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+func (d *Datastore) SetHostTags(hostID uint, tags map[string]string) error {
+	err := d.withRetryTxx(func(tx *sqlx.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM host_tags WHERE host_id = ?`, hostID); err != nil {
+			return errors.Wrap(err, "delete existing host tags")
+		}
+
+		if len(tags) == 0 {
+			return nil
+		}
+
+		values := strings.TrimSuffix(strings.Repeat("(?,?),", len(tags)), ",")
+		args := make([]interface{}, 0, 2*len(tags))
+		for key, value := range tags {
+			args = append(args, key, value)
+		}
+
+		sql := "INSERT IGNORE INTO tags (`key`, value) VALUES " + values
+		if _, err := tx.Exec(sql, args...); err != nil {
+			return errors.Wrap(err, "insert tags")
+		}
+
+		conditions := strings.TrimSuffix(strings.Repeat("(`key` = ? AND value = ?) OR ", len(tags)), " OR ")
+		sql = `
+			INSERT IGNORE INTO host_tags (host_id, tag_id)
+			SELECT ?, id FROM tags WHERE ` + conditions
+		if _, err := tx.Exec(sql, append([]interface{}{hostID}, args...)...); err != nil {
+			return errors.Wrap(err, "insert host tags")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "set tags for host %d", hostID)
+	}
+
+	return nil
+}
+
+func (d *Datastore) ListTagsForHost(hostID uint) (map[string]string, error) {
+	sql := "" +
+		"SELECT t.id, t.`key`, t.value " +
+		"FROM tags t " +
+		"JOIN host_tags ht ON (ht.tag_id = t.id) " +
+		"WHERE ht.host_id = ?"
+	rows := []kolide.HostTag{}
+	if err := d.db.Select(&rows, sql, hostID); err != nil {
+		return nil, errors.Wrapf(err, "list tags for host %d", hostID)
+	}
+
+	tags := make(map[string]string, len(rows))
+	for _, tag := range rows {
+		tags[tag.Key] = tag.Value
+	}
+	return tags, nil
+}
+
+func (d *Datastore) TagIDsByName(names []string) ([]uint, error) {
+	if len(names) == 0 {
+		return []uint{}, nil
+	}
+
+	conditions := make([]string, 0, len(names))
+	args := make([]interface{}, 0, 2*len(names))
+	for _, name := range names {
+		tag, err := kolide.ParseHostTagName(name)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, "(`key` = ? AND value = ?)")
+		args = append(args, tag.Key, tag.Value)
+	}
+
+	sql := `SELECT id FROM tags WHERE ` + strings.Join(conditions, " OR ")
+	ids := []uint{}
+	if err := d.db.Select(&ids, sql, args...); err != nil {
+		return nil, errors.Wrap(err, "get tags by name")
+	}
+
+	return ids, nil
+}
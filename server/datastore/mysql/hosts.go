@@ -35,6 +35,7 @@ func (d *Datastore) NewHost(host *kolide.Host) (*kolide.Host, error) {
 	}
 	id, _ := result.LastInsertId()
 	host.ID = uint(id)
+	host.MarkStored()
 	return host, nil
 }
 
@@ -248,10 +249,14 @@ func (d *Datastore) SaveHost(host *kolide.Host) error {
 			}
 		}
 
-		return nil
+		return d.recordHostHistory(tx, host)
 	})
+	if err != nil {
+		return err
+	}
 
-	return err
+	host.MarkStored()
+	return nil
 }
 
 func (d *Datastore) DeleteHost(hid uint) error {
@@ -262,6 +267,44 @@ func (d *Datastore) DeleteHost(hid uint) error {
 	return nil
 }
 
+func (d *Datastore) DeleteHosts(ids []uint, opts ...kolide.OptionalArg) (uint, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	db := d.getTransaction(opts)
+	query, args, err := sqlx.In(`DELETE FROM hosts WHERE id IN (?)`, ids)
+	if err != nil {
+		return 0, errors.Wrap(err, "building delete hosts query")
+	}
+	result, err := db.Exec(query, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "deleting hosts")
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "deleting hosts reading rows affected")
+	}
+
+	return uint(deleted), nil
+}
+
+func (d *Datastore) ExistingHostIDs(ids []uint) ([]uint, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	query, args, err := sqlx.In(`SELECT id FROM hosts WHERE id IN (?) AND NOT deleted ORDER BY id`, ids)
+	if err != nil {
+		return nil, errors.Wrap(err, "building existing host IDs query")
+	}
+	var existing []uint
+	if err := d.db.Select(&existing, query, args...); err != nil {
+		return nil, errors.Wrap(err, "selecting existing host IDs")
+	}
+	return existing, nil
+}
+
 func (d *Datastore) Host(id uint) (*kolide.Host, error) {
 	sqlStatement := `
 		SELECT * FROM hosts
@@ -276,23 +319,30 @@ func (d *Datastore) Host(id uint) (*kolide.Host, error) {
 	if err := d.getNetInterfacesForHost(host); err != nil {
 		return nil, err
 	}
+	host.MarkStored()
 
 	return host, nil
 
 }
 
-func (d *Datastore) ListHosts(opt kolide.ListOptions) ([]*kolide.Host, error) {
+func (d *Datastore) ListHosts(opt kolide.HostListOptions) ([]*kolide.Host, error) {
+	filterSQL, args, err := hostFilterToSQL(opt.Filter, d.clock.Now())
+	if err != nil {
+		return nil, errors.Wrap(err, "list hosts")
+	}
+
 	sqlStatement := `
 		SELECT * FROM hosts
-		WHERE NOT deleted
+		WHERE NOT deleted AND ` + filterSQL + `
 	`
-	sqlStatement = appendListOptionsToSQL(sqlStatement, opt)
+	sqlStatement = appendListOptionsToSQL(sqlStatement, opt.ListOptions)
 	hosts := []*kolide.Host{}
-	if err := d.db.Select(&hosts, sqlStatement); err != nil {
+	if err := d.db.Select(&hosts, sqlStatement, args...); err != nil {
 		return nil, errors.Wrap(err, "list hosts")
 	}
 
-	if opt.PerPage == 0 || (opt.Page == 0 && uint(len(hosts)) < opt.PerPage) {
+	unfiltered := opt.Filter == nil || len(opt.Filter.Terms) == 0
+	if unfiltered && (opt.PerPage == 0 || (opt.Page == 0 && uint(len(hosts)) < opt.PerPage)) {
 		// If all hosts, we can use the optimized network interface retrieval function
 		if err := d.getNetInterfacesForAllHosts(hosts); err != nil {
 			return nil, err
@@ -303,6 +353,9 @@ func (d *Datastore) ListHosts(opt kolide.ListOptions) ([]*kolide.Host, error) {
 			return nil, err
 		}
 	}
+	for _, host := range hosts {
+		host.MarkStored()
+	}
 
 	return hosts, nil
 }
@@ -320,6 +373,49 @@ func (d *Datastore) CleanupIncomingHosts(now time.Time) error {
 	return nil
 }
 
+func (d *Datastore) ListExpiredHosts(now time.Time, window time.Duration) ([]*kolide.Host, error) {
+	sqlStatement := `
+		SELECT * FROM hosts
+		WHERE seen_time < ? AND NOT deleted
+		ORDER BY id
+	`
+	hosts := []*kolide.Host{}
+	if err := d.db.Select(&hosts, sqlStatement, now.Add(-window)); err != nil {
+		return nil, errors.Wrap(err, "list expired hosts")
+	}
+
+	return hosts, nil
+}
+
+func (d *Datastore) ExpireHosts(now time.Time, window time.Duration) ([]uint, error) {
+	cutoff := now.Add(-window)
+	var ids []uint
+	err := d.withRetryTxx(func(tx *sqlx.Tx) error {
+		ids = []uint{}
+		if err := tx.Select(&ids, `SELECT id FROM hosts WHERE seen_time < ? AND NOT deleted FOR UPDATE`, cutoff); err != nil {
+			return errors.Wrap(err, "select expired hosts")
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		query, args, err := sqlx.In(`DELETE FROM hosts WHERE id IN (?)`, ids)
+		if err != nil {
+			return errors.Wrap(err, "building expire hosts query")
+		}
+		if _, err := tx.Exec(query, args...); err != nil {
+			return errors.Wrap(err, "delete expired hosts")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "expire hosts")
+	}
+
+	return ids, nil
+}
+
 func (d *Datastore) GenerateHostStatusStatistics(now time.Time) (online, offline, mia, new uint, e error) {
 	// The logic in this function should remain synchronized with
 	// host.Status and CountHostsInTargets
@@ -479,6 +575,7 @@ func (d *Datastore) EnrollHost(osqueryHostID, nodeKey, secretName string) (*koli
 	if err != nil {
 		return nil, errors.Wrap(err, "getting the host to return")
 	}
+	host.MarkStored()
 
 	return host, nil
 
@@ -539,6 +636,7 @@ func (d *Datastore) AuthenticateHost(nodeKey string) (*kolide.Host, error) {
 	if err := d.getNetInterfacesForHost(host); err != nil {
 		return nil, errors.Wrap(err, "getting interfaces")
 	}
+	host.MarkStored()
 
 	return host, nil
 }
@@ -702,18 +800,21 @@ func (d *Datastore) DistributedQueriesForHost(host *kolide.Host) (map[uint]strin
 		    ON (dqc.id = dqct.distributed_query_campaign_id)
 		LEFT JOIN label_query_executions lqe
 		    ON (dqct.type = ? AND dqct.target_id = lqe.label_id AND lqe.matches)
+		LEFT JOIN host_tags ht
+		    ON (dqct.type = ? AND dqct.target_id = ht.tag_id)
 		LEFT JOIN hosts h
-		    ON ((dqct.type = ? AND lqe.host_id = h.id) OR (dqct.type = ? AND dqct.target_id = h.id))
+		    ON ((dqct.type = ? AND lqe.host_id = h.id) OR (dqct.type = ? AND dqct.target_id = h.id) OR (dqct.type = ? AND ht.host_id = h.id))
 		LEFT JOIN distributed_query_executions dqe
 		    ON (h.id = dqe.host_id AND dqc.id = dqe.distributed_query_campaign_id)
 		JOIN queries q
 		    ON (dqc.query_id = q.id)
-		WHERE dqe.status IS NULL AND dqc.status = ? AND h.id = ?
+		WHERE (dqe.status IS NULL OR (dqe.status = ? AND NOT dqc.deferred))
+			AND dqc.status = ? AND h.id = ?
 			AND NOT q.deleted
 			AND NOT dqc.deleted
  `
-	rows, err := d.db.Query(sqlStatement, kolide.TargetLabel, kolide.TargetLabel,
-		kolide.TargetHost, kolide.QueryRunning, host.ID)
+	rows, err := d.db.Query(sqlStatement, kolide.TargetLabel, kolide.TargetTag, kolide.TargetLabel,
+		kolide.TargetHost, kolide.TargetTag, kolide.ExecutionRequested, kolide.QueryRunning, host.ID)
 	if err != nil {
 		return nil, errors.Wrap(err, "finding distributed queries for host")
 	}
@@ -761,3 +862,239 @@ func (d *Datastore) HostIDsByName(hostnames []string) ([]uint, error) {
 	return hostIDs, nil
 
 }
+
+func (d *Datastore) FindHostByIdentity(strategy kolide.HostIdentityStrategy, identity kolide.HostIdentity) (*kolide.Host, error) {
+	var where string
+	var args []interface{}
+	switch strategy {
+	case kolide.HostIdentityUUID:
+		if identity.UUID == "" {
+			return nil, notFound("Host")
+		}
+		where, args = "uuid = ?", []interface{}{identity.UUID}
+	case kolide.HostIdentitySerial, kolide.HostIdentityHostnameSerial:
+		// Placeholder serials are shared by many machines, which are
+		// told apart by their UUID instead.
+		if kolide.IsPlaceholderSerial(identity.HardwareSerial) {
+			return d.FindHostByIdentity(kolide.HostIdentityUUID, identity)
+		}
+		if strategy == kolide.HostIdentitySerial {
+			where, args = "hardware_serial = ?", []interface{}{identity.HardwareSerial}
+			break
+		}
+		if identity.HostName == "" {
+			return nil, notFound("Host")
+		}
+		where = "host_name = ? AND hardware_serial = ?"
+		args = []interface{}{identity.HostName, identity.HardwareSerial}
+	default:
+		return nil, errors.Errorf("unsupported host identity strategy %q", strategy)
+	}
+
+	sqlStatement := `
+		SELECT * FROM hosts
+		WHERE NOT deleted AND ` + where + `
+		ORDER BY seen_time DESC
+		LIMIT 1
+	`
+	host := &kolide.Host{}
+	if err := d.db.Get(host, sqlStatement, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFound("Host")
+		}
+		return nil, errors.Wrap(err, "find host by identity")
+	}
+	host.MarkStored()
+
+	return host, nil
+}
+
+func (d *Datastore) ReenrollHost(hostID uint, osqueryHostID, nodeKey, secretName string) (*kolide.Host, error) {
+	if osqueryHostID == "" {
+		return nil, fmt.Errorf("missing osquery host identifier")
+	}
+
+	err := d.withRetryTxx(func(tx *sqlx.Tx) error {
+		// A host may already exist with the identifier (for instance, if
+		// the agent enrolled before the identity strategy was enabled).
+		// Fold it into the host being re-enrolled so that the unique
+		// identifier can be moved.
+		var conflictID uint
+		err := tx.Get(&conflictID,
+			`SELECT id FROM hosts WHERE osquery_host_id = ? AND id != ?`,
+			osqueryHostID, hostID,
+		)
+		switch err {
+		case nil:
+			if err := mergeHostsDB(tx, hostID, conflictID); err != nil {
+				return err
+			}
+		case sql.ErrNoRows:
+		default:
+			return errors.Wrap(err, "check for conflicting host")
+		}
+
+		// Reset the detail update time so that the details of the
+		// (possibly reimaged) machine are refreshed on the next check in.
+		result, err := tx.Exec(`
+			UPDATE hosts SET
+				osquery_host_id = ?,
+				node_key = ?,
+				enroll_secret_name = ?,
+				detail_update_time = ?,
+				deleted = FALSE
+			WHERE id = ?
+			`,
+			osqueryHostID, nodeKey, secretName, time.Unix(0, 0).Add(24*time.Hour), hostID,
+		)
+		if err != nil {
+			return errors.Wrap(err, "update host")
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return errors.Wrap(err, "rows affected updating host")
+		}
+		if rows == 0 {
+			return notFound("Host").WithID(hostID)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "re-enroll host %d", hostID)
+	}
+
+	host := &kolide.Host{}
+	if err := d.db.Get(host, `SELECT * FROM hosts WHERE id = ? LIMIT 1`, hostID); err != nil {
+		return nil, errors.Wrap(err, "getting the host to return")
+	}
+	host.MarkStored()
+
+	return host, nil
+}
+
+func (d *Datastore) MergeHosts(survivorID, duplicateID uint) error {
+	if survivorID == duplicateID {
+		return errors.New("cannot merge a host into itself")
+	}
+
+	err := d.withRetryTxx(func(tx *sqlx.Tx) error {
+		for _, id := range []uint{survivorID, duplicateID} {
+			var exists bool
+			err := tx.Get(&exists, `SELECT EXISTS(SELECT 1 FROM hosts WHERE id = ?)`, id)
+			if err != nil {
+				return errors.Wrap(err, "check host exists")
+			}
+			if !exists {
+				return notFound("Host").WithID(id)
+			}
+		}
+
+		return mergeHostsDB(tx, survivorID, duplicateID)
+	})
+	if err != nil {
+		return errors.Wrapf(err, "merge host %d into host %d", duplicateID, survivorID)
+	}
+
+	return nil
+}
+
+// mergeHostsDB moves the label memberships, pack memberships, tags, software,
+// campaign targets, executions and results, history and status logs of the
+// duplicate host to the survivor and deletes the duplicate. Where both hosts
+// have a row for the same label, pack, tag key, software or campaign
+// execution, the survivor's is kept.
+func mergeHostsDB(tx *sqlx.Tx, survivorID, duplicateID uint) error {
+	statements := []struct {
+		desc string
+		sql  string
+		args []interface{}
+	}{
+		{
+			"move label memberships",
+			`UPDATE IGNORE label_query_executions SET host_id = ? WHERE host_id = ?`,
+			[]interface{}{survivorID, duplicateID},
+		},
+		{
+			"delete conflicting label memberships",
+			`DELETE FROM label_query_executions WHERE host_id = ?`,
+			[]interface{}{duplicateID},
+		},
+		{
+			"move pack memberships",
+			`UPDATE IGNORE pack_targets SET target_id = ? WHERE type = ? AND target_id = ?`,
+			[]interface{}{survivorID, kolide.TargetHost, duplicateID},
+		},
+		{
+			"delete conflicting pack memberships",
+			`DELETE FROM pack_targets WHERE type = ? AND target_id = ?`,
+			[]interface{}{kolide.TargetHost, duplicateID},
+		},
+		{
+			"move tags",
+			`INSERT IGNORE INTO host_tags (host_id, tag_id)
+			SELECT ?, ht.tag_id
+			FROM host_tags ht
+			JOIN tags t ON (ht.tag_id = t.id)
+			WHERE ht.host_id = ? AND t.key NOT IN (
+				SELECT st.key
+				FROM host_tags sht
+				JOIN tags st ON (sht.tag_id = st.id)
+				WHERE sht.host_id = ?
+			)`,
+			[]interface{}{survivorID, duplicateID, survivorID},
+		},
+		{
+			"move software",
+			`UPDATE IGNORE host_software SET host_id = ? WHERE host_id = ?`,
+			[]interface{}{survivorID, duplicateID},
+		},
+		{
+			"delete conflicting software",
+			`DELETE FROM host_software WHERE host_id = ?`,
+			[]interface{}{duplicateID},
+		},
+		{
+			"move campaign targets",
+			`UPDATE distributed_query_campaign_targets SET target_id = ? WHERE type = ? AND target_id = ?`,
+			[]interface{}{survivorID, kolide.TargetHost, duplicateID},
+		},
+		{
+			"move campaign executions",
+			`UPDATE IGNORE distributed_query_executions SET host_id = ? WHERE host_id = ?`,
+			[]interface{}{survivorID, duplicateID},
+		},
+		{
+			"delete conflicting campaign executions",
+			`DELETE FROM distributed_query_executions WHERE host_id = ?`,
+			[]interface{}{duplicateID},
+		},
+		{
+			"move campaign results",
+			`UPDATE distributed_query_campaign_results SET host_id = ? WHERE host_id = ?`,
+			[]interface{}{survivorID, duplicateID},
+		},
+		{
+			"move history",
+			`UPDATE host_history SET host_id = ? WHERE host_id = ?`,
+			[]interface{}{survivorID, duplicateID},
+		},
+		{
+			"move status logs",
+			`UPDATE host_status_logs SET host_id = ? WHERE host_id = ?`,
+			[]interface{}{survivorID, duplicateID},
+		},
+		{
+			"delete duplicate host",
+			`DELETE FROM hosts WHERE id = ?`,
+			[]interface{}{duplicateID},
+		},
+	}
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt.sql, stmt.args...); err != nil {
+			return errors.Wrap(err, stmt.desc)
+		}
+	}
+
+	return nil
+}
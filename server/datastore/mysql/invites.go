@@ -18,20 +18,20 @@ func (d *Datastore) NewInvite(i *kolide.Invite) (*kolide.Invite, error) {
 	switch err {
 	case nil:
 		sqlStmt = `
-		REPLACE INTO invites ( invited_by, email, admin, name, position, token, deleted, sso_enabled)
-		  VALUES ( ?, ?, ?, ?, ?, ?, ?, ?)
+		REPLACE INTO invites ( invited_by, email, admin, role, name, position, token, deleted, sso_enabled)
+		  VALUES ( ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		`
 	case sql.ErrNoRows:
 		sqlStmt = `
-		INSERT INTO invites ( invited_by, email, admin, name, position, token, deleted, sso_enabled)
-		  VALUES ( ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO invites ( invited_by, email, admin, role, name, position, token, deleted, sso_enabled)
+		  VALUES ( ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		`
 	default:
 		return nil, errors.Wrap(err, "check for existing invite")
 	}
 
 	deleted := false
-	result, err := d.db.Exec(sqlStmt, i.InvitedBy, i.Email, i.Admin,
+	result, err := d.db.Exec(sqlStmt, i.InvitedBy, i.Email, i.Admin, i.Role,
 		i.Name, i.Position, i.Token, deleted, i.SSOEnabled)
 	if err != nil && isDuplicate(err) {
 		return nil, alreadyExists("Invite", 0)
@@ -103,12 +103,12 @@ func (d *Datastore) InviteByToken(token string) (*kolide.Invite, error) {
 // SaveInvite modifies existing Invite
 func (d *Datastore) SaveInvite(i *kolide.Invite) error {
 	sql := `
-	UPDATE invites SET invited_by = ?, email = ?, admin = ?,
+	UPDATE invites SET invited_by = ?, email = ?, admin = ?, role = ?,
 	   name = ?, position = ?, token = ?, sso_enabled = ?
 		 WHERE id = ? AND NOT deleted
 	`
 	results, err := d.db.Exec(sql, i.InvitedBy, i.Email,
-		i.Admin, i.Name, i.Position, i.Token, i.SSOEnabled, i.ID,
+		i.Admin, i.Role, i.Name, i.Position, i.Token, i.SSOEnabled, i.ID,
 	)
 	if err != nil {
 		return errors.Wrap(err, "save invite")
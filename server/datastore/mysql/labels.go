@@ -2,6 +2,7 @@ package mysql
 
 import (
 	"database/sql"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -233,7 +234,8 @@ func (d *Datastore) RecordLabelQueryExecutions(host *kolide.Host, results map[ui
 	sqlStatement += `
 		ON DUPLICATE KEY UPDATE
 		updated_at = VALUES(updated_at),
-		matches = VALUES(matches)
+		matches = VALUES(matches),
+		error = NULL
 	`
 
 	_, err := d.db.Exec(sqlStatement, vals...)
@@ -244,6 +246,63 @@ func (d *Datastore) RecordLabelQueryExecutions(host *kolide.Host, results map[ui
 	return nil
 }
 
+func (d *Datastore) RecordLabelQueryErrors(host *kolide.Host, errs map[uint]string, updated time.Time) error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	sqlStatement := `
+	INSERT INTO label_query_executions (updated_at, matches, error, label_id, host_id) VALUES
+	`
+	vals := []interface{}{}
+	bindvars := []string{}
+	for labelID, msg := range errs {
+		bindvars = append(bindvars, "(?,FALSE,?,?,?)")
+		vals = append(vals, updated, msg, labelID, host.ID)
+	}
+
+	sqlStatement += strings.Join(bindvars, ",")
+	sqlStatement += `
+		ON DUPLICATE KEY UPDATE
+		updated_at = VALUES(updated_at),
+		matches = VALUES(matches),
+		error = VALUES(error)
+	`
+
+	_, err := d.db.Exec(sqlStatement, vals...)
+	if err != nil {
+		return errors.Wrap(err, "inserting label query errors")
+	}
+
+	return nil
+}
+
+func (d *Datastore) ListLabelExecutionsForHost(host *kolide.Host) ([]*kolide.HostLabelExecution, error) {
+	sqlStatement := `
+		SELECT
+			l.id AS label_id,
+			l.name AS label_name,
+			l.label_type,
+			COALESCE(lqe.matches, FALSE) AS matches,
+			lqe.updated_at,
+			lqe.error
+		FROM labels l
+		LEFT JOIN label_query_executions lqe
+		ON lqe.label_id = l.id AND lqe.host_id = ?
+		WHERE NOT l.deleted
+		AND (l.platform = ? OR l.platform = '' OR lqe.id IS NOT NULL)
+		ORDER BY l.id
+	`
+
+	executions := []*kolide.HostLabelExecution{}
+	err := d.db.Select(&executions, sqlStatement, host.ID, host.Platform)
+	if err != nil {
+		return nil, errors.Wrap(err, "selecting host label executions")
+	}
+
+	return executions, nil
+}
+
 // ListLabelsForHost returns a list of kolide.Label for a given host id.
 func (d *Datastore) ListLabelsForHost(hid uint) ([]kolide.Label, error) {
 	sqlStatement := `
@@ -264,6 +323,41 @@ func (d *Datastore) ListLabelsForHost(hid uint) ([]kolide.Label, error) {
 
 }
 
+// ListLabelsForHosts returns the labels of each of the given hosts, keyed
+// by host ID.
+func (d *Datastore) ListLabelsForHosts(hids []uint) (map[uint][]kolide.Label, error) {
+	labels := map[uint][]kolide.Label{}
+	if len(hids) == 0 {
+		return labels, nil
+	}
+
+	sqlStatement := `
+		SELECT lqe.host_id, labels.* from labels, label_query_executions lqe
+		WHERE lqe.host_id IN (?)
+		AND lqe.label_id = labels.id
+		AND lqe.matches
+		AND NOT labels.deleted
+		ORDER BY lqe.host_id, labels.id
+	`
+	query, args, err := sqlx.In(sqlStatement, hids)
+	if err != nil {
+		return nil, errors.Wrap(err, "building host labels query")
+	}
+
+	var rows []struct {
+		HostID uint `db:"host_id"`
+		kolide.Label
+	}
+	if err := d.db.Select(&rows, query, args...); err != nil {
+		return nil, errors.Wrap(err, "selecting labels for hosts")
+	}
+	for _, row := range rows {
+		labels[row.HostID] = append(labels[row.HostID], row.Label)
+	}
+
+	return labels, nil
+}
+
 // ListHostsInLabel returns a list of kolide.Host that are associated
 // with kolide.Label referened by Label ID
 func (d *Datastore) ListHostsInLabel(lid uint) ([]kolide.Host, error) {
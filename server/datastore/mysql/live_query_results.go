@@ -0,0 +1,168 @@
+package mysql
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+func (d *Datastore) NewLiveQueryReader(campaignID uint) (uint, error) {
+	sqlStatement := `
+		INSERT INTO live_query_readers (campaign_id, updated_at)
+		VALUES (?, ?)
+	`
+	res, err := d.db.Exec(sqlStatement, campaignID, d.clock.Now())
+	if err != nil {
+		return 0, errors.Wrap(err, "insert live query reader")
+	}
+	id, _ := res.LastInsertId()
+	return uint(id), nil
+}
+
+func (d *Datastore) TouchLiveQueryReader(id uint) error {
+	sqlStatement := `
+		UPDATE live_query_readers
+		SET updated_at = ?
+		WHERE id = ?
+	`
+	res, err := d.db.Exec(sqlStatement, d.clock.Now(), id)
+	if err != nil {
+		return errors.Wrap(err, "touch live query reader")
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "rows affected touching live query reader")
+	}
+	if rows == 0 {
+		return notFound("LiveQueryReader").WithID(id)
+	}
+	return nil
+}
+
+func (d *Datastore) DeleteLiveQueryReader(id uint) error {
+	var campaignID uint
+	err := d.db.Get(&campaignID, "SELECT campaign_id FROM live_query_readers WHERE id = ?", id)
+	if err == sql.ErrNoRows {
+		return notFound("LiveQueryReader").WithID(id)
+	}
+	if err != nil {
+		return errors.Wrap(err, "select live query reader")
+	}
+
+	if _, err := d.db.Exec("DELETE FROM live_query_readers WHERE id = ?", id); err != nil {
+		return errors.Wrap(err, "delete live query reader")
+	}
+
+	// Results are only retained while the campaign has readers
+	sqlStatement := `
+		DELETE FROM live_query_results
+		WHERE campaign_id = ?
+		AND NOT EXISTS (
+			SELECT 1 FROM live_query_readers WHERE campaign_id = ?
+		)
+	`
+	if _, err := d.db.Exec(sqlStatement, campaignID, campaignID); err != nil {
+		return errors.Wrap(err, "delete live query results")
+	}
+	return d.deleteUnusedLiveQueryResultSequences()
+}
+
+// deleteUnusedLiveQueryResultSequences removes the sequences of the
+// campaigns without readers nor results.
+func (d *Datastore) deleteUnusedLiveQueryResultSequences() error {
+	sqlStatement := `
+		DELETE FROM live_query_result_sequences
+		WHERE campaign_id NOT IN (SELECT campaign_id FROM live_query_readers)
+		AND campaign_id NOT IN (SELECT campaign_id FROM live_query_results)
+	`
+	if _, err := d.db.Exec(sqlStatement); err != nil {
+		return errors.Wrap(err, "delete live query result sequences")
+	}
+	return nil
+}
+
+func (d *Datastore) NewLiveQueryResult(campaignID uint, result []byte, activeSince time.Time) (bool, error) {
+	saved := false
+	err := d.withRetryTxx(func(tx *sqlx.Tx) error {
+		saved = false
+		// Results are not stored for campaigns that nobody reads.
+		var active bool
+		sqlStatement := `
+			SELECT EXISTS (
+				SELECT 1 FROM live_query_readers
+				WHERE campaign_id = ? AND updated_at >= ?
+			)
+		`
+		if err := tx.Get(&active, sqlStatement, campaignID, activeSince); err != nil {
+			return errors.Wrap(err, "select live query readers")
+		}
+		if !active {
+			return nil
+		}
+
+		// The sequence row stays locked until the result is committed,
+		// so that results become visible in the order of their sequence
+		// and readers never skip a result committed late.
+		sqlStatement = `
+			INSERT INTO live_query_result_sequences (campaign_id, seq)
+			VALUES (?, 1)
+			ON DUPLICATE KEY UPDATE seq = seq + 1
+		`
+		if _, err := tx.Exec(sqlStatement, campaignID); err != nil {
+			return errors.Wrap(err, "increment live query result sequence")
+		}
+		var seq uint
+		err := tx.Get(&seq, "SELECT seq FROM live_query_result_sequences WHERE campaign_id = ?", campaignID)
+		if err != nil {
+			return errors.Wrap(err, "select live query result sequence")
+		}
+
+		sqlStatement = `
+			INSERT INTO live_query_results (campaign_id, seq, result, created_at)
+			VALUES (?, ?, ?, ?)
+		`
+		if _, err := tx.Exec(sqlStatement, campaignID, seq, result, d.clock.Now()); err != nil {
+			return errors.Wrap(err, "insert live query result")
+		}
+		saved = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return saved, nil
+}
+
+func (d *Datastore) ListLiveQueryResults(campaignID uint, afterSeq uint, limit uint) ([]*kolide.LiveQueryResult, error) {
+	sqlStatement := `
+		SELECT * FROM live_query_results
+		WHERE campaign_id = ? AND seq > ?
+		ORDER BY seq
+		LIMIT ?
+	`
+	results := []*kolide.LiveQueryResult{}
+	if err := d.db.Select(&results, sqlStatement, campaignID, afterSeq, limit); err != nil {
+		return nil, errors.Wrap(err, "select live query results")
+	}
+	return results, nil
+}
+
+func (d *Datastore) CleanupLiveQueryResults(activeSince, createdBefore time.Time) error {
+	_, err := d.db.Exec("DELETE FROM live_query_readers WHERE updated_at < ?", activeSince)
+	if err != nil {
+		return errors.Wrap(err, "delete inactive live query readers")
+	}
+
+	sqlStatement := `
+		DELETE FROM live_query_results
+		WHERE created_at < ?
+		OR campaign_id NOT IN (SELECT campaign_id FROM live_query_readers)
+	`
+	if _, err := d.db.Exec(sqlStatement, createdBefore); err != nil {
+		return errors.Wrap(err, "delete live query results")
+	}
+	return d.deleteUnusedLiveQueryResultSequences()
+}
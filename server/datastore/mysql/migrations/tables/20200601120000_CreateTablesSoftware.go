@@ -0,0 +1,49 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20200601120000, Down_20200601120000)
+}
+
+func Up_20200601120000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"CREATE TABLE `software` (" +
+			"`id` INT(10) UNSIGNED NOT NULL AUTO_INCREMENT," +
+			"`name` VARCHAR(255) NOT NULL," +
+			"`version` VARCHAR(255) NOT NULL DEFAULT ''," +
+			"`source` VARCHAR(64) NOT NULL," +
+			"PRIMARY KEY (`id`)," +
+			"UNIQUE KEY `idx_software_unique_name_version_source` (`name`, `version`, `source`)" +
+			") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;",
+	)
+	if err != nil {
+		return errors.Wrap(err, "create software table")
+	}
+
+	_, err = tx.Exec(
+		"CREATE TABLE `host_software` (" +
+			"`host_id` INT(10) UNSIGNED NOT NULL," +
+			"`software_id` INT(10) UNSIGNED NOT NULL," +
+			"PRIMARY KEY (`host_id`, `software_id`)," +
+			"KEY `idx_host_software_software_id` (`software_id`)," +
+			"FOREIGN KEY `fk_host_software_host_id` (`host_id`) " +
+			"REFERENCES hosts(id) ON DELETE CASCADE," +
+			"FOREIGN KEY `fk_host_software_software_id` (`software_id`) " +
+			"REFERENCES software(id) ON DELETE CASCADE" +
+			") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;",
+	)
+	if err != nil {
+		return errors.Wrap(err, "create host_software table")
+	}
+
+	return nil
+}
+
+func Down_20200601120000(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,37 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20200602120000, Down_20200602120000)
+}
+
+func Up_20200602120000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"CREATE TABLE `host_history` (" +
+			"`id` INT(10) UNSIGNED NOT NULL AUTO_INCREMENT," +
+			"`host_id` INT(10) UNSIGNED NOT NULL," +
+			"`field` VARCHAR(64) NOT NULL," +
+			"`old_value` VARCHAR(255) NOT NULL DEFAULT ''," +
+			"`new_value` VARCHAR(255) NOT NULL DEFAULT ''," +
+			"`created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP," +
+			"PRIMARY KEY (`id`)," +
+			"KEY `idx_host_history_host_id_created_at` (`host_id`, `created_at`)," +
+			"FOREIGN KEY `fk_host_history_host_id` (`host_id`) " +
+			"REFERENCES hosts(id) ON DELETE CASCADE" +
+			") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;",
+	)
+	if err != nil {
+		return errors.Wrap(err, "create host_history table")
+	}
+
+	return nil
+}
+
+func Down_20200602120000(tx *sql.Tx) error {
+	return nil
+}
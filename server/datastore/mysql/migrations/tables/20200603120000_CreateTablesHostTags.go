@@ -0,0 +1,48 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20200603120000, Down_20200603120000)
+}
+
+func Up_20200603120000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"CREATE TABLE `tags` (" +
+			"`id` INT(10) UNSIGNED NOT NULL AUTO_INCREMENT," +
+			"`key` VARCHAR(255) NOT NULL," +
+			"`value` VARCHAR(255) NOT NULL DEFAULT ''," +
+			"PRIMARY KEY (`id`)," +
+			"UNIQUE KEY `idx_tags_unique_key_value` (`key`, `value`)" +
+			") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;",
+	)
+	if err != nil {
+		return errors.Wrap(err, "create tags table")
+	}
+
+	_, err = tx.Exec(
+		"CREATE TABLE `host_tags` (" +
+			"`host_id` INT(10) UNSIGNED NOT NULL," +
+			"`tag_id` INT(10) UNSIGNED NOT NULL," +
+			"PRIMARY KEY (`host_id`, `tag_id`)," +
+			"KEY `idx_host_tags_tag_id` (`tag_id`)," +
+			"FOREIGN KEY `fk_host_tags_host_id` (`host_id`) " +
+			"REFERENCES hosts(id) ON DELETE CASCADE," +
+			"FOREIGN KEY `fk_host_tags_tag_id` (`tag_id`) " +
+			"REFERENCES tags(id) ON DELETE CASCADE" +
+			") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;",
+	)
+	if err != nil {
+		return errors.Wrap(err, "create host_tags table")
+	}
+
+	return nil
+}
+
+func Down_20200603120000(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,28 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/VividCortex/mysqlerr"
+	"github.com/go-sql-driver/mysql"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20200604120000, Down_20200604120000)
+}
+
+// Host expiry is now performed by the Fleet server rather than by a MySQL
+// event, so the event created by previous versions is removed.
+func Up_20200604120000(tx *sql.Tx) error {
+	if _, err := tx.Exec("DROP EVENT IF EXISTS host_expiry"); err != nil {
+		if driverErr, ok := err.(*mysql.MySQLError); !ok || driverErr.Number != mysqlerr.ER_DBACCESS_DENIED_ERROR {
+			return errors.Wrap(err, "drop host_expiry event")
+		}
+	}
+	return nil
+}
+
+func Down_20200604120000(tx *sql.Tx) error {
+	return nil
+}
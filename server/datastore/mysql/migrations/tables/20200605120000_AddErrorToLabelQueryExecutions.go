@@ -0,0 +1,32 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20200605120000, Down_20200605120000)
+}
+
+func Up_20200605120000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `label_query_executions` " +
+			"ADD COLUMN `error` TEXT NULL DEFAULT NULL",
+	)
+	if err != nil {
+		return errors.Wrap(err, "add error to label_query_executions")
+	}
+	return nil
+}
+
+func Down_20200605120000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `label_query_executions` DROP COLUMN `error`",
+	)
+	if err != nil {
+		return errors.Wrap(err, "drop error from label_query_executions")
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20200606120000, Down_20200606120000)
+}
+
+func Up_20200606120000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"CREATE TABLE `host_status_logs` (" +
+			"`id` INT(10) UNSIGNED NOT NULL AUTO_INCREMENT," +
+			"`host_id` INT(10) UNSIGNED NOT NULL," +
+			"`severity` TINYINT UNSIGNED NOT NULL," +
+			"`filename` VARCHAR(255) NOT NULL DEFAULT ''," +
+			"`line` INT(10) UNSIGNED NOT NULL DEFAULT 0," +
+			"`message` TEXT NOT NULL," +
+			"`query_name` VARCHAR(255) NULL DEFAULT NULL," +
+			"`created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP," +
+			"PRIMARY KEY (`id`)," +
+			"KEY `idx_host_status_logs_host_id` (`host_id`)," +
+			"KEY `idx_host_status_logs_query_name` (`query_name`)," +
+			"FOREIGN KEY `fk_host_status_logs_host_id` (`host_id`) " +
+			"REFERENCES hosts(id) ON DELETE CASCADE" +
+			") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;",
+	)
+	if err != nil {
+		return errors.Wrap(err, "create host_status_logs table")
+	}
+
+	return nil
+}
+
+func Down_20200606120000(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,47 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20200607120000, Down_20200607120000)
+}
+
+func Up_20200607120000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `distributed_query_campaigns` " +
+			"ADD COLUMN `persist_results` TINYINT(1) NOT NULL DEFAULT FALSE",
+	)
+	if err != nil {
+		return errors.Wrap(err, "add persist_results to distributed_query_campaigns")
+	}
+
+	_, err = tx.Exec(
+		"CREATE TABLE `distributed_query_campaign_results` (" +
+			"`id` INT(10) UNSIGNED NOT NULL AUTO_INCREMENT," +
+			"`distributed_query_campaign_id` INT(10) UNSIGNED NOT NULL," +
+			"`host_id` INT(10) UNSIGNED NOT NULL," +
+			"`host_name` VARCHAR(255) NOT NULL DEFAULT ''," +
+			"`rows_json` MEDIUMTEXT NOT NULL," +
+			"`row_count` INT(10) UNSIGNED NOT NULL DEFAULT 0," +
+			"`error` TEXT NULL DEFAULT NULL," +
+			"`created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP," +
+			"PRIMARY KEY (`id`)," +
+			"KEY `idx_dqcr_campaign_id` (`distributed_query_campaign_id`)," +
+			"FOREIGN KEY `fk_dqcr_campaign_id` (`distributed_query_campaign_id`) " +
+			"REFERENCES distributed_query_campaigns(id) ON DELETE CASCADE" +
+			") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;",
+	)
+	if err != nil {
+		return errors.Wrap(err, "create distributed_query_campaign_results table")
+	}
+
+	return nil
+}
+
+func Down_20200607120000(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,29 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20200608120000, Down_20200608120000)
+}
+
+func Up_20200608120000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `distributed_query_campaigns` " +
+			"ADD COLUMN `expected_results` INT(10) UNSIGNED NOT NULL DEFAULT 0, " +
+			"ADD COLUMN `received_results` INT(10) UNSIGNED NOT NULL DEFAULT 0, " +
+			"ADD KEY `idx_dqc_created_at` (`created_at`)",
+	)
+	if err != nil {
+		return errors.Wrap(err, "add result counts to distributed_query_campaigns")
+	}
+
+	return nil
+}
+
+func Down_20200608120000(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,28 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20200609120000, Down_20200609120000)
+}
+
+func Up_20200609120000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `distributed_query_campaigns` " +
+			"ADD COLUMN `deferred` TINYINT(1) NOT NULL DEFAULT FALSE, " +
+			"ADD COLUMN `expires_at` TIMESTAMP NULL DEFAULT NULL",
+	)
+	if err != nil {
+		return errors.Wrap(err, "add deferred to distributed_query_campaigns")
+	}
+
+	return nil
+}
+
+func Down_20200609120000(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,27 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20200610120000, Down_20200610120000)
+}
+
+func Up_20200610120000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `distributed_query_campaigns` " +
+			"ADD COLUMN `viewers` INT(10) UNSIGNED NOT NULL DEFAULT 0",
+	)
+	if err != nil {
+		return errors.Wrap(err, "add viewers to distributed_query_campaigns")
+	}
+
+	return nil
+}
+
+func Down_20200610120000(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,47 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20200611120000, Down_20200611120000)
+}
+
+func Up_20200611120000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"CREATE TABLE `live_query_readers` (" +
+			"`id` INT(10) UNSIGNED NOT NULL AUTO_INCREMENT," +
+			"`campaign_id` INT(10) UNSIGNED NOT NULL," +
+			"`updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP," +
+			"PRIMARY KEY (`id`)," +
+			"KEY `idx_live_query_readers_campaign_id` (`campaign_id`, `updated_at`)" +
+			") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;",
+	)
+	if err != nil {
+		return errors.Wrap(err, "create live_query_readers table")
+	}
+
+	_, err = tx.Exec(
+		"CREATE TABLE `live_query_results` (" +
+			"`id` INT(10) UNSIGNED NOT NULL AUTO_INCREMENT," +
+			"`campaign_id` INT(10) UNSIGNED NOT NULL," +
+			"`result` MEDIUMBLOB NOT NULL," +
+			"`created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP," +
+			"PRIMARY KEY (`id`)," +
+			"KEY `idx_live_query_results_campaign_id` (`campaign_id`, `id`)," +
+			"KEY `idx_live_query_results_created_at` (`created_at`)" +
+			") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;",
+	)
+	if err != nil {
+		return errors.Wrap(err, "create live_query_results table")
+	}
+
+	return nil
+}
+
+func Down_20200611120000(tx *sql.Tx) error {
+	return nil
+}
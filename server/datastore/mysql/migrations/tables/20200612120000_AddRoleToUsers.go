@@ -0,0 +1,35 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20200612120000, Down_20200612120000)
+}
+
+func Up_20200612120000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `users` " +
+			"ADD COLUMN `role` VARCHAR(32) NOT NULL DEFAULT ''",
+	)
+	if err != nil {
+		return errors.Wrap(err, "add role to users")
+	}
+
+	// Existing users keep the permissions they had before roles
+	_, err = tx.Exec(
+		"UPDATE `users` SET `role` = IF(`admin`, 'admin', 'maintainer')",
+	)
+	if err != nil {
+		return errors.Wrap(err, "set role of existing users")
+	}
+
+	return nil
+}
+
+func Down_20200612120000(tx *sql.Tx) error {
+	return nil
+}
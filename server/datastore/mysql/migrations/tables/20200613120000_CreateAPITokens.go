@@ -0,0 +1,47 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20200613120000, Down_20200613120000)
+}
+
+func Up_20200613120000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `users` " +
+			"ADD COLUMN `api_only` TINYINT(1) NOT NULL DEFAULT FALSE",
+	)
+	if err != nil {
+		return errors.Wrap(err, "add api_only to users")
+	}
+
+	_, err = tx.Exec(
+		"CREATE TABLE `api_tokens` (" +
+			"`id` INT(10) UNSIGNED NOT NULL AUTO_INCREMENT," +
+			"`created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP," +
+			"`user_id` INT(10) UNSIGNED NOT NULL," +
+			"`name` VARCHAR(255) NOT NULL," +
+			"`hash` VARBINARY(32) NOT NULL," +
+			"`role` VARCHAR(32) NOT NULL," +
+			"`expires_at` TIMESTAMP NULL DEFAULT NULL," +
+			"`last_used_at` TIMESTAMP NULL DEFAULT NULL," +
+			"PRIMARY KEY (`id`)," +
+			"UNIQUE KEY `idx_api_tokens_hash` (`hash`)," +
+			"UNIQUE KEY `idx_api_tokens_user_id_name` (`user_id`, `name`)," +
+			"FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE" +
+			") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;",
+	)
+	if err != nil {
+		return errors.Wrap(err, "create api_tokens table")
+	}
+
+	return nil
+}
+
+func Down_20200613120000(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,43 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20200614120000, Down_20200614120000)
+}
+
+func Up_20200614120000(tx *sql.Tx) error {
+	// The actor and target names are copied so that the activities are
+	// still readable after the users and objects are deleted.
+	_, err := tx.Exec(
+		"CREATE TABLE `activities` (" +
+			"`id` INT(10) UNSIGNED NOT NULL AUTO_INCREMENT," +
+			"`created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP," +
+			"`actor_id` INT(10) UNSIGNED DEFAULT NULL," +
+			"`actor_name` VARCHAR(255) NOT NULL DEFAULT ''," +
+			"`activity_type` VARCHAR(255) NOT NULL," +
+			"`target_type` VARCHAR(255) NOT NULL DEFAULT ''," +
+			"`target_id` INT(10) UNSIGNED DEFAULT NULL," +
+			"`target_name` VARCHAR(255) NOT NULL DEFAULT ''," +
+			"`details` JSON DEFAULT NULL," +
+			"PRIMARY KEY (`id`)," +
+			"KEY `idx_activities_created_at` (`created_at`)," +
+			"KEY `idx_activities_actor_id` (`actor_id`)," +
+			"KEY `idx_activities_activity_type` (`activity_type`)," +
+			"KEY `idx_activities_target` (`target_type`, `target_id`)" +
+			") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;",
+	)
+	if err != nil {
+		return errors.Wrap(err, "create activities table")
+	}
+
+	return nil
+}
+
+func Down_20200614120000(tx *sql.Tx) error {
+	return nil
+}
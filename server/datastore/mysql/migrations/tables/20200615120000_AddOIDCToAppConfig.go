@@ -0,0 +1,31 @@
+package tables
+
+import (
+	"database/sql"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20200615120000, Down_20200615120000)
+}
+
+func Up_20200615120000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `app_configs` " +
+			"ADD COLUMN `sso_provider` VARCHAR(32) NOT NULL DEFAULT 'saml' AFTER `enable_sso`, " +
+			"ADD COLUMN `oidc_issuer_url` VARCHAR(512) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL DEFAULT '' AFTER `sso_provider`, " +
+			"ADD COLUMN `oidc_client_id` VARCHAR(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL DEFAULT '' AFTER `oidc_issuer_url`, " +
+			"ADD COLUMN `oidc_client_secret` VARCHAR(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL DEFAULT '' AFTER `oidc_client_id`;",
+	)
+	return err
+}
+
+func Down_20200615120000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `app_configs` " +
+			"DROP COLUMN `sso_provider`, " +
+			"DROP COLUMN `oidc_issuer_url`, " +
+			"DROP COLUMN `oidc_client_id`, " +
+			"DROP COLUMN `oidc_client_secret`;",
+	)
+	return err
+}
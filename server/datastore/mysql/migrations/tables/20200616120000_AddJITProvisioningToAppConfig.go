@@ -0,0 +1,31 @@
+package tables
+
+import (
+	"database/sql"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20200616120000, Down_20200616120000)
+}
+
+func Up_20200616120000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `app_configs` " +
+			"ADD COLUMN `enable_jit_provisioning` TINYINT(1) NOT NULL DEFAULT FALSE AFTER `oidc_client_secret`, " +
+			"ADD COLUMN `jit_group_attribute` VARCHAR(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL DEFAULT '' AFTER `enable_jit_provisioning`, " +
+			"ADD COLUMN `jit_admin_groups` TEXT CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL AFTER `jit_group_attribute`, " +
+			"ADD COLUMN `jit_user_groups` TEXT CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL AFTER `jit_admin_groups`;",
+	)
+	return err
+}
+
+func Down_20200616120000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `app_configs` " +
+			"DROP COLUMN `enable_jit_provisioning`, " +
+			"DROP COLUMN `jit_group_attribute`, " +
+			"DROP COLUMN `jit_admin_groups`, " +
+			"DROP COLUMN `jit_user_groups`;",
+	)
+	return err
+}
@@ -0,0 +1,27 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20200617120000, Down_20200617120000)
+}
+
+func Up_20200617120000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `distributed_query_campaigns` " +
+			"ADD COLUMN `results_truncated` TINYINT(1) NOT NULL DEFAULT FALSE",
+	)
+	if err != nil {
+		return errors.Wrap(err, "add results_truncated to distributed_query_campaigns")
+	}
+
+	return nil
+}
+
+func Down_20200617120000(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,25 @@
+package tables
+
+import (
+	"database/sql"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20200619120000, Down_20200619120000)
+}
+
+func Up_20200619120000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `distributed_query_executions` " +
+			"ADD COLUMN `requested_at` TIMESTAMP(6) NULL DEFAULT NULL AFTER `execution_duration`;",
+	)
+	return err
+}
+
+func Down_20200619120000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `distributed_query_executions` " +
+			"DROP COLUMN `requested_at`;",
+	)
+	return err
+}
@@ -0,0 +1,42 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20200620120000, Down_20200620120000)
+}
+
+func Up_20200620120000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"CREATE TABLE `distributed_query_campaign_viewers` (" +
+			"`id` INT(10) UNSIGNED NOT NULL AUTO_INCREMENT," +
+			"`campaign_id` INT(10) UNSIGNED NOT NULL," +
+			"`updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP," +
+			"PRIMARY KEY (`id`)," +
+			"KEY `idx_dqc_viewers_campaign_id` (`campaign_id`, `updated_at`)" +
+			") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;",
+	)
+	if err != nil {
+		return errors.Wrap(err, "create distributed_query_campaign_viewers table")
+	}
+
+	// The viewers are no longer counted on the campaign, as the count was
+	// never decremented for the streams of a Fleet instance that stopped.
+	_, err = tx.Exec(
+		"ALTER TABLE `distributed_query_campaigns` " +
+			"DROP COLUMN `viewers`",
+	)
+	if err != nil {
+		return errors.Wrap(err, "drop viewers from distributed_query_campaigns")
+	}
+
+	return nil
+}
+
+func Down_20200620120000(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,48 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20200621120000, Down_20200621120000)
+}
+
+func Up_20200621120000(tx *sql.Tx) error {
+	// Results are read in the order of a per campaign sequence rather than
+	// of their auto increment ID, as IDs are allocated before the results
+	// are committed and concurrent writers can commit them out of order.
+	_, err := tx.Exec(
+		"CREATE TABLE `live_query_result_sequences` (" +
+			"`campaign_id` INT(10) UNSIGNED NOT NULL," +
+			"`seq` INT(10) UNSIGNED NOT NULL," +
+			"PRIMARY KEY (`campaign_id`)" +
+			") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;",
+	)
+	if err != nil {
+		return errors.Wrap(err, "create live_query_result_sequences table")
+	}
+
+	// Stored results only live as long as their readers, so they are
+	// dropped rather than numbered.
+	if _, err := tx.Exec("DELETE FROM `live_query_results`"); err != nil {
+		return errors.Wrap(err, "delete live query results")
+	}
+	_, err = tx.Exec(
+		"ALTER TABLE `live_query_results` " +
+			"ADD COLUMN `seq` INT(10) UNSIGNED NOT NULL AFTER `campaign_id`, " +
+			"DROP KEY `idx_live_query_results_campaign_id`, " +
+			"ADD UNIQUE KEY `idx_live_query_results_campaign_id_seq` (`campaign_id`, `seq`)",
+	)
+	if err != nil {
+		return errors.Wrap(err, "add seq to live_query_results")
+	}
+
+	return nil
+}
+
+func Down_20200621120000(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,35 @@
+package tables
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	MigrationClient.AddMigration(Up_20200622120000, Down_20200622120000)
+}
+
+func Up_20200622120000(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		"ALTER TABLE `invites` " +
+			"ADD COLUMN `role` VARCHAR(32) NOT NULL DEFAULT ''",
+	)
+	if err != nil {
+		return errors.Wrap(err, "add role to invites")
+	}
+
+	// Pending invites keep the permissions they were created with
+	_, err = tx.Exec(
+		"UPDATE `invites` SET `role` = IF(`admin`, 'admin', 'maintainer')",
+	)
+	if err != nil {
+		return errors.Wrap(err, "set role of existing invites")
+	}
+
+	return nil
+}
+
+func Down_20200622120000(tx *sql.Tx) error {
+	return nil
+}
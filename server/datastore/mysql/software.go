@@ -0,0 +1,155 @@
+package mysql
+
+import (
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// softwareInsertBatchSize is the maximum number of software rows inserted
+// in a single statement. Each row uses 3 placeholders, keeping us well below
+// the MySQL placeholder limit.
+const softwareInsertBatchSize = 500
+
+func (d *Datastore) SaveHostSoftware(hostID uint, software []kolide.Software) error {
+	err := d.withRetryTxx(func(tx *sqlx.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM host_software WHERE host_id = ?`, hostID); err != nil {
+			return errors.Wrap(err, "delete existing host software")
+		}
+
+		for start := 0; start < len(software); start += softwareInsertBatchSize {
+			end := start + softwareInsertBatchSize
+			if end > len(software) {
+				end = len(software)
+			}
+			batch := software[start:end]
+
+			values := strings.TrimSuffix(strings.Repeat("(?,?,?),", len(batch)), ",")
+			args := make([]interface{}, 0, 3*len(batch))
+			for _, s := range batch {
+				args = append(args, s.Name, s.Version, s.Source)
+			}
+
+			sql := `INSERT IGNORE INTO software (name, version, source) VALUES ` + values
+			if _, err := tx.Exec(sql, args...); err != nil {
+				return errors.Wrap(err, "insert software")
+			}
+
+			// The row constructor comparison matches the unique key
+			// so that each new host_software row references the
+			// (possibly pre-existing) software row.
+			conditions := strings.TrimSuffix(strings.Repeat("(name = ? AND version = ? AND source = ?) OR ", len(batch)), " OR ")
+			sql = `
+				INSERT IGNORE INTO host_software (host_id, software_id)
+				SELECT ?, id FROM software WHERE ` + conditions
+			if _, err := tx.Exec(sql, append([]interface{}{hostID}, args...)...); err != nil {
+				return errors.Wrap(err, "insert host software")
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "save software for host %d", hostID)
+	}
+
+	return nil
+}
+
+func (d *Datastore) ListSoftwareForHost(hostID uint) ([]kolide.Software, error) {
+	sql := `
+		SELECT s.id, s.name, s.version, s.source
+		FROM software s
+		JOIN host_software hs ON (hs.software_id = s.id)
+		WHERE hs.host_id = ?
+		ORDER BY s.name, s.version, s.source
+	`
+	software := []kolide.Software{}
+	if err := d.db.Select(&software, sql, hostID); err != nil {
+		return nil, errors.Wrapf(err, "list software for host %d", hostID)
+	}
+
+	return software, nil
+}
+
+func (d *Datastore) ListSoftware(name, version string, opt kolide.ListOptions) ([]*kolide.Software, error) {
+	sql := `
+		SELECT s.id, s.name, s.version, s.source
+		FROM software s
+		WHERE EXISTS (SELECT 1 FROM host_software hs WHERE hs.software_id = s.id)
+	`
+	args := []interface{}{}
+	if name != "" {
+		sql += ` AND s.name = ?`
+		args = append(args, name)
+	}
+	if version != "" {
+		sql += ` AND s.version LIKE ?`
+		args = append(args, escapeLike(version)+"%")
+	}
+	if opt.OrderKey == "" {
+		opt.OrderKey = "name"
+	}
+	sql = appendListOptionsToSQL(sql, opt)
+
+	software := []*kolide.Software{}
+	if err := d.db.Select(&software, sql, args...); err != nil {
+		return nil, errors.Wrap(err, "list software")
+	}
+
+	if err := d.loadHostsForSoftware(software); err != nil {
+		return nil, err
+	}
+
+	return software, nil
+}
+
+// loadHostsForSoftware populates the Hosts field of each of the provided
+// software entries.
+func (d *Datastore) loadHostsForSoftware(software []*kolide.Software) error {
+	if len(software) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(software))
+	byID := make(map[uint]*kolide.Software, len(software))
+	for _, s := range software {
+		ids = append(ids, s.ID)
+		byID[s.ID] = s
+	}
+
+	sql := `
+		SELECT hs.software_id, h.id, h.host_name
+		FROM host_software hs
+		JOIN hosts h ON (hs.host_id = h.id)
+		WHERE hs.software_id IN (?) AND NOT h.deleted
+		ORDER BY h.host_name
+	`
+	sql, args, err := sqlx.In(sql, ids)
+	if err != nil {
+		return errors.Wrap(err, "building query to load software hosts")
+	}
+
+	rows := []struct {
+		SoftwareID uint `db:"software_id"`
+		kolide.SoftwareHost
+	}{}
+	if err := d.db.Select(&rows, d.db.Rebind(sql), args...); err != nil {
+		return errors.Wrap(err, "load software hosts")
+	}
+
+	for _, row := range rows {
+		s := byID[row.SoftwareID]
+		s.Hosts = append(s.Hosts, row.SoftwareHost)
+	}
+
+	return nil
+}
+
+// escapeLike escapes the wildcard characters of a MySQL LIKE pattern so that
+// the provided value is matched literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -9,11 +9,11 @@ import (
 	"github.com/pkg/errors"
 )
 
-func (d *Datastore) CountHostsInTargets(hostIDs []uint, labelIDs []uint, now time.Time) (kolide.TargetMetrics, error) {
+func (d *Datastore) CountHostsInTargets(hostIDs []uint, labelIDs []uint, tagIDs []uint, now time.Time) (kolide.TargetMetrics, error) {
 	// The logic in this function should remain synchronized with
 	// host.Status and GenerateHostStatusStatistics
 
-	if len(hostIDs) == 0 && len(labelIDs) == 0 {
+	if len(hostIDs) == 0 && len(labelIDs) == 0 && len(tagIDs) == 0 {
 		// No need to query if no targets selected
 		return kolide.TargetMetrics{}, nil
 	}
@@ -26,7 +26,7 @@ func (d *Datastore) CountHostsInTargets(hostIDs []uint, labelIDs []uint, now tim
 			COALESCE(SUM(CASE WHEN DATE_ADD(seen_time, INTERVAL LEAST(distributed_interval, config_tls_refresh) + %d SECOND) > ? THEN 1 ELSE 0 END), 0) online,
 			COALESCE(SUM(CASE WHEN DATE_ADD(created_at, INTERVAL 1 DAY) >= ? THEN 1 ELSE 0 END), 0) new
 		FROM hosts h
-		WHERE (id IN (?) OR (id IN (SELECT DISTINCT host_id FROM label_query_executions WHERE label_id IN (?) AND matches = 1)))
+		WHERE (id IN (?) OR (id IN (SELECT DISTINCT host_id FROM label_query_executions WHERE label_id IN (?) AND matches = 1)) OR (id IN (SELECT DISTINCT host_id FROM host_tags WHERE tag_id IN (?))))
 		AND NOT deleted
 `, kolide.OnlineIntervalBuffer, kolide.OnlineIntervalBuffer)
 
@@ -38,12 +38,16 @@ func (d *Datastore) CountHostsInTargets(hostIDs []uint, labelIDs []uint, now tim
 	for _, id := range labelIDs {
 		queryLabelIDs = append(queryLabelIDs, int(id))
 	}
+	queryTagIDs := []int{-1}
+	for _, id := range tagIDs {
+		queryTagIDs = append(queryTagIDs, int(id))
+	}
 	queryHostIDs := []int{-1}
 	for _, id := range hostIDs {
 		queryHostIDs = append(queryHostIDs, int(id))
 	}
 
-	query, args, err := sqlx.In(sql, now, now, now, now, now, queryHostIDs, queryLabelIDs)
+	query, args, err := sqlx.In(sql, now, now, now, now, now, queryHostIDs, queryLabelIDs, queryTagIDs)
 	if err != nil {
 		return kolide.TargetMetrics{}, errors.Wrap(err, "sqlx.In CountHostsInTargets")
 	}
@@ -18,16 +18,19 @@ func (d *Datastore) NewUser(user *kolide.User) (*kolide.User, error) {
       	username,
       	email,
       	admin,
+      	role,
       	enabled,
       	admin_forced_password_reset,
       	gravatar_url,
       	position,
-        sso_enabled
-      ) VALUES (?,?,?,?,?,?,?,?,?,?,?)
+        sso_enabled,
+        api_only
+      ) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?)
       `
 	result, err := d.db.Exec(sqlStatement, user.Password, user.Salt, user.Name,
-		user.Username, user.Email, user.Admin, user.Enabled,
-		user.AdminForcedPasswordReset, user.GravatarURL, user.Position, user.SSOEnabled)
+		user.Username, user.Email, user.Admin, user.Role, user.Enabled,
+		user.AdminForcedPasswordReset, user.GravatarURL, user.Position, user.SSOEnabled,
+		user.APIOnly)
 	if err != nil {
 		return nil, errors.Wrap(err, "create new user")
 	}
@@ -96,6 +99,7 @@ func (d *Datastore) SaveUser(user *kolide.User) error {
       	name = ?,
       	email = ?,
       	admin = ?,
+      	role = ?,
       	enabled = ?,
       	admin_forced_password_reset = ?,
       	gravatar_url = ?,
@@ -104,7 +108,7 @@ func (d *Datastore) SaveUser(user *kolide.User) error {
       WHERE id = ?
       `
 	result, err := d.db.Exec(sqlStatement, user.Username, user.Password,
-		user.Salt, user.Name, user.Email, user.Admin, user.Enabled,
+		user.Salt, user.Name, user.Email, user.Admin, user.Role, user.Enabled,
 		user.AdminForcedPasswordReset, user.GravatarURL, user.Position, user.SSOEnabled, user.ID)
 	if err != nil {
 		return errors.Wrap(err, "save user")
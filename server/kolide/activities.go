@@ -0,0 +1,52 @@
+package kolide
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// ActivityStore contains methods for recording the administrative actions
+// performed in Fleet.
+type ActivityStore interface {
+	// NewActivity records an activity.
+	NewActivity(activity *Activity) (*Activity, error)
+	// ListActivities returns the activities matching the filter, most
+	// recent first unless another order is requested.
+	ListActivities(filter ActivityFilter, opt ListOptions) ([]*Activity, error)
+}
+
+// ActivityService contains methods for reading the activity log.
+type ActivityService interface {
+	// ListActivities returns the activities matching the filter.
+	ListActivities(ctx context.Context, filter ActivityFilter, opt ListOptions) ([]*Activity, error)
+}
+
+// Activity records an administrative action: who performed it, what it was,
+// and which object it was performed on.
+type Activity struct {
+	CreateTimestamp
+	ID uint `json:"id"`
+	// ActorID is nil for the actions performed by Fleet itself, such as
+	// expiring hosts.
+	ActorID   *uint  `json:"actor_id" db:"actor_id"`
+	ActorName string `json:"actor_name" db:"actor_name"`
+	// Type is the action, such as "applied_pack_specs" or "deleted_host".
+	Type       string `json:"type" db:"activity_type"`
+	TargetType string `json:"target_type" db:"target_type"`
+	// TargetID is nil when the action targets several objects, or an object
+	// without ID such as the app config.
+	TargetID   *uint  `json:"target_id" db:"target_id"`
+	TargetName string `json:"target_name" db:"target_name"`
+	// Details is a summary of the action, or a diff of the modified fields.
+	Details *json.RawMessage `json:"details,omitempty" db:"details"`
+}
+
+// ActivityFilter selects activities. Unset fields match all activities.
+type ActivityFilter struct {
+	ActorID    *uint      `json:"actor_id"`
+	Type       *string    `json:"type"`
+	TargetType *string    `json:"target_type"`
+	TargetID   *uint      `json:"target_id"`
+	Since      *time.Time `json:"since"`
+}
@@ -0,0 +1,110 @@
+package kolide
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"time"
+)
+
+// APITokenStore contains methods for managing the API tokens of service
+// accounts in a datastore.
+type APITokenStore interface {
+	// NewAPIToken stores a new API token.
+	NewAPIToken(token *APIToken) (*APIToken, error)
+	// APITokenByHash returns the API token with the given hash.
+	APITokenByHash(hash []byte) (*APIToken, error)
+	// ListAPITokensForUser returns the API tokens of the user.
+	ListAPITokensForUser(userID uint) ([]*APIToken, error)
+	// DeleteAPIToken removes the API token of the user.
+	DeleteAPIToken(userID, id uint) error
+	// MarkAPITokenUsed records the time at which the API token was last
+	// used to authenticate.
+	MarkAPITokenUsed(id uint, usedAt time.Time) error
+}
+
+// APITokenService contains methods for managing service accounts and their
+// API tokens.
+type APITokenService interface {
+	// NewServiceAccount creates a service account user, which cannot log
+	// in interactively and authenticates with API tokens instead.
+	NewServiceAccount(ctx context.Context, p ServiceAccountPayload) (*User, error)
+	// NewAPIToken mints an API token for the service account identified
+	// by userID. The secret token is only returned on creation.
+	NewAPIToken(ctx context.Context, userID uint, p APITokenPayload) (token string, apiToken *APIToken, err error)
+	// ListAPITokens returns the API tokens of the service account.
+	ListAPITokens(ctx context.Context, userID uint) ([]*APIToken, error)
+	// DeleteAPIToken revokes an API token of the service account.
+	DeleteAPIToken(ctx context.Context, userID, id uint) error
+	// AuthenticateAPIToken returns the user and API token authenticated
+	// by the secret token.
+	AuthenticateAPIToken(ctx context.Context, token string) (*User, *APIToken, error)
+}
+
+// APIToken is a long-lived token used by a service account to authenticate
+// to the API. Only a hash of the token is stored.
+type APIToken struct {
+	CreateTimestamp
+	ID     uint   `json:"id"`
+	UserID uint   `json:"user_id" db:"user_id"`
+	Name   string `json:"name"`
+	Hash   []byte `json:"-"`
+	// Role limits the permissions of requests authenticated with the
+	// token to those of the role, when they are lower than those of the
+	// user.
+	Role       Role       `json:"role"`
+	ExpiresAt  *time.Time `json:"expires_at" db:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at" db:"last_used_at"`
+}
+
+// Expired returns true if the token has expired at the given time.
+func (t *APIToken) Expired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
+
+// APITokenPayload is used to create an API token.
+type APITokenPayload struct {
+	Name string `json:"name"`
+	// Role defaults to the role of the user.
+	Role      *Role      `json:"role,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// ServiceAccountPayload is used to create a service account.
+type ServiceAccountPayload struct {
+	Username string `json:"username"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	// Role defaults to observer.
+	Role *Role `json:"role,omitempty"`
+}
+
+// APITokenPrefix starts every API token, which distinguishes API tokens from
+// session JWTs.
+const APITokenPrefix = "fleet_"
+
+// apiTokenSize is the number of random bytes in API tokens.
+const apiTokenSize = 32
+
+// NewAPITokenSecret generates a random API token.
+func NewAPITokenSecret() (string, error) {
+	key := make([]byte, apiTokenSize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return APITokenPrefix + base64.RawURLEncoding.EncodeToString(key), nil
+}
+
+// IsAPIToken returns true if the bearer token is an API token.
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, APITokenPrefix)
+}
+
+// HashAPIToken returns the hash under which the API token is stored. The
+// tokens are random, so they do not need a salted hash.
+func HashAPIToken(token string) []byte {
+	hash := sha256.Sum256([]byte(token))
+	return hash[:]
+}
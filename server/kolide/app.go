@@ -2,8 +2,11 @@ package kolide
 
 import (
 	"context"
+	"database/sql/driver"
 	"encoding/json"
 	"time"
+
+	"github.com/pkg/errors"
 )
 
 // AppConfigStore contains method for saving and retrieving
@@ -93,6 +96,12 @@ func (m SMTPAuthMethod) String() string {
 	}
 }
 
+// Single sign on protocols supported for AppConfig.SSOProvider
+const (
+	SSOProviderSAML = "saml"
+	SSOProviderOIDC = "oidc"
+)
+
 // AppConfig holds configuration about the Fleet application.
 // AppConfig data can be managed by a Fleet API user.
 type AppConfig struct {
@@ -144,6 +153,31 @@ type AppConfig struct {
 	IDPName string `db:"idp_name"`
 	// EnableSSO flag to determine whether or not to enable SSO
 	EnableSSO bool `db:"enable_sso"`
+	// SSOProvider is the protocol used to sign on with the IDP, either
+	// SSOProviderSAML or SSOProviderOIDC
+	SSOProvider string `db:"sso_provider"`
+	// OIDCIssuerURL is the URL of the OpenID Connect provider, used to
+	// discover its endpoints and signing keys
+	OIDCIssuerURL string `db:"oidc_issuer_url"`
+	// OIDCClientID identifies Fleet to the OpenID Connect provider
+	OIDCClientID string `db:"oidc_client_id"`
+	// OIDCClientSecret authenticates Fleet to the OpenID Connect provider
+	OIDCClientSecret string `db:"oidc_client_secret"`
+	// OIDCUserClaim is the ID token claim identifying users, such as sub.
+	// When empty, users are identified by their verified email.
+	OIDCUserClaim string `db:"oidc_user_claim"`
+	// EnableJITProvisioning creates SAML users on their first login, and
+	// updates them from the assertion on every login
+	EnableJITProvisioning bool `db:"enable_jit_provisioning"`
+	// JITGroupAttribute is the SAML attribute listing the groups of the user.
+	// When empty, the admin and enabled state of users is not managed.
+	JITGroupAttribute string `db:"jit_group_attribute"`
+	// JITAdminGroups are the groups whose members are admins. When empty,
+	// the admin state of users is not managed.
+	JITAdminGroups StringList `db:"jit_admin_groups"`
+	// JITUserGroups are the groups whose members are enabled. When empty,
+	// the enabled state of users is not managed.
+	JITUserGroups StringList `db:"jit_user_groups"`
 	// FIMInterval defines the interval when file integrity checks will occur
 	FIMInterval int `db:"fim_interval"`
 	// FIMFileAccess defines the FIMSections which will be monitored for file access events as a JSON formatted array
@@ -162,6 +196,49 @@ type AppConfig struct {
 	AdditionalQueries *json.RawMessage `db:"additional_queries"`
 }
 
+// StringList is a list of strings stored as a JSON array.
+type StringList []string
+
+// Value is called by the DB driver.
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal([]string(l))
+	return string(b), err
+}
+
+// Scan reads the JSON array from the DB.
+func (l *StringList) Scan(src interface{}) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return errors.Errorf("cannot scan %T into StringList", src)
+	}
+	*l = nil
+	if len(b) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b, (*[]string)(l))
+}
+
+// Contains returns whether any of the values is in the list.
+func (l StringList) Contains(values ...string) bool {
+	for _, s := range l {
+		for _, v := range values {
+			if s == v {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // ModifyAppConfigRequest contains application configuration information
 // sent from front end and used to change app config elements.
 type ModifyAppConfigRequest struct {
@@ -189,6 +266,26 @@ type SSOSettingsPayload struct {
 	IDPName *string `json:"idp_name"`
 	// EnableSSO flag to determine whether or not to enable SSO
 	EnableSSO *bool `json:"enable_sso"`
+	// Provider is the protocol used to sign on with the IDP, either "saml"
+	// (the default) or "oidc"
+	Provider *string `json:"provider"`
+	// OIDCIssuerURL is the URL of the OpenID Connect provider
+	OIDCIssuerURL *string `json:"oidc_issuer_url"`
+	// OIDCClientID identifies Fleet to the OpenID Connect provider
+	OIDCClientID *string `json:"oidc_client_id"`
+	// OIDCClientSecret authenticates Fleet to the OpenID Connect provider
+	OIDCClientSecret *string `json:"oidc_client_secret"`
+	// OIDCUserClaim is the ID token claim identifying users, the verified
+	// email when empty
+	OIDCUserClaim *string `json:"oidc_user_claim"`
+	// EnableJITProvisioning creates SAML users on their first login
+	EnableJITProvisioning *bool `json:"enable_jit_provisioning"`
+	// JITGroupAttribute is the SAML attribute listing the groups of the user
+	JITGroupAttribute *string `json:"jit_group_attribute"`
+	// JITAdminGroups are the groups whose members are admins
+	JITAdminGroups *[]string `json:"jit_admin_groups"`
+	// JITUserGroups are the groups whose members are enabled
+	JITUserGroups *[]string `json:"jit_user_groups"`
 }
 
 // SMTPSettingsPayload is part of the AppConfigPayload which defines the wire representation
@@ -0,0 +1,52 @@
+package kolide
+
+import (
+	"context"
+	"time"
+)
+
+// CampaignResultStore defines the datastore methods for the persisted results
+// of distributed query campaigns.
+type CampaignResultStore interface {
+	// SaveCampaignResult persists a host's result for a campaign, unless
+	// the campaign already has maxRows result rows stored. Rows over the
+	// limit are dropped from the result, which is then marked as
+	// truncated. It returns whether the result was saved.
+	SaveCampaignResult(result *CampaignResult, maxRows uint) (saved bool, err error)
+	// MarkCampaignResultsTruncated records that results of the campaign
+	// were not saved because of the rows limit.
+	MarkCampaignResultsTruncated(campaignID uint) error
+	// ListCampaignResults returns the persisted results of the campaign in
+	// the order they were received, unless another order is requested.
+	ListCampaignResults(campaignID uint, opt ListOptions) ([]*CampaignResult, error)
+	// CleanupCampaignResults deletes the persisted results of deleted
+	// campaigns, and of completed campaigns that ended before the given
+	// time. It returns the number of deleted results.
+	CleanupCampaignResults(before time.Time) (deleted uint, err error)
+}
+
+// CampaignResultService defines the service methods for the persisted results
+// of distributed query campaigns.
+type CampaignResultService interface {
+	// ListCampaignResults returns the persisted results of the campaign
+	// with the given ID, and whether results were left out because the
+	// campaign reached the stored rows limit.
+	ListCampaignResults(ctx context.Context, campaignID uint, opt ListOptions) (results []*CampaignResult, truncated bool, err error)
+}
+
+// CampaignResult is a host's result for a distributed query campaign with
+// persisted results.
+type CampaignResult struct {
+	ID                         uint   `json:"id" db:"id"`
+	DistributedQueryCampaignID uint   `json:"campaign_id" db:"distributed_query_campaign_id"`
+	HostID                     uint   `json:"host_id" db:"host_id"`
+	HostName                   string `json:"host_hostname" db:"host_name"`
+	// Rows are stored as a JSON document by the datastore.
+	Rows     []map[string]string `json:"rows" db:"-"`
+	RowCount uint                `json:"row_count" db:"row_count"`
+	// Truncated is set when rows of the result were not stored because
+	// the campaign reached the stored rows limit.
+	Truncated bool      `json:"truncated" db:"truncated"`
+	Error     *string   `json:"error" db:"error"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
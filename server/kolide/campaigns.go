@@ -2,9 +2,8 @@ package kolide
 
 import (
 	"context"
+	"fmt"
 	"time"
-
-	"github.com/kolide/fleet/server/websocket"
 )
 
 // CampaignStore defines the distributed query campaign related datastore
@@ -17,48 +16,119 @@ type CampaignStore interface {
 	// SaveDistributedQueryCampaign updates an existing distributed query
 	// campaign
 	SaveDistributedQueryCampaign(camp *DistributedQueryCampaign) error
+	// NewDistributedQueryCampaignViewer registers a result stream
+	// following the campaign, returning the ID of the viewer. Viewers that
+	// are not touched within CampaignViewerTimeout are no longer counted.
+	NewDistributedQueryCampaignViewer(campaignID uint) (uint, error)
+	// TouchDistributedQueryCampaignViewer records that the viewer is still
+	// following the campaign.
+	TouchDistributedQueryCampaignViewer(id uint) error
+	// DeleteDistributedQueryCampaignViewer removes the viewer, returning
+	// the number of other viewers of the campaign active since
+	// activeSince.
+	DeleteDistributedQueryCampaignViewer(id uint, activeSince time.Time) (remaining uint, err error)
+
 	// DistributedQueryCampaignTargetIDs gets the IDs of the targets for
 	// the query campaign of the provided ID
-	DistributedQueryCampaignTargetIDs(id uint) (hostIDs []uint, labelIDs []uint, err error)
+	DistributedQueryCampaignTargetIDs(id uint) (hostIDs []uint, labelIDs []uint, tagIDs []uint, err error)
 
 	// NewDistributedQueryCampaignTarget adds a new target to an existing
 	// distributed query campaign
 	NewDistributedQueryCampaignTarget(target *DistributedQueryCampaignTarget) (*DistributedQueryCampaignTarget, error)
 
 	// NewDistributedQueryCampaignExecution records a new execution for a
-	// distributed query campaign
+	// distributed query campaign and counts it as a received result of the
+	// campaign. It replaces an execution previously recorded as requested,
+	// in which case the execution duration is set to the time between the
+	// request and ReceivedAt if it is not set.
 	NewDistributedQueryExecution(exec *DistributedQueryExecution) (*DistributedQueryExecution, error)
 
+	// MarkDistributedQueriesRequested records that the queries of the
+	// provided campaign IDs were sent to the host at the provided time.
+	// Only the first request is recorded. The queries of deferred campaigns
+	// are not sent to the host again, while the queries of other campaigns
+	// are sent until the host returns results.
+	MarkDistributedQueriesRequested(hostID uint, campaignIDs []uint, now time.Time) error
+
 	// CleanupDistributedQueryCampaigns will clean and trim metadata for
 	// old distributed query campaigns. Any campaign in the QueryWaiting
 	// state will be moved to QueryComplete after one minute. Any campaign
 	// in the QueryRunning state will be moved to QueryComplete after one
-	// day. Any campaign in the QueryComplete state will have the
-	// associated executions deleted. All times are from creation time. The
-	// now parameter makes this method easier to test. The return values
-	// indicate how many campaigns were expired, how many executions were
-	// deleted, and any error.
+	// day. All times are from creation time, except for running campaigns
+	// with an expiry time (deferred campaigns and campaigns with persisted
+	// results), which are moved to QueryComplete once they expire. Running
+	// campaigns without persisted results are also moved to QueryComplete
+	// once all their viewers timed out, as happens when the Fleet instance
+	// streaming the results stops. Viewers that timed out are deleted. Any
+	// campaign in the QueryComplete state will have the associated
+	// executions deleted, except for deferred campaigns, which keep their
+	// per host state until they are past their expiry. The now parameter
+	// makes this method easier to test.
+	// The return values indicate how many campaigns were expired, how many
+	// executions were deleted, and any error.
 	CleanupDistributedQueryCampaigns(now time.Time) (expired uint, deleted uint, err error)
+
+	// ListDistributedQueryCampaigns returns summaries of the distributed
+	// query campaigns matching the filter, most recent first unless
+	// another order is requested.
+	ListDistributedQueryCampaigns(opt ListOptions, filter DistributedQueryCampaignFilter) ([]*DistributedQueryCampaignSummary, error)
+
+	// ListDistributedQueryCampaignHosts returns the hosts currently
+	// targeted by the campaign, along with any host that has an execution
+	// recorded for it, with the state of the execution on each host.
+	ListDistributedQueryCampaignHosts(campaignID uint, opt ListOptions) ([]*DistributedQueryCampaignHost, error)
 }
 
 // CampaignService defines the distributed query campaign related service
 // methods
 type CampaignService interface {
 	// NewDistributedQueryCampaign creates a new distributed query campaign
-	// with the provided query and host/label targets (specified by name).
-	NewDistributedQueryCampaignByNames(ctx context.Context, queryString string, hosts []string, labels []string) (*DistributedQueryCampaign, error)
+	// with the provided query and host/label/tag targets (specified by
+	// name, with tags in "key=value" form).
+	NewDistributedQueryCampaignByNames(ctx context.Context, queryString string, hosts []string, labels []string, tags []string, opts CampaignOptions) (*DistributedQueryCampaign, error)
 
 	// NewDistributedQueryCampaign creates a new distributed query campaign
-	// with the provided query and host/label targets
-	NewDistributedQueryCampaign(ctx context.Context, queryString string, hosts []uint, labels []uint) (*DistributedQueryCampaign, error)
+	// with the provided query and host/label/tag targets
+	NewDistributedQueryCampaign(ctx context.Context, queryString string, hosts []uint, labels []uint, tags []uint, opts CampaignOptions) (*DistributedQueryCampaign, error)
 
 	// StreamCampaignResults streams updates with query results and
-	// expected host totals over the provided websocket. Note that the type
-	// signature is somewhat inconsistent due to this being a streaming API
-	// and not the typical go-kit RPC style.
-	StreamCampaignResults(ctx context.Context, conn *websocket.Conn, campaignID uint)
+	// expected host totals over the provided stream (a websocket or a
+	// plain HTTP response). The stream ends when the campaign completes or
+	// ctx is canceled. Streaming a waiting campaign starts it, and is
+	// limited to maintainers and the user that created the campaign.
+	// Observers can stream the results of campaigns that are already
+	// running. Note that the type signature is somewhat inconsistent due
+	// to this being a streaming API and not the typical go-kit RPC style.
+	StreamCampaignResults(ctx context.Context, conn CampaignStreamWriter, campaignID uint)
+
+	// ListDistributedQueryCampaigns returns summaries of the distributed
+	// query campaigns matching the filter, including who ran which query
+	// against which targets.
+	ListDistributedQueryCampaigns(ctx context.Context, opt ListOptions, filter DistributedQueryCampaignFilter) ([]*DistributedQueryCampaignSummary, error)
+
+	// ListDistributedQueryCampaignHosts returns the execution state of the
+	// campaign on each of its targeted hosts.
+	ListDistributedQueryCampaignHosts(ctx context.Context, campaignID uint, opt ListOptions) ([]*DistributedQueryCampaignHost, error)
+
+	// CancelDistributedQueryCampaign completes the campaign so that its
+	// query is no longer sent to hosts. Only the user that created the
+	// campaign and admins may cancel it.
+	CancelDistributedQueryCampaign(ctx context.Context, campaignID uint) (*DistributedQueryCampaign, error)
+}
+
+// CampaignStreamWriter writes the typed JSON messages ("totals", "status",
+// "result" and "error") that make up a stream of campaign results.
+type CampaignStreamWriter interface {
+	// WriteJSONMessage writes data as a message of the provided type.
+	WriteJSONMessage(typ string, data interface{}) error
+	// WriteJSONError writes data as an error message.
+	WriteJSONError(data interface{}) error
 }
 
+// CampaignViewerTimeout is how long a viewer of a campaign that stopped
+// touching its registration is still counted as following the campaign.
+const CampaignViewerTimeout = time.Minute
+
 // DistributedQueryStatus is the lifecycle status of a distributed query
 // campaign.
 type DistributedQueryStatus int
@@ -69,16 +139,102 @@ const (
 	QueryComplete
 )
 
+var distributedQueryStatusNames = map[DistributedQueryStatus]string{
+	QueryWaiting:  "waiting",
+	QueryRunning:  "running",
+	QueryComplete: "complete",
+}
+
+func (s DistributedQueryStatus) String() string {
+	if name, ok := distributedQueryStatusNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown (%d)", int(s))
+}
+
+// ParseDistributedQueryStatus returns the status with the given name, as
+// returned by DistributedQueryStatus.String.
+func ParseDistributedQueryStatus(name string) (DistributedQueryStatus, error) {
+	for status, statusName := range distributedQueryStatusNames {
+		if statusName == name {
+			return status, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid campaign status %q", name)
+}
+
+// CampaignOptions are the optional settings of a new distributed query
+// campaign.
+type CampaignOptions struct {
+	// PersistResults stores the results of the campaign so that they can
+	// be retrieved after the result stream is closed. The campaign then
+	// keeps running until it expires, after the configured persisted
+	// campaign TTL.
+	PersistResults bool `json:"persist_results"`
+	// Deferred campaigns run without waiting for a result stream to be
+	// opened, and keep waiting for targeted hosts to check in until they
+	// expire. Their results are always persisted.
+	Deferred bool `json:"deferred"`
+	// TTLDays is the number of days a deferred campaign waits for hosts
+	// to check in. Zero uses the configured default.
+	TTLDays uint `json:"ttl_days"`
+}
+
 // DistributedQueryCampaign is the basic metadata associated with a distributed
 // query.
 type DistributedQueryCampaign struct {
 	UpdateCreateTimestamps
 	DeleteFields
-	Metrics TargetMetrics
-	ID      uint                   `json:"id"`
-	QueryID uint                   `json:"query_id" db:"query_id"`
-	Status  DistributedQueryStatus `json:"status"`
-	UserID  uint                   `json:"user_id" db:"user_id"`
+	Metrics        TargetMetrics
+	ID             uint                   `json:"id"`
+	QueryID        uint                   `json:"query_id" db:"query_id"`
+	Status         DistributedQueryStatus `json:"status"`
+	UserID         uint                   `json:"user_id" db:"user_id"`
+	PersistResults bool                   `json:"persist_results" db:"persist_results"`
+	// ExpectedResults is the number of targeted hosts when the campaign
+	// was created.
+	ExpectedResults uint `json:"expected_results" db:"expected_results"`
+	// ReceivedResults is the number of hosts that have returned a result
+	// for the campaign.
+	ReceivedResults uint `json:"received_results" db:"received_results"`
+	Deferred        bool `json:"deferred" db:"deferred"`
+	// ExpiresAt is when a deferred campaign, or a campaign with persisted
+	// results, stops waiting for hosts.
+	ExpiresAt *time.Time `json:"expires_at" db:"expires_at"`
+	// ResultsTruncated is set when results of the campaign were not
+	// persisted because the campaign reached the stored rows limit.
+	ResultsTruncated bool `json:"results_truncated" db:"results_truncated"`
+	// Warnings are the problems found when validating the campaign query,
+	// they are only set when the campaign is created.
+	Warnings []string `json:"warnings,omitempty" db:"-"`
+}
+
+// DistributedQueryCampaignFilter restricts the campaigns returned by
+// ListDistributedQueryCampaigns. Nil fields match all campaigns.
+type DistributedQueryCampaignFilter struct {
+	UserID *uint                   `json:"user_id"`
+	Status *DistributedQueryStatus `json:"status"`
+}
+
+// DistributedQueryCampaignSummary describes a distributed query campaign for
+// auditing: the query that was run, by whom, and against which targets.
+type DistributedQueryCampaignSummary struct {
+	ID              uint                   `json:"id" db:"id"`
+	CreatedAt       time.Time              `json:"created_at" db:"created_at"`
+	QueryID         uint                   `json:"query_id" db:"query_id"`
+	Query           string                 `json:"query" db:"query"`
+	UserID          uint                   `json:"user_id" db:"user_id"`
+	Username        string                 `json:"username" db:"username"`
+	Status          DistributedQueryStatus `json:"status" db:"status"`
+	PersistResults  bool                   `json:"persist_results" db:"persist_results"`
+	Deferred        bool                   `json:"deferred" db:"deferred"`
+	ExpiresAt       *time.Time             `json:"expires_at" db:"expires_at"`
+	ExpectedResults uint                   `json:"expected_results" db:"expected_results"`
+	ReceivedResults uint                   `json:"received_results" db:"received_results"`
+	// Target names are loaded separately from the campaign.
+	Hosts  []string `json:"hosts" db:"-"`
+	Labels []string `json:"labels" db:"-"`
+	Tags   []string `json:"tags" db:"-"`
 }
 
 // DistributedQueryCampaignTarget stores a target (host or label) for a
@@ -102,6 +258,20 @@ const (
 	ExecutionFailed
 )
 
+func (s DistributedQueryExecutionStatus) String() string {
+	switch s {
+	case ExecutionWaiting:
+		return "waiting"
+	case ExecutionRequested:
+		return "requested"
+	case ExecutionSucceeded:
+		return "succeeded"
+	case ExecutionFailed:
+		return "failed"
+	}
+	return fmt.Sprintf("unknown (%d)", int(s))
+}
+
 // DistributedQueryResult is the result returned from the execution of a
 // distributed query on a single host.
 type DistributedQueryResult struct {
@@ -115,6 +285,18 @@ type DistributedQueryResult struct {
 	Error *string `json:"error"`
 }
 
+// DistributedQueryCampaignHost is the state of a distributed query campaign on
+// one of its targeted hosts. Hosts that have not returned results are
+// ExecutionWaiting, or ExecutionRequested once the query of the campaign was
+// sent to them.
+type DistributedQueryCampaignHost struct {
+	HostID            uint                            `json:"host_id" db:"host_id"`
+	HostName          string                          `json:"host_hostname" db:"host_name"`
+	Status            DistributedQueryExecutionStatus `json:"status" db:"status"`
+	Error             string                          `json:"error" db:"error"`
+	ExecutionDuration time.Duration                   `json:"execution_duration" db:"execution_duration"`
+}
+
 // DistributedQueryExecution is the metadata associated with a distributed
 // query execution on a single host.
 type DistributedQueryExecution struct {
@@ -124,4 +306,8 @@ type DistributedQueryExecution struct {
 	Status                     DistributedQueryExecutionStatus
 	Error                      string
 	ExecutionDuration          time.Duration `db:"execution_duration"`
+	// RequestedAt is when the query was sent to the host, if recorded
+	RequestedAt *time.Time `db:"requested_at"`
+	// ReceivedAt is when the result of the host was received
+	ReceivedAt time.Time `db:"-"`
 }
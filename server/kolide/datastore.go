@@ -11,6 +11,7 @@ type Datastore interface {
 	TargetStore
 	PasswordResetStore
 	SessionStore
+	APITokenStore
 	AppConfigStore
 	InviteStore
 	ScheduledQueryStore
@@ -18,6 +19,13 @@ type Datastore interface {
 	FileIntegrityMonitoringStore
 	YARAStore
 	OsqueryOptionsStore
+	SoftwareStore
+	HostHistoryStore
+	HostTagStore
+	HostStatusLogStore
+	CampaignResultStore
+	LiveQueryResultStore
+	ActivityStore
 	Name() string
 	Drop() error
 	// MigrateTables creates and migrates the table schemas
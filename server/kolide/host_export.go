@@ -0,0 +1,24 @@
+package kolide
+
+// HostExportFormat is the output format of a host export.
+type HostExportFormat string
+
+const (
+	// HostExportCSV exports hosts as CSV, with a header row.
+	HostExportCSV HostExportFormat = "csv"
+	// HostExportNDJSON exports hosts as newline delimited JSON objects.
+	HostExportNDJSON HostExportFormat = "ndjson"
+)
+
+// Valid returns whether the format is one of the known export formats.
+func (f HostExportFormat) Valid() bool {
+	return f == HostExportCSV || f == HostExportNDJSON
+}
+
+// HostExport is a host along with the related data included in host exports.
+type HostExport struct {
+	Host   *Host
+	Status string
+	// Labels are the names of the labels the host is a member of.
+	Labels []string
+}
@@ -0,0 +1,317 @@
+package kolide
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// HostFilter is a parsed host filter expression, such as
+//
+//	platform:darwin status:offline osquery_version<4.2 label:"Prod" memory>8GB
+//
+// A filter is a whitespace separated list of terms, all of which must match
+// for a host to match the filter. Each term is of the form <field><op><value>
+// where op is one of ":", "=", "!=", "<", "<=", ">" or ">=" (":" and "=" are
+// equivalent). Prefixing a term with "-" negates it. Values containing
+// whitespace may be quoted with double quotes. String values may contain "*"
+// wildcards.
+type HostFilter struct {
+	Terms []HostFilterTerm
+}
+
+// HostFilterTerm is a single comparison within a HostFilter.
+type HostFilterTerm struct {
+	Field  string
+	Op     HostFilterOp
+	Negate bool
+	// Value is the value as provided in the filter expression.
+	Value string
+	// Number is the parsed value for numeric fields. Values with units
+	// (eg. "8GB") are converted to the base unit.
+	Number int64
+	// Version is the parsed value for version fields, padded with zeros to
+	// major, minor and patch components.
+	Version [3]int64
+}
+
+// HasWildcard returns whether the value of the term contains a "*" wildcard.
+func (t HostFilterTerm) HasWildcard() bool {
+	return strings.Contains(t.Value, "*")
+}
+
+// HostFilterOp is the comparison operator of a HostFilterTerm.
+type HostFilterOp string
+
+const (
+	HostFilterEqual          HostFilterOp = "="
+	HostFilterLess           HostFilterOp = "<"
+	HostFilterLessOrEqual    HostFilterOp = "<="
+	HostFilterGreater        HostFilterOp = ">"
+	HostFilterGreaterOrEqual HostFilterOp = ">="
+)
+
+// HostFilterFieldKind describes how the values of a filter field are parsed
+// and compared.
+type HostFilterFieldKind int
+
+const (
+	// HostFilterString fields support equality with wildcards.
+	HostFilterString HostFilterFieldKind = iota
+	// HostFilterVersion fields support ordered comparisons of dotted
+	// numeric versions.
+	HostFilterVersion
+	// HostFilterNumber fields support ordered comparisons of integers,
+	// optionally with a byte size unit.
+	HostFilterNumber
+	// HostFilterStatus is the online status of the host.
+	HostFilterStatus
+	// HostFilterLabel matches hosts that are members of the named label.
+	HostFilterLabel
+)
+
+// HostFilterFields maps the field names accepted in host filters to their
+// kind.
+var HostFilterFields = map[string]HostFilterFieldKind{
+	"hostname":           HostFilterString,
+	"computer_name":      HostFilterString,
+	"uuid":               HostFilterString,
+	"platform":           HostFilterString,
+	"os_version":         HostFilterString,
+	"hardware_serial":    HostFilterString,
+	"hardware_vendor":    HostFilterString,
+	"hardware_model":     HostFilterString,
+	"osquery_version":    HostFilterVersion,
+	"memory":             HostFilterNumber,
+	"cpu_physical_cores": HostFilterNumber,
+	"cpu_logical_cores":  HostFilterNumber,
+	"status":             HostFilterStatus,
+	"label":              HostFilterLabel,
+}
+
+var hostFilterVersionRegexp = regexp.MustCompile(`^\d+(\.\d+){0,2}$`)
+
+var hostFilterUnits = map[string]int64{
+	"":   1,
+	"b":  1,
+	"kb": 1 << 10,
+	"mb": 1 << 20,
+	"gb": 1 << 30,
+	"tb": 1 << 40,
+}
+
+// HostFilterError is returned when a host filter expression cannot be
+// parsed.
+type HostFilterError struct {
+	// Pos is the byte offset in the expression at which the error was
+	// detected.
+	Pos    int
+	Reason string
+}
+
+func (e HostFilterError) Error() string {
+	return fmt.Sprintf("invalid host filter at position %d: %s", e.Pos, e.Reason)
+}
+
+// ParseHostFilter parses a host filter expression. An empty expression
+// results in a nil filter that matches all hosts.
+func ParseHostFilter(expr string) (*HostFilter, error) {
+	p := hostFilterParser{input: expr}
+	var terms []HostFilterTerm
+	for {
+		p.skipSpace()
+		if p.done() {
+			break
+		}
+		term, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	return &HostFilter{Terms: terms}, nil
+}
+
+type hostFilterParser struct {
+	input string
+	pos   int
+}
+
+func (p *hostFilterParser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *hostFilterParser) skipSpace() {
+	for !p.done() && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *hostFilterParser) errorf(pos int, format string, args ...interface{}) error {
+	return HostFilterError{Pos: pos, Reason: fmt.Sprintf(format, args...)}
+}
+
+func (p *hostFilterParser) parseTerm() (HostFilterTerm, error) {
+	var term HostFilterTerm
+	start := p.pos
+
+	if p.input[p.pos] == '-' {
+		term.Negate = true
+		p.pos++
+	}
+
+	// Field name
+	fieldStart := p.pos
+	for !p.done() {
+		c := p.input[p.pos]
+		if !(c >= 'a' && c <= 'z') && !(c >= 'A' && c <= 'Z') && c != '_' {
+			break
+		}
+		p.pos++
+	}
+	term.Field = strings.ToLower(p.input[fieldStart:p.pos])
+	if term.Field == "" {
+		return term, p.errorf(start, "expected field name")
+	}
+	kind, ok := HostFilterFields[term.Field]
+	if !ok {
+		return term, p.errorf(fieldStart, "unknown field %q (valid fields: %s)",
+			term.Field, strings.Join(hostFilterFieldNames(), ", "))
+	}
+
+	// Operator
+	opStart := p.pos
+	switch {
+	case strings.HasPrefix(p.input[p.pos:], "!="):
+		term.Op = HostFilterEqual
+		term.Negate = !term.Negate
+		p.pos += 2
+	case strings.HasPrefix(p.input[p.pos:], "<="):
+		term.Op = HostFilterLessOrEqual
+		p.pos += 2
+	case strings.HasPrefix(p.input[p.pos:], ">="):
+		term.Op = HostFilterGreaterOrEqual
+		p.pos += 2
+	case strings.HasPrefix(p.input[p.pos:], "<"):
+		term.Op = HostFilterLess
+		p.pos++
+	case strings.HasPrefix(p.input[p.pos:], ">"):
+		term.Op = HostFilterGreater
+		p.pos++
+	case strings.HasPrefix(p.input[p.pos:], ":"), strings.HasPrefix(p.input[p.pos:], "="):
+		term.Op = HostFilterEqual
+		p.pos++
+	default:
+		return term, p.errorf(opStart, "expected operator after field %q", term.Field)
+	}
+	if term.Op != HostFilterEqual && kind != HostFilterVersion && kind != HostFilterNumber {
+		return term, p.errorf(opStart, "operator %q is not supported for field %q", term.Op, term.Field)
+	}
+
+	// Value
+	valueStart := p.pos
+	value, err := p.parseValue()
+	if err != nil {
+		return term, err
+	}
+	term.Value = value
+
+	switch kind {
+	case HostFilterVersion:
+		if !hostFilterVersionRegexp.MatchString(value) {
+			return term, p.errorf(valueStart, "invalid version %q for field %q", value, term.Field)
+		}
+		for i, part := range strings.Split(value, ".") {
+			term.Version[i], err = strconv.ParseInt(part, 10, 64)
+			if err != nil {
+				return term, p.errorf(valueStart, "invalid version %q for field %q", value, term.Field)
+			}
+		}
+	case HostFilterNumber:
+		term.Number, err = parseHostFilterNumber(value)
+		if err != nil {
+			return term, p.errorf(valueStart, "invalid number %q for field %q", value, term.Field)
+		}
+	case HostFilterStatus:
+		term.Value = strings.ToLower(value)
+		switch term.Value {
+		case StatusOnline, StatusOffline, StatusMIA, "new":
+		default:
+			return term, p.errorf(valueStart, "invalid status %q (valid statuses: %s, %s, %s, new)",
+				value, StatusOnline, StatusOffline, StatusMIA)
+		}
+	}
+
+	return term, nil
+}
+
+func (p *hostFilterParser) parseValue() (string, error) {
+	start := p.pos
+	if p.done() || unicode.IsSpace(rune(p.input[p.pos])) {
+		return "", p.errorf(start, "expected value")
+	}
+
+	if p.input[p.pos] != '"' {
+		for !p.done() && !unicode.IsSpace(rune(p.input[p.pos])) {
+			p.pos++
+		}
+		return p.input[start:p.pos], nil
+	}
+
+	// Quoted value, allowing \" and \\ escapes
+	p.pos++
+	var b strings.Builder
+	for !p.done() {
+		c := p.input[p.pos]
+		switch {
+		case c == '\\' && p.pos+1 < len(p.input):
+			b.WriteByte(p.input[p.pos+1])
+			p.pos += 2
+		case c == '"':
+			p.pos++
+			if !p.done() && !unicode.IsSpace(rune(p.input[p.pos])) {
+				return "", p.errorf(p.pos, "expected whitespace after quoted value")
+			}
+			return b.String(), nil
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+	return "", p.errorf(start, "unterminated quoted value")
+}
+
+func parseHostFilterNumber(value string) (int64, error) {
+	lower := strings.ToLower(value)
+	i := 0
+	for i < len(lower) && (lower[i] >= '0' && lower[i] <= '9' || lower[i] == '.') {
+		i++
+	}
+	multiplier, ok := hostFilterUnits[lower[i:]]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit %q", value[i:])
+	}
+	if multiplier == 1 {
+		return strconv.ParseInt(lower[:i], 10, 64)
+	}
+	n, err := strconv.ParseFloat(lower[:i], 64)
+	if err != nil {
+		return 0, err
+	}
+	return int64(n * float64(multiplier)), nil
+}
+
+func hostFilterFieldNames() []string {
+	names := make([]string, 0, len(HostFilterFields))
+	for name := range HostFilterFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,83 @@
+package kolide
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHostFilter(t *testing.T) {
+	var testCases = []struct {
+		expr     string
+		expected *HostFilter
+	}{
+		{"", nil},
+		{"   ", nil},
+		{
+			"platform:darwin",
+			&HostFilter{Terms: []HostFilterTerm{
+				{Field: "platform", Op: HostFilterEqual, Value: "darwin"},
+			}},
+		},
+		{
+			`-hostname=web* label:"Production Hosts"  status:Offline`,
+			&HostFilter{Terms: []HostFilterTerm{
+				{Field: "hostname", Op: HostFilterEqual, Negate: true, Value: "web*"},
+				{Field: "label", Op: HostFilterEqual, Value: "Production Hosts"},
+				{Field: "status", Op: HostFilterEqual, Value: "offline"},
+			}},
+		},
+		{
+			`os_version!="10.15.4" -uuid!=abc`,
+			&HostFilter{Terms: []HostFilterTerm{
+				{Field: "os_version", Op: HostFilterEqual, Negate: true, Value: "10.15.4"},
+				{Field: "uuid", Op: HostFilterEqual, Value: "abc"},
+			}},
+		},
+		{
+			"osquery_version<4.2 memory>=1.5GB cpu_logical_cores>4",
+			&HostFilter{Terms: []HostFilterTerm{
+				{Field: "osquery_version", Op: HostFilterLess, Value: "4.2", Version: [3]int64{4, 2, 0}},
+				{Field: "memory", Op: HostFilterGreaterOrEqual, Value: "1.5GB", Number: 3 << 29},
+				{Field: "cpu_logical_cores", Op: HostFilterGreater, Value: "4", Number: 4},
+			}},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.expr, func(t *testing.T) {
+			filter, err := ParseHostFilter(tt.expr)
+			require.Nil(t, err)
+			assert.Equal(t, tt.expected, filter)
+		})
+	}
+}
+
+func TestParseHostFilterErrors(t *testing.T) {
+	var testCases = []struct {
+		expr string
+		pos  int
+	}{
+		{"platform", 8},
+		{"platform:", 9},
+		{"foo:bar", 0},
+		{"platform:darwin -", 16},
+		{"hostname<foo", 8},
+		{"osquery_version>4.x", 16},
+		{"memory>8XB", 7},
+		{"status:away", 7},
+		{`label:"unterminated`, 6},
+		{`label:"foo"bar`, 11},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := ParseHostFilter(tt.expr)
+			require.NotNil(t, err)
+			filterErr, ok := err.(HostFilterError)
+			require.True(t, ok)
+			assert.Equal(t, tt.pos, filterErr.Pos)
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package kolide
+
+import (
+	"context"
+	"time"
+)
+
+// The host attributes for which changes are recorded in the host history.
+const (
+	HostHistoryHostName       = "hostname"
+	HostHistoryOSVersion      = "os_version"
+	HostHistoryOsqueryVersion = "osquery_version"
+	HostHistoryHardwareSerial = "hardware_serial"
+	HostHistoryPrimaryIP      = "primary_ip"
+)
+
+// HostHistoryStore defines the datastore methods for the host attribute
+// change history. History entries are recorded by SaveHost whenever one of
+// the tracked attributes differs from the value the host was loaded with
+// (see Host.HistoryChanges).
+type HostHistoryStore interface {
+	// ListHostHistory returns the recorded attribute changes for the host
+	// with the given ID, most recent first unless another order is
+	// requested.
+	ListHostHistory(hostID uint, opt ListOptions) ([]*HostHistory, error)
+}
+
+// HostHistoryService defines the service methods for the host attribute
+// change history.
+type HostHistoryService interface {
+	// ListHostHistory returns the recorded attribute changes for the host
+	// with the given ID.
+	ListHostHistory(ctx context.Context, hostID uint, opt ListOptions) ([]*HostHistory, error)
+}
+
+// HostHistory is a single recorded change of a host attribute.
+type HostHistory struct {
+	ID     uint `json:"id" db:"id"`
+	HostID uint `json:"host_id" db:"host_id"`
+	// Field is the attribute that changed (one of the HostHistory*
+	// constants).
+	Field     string    `json:"field" db:"field"`
+	OldValue  string    `json:"old_value" db:"old_value"`
+	NewValue  string    `json:"new_value" db:"new_value"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// HostHistoryChange is a change of a tracked host attribute, not yet
+// recorded in the host history.
+type HostHistoryChange struct {
+	Field    string
+	OldValue string
+	NewValue string
+}
+
+// hostHistoryValues holds the values of the host attributes tracked in the
+// host history.
+type hostHistoryValues struct {
+	hostName       string
+	osVersion      string
+	osqueryVersion string
+	hardwareSerial string
+	primaryIP      string
+}
+
+func (h *Host) historyValues() *hostHistoryValues {
+	return &hostHistoryValues{
+		hostName:       h.HostName,
+		osVersion:      h.OSVersion,
+		osqueryVersion: h.OsqueryVersion,
+		hardwareSerial: h.HardwareSerial,
+		primaryIP:      h.primaryIP(),
+	}
+}
+
+// MarkStored records the current values of the tracked attributes as the
+// values stored for the host. Datastores call it when the host is loaded or
+// saved, so that HistoryChanges can be computed without reading the stored
+// host again.
+func (h *Host) MarkStored() {
+	h.storedHistoryValues = h.historyValues()
+}
+
+// HistoryChanges returns the changes of the tracked attributes since the
+// host was loaded or last saved. No changes are returned for a host that was
+// not loaded from the datastore. Changes from an empty value (the attribute
+// being populated for the first time) are not returned. The primary IP is
+// only compared when the host has network interfaces, as they are not
+// loaded for all hosts.
+func (h *Host) HistoryChanges() []HostHistoryChange {
+	stored := h.storedHistoryValues
+	if stored == nil {
+		return nil
+	}
+
+	current := h.historyValues()
+	candidates := []HostHistoryChange{
+		{HostHistoryHostName, stored.hostName, current.hostName},
+		{HostHistoryOSVersion, stored.osVersion, current.osVersion},
+		{HostHistoryOsqueryVersion, stored.osqueryVersion, current.osqueryVersion},
+		{HostHistoryHardwareSerial, stored.hardwareSerial, current.hardwareSerial},
+	}
+	if len(h.NetworkInterfaces) > 0 {
+		candidates = append(candidates, HostHistoryChange{HostHistoryPrimaryIP, stored.primaryIP, current.primaryIP})
+	}
+
+	var changes []HostHistoryChange
+	for _, c := range candidates {
+		if c.OldValue == "" || c.OldValue == c.NewValue {
+			continue
+		}
+		changes = append(changes, c)
+	}
+	return changes
+}
+
+// primaryIP returns the IP address of the primary network interface of the
+// host, or an empty string if there is none.
+func (h *Host) primaryIP() string {
+	if h.PrimaryNetworkInterfaceID == nil {
+		return ""
+	}
+	for _, nic := range h.NetworkInterfaces {
+		if nic.ID == *h.PrimaryNetworkInterfaceID {
+			return nic.IPAddress
+		}
+	}
+	return ""
+}
@@ -0,0 +1,70 @@
+package kolide
+
+import (
+	"context"
+	"time"
+)
+
+// HostStatusLogStore defines the datastore methods for the osquery status
+// logs retained for each host.
+type HostStatusLogStore interface {
+	// SaveHostStatusLogs saves the status logs for the host with the given
+	// ID, keeping only the most recent keep logs for the host. Nothing is
+	// saved if keep is 0.
+	SaveHostStatusLogs(hostID uint, logs []*HostStatusLog, keep uint) error
+	// ListHostStatusLogs returns the retained status logs for the host with
+	// the given ID, most recent first unless another order is requested.
+	ListHostStatusLogs(hostID uint, opt ListOptions) ([]*HostStatusLog, error)
+	// ListScheduledQueryErrors aggregates the retained status logs that are
+	// attributed to a scheduled query by query name.
+	ListScheduledQueryErrors() ([]*ScheduledQueryErrors, error)
+}
+
+// HostStatusLogService defines the service methods for the osquery status
+// logs retained for each host.
+type HostStatusLogService interface {
+	// ListHostStatusLogs returns the retained status logs for the host with
+	// the given ID.
+	ListHostStatusLogs(ctx context.Context, hostID uint, opt ListOptions) ([]*HostStatusLog, error)
+	// ListScheduledQueryErrors returns the scheduled query errors reported
+	// across all hosts, aggregated by query name.
+	ListScheduledQueryErrors(ctx context.Context) ([]*ScheduledQueryErrors, error)
+}
+
+// StatusLogSeverity is the severity of an osquery status log, following the
+// glog levels used by osquery.
+type StatusLogSeverity int
+
+const (
+	StatusLogInfo StatusLogSeverity = iota
+	StatusLogWarning
+	StatusLogError
+	StatusLogFatal
+)
+
+// HostStatusLog is an osquery status log retained for a host. Only logs with
+// a severity of warning or above are retained.
+type HostStatusLog struct {
+	ID       uint              `json:"id" db:"id"`
+	HostID   uint              `json:"host_id" db:"host_id"`
+	Severity StatusLogSeverity `json:"severity" db:"severity"`
+	Filename string            `json:"filename" db:"filename"`
+	Line     uint              `json:"line" db:"line"`
+	Message  string            `json:"message" db:"message"`
+	// QueryName is the name of the scheduled query the log refers to, if
+	// any.
+	QueryName *string `json:"query_name" db:"query_name"`
+	// CreatedAt is the time the log was emitted by osquery (or received by
+	// Fleet if osquery did not provide it).
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// ScheduledQueryErrors summarizes the status logs reported for a scheduled
+// query.
+type ScheduledQueryErrors struct {
+	QueryName   string    `json:"query_name" db:"query_name"`
+	Count       uint      `json:"count" db:"count"`
+	HostCount   uint      `json:"host_count" db:"host_count"`
+	LastMessage string    `json:"last_message" db:"last_message"`
+	LastSeen    time.Time `json:"last_seen" db:"last_seen"`
+}
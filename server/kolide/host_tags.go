@@ -0,0 +1,80 @@
+package kolide
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// HostTagStore defines the datastore methods for operator-defined host tags.
+type HostTagStore interface {
+	// SetHostTags replaces the tags of the host with the provided set,
+	// mapping tag key to value.
+	SetHostTags(hostID uint, tags map[string]string) error
+	// ListTagsForHost returns the tags of the host, mapping tag key to
+	// value.
+	ListTagsForHost(hostID uint) (map[string]string, error)
+	// TagIDsByName returns the IDs of the tags with the provided names (in
+	// "key=value" form). Names that do not match a known tag are ignored.
+	TagIDsByName(tags []string) ([]uint, error)
+}
+
+// HostTagService defines the service methods for operator-defined host tags.
+type HostTagService interface {
+	// ModifyHostTags updates the tags of the host. Tags with a nil value
+	// are removed, others are added or updated. Tags that are not
+	// included are left unchanged. The resulting tags are returned.
+	ModifyHostTags(ctx context.Context, hostID uint, tags map[string]*string) (map[string]string, error)
+	// ApplyHostTagSpecs replaces the tags of the hosts named in the specs.
+	ApplyHostTagSpecs(ctx context.Context, specs []*HostTagSpec) error
+}
+
+// HostTag is a key/value tag that can be set on hosts by operators (eg.
+// owner=team-infra or env=prod). Tags can be used as targets for
+// distributed queries, in the same way as labels.
+type HostTag struct {
+	ID    uint   `json:"id" db:"id"`
+	Key   string `json:"key" db:"key"`
+	Value string `json:"value" db:"value"`
+}
+
+// Name returns the "key=value" name of the tag, as used when targeting
+// tags by name.
+func (t HostTag) Name() string {
+	return t.Key + "=" + t.Value
+}
+
+// HostTagSpec is the declarative (fleetctl apply) representation of the tags
+// of a host.
+type HostTagSpec struct {
+	Hostname string            `json:"hostname"`
+	Tags     map[string]string `json:"tags"`
+}
+
+// maxHostTagLength is the maximum length of tag keys and values.
+const maxHostTagLength = 255
+
+// ValidateHostTag returns an error if the tag key or value is invalid.
+func ValidateHostTag(key, value string) error {
+	switch {
+	case key == "":
+		return errors.New("tag key must not be empty")
+	case strings.Contains(key, "="):
+		return errors.Errorf("tag key %q must not contain '='", key)
+	case len(key) > maxHostTagLength:
+		return errors.Errorf("tag key %q is longer than %d characters", key, maxHostTagLength)
+	case len(value) > maxHostTagLength:
+		return errors.Errorf("value of tag %q is longer than %d characters", key, maxHostTagLength)
+	}
+	return nil
+}
+
+// ParseHostTagName parses a tag name of the form "key=value".
+func ParseHostTagName(name string) (HostTag, error) {
+	parts := strings.SplitN(name, "=", 2)
+	if len(parts) != 2 {
+		return HostTag{}, errors.Errorf("tag %q must be of the form key=value", name)
+	}
+	return HostTag{Key: parts[0], Value: parts[1]}, nil
+}
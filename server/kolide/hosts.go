@@ -6,6 +6,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"net"
+	"strings"
 	"time"
 )
 
@@ -37,10 +38,20 @@ type HostStore interface {
 	// NewHost is deprecated and will be removed. Hosts should always be
 	// enrolled via EnrollHost.
 	NewHost(host *Host) (*Host, error)
+	// SaveHost saves the host, recording the changes of the attributes
+	// tracked in the host history since the host was loaded.
 	SaveHost(host *Host) error
 	DeleteHost(hid uint) error
+	// DeleteHosts deletes the hosts with the provided IDs, returning the
+	// number of hosts deleted.
+	DeleteHosts(ids []uint, opts ...OptionalArg) (uint, error)
+	// ExistingHostIDs returns the IDs, among the provided ones, of the hosts
+	// that exist.
+	ExistingHostIDs(ids []uint) ([]uint, error)
 	Host(id uint) (*Host, error)
-	ListHosts(opt ListOptions) ([]*Host, error)
+	// ListHosts returns the hosts matching the filter in the provided
+	// options.
+	ListHosts(opt HostListOptions) ([]*Host, error)
 	EnrollHost(osqueryHostId, nodeKey, secretName string) (*Host, error)
 	// AuthenticateHost authenticates and returns host metadata by node key.
 	// This method should not return the host "additional" information as this
@@ -57,6 +68,12 @@ type HostStore interface {
 	// osquery_version fields are empty. This means that multiple different
 	// osquery queries failed to populate details.
 	CleanupIncomingHosts(now time.Time) error
+	// ListExpiredHosts returns the hosts that have not been seen within the
+	// expiry window before now.
+	ListExpiredHosts(now time.Time, window time.Duration) ([]*Host, error)
+	// ExpireHosts deletes the hosts that have not been seen within the
+	// expiry window before now, returning the IDs of the deleted hosts.
+	ExpireHosts(now time.Time, window time.Duration) ([]uint, error)
 	// GenerateHostStatusStatistics retrieves the count of online, offline,
 	// MIA and new hosts.
 	GenerateHostStatusStatistics(now time.Time) (online, offline, mia, new uint, err error)
@@ -66,13 +83,168 @@ type HostStore interface {
 	DistributedQueriesForHost(host *Host) (map[uint]string, error)
 	// HostIDsByName Retrieve the IDs associated with the given hostnames
 	HostIDsByName(hostnames []string) ([]uint, error)
+	// FindHostByIdentity returns the most recently seen host matching the
+	// identity according to the strategy. Identities with a placeholder
+	// serial are matched by UUID. A NotFoundError is returned if no host
+	// matches, or if the identity is missing values required by the
+	// strategy.
+	FindHostByIdentity(strategy HostIdentityStrategy, identity HostIdentity) (*Host, error)
+	// ReenrollHost attaches a re-enrolling osquery agent to the existing host
+	// with the given ID, replacing its osquery host identifier and node key.
+	// If another host is already enrolled with the osquery host identifier,
+	// it is merged into the existing host.
+	ReenrollHost(hostID uint, osqueryHostID, nodeKey, secretName string) (*Host, error)
+	// MergeHosts folds the duplicate host into the surviving host. The label
+	// memberships, pack memberships and history of the duplicate are moved
+	// to the survivor (keeping the survivor's where both exist) and the
+	// duplicate is deleted.
+	MergeHosts(survivorID, duplicateID uint) error
 }
 
 type HostService interface {
-	ListHosts(ctx context.Context, opt ListOptions) (hosts []*Host, err error)
+	ListHosts(ctx context.Context, opt HostListOptions) (hosts []*Host, err error)
 	GetHost(ctx context.Context, id uint) (host *Host, err error)
 	GetHostSummary(ctx context.Context) (summary *HostSummary, err error)
 	DeleteHost(ctx context.Context, id uint) (err error)
+	// DeleteHosts deletes the hosts selected by the options in a single
+	// transaction. If more than BulkHostDeleteConfirmationThreshold hosts
+	// are selected, nothing is deleted unless the options carry the token
+	// of a preview that selected the same hosts.
+	DeleteHosts(ctx context.Context, opt BulkHostDeleteOptions) (result *BulkHostDeleteResult, err error)
+	// ExportHosts pages through the hosts matching the options (ignoring
+	// any paging in the options), calling fn for each host in ID order.
+	// Iteration stops at the first error returned by fn.
+	ExportHosts(ctx context.Context, opt HostListOptions, fn func(*HostExport) error) (err error)
+	// ListHostLabels explains the label membership of the host, returning
+	// the last execution of every label applicable to the host.
+	ListHostLabels(ctx context.Context, hostID uint) ([]*HostLabelExecution, error)
+	// MergeHosts folds the duplicate host into the surviving host and
+	// returns the updated survivor.
+	MergeHosts(ctx context.Context, survivorID, duplicateID uint) (host *Host, err error)
+	// ListExpiredHosts returns the hosts that would be removed by host
+	// expiry with the given window (in days). If the window is zero, the
+	// configured host expiry window is used.
+	ListExpiredHosts(ctx context.Context, window int) (hosts []*Host, err error)
+	// ExpireHosts removes the hosts that have not been seen within the
+	// configured host expiry window, returning the IDs of the removed
+	// hosts. It does nothing if host expiry is disabled.
+	ExpireHosts(ctx context.Context) (hostIDs []uint, err error)
+}
+
+// BulkHostDeleteConfirmationThreshold is the number of hosts above which a
+// bulk delete must be explicitly confirmed.
+const BulkHostDeleteConfirmationThreshold = 50
+
+// BulkHostDeleteOptions selects the hosts to delete in a bulk delete. Exactly
+// one of HostIDs, LabelID or Filter must be set.
+type BulkHostDeleteOptions struct {
+	HostIDs []uint
+	LabelID *uint
+	Filter  *HostFilter
+	// ConfirmToken must be set to the Token of the preview result to
+	// delete more than BulkHostDeleteConfirmationThreshold hosts. The
+	// delete is refused if the hosts selected differ from the previewed
+	// ones.
+	ConfirmToken string
+}
+
+// BulkHostDeleteResult is the result of a bulk host delete.
+type BulkHostDeleteResult struct {
+	// Matched is the number of existing hosts selected by the options.
+	Matched uint `json:"matched"`
+	// Deleted is the number of hosts deleted.
+	Deleted uint `json:"deleted"`
+	// ConfirmationRequired is set when nothing was deleted because the
+	// delete was not confirmed.
+	ConfirmationRequired bool `json:"confirmation_required"`
+	// HostIDs are the IDs of the selected hosts, set along with
+	// ConfirmationRequired. Deleting exactly these hosts with the Token
+	// confirms the preview.
+	HostIDs []uint `json:"host_ids,omitempty"`
+	// Token binds the confirmation to the previewed hosts.
+	Token string `json:"token,omitempty"`
+}
+
+// HostIdentityStrategy determines how an enrolling osquery agent is matched
+// to an existing host, allowing a reimaged machine (or one whose osquery
+// database was wiped) to re-attach to its host rather than appearing as a
+// duplicate.
+type HostIdentityStrategy string
+
+const (
+	// HostIdentityOsqueryHostID matches hosts only by the osquery host
+	// identifier. This is the default.
+	HostIdentityOsqueryHostID HostIdentityStrategy = "osquery_host_id"
+	// HostIdentityUUID matches hosts by hardware UUID.
+	HostIdentityUUID HostIdentityStrategy = "uuid"
+	// HostIdentitySerial matches hosts by hardware serial number. Hosts
+	// reporting an empty or placeholder serial are matched by UUID.
+	HostIdentitySerial HostIdentityStrategy = "serial"
+	// HostIdentityHostnameSerial matches hosts by both hostname and
+	// hardware serial number. Hosts reporting an empty or placeholder
+	// serial are matched by UUID.
+	HostIdentityHostnameSerial HostIdentityStrategy = "hostname_serial"
+)
+
+// placeholderSerials are the (lowercased) hardware serials reported by
+// machines whose vendor did not set one, such as virtual machines and
+// whitebox hardware.
+var placeholderSerials = map[string]bool{
+	"":                       true,
+	"0":                      true,
+	"none":                   true,
+	"unknown":                true,
+	"n/a":                    true,
+	"na":                     true,
+	"not specified":          true,
+	"not applicable":         true,
+	"not available":          true,
+	"default string":         true,
+	"to be filled by o.e.m.": true,
+	"to be filled by oem":    true,
+	"system serial number":   true,
+	"chassis serial number":  true,
+	"serial number":          true,
+	"0123456789":             true,
+	"123456789":              true,
+	"1234567890":             true,
+}
+
+// IsPlaceholderSerial returns whether the hardware serial is empty or a
+// placeholder, which cannot identify a machine.
+func IsPlaceholderSerial(serial string) bool {
+	serial = strings.ToLower(strings.TrimSpace(serial))
+	if placeholderSerials[serial] {
+		return true
+	}
+	// Serials made only of zeros
+	return strings.Trim(serial, "0") == ""
+}
+
+// Valid returns whether the strategy is one of the known strategies. The
+// empty strategy is valid and equivalent to HostIdentityOsqueryHostID.
+func (s HostIdentityStrategy) Valid() bool {
+	switch s {
+	case "", HostIdentityOsqueryHostID, HostIdentityUUID, HostIdentitySerial, HostIdentityHostnameSerial:
+		return true
+	}
+	return false
+}
+
+// HostIdentity holds the identifiers reported by an osquery agent at
+// enrollment (from the system_info table).
+type HostIdentity struct {
+	UUID           string
+	HardwareSerial string
+	HostName       string
+}
+
+// HostListOptions are the options for listing hosts.
+type HostListOptions struct {
+	ListOptions
+	// Filter restricts the listed hosts to those matching the filter
+	// expression. A nil filter matches all hosts.
+	Filter *HostFilter
 }
 
 type Host struct {
@@ -116,6 +288,10 @@ type Host struct {
 	LoggerTLSPeriod           uint                `json:"logger_tls_period" db:"logger_tls_period"`
 	Additional                *json.RawMessage    `json:"additional,omitempty" db:"additional"`
 	EnrollSecretName          string              `json:"enroll_secret_name" db:"enroll_secret_name"`
+
+	// storedHistoryValues are the values of the attributes tracked in the
+	// host history when the host was loaded or last saved.
+	storedHistoryValues *hostHistoryValues
 }
 
 // HostSummary is a structure which represents a data summary about the total
@@ -217,3 +393,49 @@ func (h *Host) IsNew(now time.Time) bool {
 	}
 	return false
 }
+
+// linuxPlatforms is the set of host platforms reported by osquery for Linux
+// hosts. osquery reports the ID of the distribution from os-release, or linux
+// when the distribution is unknown.
+var linuxPlatforms = map[string]bool{
+	"linux":               true,
+	"almalinux":           true,
+	"alpine":              true,
+	"amzn":                true,
+	"arch":                true,
+	"centos":              true,
+	"clear-linux-os":      true,
+	"coreos":              true,
+	"debian":              true,
+	"elementary":          true,
+	"endeavouros":         true,
+	"fedora":              true,
+	"flatcar":             true,
+	"gentoo":              true,
+	"kali":                true,
+	"linuxmint":           true,
+	"mageia":              true,
+	"manjaro":             true,
+	"nixos":               true,
+	"ol":                  true,
+	"opensuse":            true,
+	"opensuse-leap":       true,
+	"opensuse-tumbleweed": true,
+	"photon":              true,
+	"pop":                 true,
+	"raspbian":            true,
+	"rhel":                true,
+	"rocky":               true,
+	"scientific":          true,
+	"sles":                true,
+	"slackware":           true,
+	"ubuntu":              true,
+	"void":                true,
+	"zorin":               true,
+}
+
+// IsLinuxPlatform returns whether the platform, as reported by osquery for a
+// host or used as a label platform, is a Linux distribution.
+func IsLinuxPlatform(platform string) bool {
+	return linuxPlatforms[strings.ToLower(platform)]
+}
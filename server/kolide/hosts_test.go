@@ -179,3 +179,60 @@ func TestHostIsNew(t *testing.T) {
 	host.CreatedAt = mockClock.Now().AddDate(0, 0, -2)
 	assert.False(t, host.IsNew(mockClock.Now()))
 }
+
+func TestIsPlaceholderSerial(t *testing.T) {
+	for _, serial := range []string{
+		"", " ", "0", "000000000", "Not Specified", "To Be Filled By O.E.M.",
+		"System Serial Number", " default string ", "None",
+	} {
+		assert.True(t, IsPlaceholderSerial(serial), serial)
+	}
+	for _, serial := range []string{"C02XK1ABJG5J", "VMware-56 4d 12 34", "0A1"} {
+		assert.False(t, IsPlaceholderSerial(serial), serial)
+	}
+}
+
+func TestHostHistoryChanges(t *testing.T) {
+	primaryID := uint(1)
+	host := Host{
+		HostName:                  "foo.local",
+		OSVersion:                 "Mac OS X 10.14.6",
+		PrimaryNetworkInterfaceID: &primaryID,
+		NetworkInterfaces: []*NetworkInterface{
+			{ID: 1, IPAddress: "192.168.1.2"},
+		},
+	}
+
+	// Hosts that were not loaded have no changes
+	host.OSVersion = "Mac OS X 10.15.4"
+	assert.Empty(t, host.HistoryChanges())
+
+	host.MarkStored()
+	assert.Empty(t, host.HistoryChanges())
+
+	host.OSVersion = "Mac OS X 10.15.5"
+	host.OsqueryVersion = "4.2.0"
+	host.NetworkInterfaces = []*NetworkInterface{{ID: 1, IPAddress: "10.0.0.5"}}
+	assert.Equal(t, []HostHistoryChange{
+		{HostHistoryOSVersion, "Mac OS X 10.15.4", "Mac OS X 10.15.5"},
+		{HostHistoryPrimaryIP, "192.168.1.2", "10.0.0.5"},
+	}, host.HistoryChanges())
+
+	// Without network interfaces, the primary IP is not compared
+	host.MarkStored()
+	host.NetworkInterfaces = nil
+	host.PrimaryNetworkInterfaceID = nil
+	host.HostName = "bar.local"
+	assert.Equal(t, []HostHistoryChange{
+		{HostHistoryHostName, "foo.local", "bar.local"},
+	}, host.HistoryChanges())
+}
+
+func TestIsLinuxPlatform(t *testing.T) {
+	for _, platform := range []string{"linux", "ubuntu", "rhel", "manjaro", "linuxmint", "Rocky", "ol"} {
+		assert.True(t, IsLinuxPlatform(platform), platform)
+	}
+	for _, platform := range []string{"", "darwin", "windows", "freebsd"} {
+		assert.False(t, IsLinuxPlatform(platform), platform)
+	}
+}
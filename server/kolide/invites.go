@@ -51,6 +51,7 @@ type InvitePayload struct {
 	InvitedBy  *uint `json:"invited_by"`
 	Email      *string
 	Admin      *bool
+	Role       *Role `json:"role"`
 	Name       *string
 	Position   *string
 	SSOEnabled *bool `json:"sso_enabled"`
@@ -64,6 +65,7 @@ type Invite struct {
 	InvitedBy  uint   `json:"invited_by" db:"invited_by"`
 	Email      string `json:"email"`
 	Admin      bool   `json:"admin"`
+	Role       Role   `json:"role"`
 	Name       string `json:"name"`
 	Position   string `json:"position,omitempty"`
 	Token      string `json:"-"`
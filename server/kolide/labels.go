@@ -34,9 +34,24 @@ type LabelStore interface {
 	// execution.
 	RecordLabelQueryExecutions(host *Host, results map[uint]bool, t time.Time) error
 
+	// RecordLabelQueryErrors saves the failed executions of label queries.
+	// The errs map is a map of label id -> error reported by osquery. A
+	// failed execution does not match the label.
+	RecordLabelQueryErrors(host *Host, errs map[uint]string, t time.Time) error
+
+	// ListLabelExecutionsForHost returns the last execution of every label
+	// that applies to the host's platform, including labels that do not
+	// match or have not yet been evaluated. Labels for other platforms are
+	// included only if they were executed on the host.
+	ListLabelExecutionsForHost(host *Host) ([]*HostLabelExecution, error)
+
 	// LabelsForHost returns the labels that the given host is in.
 	ListLabelsForHost(hid uint) ([]Label, error)
 
+	// ListLabelsForHosts returns the labels that each of the given hosts
+	// is in, keyed by host ID. Hosts in no label have no entry.
+	ListLabelsForHosts(hids []uint) (map[uint][]Label, error)
+
 	// ListHostsInLabel returns a slice of hosts in the label with the
 	// given ID.
 	ListHostsInLabel(lid uint) ([]Host, error)
@@ -54,8 +69,9 @@ type LabelStore interface {
 
 type LabelService interface {
 	// ApplyLabelSpecs applies a list of LabelSpecs to the datastore,
-	// creating and updating labels as necessary.
-	ApplyLabelSpecs(ctx context.Context, specs []*LabelSpec) error
+	// creating and updating labels as necessary. The warnings found when
+	// validating the label queries are returned.
+	ApplyLabelSpecs(ctx context.Context, specs []*LabelSpec) (warnings []string, err error)
 	// GetLabelSpecs returns all of the stored LabelSpecs.
 	GetLabelSpecs(ctx context.Context) ([]*LabelSpec, error)
 	// GetLabelSpec gets the spec for the label with the given name.
@@ -116,6 +132,20 @@ type LabelQueryExecution struct {
 	Matches   bool
 	LabelID   uint
 	HostID    uint
+	Error     *string
+}
+
+// HostLabelExecution explains the membership of a host in a label.
+type HostLabelExecution struct {
+	LabelID   uint      `json:"label_id" db:"label_id"`
+	LabelName string    `json:"label_name" db:"label_name"`
+	LabelType LabelType `json:"label_type" db:"label_type"`
+	Matches   bool      `json:"matches" db:"matches"`
+	// UpdatedAt is the time of the last execution of the label query on
+	// the host, nil if the query has not been executed yet.
+	UpdatedAt *time.Time `json:"updated_at" db:"updated_at"`
+	// Error is the error reported by osquery if the last execution failed.
+	Error *string `json:"error" db:"error"`
 }
 
 type LabelSpec struct {
@@ -0,0 +1,43 @@
+package kolide
+
+import "time"
+
+// LiveQueryResultStore defines the datastore methods used to pass live query
+// results between Fleet instances through the datastore, for deployments
+// without Redis. Readers poll for the results stored after the last result
+// they received, in the order of a per campaign sequence.
+type LiveQueryResultStore interface {
+	// NewLiveQueryReader registers a reader of the live query results of
+	// the campaign, returning the ID of the reader.
+	NewLiveQueryReader(campaignID uint) (uint, error)
+	// TouchLiveQueryReader records that the reader is still active.
+	TouchLiveQueryReader(id uint) error
+	// DeleteLiveQueryReader removes the reader. The results of the
+	// campaign are deleted along with its last reader.
+	DeleteLiveQueryReader(id uint) error
+	// NewLiveQueryResult stores a result for the campaign, unless the
+	// campaign has no reader active since activeSince. It returns whether
+	// the result was stored. Results are numbered with the next sequence
+	// number of the campaign, and become visible to readers in the order
+	// of their sequence number.
+	NewLiveQueryResult(campaignID uint, result []byte, activeSince time.Time) (saved bool, err error)
+	// ListLiveQueryResults returns up to limit results of the campaign
+	// with a sequence number greater than afterSeq, in sequence order.
+	ListLiveQueryResults(campaignID uint, afterSeq uint, limit uint) ([]*LiveQueryResult, error)
+	// CleanupLiveQueryResults removes the readers that have not been
+	// active since activeSince, then the results of campaigns without
+	// readers and the results stored before createdBefore.
+	CleanupLiveQueryResults(activeSince, createdBefore time.Time) error
+}
+
+// LiveQueryResult is a live query result stored in the datastore until it is
+// read.
+type LiveQueryResult struct {
+	ID         uint `db:"id"`
+	CampaignID uint `db:"campaign_id"`
+	// Seq is the sequence number of the result within the campaign.
+	Seq uint `db:"seq"`
+	// Result is the JSON encoded DistributedQueryResult.
+	Result    []byte    `db:"result"`
+	CreatedAt time.Time `db:"created_at"`
+}
@@ -0,0 +1,547 @@
+package kolide
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// OsquerySchemaAsset is the path of the osquery schema embedded in the Fleet
+// binary.
+const OsquerySchemaAsset = "assets/osquery/schema.json"
+
+// OsquerySchema describes the tables osquery provides, along with the columns
+// of each table and the platforms on which it is available.
+type OsquerySchema struct {
+	// Version is the osquery version the schema was generated from.
+	Version string                        `json:"version"`
+	Tables  map[string]OsquerySchemaTable `json:"tables"`
+}
+
+// OsquerySchemaTable describes a single osquery table.
+type OsquerySchemaTable struct {
+	Platforms []string `json:"platforms"`
+	Columns   []string `json:"columns"`
+}
+
+// QueryValidation holds the problems found when validating the SQL of
+// queries. Errors are problems that would cause the query to fail on every
+// targeted host. Warnings may be false positives, for example tables provided
+// by osquery extensions.
+type QueryValidation struct {
+	Errors   []string `json:"errors,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Append adds the problems of other to v, prefixing each of them.
+func (v *QueryValidation) Append(prefix string, other QueryValidation) {
+	for _, e := range other.Errors {
+		v.Errors = append(v.Errors, prefix+e)
+	}
+	for _, w := range other.Warnings {
+		v.Warnings = append(v.Warnings, prefix+w)
+	}
+}
+
+// ParseOsquerySchema parses the JSON representation of an osquery schema.
+func ParseOsquerySchema(data []byte) (*OsquerySchema, error) {
+	var schema OsquerySchema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, errors.Wrap(err, "parsing osquery schema")
+	}
+	if len(schema.Tables) == 0 {
+		return nil, errors.New("osquery schema contains no tables")
+	}
+	return &schema, nil
+}
+
+var osqueryPlatformsByName = map[string][]string{
+	"darwin":  {"darwin"},
+	"freebsd": {"freebsd"},
+	"linux":   {"linux"},
+	"windows": {"windows"},
+	"posix":   {"darwin", "freebsd", "linux"},
+}
+
+// osqueryPlatforms returns the osquery platforms targeted by a pack, query or
+// label platform string, which may be a comma separated list. Nil is returned
+// when all platforms are targeted.
+func osqueryPlatforms(platform string) []string {
+	seen := map[string]bool{}
+	var platforms []string
+	for _, p := range strings.Split(platform, ",") {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if p == "any" || p == "all" {
+			return nil
+		}
+		names := osqueryPlatformsByName[p]
+		if names == nil && IsLinuxPlatform(p) {
+			// Label platforms use the platform reported by hosts,
+			// which is the distribution on linux.
+			names = []string{"linux"}
+		}
+		for _, name := range names {
+			if !seen[name] {
+				seen[name] = true
+				platforms = append(platforms, name)
+			}
+		}
+	}
+	sort.Strings(platforms)
+	return platforms
+}
+
+// ValidateQuery checks the SQL of a query that will run on the provided
+// platform (empty for all platforms). The syntax is always checked, while
+// tables and columns are only checked when the schema is not nil.
+func (s *OsquerySchema) ValidateQuery(query, platform string) QueryValidation {
+	var v QueryValidation
+
+	tokens, err := tokenizeSQL(query)
+	if err != nil {
+		v.Errors = append(v.Errors, err.Error())
+		return v
+	}
+	if len(tokens) == 0 {
+		v.Errors = append(v.Errors, "query is empty")
+		return v
+	}
+	depth := 0
+	for _, tok := range tokens {
+		if tok.is("(") {
+			depth++
+		} else if tok.is(")") {
+			depth--
+			if depth < 0 {
+				v.Errors = append(v.Errors, "unexpected closing parenthesis")
+				return v
+			}
+		}
+	}
+	if depth != 0 {
+		v.Errors = append(v.Errors, "unbalanced parentheses")
+		return v
+	}
+
+	if s == nil {
+		return v
+	}
+
+	refs := findSQLReferences(tokens)
+	targets := osqueryPlatforms(platform)
+
+	// Map table names and aliases to the schema tables they refer to
+	sources := map[string]*OsquerySchemaTable{}
+	sourceNames := map[string]string{}
+	var tableNames []string
+	allKnown := !refs.opaque
+	for _, ref := range refs.tables {
+		if refs.ctes[ref.name] || strings.HasPrefix(ref.name, "sqlite_") {
+			allKnown = false
+			continue
+		}
+		table, ok := s.Tables[ref.name]
+		if !ok {
+			allKnown = false
+			v.Warnings = append(v.Warnings, fmt.Sprintf(
+				"table %q is not in the osquery %s schema, it may be provided by an extension", ref.name, s.Version))
+			continue
+		}
+		sources[ref.name] = &table
+		sourceNames[ref.name] = ref.name
+		if ref.alias != "" {
+			sources[ref.alias] = &table
+			sourceNames[ref.alias] = ref.name
+		}
+		tableNames = append(tableNames, ref.name)
+
+		if missing := missingPlatforms(table.Platforms, targets); len(missing) > 0 {
+			problem := fmt.Sprintf("table %q is not available on %s", ref.name, strings.Join(missing, ", "))
+			if len(missing) == len(targets) {
+				v.Errors = append(v.Errors, problem)
+			} else {
+				v.Warnings = append(v.Warnings, problem)
+			}
+		}
+	}
+
+	for _, col := range refs.qualifiedColumns {
+		table, ok := sources[col.table]
+		if !ok || hasColumn(table, col.name) {
+			continue
+		}
+		v.Warnings = append(v.Warnings, fmt.Sprintf("column %q is not in table %q", col.name, sourceNames[col.table]))
+	}
+
+	// Unqualified columns can only be attributed when every table queried is
+	// known
+	if !allKnown || len(tableNames) == 0 {
+		return v
+	}
+	for _, name := range refs.columns {
+		if sources[name] != nil {
+			continue
+		}
+		found := false
+		for _, t := range tableNames {
+			if hasColumn(sources[t], name) {
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		if len(tableNames) == 1 {
+			v.Warnings = append(v.Warnings, fmt.Sprintf("column %q is not in table %q", name, tableNames[0]))
+		} else {
+			v.Warnings = append(v.Warnings, fmt.Sprintf(
+				"column %q is not in any of the tables %s", name, strings.Join(tableNames, ", ")))
+		}
+	}
+
+	return v
+}
+
+func missingPlatforms(available, targets []string) []string {
+	var missing []string
+	for _, target := range targets {
+		found := false
+		for _, p := range available {
+			if p == target {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, target)
+		}
+	}
+	return missing
+}
+
+func hasColumn(table *OsquerySchemaTable, name string) bool {
+	switch name {
+	case "*", "rowid", "oid", "_rowid_":
+		return true
+	}
+	for _, c := range table.Columns {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+type sqlTokenKind int
+
+const (
+	sqlIdentifier sqlTokenKind = iota
+	// sqlQuotedIdentifier is a double quoted identifier, which SQLite
+	// interprets as a string when no such column exists.
+	sqlQuotedIdentifier
+	sqlString
+	sqlNumber
+	sqlPunctuation
+)
+
+type sqlToken struct {
+	kind sqlTokenKind
+	text string
+}
+
+// is reports whether the token is the provided punctuation or (case
+// insensitive) keyword.
+func (t sqlToken) is(text string) bool {
+	switch t.kind {
+	case sqlPunctuation:
+		return t.text == text
+	case sqlIdentifier:
+		return strings.EqualFold(t.text, text)
+	}
+	return false
+}
+
+func (t sqlToken) isKeyword() bool {
+	return t.kind == sqlIdentifier && sqlKeywords[strings.ToUpper(t.text)]
+}
+
+// isName reports whether the token can name a table, column or alias.
+func (t sqlToken) isName() bool {
+	return (t.kind == sqlIdentifier && !t.isKeyword()) || t.kind == sqlQuotedIdentifier
+}
+
+// name returns the normalized name of an identifier token.
+func (t sqlToken) name() string {
+	return strings.ToLower(t.text)
+}
+
+func isIdentifierChar(c byte, first bool) bool {
+	return c == '_' || c >= 0x80 ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') ||
+		(!first && (c == '$' || (c >= '0' && c <= '9')))
+}
+
+func tokenizeSQL(query string) ([]sqlToken, error) {
+	var tokens []sqlToken
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			i++
+
+		case strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			i += end
+
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				i = len(query)
+			} else {
+				i += end + 4
+			}
+
+		case c == '\'' || c == '"' || c == '`' || c == '[':
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			var text strings.Builder
+			j := i + 1
+			for ; j < len(query); j++ {
+				if query[j] != closing {
+					text.WriteByte(query[j])
+					continue
+				}
+				// Quotes are escaped by doubling them
+				if closing != ']' && j+1 < len(query) && query[j+1] == closing {
+					text.WriteByte(closing)
+					j++
+					continue
+				}
+				break
+			}
+			if j >= len(query) {
+				if c == '\'' {
+					return nil, errors.New("unterminated string literal")
+				}
+				return nil, errors.New("unterminated quoted identifier")
+			}
+			kind := sqlIdentifier
+			switch c {
+			case '\'':
+				kind = sqlString
+			case '"':
+				kind = sqlQuotedIdentifier
+			}
+			tokens = append(tokens, sqlToken{kind: kind, text: text.String()})
+			i = j + 1
+
+		case isIdentifierChar(c, true):
+			j := i + 1
+			for j < len(query) && isIdentifierChar(query[j], false) {
+				j++
+			}
+			tokens = append(tokens, sqlToken{kind: sqlIdentifier, text: query[i:j]})
+			i = j
+
+		case (c >= '0' && c <= '9') || (c == '.' && i+1 < len(query) && query[i+1] >= '0' && query[i+1] <= '9'):
+			j := i + 1
+			for j < len(query) && (isIdentifierChar(query[j], false) || query[j] == '.' ||
+				((query[j] == '+' || query[j] == '-') && (query[j-1] == 'e' || query[j-1] == 'E'))) {
+				j++
+			}
+			tokens = append(tokens, sqlToken{kind: sqlNumber, text: query[i:j]})
+			i = j
+
+		default:
+			tokens = append(tokens, sqlToken{kind: sqlPunctuation, text: string(c)})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+type sqlTableReference struct {
+	name  string
+	alias string
+}
+
+type sqlColumnReference struct {
+	table string
+	name  string
+}
+
+type sqlReferences struct {
+	tables           []sqlTableReference
+	ctes             map[string]bool
+	qualifiedColumns []sqlColumnReference
+	columns          []string
+	// opaque is set when the query reads from subqueries or table valued
+	// functions, whose columns are not known.
+	opaque bool
+}
+
+// matchingParen returns the index of the parenthesis closing the one at
+// index i.
+func matchingParen(tokens []sqlToken, i int) int {
+	depth := 0
+	for ; i < len(tokens); i++ {
+		if tokens[i].is("(") {
+			depth++
+		} else if tokens[i].is(")") {
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(tokens) - 1
+}
+
+// findSQLReferences finds the tables and columns referenced by the tokens of
+// a (syntactically balanced) query.
+func findSQLReferences(tokens []sqlToken) sqlReferences {
+	refs := sqlReferences{ctes: map[string]bool{}}
+	at := func(i int) sqlToken {
+		if i < 0 || i >= len(tokens) {
+			return sqlToken{kind: sqlPunctuation}
+		}
+		return tokens[i]
+	}
+
+	// Names that are not columns: table aliases, result column aliases,
+	// types and collations
+	notColumns := map[string]bool{}
+	candidates := []int{}
+
+	// readAlias reads an optional alias at index i, returning the alias and
+	// the index following it.
+	readAlias := func(i int) (string, int) {
+		if at(i).is("AS") && at(i+1).isName() {
+			return at(i + 1).name(), i + 2
+		}
+		if at(i).isName() {
+			return at(i).name(), i + 1
+		}
+		return "", i
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+
+		// Common table expressions: name [(columns)] AS (
+		if tok.isName() && (at(i-1).is("WITH") || at(i-1).is("RECURSIVE") || at(i-1).is(",")) {
+			j := i + 1
+			if at(j).is("(") {
+				j = matchingParen(tokens, j) + 1
+			}
+			if at(j).is("AS") && at(j+1).is("(") {
+				refs.ctes[tok.name()] = true
+				refs.opaque = true
+				i = j
+				continue
+			}
+		}
+
+		if tok.is("FROM") || tok.is("JOIN") {
+			j := i + 1
+			for at(j).isName() {
+				name := at(j).name()
+				j++
+				if at(j).is(".") && at(j+1).isName() {
+					// Schema qualified table
+					name = at(j + 1).name()
+					j += 2
+				}
+				if at(j).is("(") {
+					// Table valued function
+					refs.opaque = true
+					j = matchingParen(tokens, j) + 1
+					var alias string
+					alias, j = readAlias(j)
+					notColumns[name] = true
+					notColumns[alias] = true
+				} else {
+					ref := sqlTableReference{name: name}
+					ref.alias, j = readAlias(j)
+					refs.tables = append(refs.tables, ref)
+					notColumns[ref.name] = true
+					notColumns[ref.alias] = true
+				}
+				if !tok.is("FROM") || !at(j).is(",") {
+					break
+				}
+				j++
+			}
+			if at(j).is("(") {
+				// Subqueries and join groups are read by the main loop
+				refs.opaque = true
+			}
+			i = j - 1
+			continue
+		}
+
+		if !tok.isName() {
+			continue
+		}
+		prev, next := at(i-1), at(i+1)
+		switch {
+		case next.is("("):
+			// Function call
+		case prev.is("."):
+			// Column of a qualified reference
+		case next.is(".") && at(i+2).isName():
+			refs.qualifiedColumns = append(refs.qualifiedColumns,
+				sqlColumnReference{table: tok.name(), name: at(i + 2).name()})
+		case next.is(".") && at(i+2).is("*"):
+		case prev.is("AS") || prev.is("COLLATE") || prev.is(":") || prev.is("@") || prev.is("$"):
+			notColumns[tok.name()] = true
+		case prev.is(")") || prev.isName() || prev.kind == sqlString || prev.kind == sqlNumber:
+			// An expression directly followed by a name is aliased
+			notColumns[tok.name()] = true
+		case tok.kind == sqlQuotedIdentifier:
+			// Likely a string literal
+		default:
+			candidates = append(candidates, i)
+		}
+	}
+
+	for _, i := range candidates {
+		name := tokens[i].name()
+		if !notColumns[name] {
+			refs.columns = append(refs.columns, name)
+		}
+	}
+	return refs
+}
+
+// sqlKeywords are the SQLite keywords, which are never reported as unknown
+// columns.
+var sqlKeywords = map[string]bool{}
+
+func init() {
+	for _, k := range strings.Fields(`ABORT ACTION ADD AFTER ALL ALTER ALWAYS ANALYZE AND AS ASC
+		ATTACH AUTOINCREMENT BEFORE BEGIN BETWEEN BY CASCADE CASE CAST CHECK COLLATE
+		COLUMN COMMIT CONFLICT CONSTRAINT CREATE CROSS CURRENT CURRENT_DATE
+		CURRENT_TIME CURRENT_TIMESTAMP DATABASE DEFAULT DEFERRABLE DEFERRED DELETE
+		DESC DETACH DISTINCT DO DROP EACH ELSE END ESCAPE EXCEPT EXCLUDE EXCLUSIVE
+		EXISTS EXPLAIN FAIL FALSE FILTER FIRST FOLLOWING FOR FOREIGN FROM FULL
+		GENERATED GLOB GROUP GROUPS HAVING IF IGNORE IMMEDIATE IN INDEX INDEXED
+		INITIALLY INNER INSERT INSTEAD INTERSECT INTO IS ISNULL JOIN KEY LAST LEFT
+		LIKE LIMIT MATCH NATURAL NO NOT NOTHING NOTNULL NULL NULLS OF OFFSET ON OR
+		ORDER OTHERS OUTER OVER PARTITION PLAN PRAGMA PRECEDING PRIMARY QUERY RAISE
+		RANGE RECURSIVE REFERENCES REGEXP REINDEX RELEASE RENAME REPLACE RESTRICT
+		RIGHT ROLLBACK ROW ROWS SAVEPOINT SELECT SET TABLE TEMP TEMPORARY THEN TIES
+		TO TRANSACTION TRIGGER TRUE UNBOUNDED UNION UNIQUE UPDATE USING VACUUM VALUES
+		VIEW VIRTUAL WHEN WHERE WINDOW WITH WITHOUT`) {
+		sqlKeywords[k] = true
+	}
+}
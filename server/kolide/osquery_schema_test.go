@@ -0,0 +1,121 @@
+package kolide
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadTestOsquerySchema(t *testing.T) *OsquerySchema {
+	data, err := ioutil.ReadFile("../../" + OsquerySchemaAsset)
+	require.Nil(t, err)
+	schema, err := ParseOsquerySchema(data)
+	require.Nil(t, err)
+	return schema
+}
+
+func TestOsqueryPlatforms(t *testing.T) {
+	assert.Nil(t, osqueryPlatforms(""))
+	assert.Nil(t, osqueryPlatforms("any"))
+	assert.Nil(t, osqueryPlatforms("darwin,all"))
+	assert.Nil(t, osqueryPlatforms("plan9"))
+	assert.Equal(t, []string{"darwin"}, osqueryPlatforms("darwin"))
+	assert.Equal(t, []string{"darwin", "freebsd", "linux"}, osqueryPlatforms("posix"))
+	assert.Equal(t, []string{"linux", "windows"}, osqueryPlatforms("windows, ubuntu,centos"))
+	assert.Equal(t, []string{"linux"}, osqueryPlatforms("linuxmint,Rocky"))
+}
+
+func TestValidateQuery(t *testing.T) {
+	schema := loadTestOsquerySchema(t)
+
+	var testCases = []struct {
+		query    string
+		platform string
+		errors   []string
+		warnings []string
+	}{
+		{query: "select * from osquery_info"},
+		{query: "SELECT name, path FROM processes WHERE pid = 1;"},
+		{query: "select u.username, p.name from users u join processes p using (uid) where p.name like '%sh'"},
+		{query: "select count(*) c from users order by c"},
+		{query: "select uid as id from users where username = \"root\" collate nocase"},
+		{query: "select key, data from registry", platform: "windows"},
+		{query: "with recursive n(x) as (select 1 union all select x + 1 from n) select x from n"},
+		{query: "select * from (select name from users) sub where sub.name = 'root'"},
+		{query: "select value from json_each('[1, 2]') -- a comment"},
+		{query: "select * from sqlite_master"},
+		{query: "select 1"},
+		{
+			query:  "",
+			errors: []string{"query is empty"},
+		},
+		{
+			query:  "  /* nothing */ ",
+			errors: []string{"query is empty"},
+		},
+		{
+			query:  "select * from users where username = 'root",
+			errors: []string{"unterminated string literal"},
+		},
+		{
+			query:  "select count(* from users",
+			errors: []string{"unbalanced parentheses"},
+		},
+		{
+			query:  "select count(*)) from users",
+			errors: []string{"unexpected closing parenthesis"},
+		},
+		{
+			query:    "select * from usrs",
+			warnings: []string{`table "usrs" is not in the osquery 3.3 schema, it may be provided by an extension`},
+		},
+		{
+			query:    "select nme from users",
+			warnings: []string{`column "nme" is not in table "users"`},
+		},
+		{
+			query:    "select u.nme from users u join processes p on u.uid = p.uid",
+			warnings: []string{`column "nme" is not in table "users"`},
+		},
+		{
+			query:    "select nme from users join processes using (uid)",
+			warnings: []string{`column "nme" is not in any of the tables users, processes`},
+		},
+		{
+			// Columns are not attributed when a table is unknown
+			query:    "select nme from users join kolide_launcher_info",
+			warnings: []string{`table "kolide_launcher_info" is not in the osquery 3.3 schema, it may be provided by an extension`},
+		},
+		{
+			query:    "select * from registry",
+			platform: "darwin",
+			errors:   []string{`table "registry" is not available on darwin`},
+		},
+		{
+			query:    "select * from registry",
+			platform: "darwin,windows",
+			warnings: []string{`table "registry" is not available on darwin`},
+		},
+		{
+			query:    "select * from users join registry",
+			platform: "ubuntu",
+			errors:   []string{`table "registry" is not available on linux`},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.query, func(t *testing.T) {
+			v := schema.ValidateQuery(tt.query, tt.platform)
+			assert.Equal(t, tt.errors, v.Errors)
+			assert.Equal(t, tt.warnings, v.Warnings)
+		})
+	}
+}
+
+func TestValidateQueryWithoutSchema(t *testing.T) {
+	var schema *OsquerySchema
+	assert.Empty(t, schema.ValidateQuery("select * from usrs", "darwin").Warnings)
+	assert.Equal(t, []string{"unbalanced parentheses"}, schema.ValidateQuery("select (", "").Errors)
+}
@@ -65,8 +65,9 @@ type PackStore interface {
 // PackService is the service interface for managing query packs.
 type PackService interface {
 	// ApplyPackSpecs applies a list of PackSpecs to the datastore,
-	// creating and updating packs as necessary.
-	ApplyPackSpecs(ctx context.Context, specs []*PackSpec) error
+	// creating and updating packs as necessary. The warnings found when
+	// validating the pack queries are returned.
+	ApplyPackSpecs(ctx context.Context, specs []*PackSpec) (warnings []string, err error)
 	// GetPackSpecs returns all of the stored PackSpecs.
 	GetPackSpecs(ctx context.Context) ([]*PackSpec, error)
 	// GetPackSpec gets the spec for the pack with the given name.
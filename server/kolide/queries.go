@@ -37,8 +37,12 @@ type QueryStore interface {
 
 type QueryService interface {
 	// ApplyQuerySpecs applies a list of queries (creating or updating
-	// them as necessary)
-	ApplyQuerySpecs(ctx context.Context, specs []*QuerySpec) error
+	// them as necessary). The warnings found when validating the queries
+	// are returned.
+	ApplyQuerySpecs(ctx context.Context, specs []*QuerySpec) (warnings []string, err error)
+	// ValidateSpecs validates the SQL of the provided queries, labels and
+	// packs against the osquery schema, without applying them.
+	ValidateSpecs(ctx context.Context, queries []*QuerySpec, labels []*LabelSpec, packs []*PackSpec) (*QueryValidation, error)
 	// GetQuerySpecs gets the YAML file representing all the stored queries.
 	GetQuerySpecs(ctx context.Context) ([]*QuerySpec, error)
 	// GetQuerySpec gets the spec for the query with the given name.
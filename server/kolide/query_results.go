@@ -14,10 +14,18 @@ type QueryResultStore interface {
 
 	// ReadChannel returns a channel to be read for incoming distributed
 	// query results. Channel values should be either
-	// DistributedQueryResult or error
+	// DistributedQueryResult, ResultHistoryTruncated or error
 	ReadChannel(ctx context.Context, query DistributedQueryCampaign) (<-chan interface{}, error)
 
 	// HealthCheck returns nil if the store is functioning properly, or an
 	// error describing the problem.
 	HealthCheck() error
 }
+
+// ResultHistoryTruncated is read from a QueryResultStore channel before the
+// results a campaign received before the reader joined it, when the oldest of
+// these results are no longer retained.
+type ResultHistoryTruncated struct {
+	// Dropped is the number of results that are no longer retained.
+	Dropped int
+}
@@ -4,6 +4,7 @@ package kolide
 type Service interface {
 	UserService
 	SessionService
+	APITokenService
 	PackService
 	LabelService
 	QueryService
@@ -18,4 +19,10 @@ type Service interface {
 	OptionService
 	FileIntegrityMonitoringService
 	StatusService
+	SoftwareService
+	HostHistoryService
+	HostTagService
+	HostStatusLogService
+	CampaignResultService
+	ActivityService
 }
@@ -35,6 +35,9 @@ type SessionStore interface {
 type Auth interface {
 	UserID() string
 	RequestID() string
+	// AttributeValues returns the values of the named attribute of the user
+	// asserted by the IDP.
+	AttributeValues(name string) []string
 }
 
 type SessionService interface {
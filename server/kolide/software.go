@@ -0,0 +1,53 @@
+package kolide
+
+import "context"
+
+// SoftwareStore defines the datastore methods for the software inventory
+// collected from hosts.
+type SoftwareStore interface {
+	// SaveHostSoftware replaces the software recorded for the host with the
+	// provided set.
+	SaveHostSoftware(hostID uint, software []Software) error
+	// ListSoftwareForHost returns the software installed on the host with
+	// the given ID.
+	ListSoftwareForHost(hostID uint) ([]Software, error)
+	// ListSoftware returns the software installed on at least one host,
+	// along with the hosts it is installed on. If name is not empty only
+	// software with that exact name is returned. If version is not empty
+	// only software with a version starting with the provided value is
+	// returned.
+	ListSoftware(name, version string, opt ListOptions) ([]*Software, error)
+}
+
+// SoftwareService defines the service methods for the software inventory.
+type SoftwareService interface {
+	// ListHostSoftware returns the software installed on the host with the
+	// given ID.
+	ListHostSoftware(ctx context.Context, hostID uint) ([]Software, error)
+	// ListSoftware returns the software matching the provided name and
+	// version prefix, along with the hosts it is installed on.
+	ListSoftware(ctx context.Context, name, version string, opt ListOptions) ([]*Software, error)
+}
+
+// Software is a named and versioned piece of software (application or
+// package) that is installed on one or more hosts.
+type Software struct {
+	ID uint `json:"id" db:"id"`
+	// Name is the name reported by osquery.
+	Name string `json:"name" db:"name"`
+	// Version is the version reported by osquery.
+	Version string `json:"version" db:"version"`
+	// Source is the osquery table the software was reported from (eg.
+	// "apps" or "deb_packages").
+	Source string `json:"source" db:"source"`
+	// Hosts is the set of hosts the software is installed on. It is only
+	// populated when listing software across hosts.
+	Hosts []SoftwareHost `json:"hosts,omitempty" db:"-"`
+}
+
+// SoftwareHost identifies a host that has a particular piece of software
+// installed.
+type SoftwareHost struct {
+	ID       uint   `json:"id" db:"id"`
+	HostName string `json:"hostname" db:"host_name"`
+}
@@ -38,14 +38,14 @@ type TargetService interface {
 	SearchTargets(ctx context.Context, query string, selectedHostIDs []uint, selectedLabelIDs []uint) (*TargetSearchResults, error)
 
 	// CountHostsInTargets returns the metrics of the hosts in the provided
-	// label and explicit host IDs.
-	CountHostsInTargets(ctx context.Context, hostIDs []uint, labelIDs []uint) (*TargetMetrics, error)
+	// label, tag and explicit host IDs.
+	CountHostsInTargets(ctx context.Context, hostIDs []uint, labelIDs []uint, tagIDs []uint) (*TargetMetrics, error)
 }
 
 type TargetStore interface {
 	// CountHostsInTargets returns the metrics of the hosts in the provided
-	// label and explicit host IDs.
-	CountHostsInTargets(hostIDs []uint, labelIDs []uint, now time.Time) (TargetMetrics, error)
+	// label, tag and explicit host IDs.
+	CountHostsInTargets(hostIDs []uint, labelIDs []uint, tagIDs []uint, now time.Time) (TargetMetrics, error)
 }
 
 type TargetType int
@@ -53,6 +53,7 @@ type TargetType int
 const (
 	TargetLabel TargetType = iota
 	TargetHost
+	TargetTag
 )
 
 type Target struct {
@@ -76,6 +76,15 @@ type UserService interface {
 	// ChangeUserAdmin is used to modify the admin state of the user identified by id.
 	ChangeUserAdmin(ctx context.Context, id uint, isAdmin bool) (*User, error)
 
+	// ChangeUserRole is used to modify the role of the user identified by id.
+	ChangeUserRole(ctx context.Context, id uint, role Role) (*User, error)
+
+	// ApplyUserRolesSpec sets the roles of the users named in the spec.
+	ApplyUserRolesSpec(ctx context.Context, spec *UserRolesSpec) error
+
+	// GetUserRolesSpec returns the roles of all users.
+	GetUserRolesSpec(ctx context.Context) (*UserRolesSpec, error)
+
 	// ChangeUserEnabled is used to enable/disable the user identified by id.
 	ChangeUserEnabled(ctx context.Context, id uint, isEnabled bool) (*User, error)
 
@@ -95,12 +104,16 @@ type User struct {
 	Name                     string `json:"name"`
 	Email                    string `json:"email"`
 	Admin                    bool   `json:"admin"`
+	Role                     Role   `json:"role"`
 	Enabled                  bool   `json:"enabled"`
 	AdminForcedPasswordReset bool   `json:"force_password_reset" db:"admin_forced_password_reset"`
 	GravatarURL              string `json:"gravatar_url" db:"gravatar_url"`
 	Position                 string `json:"position,omitempty"` // job role
 	// SSOEnabled if true, the single siqn on is used to log in
 	SSOEnabled bool `json:"sso_enabled" db:"sso_enabled"`
+	// APIOnly is true for service accounts, which cannot log in
+	// interactively and authenticate with API tokens instead.
+	APIOnly bool `json:"api_only" db:"api_only"`
 }
 
 // UserPayload is used to modify an existing user
@@ -109,6 +122,7 @@ type UserPayload struct {
 	Name        *string `json:"name,omitempty"`
 	Email       *string `json:"email,omitempty"`
 	Admin       *bool   `json:"admin,omitempty"`
+	Role        *Role   `json:"role,omitempty"`
 	Enabled     *bool   `json:"enabled,omitempty"`
 	Password    *string `json:"password,omitempty"`
 	GravatarURL *string `json:"gravatar_url,omitempty"`
@@ -123,9 +137,16 @@ func (p UserPayload) User(keySize, cost int) (*User, error) {
 	user := &User{
 		Username: *p.Username,
 		Email:    *p.Email,
-		Admin:    falseIfNil(p.Admin),
 		Enabled:  true,
 	}
+	switch {
+	case falseIfNil(p.Admin):
+		user.SetRole(RoleAdmin)
+	case p.Role != nil:
+		user.SetRole(*p.Role)
+	default:
+		user.SetRole(RoleMaintainer)
+	}
 	if err := user.SetPassword(*p.Password, keySize, cost); err != nil {
 		return nil, err
 	}
@@ -147,6 +168,63 @@ func (p UserPayload) User(keySize, cost int) (*User, error) {
 	return user, nil
 }
 
+// Role is the set of actions a user is allowed to perform. Each role includes
+// the permissions of the roles before it: observer, maintainer, admin.
+type Role string
+
+const (
+	// RoleObserver can read everything, but cannot modify anything or run
+	// live queries.
+	RoleObserver Role = "observer"
+	// RoleMaintainer can also manage queries, packs and labels, and run
+	// live queries.
+	RoleMaintainer Role = "maintainer"
+	// RoleAdmin can also manage users and the Fleet configuration.
+	RoleAdmin Role = "admin"
+)
+
+var roleRanks = map[Role]int{
+	RoleObserver:   1,
+	RoleMaintainer: 2,
+	RoleAdmin:      3,
+}
+
+// Valid returns true if the role is one of the known roles.
+func (r Role) Valid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+// Includes returns true if the role has all the permissions of other.
+func (r Role) Includes(other Role) bool {
+	return r.Valid() && roleRanks[r] >= roleRanks[other]
+}
+
+// UserRolesSpec is the fleetctl spec type for the roles of users.
+type UserRolesSpec struct {
+	// Roles maps usernames to their role.
+	Roles map[string]Role `json:"roles"`
+}
+
+// EffectiveRole returns the role used to authorize the user. Users that have
+// no role set (such as users created before roles were introduced) are
+// admins or maintainers, according to their admin flag.
+func (u *User) EffectiveRole() Role {
+	if u.Admin {
+		return RoleAdmin
+	}
+	if u.Role == "" {
+		return RoleMaintainer
+	}
+	return u.Role
+}
+
+// SetRole sets the role of the user, keeping the admin flag in sync.
+func (u *User) SetRole(role Role) {
+	u.Role = role
+	u.Admin = role == RoleAdmin
+}
+
 // ValidatePassword accepts a potential password for a given user and attempts
 // to validate it against the hash stored in the database after joining the
 // supplied password with the stored password salt
@@ -45,3 +45,20 @@ func newTestUser(t *testing.T, username, password, email string) *User {
 		Email:    email,
 	}
 }
+
+func TestUserRoles(t *testing.T) {
+	assert.True(t, RoleAdmin.Includes(RoleObserver))
+	assert.True(t, RoleMaintainer.Includes(RoleMaintainer))
+	assert.False(t, RoleMaintainer.Includes(RoleAdmin))
+	assert.False(t, RoleObserver.Includes(RoleMaintainer))
+	assert.False(t, Role("root").Includes(RoleObserver))
+
+	// Users from before roles keep their permissions
+	assert.Equal(t, RoleMaintainer, (&User{}).EffectiveRole())
+	assert.Equal(t, RoleAdmin, (&User{Admin: true}).EffectiveRole())
+
+	user := &User{Admin: true}
+	user.SetRole(RoleObserver)
+	assert.False(t, user.Admin)
+	assert.Equal(t, RoleObserver, user.EffectiveRole())
+}
@@ -120,7 +120,7 @@ func (svc *launcherWrapper) PublishResults(ctx context.Context, nodeKey string,
 		osqueryResults[result.QueryName] = result.Rows
 	}
 
-	err = svc.tls.SubmitDistributedQueryResults(newCtx, osqueryResults, statuses)
+	err = svc.tls.SubmitDistributedQueryResults(newCtx, osqueryResults, statuses, nil)
 	return "", "", false, errors.Wrap(err, "submit launcher results")
 }
 
@@ -66,7 +66,7 @@ func TestLauncherPublishResults(t *testing.T) {
 	tls.SubmitDistributedQueryResultsFunc = func(
 		ctx context.Context,
 		results kolide.OsqueryDistributedQueryResults,
-		statuses map[string]kolide.OsqueryStatus) (err error) {
+		statuses map[string]kolide.OsqueryStatus, messages map[string]string) (err error) {
 		assert.Equal(t, results["query"][0], result)
 		return nil
 	}
@@ -146,6 +146,7 @@ func newTLSService(t *testing.T) *mock.TLSService {
 			ctx context.Context,
 			results kolide.OsqueryDistributedQueryResults,
 			statuses map[string]kolide.OsqueryStatus,
+			messages map[string]string,
 		) (err error) {
 			return
 		},
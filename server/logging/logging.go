@@ -13,6 +13,9 @@ import (
 type OsqueryLogger struct {
 	Status kolide.JSONLogger
 	Result kolide.JSONLogger
+	// LiveQuery receives the results of live query campaigns. It is nil
+	// when live query results are not logged.
+	LiveQuery kolide.JSONLogger
 }
 
 func New(config config.KolideConfig, logger log.Logger) (*OsqueryLogger, error) {
@@ -98,5 +101,92 @@ func New(config config.KolideConfig, logger log.Logger) (*OsqueryLogger, error)
 			"unknown result log plugin: %s", config.Osquery.StatusLogPlugin,
 		)
 	}
-	return &OsqueryLogger{Status: status, Result: result}, nil
+
+	var liveQuery kolide.JSONLogger
+	switch config.Osquery.LiveQueryLogPlugin {
+	case "":
+		// Live query results are only logged when configured
+	case "result":
+		liveQuery = result
+	case "filesystem":
+		liveQuery, err = NewFilesystemLogWriter(
+			config.Filesystem.LiveQueryLogFile,
+			logger,
+			config.Filesystem.EnableLogRotation,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "create filesystem live query logger")
+		}
+	case "firehose":
+		liveQuery, err = NewFirehoseLogWriter(
+			config.Firehose.Region,
+			config.Firehose.AccessKeyID,
+			config.Firehose.SecretAccessKey,
+			config.Firehose.LiveQueryStream,
+			logger,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "create firehose live query logger")
+		}
+	case "pubsub":
+		liveQuery, err = NewPubSubLogWriter(
+			config.PubSub.Project,
+			config.PubSub.LiveQueryTopic,
+			logger,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "create pubsub live query logger")
+		}
+	default:
+		return nil, errors.Errorf(
+			"unknown live query log plugin: %s", config.Osquery.LiveQueryLogPlugin,
+		)
+	}
+
+	return &OsqueryLogger{Status: status, Result: result, LiveQuery: liveQuery}, nil
+}
+
+// NewActivityLogger returns the logger that receives a copy of the activity
+// log, or nil when the activity log is only stored in the database.
+func NewActivityLogger(config config.KolideConfig, logger log.Logger) (kolide.JSONLogger, error) {
+	switch config.Logging.ActivityLogPlugin {
+	case "":
+		return nil, nil
+	case "filesystem":
+		writer, err := NewFilesystemLogWriter(
+			config.Filesystem.ActivityLogFile,
+			logger,
+			config.Filesystem.EnableLogRotation,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "create filesystem activity logger")
+		}
+		return writer, nil
+	case "firehose":
+		writer, err := NewFirehoseLogWriter(
+			config.Firehose.Region,
+			config.Firehose.AccessKeyID,
+			config.Firehose.SecretAccessKey,
+			config.Firehose.ActivityStream,
+			logger,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "create firehose activity logger")
+		}
+		return writer, nil
+	case "pubsub":
+		writer, err := NewPubSubLogWriter(
+			config.PubSub.Project,
+			config.PubSub.ActivityTopic,
+			logger,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "create pubsub activity logger")
+		}
+		return writer, nil
+	default:
+		return nil, errors.Errorf(
+			"unknown activity log plugin: %s", config.Logging.ActivityLogPlugin,
+		)
+	}
 }
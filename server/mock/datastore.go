@@ -14,6 +14,14 @@ package mock
 //go:generate mockimpl -o datastore_query_results.go "s *QueryResultStore" "kolide.QueryResultStore"
 //go:generate mockimpl -o datastore_campaigns.go "s *CampaignStore" "kolide.CampaignStore"
 //go:generate mockimpl -o datastore_sessions.go "s *SessionStore" "kolide.SessionStore"
+//go:generate mockimpl -o datastore_software.go "s *SoftwareStore" "kolide.SoftwareStore"
+//go:generate mockimpl -o datastore_host_history.go "s *HostHistoryStore" "kolide.HostHistoryStore"
+//go:generate mockimpl -o datastore_host_tags.go "s *HostTagStore" "kolide.HostTagStore"
+//go:generate mockimpl -o datastore_host_status_logs.go "s *HostStatusLogStore" "kolide.HostStatusLogStore"
+//go:generate mockimpl -o datastore_campaign_results.go "s *CampaignResultStore" "kolide.CampaignResultStore"
+//go:generate mockimpl -o datastore_live_query_results.go "s *LiveQueryResultStore" "kolide.LiveQueryResultStore"
+//go:generate mockimpl -o datastore_api_tokens.go "s *APITokenStore" "kolide.APITokenStore"
+//go:generate mockimpl -o datastore_activities.go "s *ActivityStore" "kolide.ActivityStore"
 
 import "github.com/kolide/fleet/server/kolide"
 
@@ -37,6 +45,14 @@ type Store struct {
 	UserStore
 	QueryStore
 	QueryResultStore
+	SoftwareStore
+	HostHistoryStore
+	HostTagStore
+	HostStatusLogStore
+	CampaignResultStore
+	LiveQueryResultStore
+	APITokenStore
+	ActivityStore
 }
 
 func (m *Store) Drop() error {
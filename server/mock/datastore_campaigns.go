@@ -16,14 +16,26 @@ type DistributedQueryCampaignFunc func(id uint) (*kolide.DistributedQueryCampaig
 
 type SaveDistributedQueryCampaignFunc func(camp *kolide.DistributedQueryCampaign) error
 
-type DistributedQueryCampaignTargetIDsFunc func(id uint) (hostIDs []uint, labelIDs []uint, err error)
+type NewDistributedQueryCampaignViewerFunc func(campaignID uint) (uint, error)
+
+type TouchDistributedQueryCampaignViewerFunc func(id uint) error
+
+type DeleteDistributedQueryCampaignViewerFunc func(id uint, activeSince time.Time) (remaining uint, err error)
+
+type DistributedQueryCampaignTargetIDsFunc func(id uint) (hostIDs []uint, labelIDs []uint, tagIDs []uint, err error)
 
 type NewDistributedQueryCampaignTargetFunc func(target *kolide.DistributedQueryCampaignTarget) (*kolide.DistributedQueryCampaignTarget, error)
 
 type NewDistributedQueryExecutionFunc func(exec *kolide.DistributedQueryExecution) (*kolide.DistributedQueryExecution, error)
 
+type MarkDistributedQueriesRequestedFunc func(hostID uint, campaignIDs []uint, now time.Time) error
+
 type CleanupDistributedQueryCampaignsFunc func(now time.Time) (expired uint, deleted uint, err error)
 
+type ListDistributedQueryCampaignsFunc func(opt kolide.ListOptions, filter kolide.DistributedQueryCampaignFilter) ([]*kolide.DistributedQueryCampaignSummary, error)
+
+type ListDistributedQueryCampaignHostsFunc func(campaignID uint, opt kolide.ListOptions) ([]*kolide.DistributedQueryCampaignHost, error)
+
 type CampaignStore struct {
 	NewDistributedQueryCampaignFunc        NewDistributedQueryCampaignFunc
 	NewDistributedQueryCampaignFuncInvoked bool
@@ -34,6 +46,15 @@ type CampaignStore struct {
 	SaveDistributedQueryCampaignFunc        SaveDistributedQueryCampaignFunc
 	SaveDistributedQueryCampaignFuncInvoked bool
 
+	NewDistributedQueryCampaignViewerFunc        NewDistributedQueryCampaignViewerFunc
+	NewDistributedQueryCampaignViewerFuncInvoked bool
+
+	TouchDistributedQueryCampaignViewerFunc        TouchDistributedQueryCampaignViewerFunc
+	TouchDistributedQueryCampaignViewerFuncInvoked bool
+
+	DeleteDistributedQueryCampaignViewerFunc        DeleteDistributedQueryCampaignViewerFunc
+	DeleteDistributedQueryCampaignViewerFuncInvoked bool
+
 	DistributedQueryCampaignTargetIDsFunc        DistributedQueryCampaignTargetIDsFunc
 	DistributedQueryCampaignTargetIDsFuncInvoked bool
 
@@ -43,8 +64,17 @@ type CampaignStore struct {
 	NewDistributedQueryExecutionFunc        NewDistributedQueryExecutionFunc
 	NewDistributedQueryExecutionFuncInvoked bool
 
+	MarkDistributedQueriesRequestedFunc        MarkDistributedQueriesRequestedFunc
+	MarkDistributedQueriesRequestedFuncInvoked bool
+
 	CleanupDistributedQueryCampaignsFunc        CleanupDistributedQueryCampaignsFunc
 	CleanupDistributedQueryCampaignsFuncInvoked bool
+
+	ListDistributedQueryCampaignsFunc        ListDistributedQueryCampaignsFunc
+	ListDistributedQueryCampaignsFuncInvoked bool
+
+	ListDistributedQueryCampaignHostsFunc        ListDistributedQueryCampaignHostsFunc
+	ListDistributedQueryCampaignHostsFuncInvoked bool
 }
 
 func (s *CampaignStore) NewDistributedQueryCampaign(camp *kolide.DistributedQueryCampaign) (*kolide.DistributedQueryCampaign, error) {
@@ -62,7 +92,22 @@ func (s *CampaignStore) SaveDistributedQueryCampaign(camp *kolide.DistributedQue
 	return s.SaveDistributedQueryCampaignFunc(camp)
 }
 
-func (s *CampaignStore) DistributedQueryCampaignTargetIDs(id uint) (hostIDs []uint, labelIDs []uint, err error) {
+func (s *CampaignStore) NewDistributedQueryCampaignViewer(campaignID uint) (uint, error) {
+	s.NewDistributedQueryCampaignViewerFuncInvoked = true
+	return s.NewDistributedQueryCampaignViewerFunc(campaignID)
+}
+
+func (s *CampaignStore) TouchDistributedQueryCampaignViewer(id uint) error {
+	s.TouchDistributedQueryCampaignViewerFuncInvoked = true
+	return s.TouchDistributedQueryCampaignViewerFunc(id)
+}
+
+func (s *CampaignStore) DeleteDistributedQueryCampaignViewer(id uint, activeSince time.Time) (remaining uint, err error) {
+	s.DeleteDistributedQueryCampaignViewerFuncInvoked = true
+	return s.DeleteDistributedQueryCampaignViewerFunc(id, activeSince)
+}
+
+func (s *CampaignStore) DistributedQueryCampaignTargetIDs(id uint) (hostIDs []uint, labelIDs []uint, tagIDs []uint, err error) {
 	s.DistributedQueryCampaignTargetIDsFuncInvoked = true
 	return s.DistributedQueryCampaignTargetIDsFunc(id)
 }
@@ -77,7 +122,22 @@ func (s *CampaignStore) NewDistributedQueryExecution(exec *kolide.DistributedQue
 	return s.NewDistributedQueryExecutionFunc(exec)
 }
 
+func (s *CampaignStore) MarkDistributedQueriesRequested(hostID uint, campaignIDs []uint, now time.Time) error {
+	s.MarkDistributedQueriesRequestedFuncInvoked = true
+	return s.MarkDistributedQueriesRequestedFunc(hostID, campaignIDs, now)
+}
+
 func (s *CampaignStore) CleanupDistributedQueryCampaigns(now time.Time) (expired uint, deleted uint, err error) {
 	s.CleanupDistributedQueryCampaignsFuncInvoked = true
 	return s.CleanupDistributedQueryCampaignsFunc(now)
 }
+
+func (s *CampaignStore) ListDistributedQueryCampaigns(opt kolide.ListOptions, filter kolide.DistributedQueryCampaignFilter) ([]*kolide.DistributedQueryCampaignSummary, error) {
+	s.ListDistributedQueryCampaignsFuncInvoked = true
+	return s.ListDistributedQueryCampaignsFunc(opt, filter)
+}
+
+func (s *CampaignStore) ListDistributedQueryCampaignHosts(campaignID uint, opt kolide.ListOptions) ([]*kolide.DistributedQueryCampaignHost, error) {
+	s.ListDistributedQueryCampaignHostsFuncInvoked = true
+	return s.ListDistributedQueryCampaignHostsFunc(campaignID, opt)
+}
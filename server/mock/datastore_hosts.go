@@ -16,9 +16,13 @@ type SaveHostFunc func(host *kolide.Host) error
 
 type DeleteHostFunc func(hid uint) error
 
+type DeleteHostsFunc func(ids []uint, opts ...kolide.OptionalArg) (uint, error)
+
+type ExistingHostIDsFunc func(ids []uint) ([]uint, error)
+
 type HostFunc func(id uint) (*kolide.Host, error)
 
-type ListHostsFunc func(opt kolide.ListOptions) ([]*kolide.Host, error)
+type ListHostsFunc func(opt kolide.HostListOptions) ([]*kolide.Host, error)
 
 type EnrollHostFunc func(osqueryHostId, nodeKey, secretName string) (*kolide.Host, error)
 
@@ -26,16 +30,26 @@ type AuthenticateHostFunc func(nodeKey string) (*kolide.Host, error)
 
 type MarkHostSeenFunc func(host *kolide.Host, t time.Time) error
 
-type CleanupIncomingHostsFunc func(t time.Time) error
-
 type SearchHostsFunc func(query string, omit ...uint) ([]*kolide.Host, error)
 
+type CleanupIncomingHostsFunc func(now time.Time) error
+
+type ListExpiredHostsFunc func(now time.Time, window time.Duration) ([]*kolide.Host, error)
+
+type ExpireHostsFunc func(now time.Time, window time.Duration) ([]uint, error)
+
 type GenerateHostStatusStatisticsFunc func(now time.Time) (online uint, offline uint, mia uint, new uint, err error)
 
 type DistributedQueriesForHostFunc func(host *kolide.Host) (map[uint]string, error)
 
 type HostIDsByNameFunc func(hostnames []string) ([]uint, error)
 
+type FindHostByIdentityFunc func(strategy kolide.HostIdentityStrategy, identity kolide.HostIdentity) (*kolide.Host, error)
+
+type ReenrollHostFunc func(hostID uint, osqueryHostID, nodeKey, secretName string) (*kolide.Host, error)
+
+type MergeHostsFunc func(survivorID, duplicateID uint) error
+
 type HostStore struct {
 	NewHostFunc        NewHostFunc
 	NewHostFuncInvoked bool
@@ -46,6 +60,12 @@ type HostStore struct {
 	DeleteHostFunc        DeleteHostFunc
 	DeleteHostFuncInvoked bool
 
+	DeleteHostsFunc        DeleteHostsFunc
+	DeleteHostsFuncInvoked bool
+
+	ExistingHostIDsFunc        ExistingHostIDsFunc
+	ExistingHostIDsFuncInvoked bool
+
 	HostFunc        HostFunc
 	HostFuncInvoked bool
 
@@ -61,11 +81,17 @@ type HostStore struct {
 	MarkHostSeenFunc        MarkHostSeenFunc
 	MarkHostSeenFuncInvoked bool
 
+	SearchHostsFunc        SearchHostsFunc
+	SearchHostsFuncInvoked bool
+
 	CleanupIncomingHostsFunc        CleanupIncomingHostsFunc
 	CleanupIncomingHostsFuncInvoked bool
 
-	SearchHostsFunc        SearchHostsFunc
-	SearchHostsFuncInvoked bool
+	ListExpiredHostsFunc        ListExpiredHostsFunc
+	ListExpiredHostsFuncInvoked bool
+
+	ExpireHostsFunc        ExpireHostsFunc
+	ExpireHostsFuncInvoked bool
 
 	GenerateHostStatusStatisticsFunc        GenerateHostStatusStatisticsFunc
 	GenerateHostStatusStatisticsFuncInvoked bool
@@ -75,6 +101,15 @@ type HostStore struct {
 
 	HostIDsByNameFunc        HostIDsByNameFunc
 	HostIDsByNameFuncInvoked bool
+
+	FindHostByIdentityFunc        FindHostByIdentityFunc
+	FindHostByIdentityFuncInvoked bool
+
+	ReenrollHostFunc        ReenrollHostFunc
+	ReenrollHostFuncInvoked bool
+
+	MergeHostsFunc        MergeHostsFunc
+	MergeHostsFuncInvoked bool
 }
 
 func (s *HostStore) NewHost(host *kolide.Host) (*kolide.Host, error) {
@@ -92,12 +127,22 @@ func (s *HostStore) DeleteHost(hid uint) error {
 	return s.DeleteHostFunc(hid)
 }
 
+func (s *HostStore) DeleteHosts(ids []uint, opts ...kolide.OptionalArg) (uint, error) {
+	s.DeleteHostsFuncInvoked = true
+	return s.DeleteHostsFunc(ids, opts...)
+}
+
+func (s *HostStore) ExistingHostIDs(ids []uint) ([]uint, error) {
+	s.ExistingHostIDsFuncInvoked = true
+	return s.ExistingHostIDsFunc(ids)
+}
+
 func (s *HostStore) Host(id uint) (*kolide.Host, error) {
 	s.HostFuncInvoked = true
 	return s.HostFunc(id)
 }
 
-func (s *HostStore) ListHosts(opt kolide.ListOptions) ([]*kolide.Host, error) {
+func (s *HostStore) ListHosts(opt kolide.HostListOptions) ([]*kolide.Host, error) {
 	s.ListHostsFuncInvoked = true
 	return s.ListHostsFunc(opt)
 }
@@ -117,16 +162,26 @@ func (s *HostStore) MarkHostSeen(host *kolide.Host, t time.Time) error {
 	return s.MarkHostSeenFunc(host, t)
 }
 
-func (s *HostStore) CleanupIncomingHosts(t time.Time) error {
-	s.CleanupIncomingHostsFuncInvoked = true
-	return s.CleanupIncomingHostsFunc(t)
-}
-
 func (s *HostStore) SearchHosts(query string, omit ...uint) ([]*kolide.Host, error) {
 	s.SearchHostsFuncInvoked = true
 	return s.SearchHostsFunc(query, omit...)
 }
 
+func (s *HostStore) CleanupIncomingHosts(now time.Time) error {
+	s.CleanupIncomingHostsFuncInvoked = true
+	return s.CleanupIncomingHostsFunc(now)
+}
+
+func (s *HostStore) ListExpiredHosts(now time.Time, window time.Duration) ([]*kolide.Host, error) {
+	s.ListExpiredHostsFuncInvoked = true
+	return s.ListExpiredHostsFunc(now, window)
+}
+
+func (s *HostStore) ExpireHosts(now time.Time, window time.Duration) ([]uint, error) {
+	s.ExpireHostsFuncInvoked = true
+	return s.ExpireHostsFunc(now, window)
+}
+
 func (s *HostStore) GenerateHostStatusStatistics(now time.Time) (online uint, offline uint, mia uint, new uint, err error) {
 	s.GenerateHostStatusStatisticsFuncInvoked = true
 	return s.GenerateHostStatusStatisticsFunc(now)
@@ -141,3 +196,18 @@ func (s *HostStore) HostIDsByName(hostnames []string) ([]uint, error) {
 	s.HostIDsByNameFuncInvoked = true
 	return s.HostIDsByNameFunc(hostnames)
 }
+
+func (s *HostStore) FindHostByIdentity(strategy kolide.HostIdentityStrategy, identity kolide.HostIdentity) (*kolide.Host, error) {
+	s.FindHostByIdentityFuncInvoked = true
+	return s.FindHostByIdentityFunc(strategy, identity)
+}
+
+func (s *HostStore) ReenrollHost(hostID uint, osqueryHostID, nodeKey, secretName string) (*kolide.Host, error) {
+	s.ReenrollHostFuncInvoked = true
+	return s.ReenrollHostFunc(hostID, osqueryHostID, nodeKey, secretName)
+}
+
+func (s *HostStore) MergeHosts(survivorID, duplicateID uint) error {
+	s.MergeHostsFuncInvoked = true
+	return s.MergeHostsFunc(survivorID, duplicateID)
+}
@@ -30,8 +30,14 @@ type LabelQueriesForHostFunc func(host *kolide.Host, cutoff time.Time) (map[stri
 
 type RecordLabelQueryExecutionsFunc func(host *kolide.Host, results map[uint]bool, t time.Time) error
 
+type RecordLabelQueryErrorsFunc func(host *kolide.Host, errs map[uint]string, t time.Time) error
+
+type ListLabelExecutionsForHostFunc func(host *kolide.Host) ([]*kolide.HostLabelExecution, error)
+
 type ListLabelsForHostFunc func(hid uint) ([]kolide.Label, error)
 
+type ListLabelsForHostsFunc func(hids []uint) (map[uint][]kolide.Label, error)
+
 type ListHostsInLabelFunc func(lid uint) ([]kolide.Host, error)
 
 type ListUniqueHostsInLabelsFunc func(labels []uint) ([]kolide.Host, error)
@@ -71,9 +77,18 @@ type LabelStore struct {
 	RecordLabelQueryExecutionsFunc        RecordLabelQueryExecutionsFunc
 	RecordLabelQueryExecutionsFuncInvoked bool
 
+	RecordLabelQueryErrorsFunc        RecordLabelQueryErrorsFunc
+	RecordLabelQueryErrorsFuncInvoked bool
+
+	ListLabelExecutionsForHostFunc        ListLabelExecutionsForHostFunc
+	ListLabelExecutionsForHostFuncInvoked bool
+
 	ListLabelsForHostFunc        ListLabelsForHostFunc
 	ListLabelsForHostFuncInvoked bool
 
+	ListLabelsForHostsFunc        ListLabelsForHostsFunc
+	ListLabelsForHostsFuncInvoked bool
+
 	ListHostsInLabelFunc        ListHostsInLabelFunc
 	ListHostsInLabelFuncInvoked bool
 
@@ -137,11 +152,26 @@ func (s *LabelStore) RecordLabelQueryExecutions(host *kolide.Host, results map[u
 	return s.RecordLabelQueryExecutionsFunc(host, results, t)
 }
 
+func (s *LabelStore) RecordLabelQueryErrors(host *kolide.Host, errs map[uint]string, t time.Time) error {
+	s.RecordLabelQueryErrorsFuncInvoked = true
+	return s.RecordLabelQueryErrorsFunc(host, errs, t)
+}
+
+func (s *LabelStore) ListLabelExecutionsForHost(host *kolide.Host) ([]*kolide.HostLabelExecution, error) {
+	s.ListLabelExecutionsForHostFuncInvoked = true
+	return s.ListLabelExecutionsForHostFunc(host)
+}
+
 func (s *LabelStore) ListLabelsForHost(hid uint) ([]kolide.Label, error) {
 	s.ListLabelsForHostFuncInvoked = true
 	return s.ListLabelsForHostFunc(hid)
 }
 
+func (s *LabelStore) ListLabelsForHosts(hids []uint) (map[uint][]kolide.Label, error) {
+	s.ListLabelsForHostsFuncInvoked = true
+	return s.ListLabelsForHostsFunc(hids)
+}
+
 func (s *LabelStore) ListHostsInLabel(lid uint) ([]kolide.Host, error) {
 	s.ListHostsInLabelFuncInvoked = true
 	return s.ListHostsInLabelFunc(lid)
@@ -10,14 +10,14 @@ import (
 
 var _ kolide.TargetStore = (*TargetStore)(nil)
 
-type CountHostsInTargetsFunc func(hostIDs, labelIDs []uint, now time.Time) (kolide.TargetMetrics, error)
+type CountHostsInTargetsFunc func(hostIDs []uint, labelIDs []uint, tagIDs []uint, now time.Time) (kolide.TargetMetrics, error)
 
 type TargetStore struct {
 	CountHostsInTargetsFunc        CountHostsInTargetsFunc
 	CountHostsInTargetsFuncInvoked bool
 }
 
-func (s *TargetStore) CountHostsInTargets(hostIDs, labelIDs []uint, now time.Time) (kolide.TargetMetrics, error) {
+func (s *TargetStore) CountHostsInTargets(hostIDs []uint, labelIDs []uint, tagIDs []uint, now time.Time) (kolide.TargetMetrics, error) {
 	s.CountHostsInTargetsFuncInvoked = true
-	return s.CountHostsInTargetsFunc(hostIDs, labelIDs, now)
+	return s.CountHostsInTargetsFunc(hostIDs, labelIDs, tagIDs, now)
 }
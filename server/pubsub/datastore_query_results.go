@@ -0,0 +1,141 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+const (
+	// liveQueryReaderHeartbeat is how often readers record that they are
+	// still active.
+	liveQueryReaderHeartbeat = 1 * time.Minute
+	// liveQueryReaderTimeout is how long a reader that stopped recording
+	// its activity (eg. because its Fleet instance exited) is considered
+	// active.
+	liveQueryReaderTimeout = 5 * liveQueryReaderHeartbeat
+	// liveQueryResultsPageSize is the maximum number of results read per
+	// query to the datastore.
+	liveQueryResultsPageSize = 1000
+)
+
+type datastoreQueryResults struct {
+	ds           kolide.LiveQueryResultStore
+	pollInterval time.Duration
+}
+
+var _ kolide.QueryResultStore = &datastoreQueryResults{}
+
+// NewDatastoreQueryResults creates a new implementation of the
+// QueryResultStore interface that passes results through the datastore, so
+// that live queries can run across Fleet instances without Redis. Readers
+// check for new results every pollInterval.
+func NewDatastoreQueryResults(ds kolide.LiveQueryResultStore, pollInterval time.Duration) *datastoreQueryResults {
+	return &datastoreQueryResults{ds: ds, pollInterval: pollInterval}
+}
+
+// CleanupDatastoreQueryResults removes the inactive readers and the expired
+// results stored by the datastore QueryResultStore.
+func CleanupDatastoreQueryResults(ds kolide.LiveQueryResultStore, now time.Time) error {
+	return ds.CleanupLiveQueryResults(now.Add(-liveQueryReaderTimeout), now.Add(-resultHistoryTTL))
+}
+
+func (d *datastoreQueryResults) WriteResult(result kolide.DistributedQueryResult) error {
+	jsonVal, err := json.Marshal(&result)
+	if err != nil {
+		return errors.Wrap(err, "marshalling JSON for result")
+	}
+
+	saved, err := d.ds.NewLiveQueryResult(result.DistributedQueryCampaignID, jsonVal,
+		time.Now().Add(-liveQueryReaderTimeout))
+	if err != nil {
+		return errors.Wrap(err, "saving result")
+	}
+	if !saved {
+		return noSubscriberError{strconv.Itoa(int(result.DistributedQueryCampaignID))}
+	}
+	return nil
+}
+
+func (d *datastoreQueryResults) ReadChannel(ctx context.Context, query kolide.DistributedQueryCampaign) (<-chan interface{}, error) {
+	readerID, err := d.ds.NewLiveQueryReader(query.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "registering reader")
+	}
+
+	outChannel := make(chan interface{})
+	go func() {
+		defer close(outChannel)
+		// Failing to remove the reader only delays the cleanup of the
+		// results until the reader times out.
+		defer d.ds.DeleteLiveQueryReader(readerID)
+
+		send := func(val interface{}) bool {
+			select {
+			case outChannel <- val:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		ticker := time.NewTicker(d.pollInterval)
+		defer ticker.Stop()
+		lastHeartbeat := time.Now()
+
+		// The cursor is the sequence number of the last result read. It
+		// starts before the first result, so that readers joining the
+		// campaign later also receive the results written so far.
+		var cursor uint
+		for {
+			results, err := d.ds.ListLiveQueryResults(query.ID, cursor, liveQueryResultsPageSize)
+			if err != nil && !send(errors.Wrap(err, "reading results")) {
+				return
+			}
+			for _, stored := range results {
+				cursor = stored.Seq
+				var res kolide.DistributedQueryResult
+				if err := json.Unmarshal(stored.Result, &res); err != nil {
+					if !send(errors.Wrap(err, "unmarshalling result")) {
+						return
+					}
+					continue
+				}
+				if !send(res) {
+					return
+				}
+			}
+
+			if time.Since(lastHeartbeat) >= liveQueryReaderHeartbeat {
+				if err := d.ds.TouchLiveQueryReader(readerID); err != nil && !send(errors.Wrap(err, "recording reader activity")) {
+					return
+				}
+				lastHeartbeat = time.Now()
+			}
+
+			if len(results) == liveQueryResultsPageSize {
+				// More results are waiting
+				continue
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return outChannel, nil
+}
+
+// HealthCheck verifies that the datastore is healthy, when it can report its
+// health.
+func (d *datastoreQueryResults) HealthCheck() error {
+	if hc, ok := d.ds.(interface{ HealthCheck() error }); ok {
+		return hc.HealthCheck()
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package pubsub
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memLiveQueryResultStore is an in-memory kolide.LiveQueryResultStore that
+// stands in for the MySQL datastore.
+type memLiveQueryResultStore struct {
+	mtx     sync.Mutex
+	nextID  uint
+	seqs    map[uint]uint
+	readers map[uint]*liveQueryReader
+	results []*kolide.LiveQueryResult
+}
+
+type liveQueryReader struct {
+	campaignID uint
+	updatedAt  time.Time
+}
+
+func newMemLiveQueryResultStore() *memLiveQueryResultStore {
+	return &memLiveQueryResultStore{seqs: map[uint]uint{}, readers: map[uint]*liveQueryReader{}}
+}
+
+func (m *memLiveQueryResultStore) hasReaders(campaignID uint, activeSince time.Time) bool {
+	for _, r := range m.readers {
+		if r.campaignID == campaignID && !r.updatedAt.Before(activeSince) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *memLiveQueryResultStore) NewLiveQueryReader(campaignID uint) (uint, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.nextID++
+	m.readers[m.nextID] = &liveQueryReader{campaignID: campaignID, updatedAt: time.Now()}
+	return m.nextID, nil
+}
+
+func (m *memLiveQueryResultStore) TouchLiveQueryReader(id uint) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	r, ok := m.readers[id]
+	if !ok {
+		return errors.New("reader not found")
+	}
+	r.updatedAt = time.Now()
+	return nil
+}
+
+func (m *memLiveQueryResultStore) DeleteLiveQueryReader(id uint) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	r, ok := m.readers[id]
+	if !ok {
+		return errors.New("reader not found")
+	}
+	delete(m.readers, id)
+	if !m.hasReaders(r.campaignID, time.Time{}) {
+		m.deleteResults(func(res *kolide.LiveQueryResult) bool { return res.CampaignID == r.campaignID })
+	}
+	return nil
+}
+
+func (m *memLiveQueryResultStore) deleteResults(match func(*kolide.LiveQueryResult) bool) {
+	var kept []*kolide.LiveQueryResult
+	for _, res := range m.results {
+		if !match(res) {
+			kept = append(kept, res)
+		}
+	}
+	m.results = kept
+}
+
+func (m *memLiveQueryResultStore) NewLiveQueryResult(campaignID uint, result []byte, activeSince time.Time) (bool, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	if !m.hasReaders(campaignID, activeSince) {
+		return false, nil
+	}
+	m.nextID++
+	m.seqs[campaignID]++
+	m.results = append(m.results, &kolide.LiveQueryResult{
+		ID:         m.nextID,
+		CampaignID: campaignID,
+		Seq:        m.seqs[campaignID],
+		Result:     result,
+		CreatedAt:  time.Now(),
+	})
+	return true, nil
+}
+
+func (m *memLiveQueryResultStore) ListLiveQueryResults(campaignID uint, afterSeq uint, limit uint) ([]*kolide.LiveQueryResult, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	var results []*kolide.LiveQueryResult
+	for _, res := range m.results {
+		if res.CampaignID == campaignID && res.Seq > afterSeq && uint(len(results)) < limit {
+			results = append(results, res)
+		}
+	}
+	return results, nil
+}
+
+func (m *memLiveQueryResultStore) CleanupLiveQueryResults(activeSince, createdBefore time.Time) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	for id, r := range m.readers {
+		if r.updatedAt.Before(activeSince) {
+			delete(m.readers, id)
+		}
+	}
+	m.deleteResults(func(res *kolide.LiveQueryResult) bool {
+		return res.CreatedAt.Before(createdBefore) || !m.hasReaders(res.CampaignID, time.Time{})
+	})
+	return nil
+}
+
+func TestDatastore(t *testing.T) {
+	for _, f := range testFunctions {
+		f := f
+		t.Run(functionName(f), func(t *testing.T) {
+			t.Parallel()
+			store := NewDatastoreQueryResults(newMemLiveQueryResultStore(), 10*time.Millisecond)
+			f(t, store)
+		})
+	}
+}
+
+func TestCleanupDatastoreQueryResults(t *testing.T) {
+	ds := newMemLiveQueryResultStore()
+	store := NewDatastoreQueryResults(ds, 10*time.Millisecond)
+
+	readerID, err := ds.NewLiveQueryReader(1)
+	require.Nil(t, err)
+	require.Nil(t, store.WriteResult(kolide.DistributedQueryResult{DistributedQueryCampaignID: 1}))
+
+	// Active readers and recent results are kept
+	require.Nil(t, CleanupDatastoreQueryResults(ds, time.Now()))
+	results, err := ds.ListLiveQueryResults(1, 0, 10)
+	require.Nil(t, err)
+	assert.Len(t, results, 1)
+
+	// Readers that stopped recording their activity time out
+	require.Nil(t, CleanupDatastoreQueryResults(ds, time.Now().Add(liveQueryReaderTimeout+time.Minute)))
+	results, err = ds.ListLiveQueryResults(1, 0, 10)
+	require.Nil(t, err)
+	assert.Empty(t, results)
+	assert.NotNil(t, ds.TouchLiveQueryReader(readerID))
+}
@@ -1,6 +1,11 @@
 // Package pubsub implements pub/sub interfaces defined in package kolide.
 package pubsub
 
+// resultHistoryLimit is the number of results retained for the readers
+// joining a campaign later. Older results are dropped, and these readers are
+// told how many.
+const resultHistoryLimit = 10000
+
 // Error defines the interface of errors specific to the pubsub package
 type Error interface {
 	error
@@ -9,8 +9,25 @@ import (
 )
 
 type inmemQueryResults struct {
-	resultChannels map[uint]chan interface{}
-	channelMutex   sync.Mutex
+	campaigns    map[uint]*inmemCampaign
+	channelMutex sync.Mutex
+	historyLimit int
+}
+
+// inmemCampaign holds the readers of a campaign, along with the last results
+// written while it has readers so that readers joining later also receive
+// them.
+type inmemCampaign struct {
+	results     []interface{}
+	dropped     int
+	subscribers map[*inmemSubscriber]struct{}
+}
+
+// inmemSubscriber queues the results for a single reader, so that writing a
+// result does not wait on the readers.
+type inmemSubscriber struct {
+	pending []interface{}
+	notify  chan struct{}
 }
 
 var _ kolide.QueryResultStore = &inmemQueryResults{}
@@ -18,45 +35,104 @@ var _ kolide.QueryResultStore = &inmemQueryResults{}
 // NewInmemQueryResults initializes a new in-memory implementation of the
 // QueryResultStore interface.
 func NewInmemQueryResults() *inmemQueryResults {
-	return &inmemQueryResults{resultChannels: map[uint]chan interface{}{}}
+	return &inmemQueryResults{
+		campaigns:    map[uint]*inmemCampaign{},
+		historyLimit: resultHistoryLimit,
+	}
 }
 
-func (im *inmemQueryResults) getChannel(id uint) chan interface{} {
+func (im *inmemQueryResults) subscribe(id uint) *inmemSubscriber {
 	im.channelMutex.Lock()
 	defer im.channelMutex.Unlock()
 
-	channel, ok := im.resultChannels[id]
+	campaign, ok := im.campaigns[id]
 	if !ok {
-		channel = make(chan interface{})
-		im.resultChannels[id] = channel
+		campaign = &inmemCampaign{subscribers: map[*inmemSubscriber]struct{}{}}
+		im.campaigns[id] = campaign
+	}
+
+	sub := &inmemSubscriber{notify: make(chan struct{}, 1)}
+	if campaign.dropped > 0 {
+		sub.pending = append(sub.pending, kolide.ResultHistoryTruncated{Dropped: campaign.dropped})
 	}
-	return channel
+	sub.pending = append(sub.pending, campaign.results...)
+	campaign.subscribers[sub] = struct{}{}
+	return sub
+}
+
+func (im *inmemQueryResults) unsubscribe(id uint, sub *inmemSubscriber) {
+	im.channelMutex.Lock()
+	defer im.channelMutex.Unlock()
+
+	campaign, ok := im.campaigns[id]
+	if !ok {
+		return
+	}
+	delete(campaign.subscribers, sub)
+	// Results are only retained while the campaign has readers
+	if len(campaign.subscribers) == 0 {
+		delete(im.campaigns, id)
+	}
+}
+
+// next returns the results queued for the subscriber.
+func (im *inmemQueryResults) next(sub *inmemSubscriber) []interface{} {
+	im.channelMutex.Lock()
+	defer im.channelMutex.Unlock()
+
+	pending := sub.pending
+	sub.pending = nil
+	return pending
 }
 
 func (im *inmemQueryResults) WriteResult(result kolide.DistributedQueryResult) error {
-	channel, ok := im.resultChannels[result.DistributedQueryCampaignID]
+	im.channelMutex.Lock()
+	defer im.channelMutex.Unlock()
+
+	campaign, ok := im.campaigns[result.DistributedQueryCampaignID]
 	if !ok {
 		return noSubscriberError{strconv.Itoa(int(result.DistributedQueryCampaignID))}
 	}
 
-	select {
-	case channel <- result:
-		// intentionally do nothing
-	default:
-		return noSubscriberError{strconv.Itoa(int(result.DistributedQueryCampaignID))}
+	campaign.results = append(campaign.results, result)
+	if len(campaign.results) > im.historyLimit {
+		campaign.results = campaign.results[1:]
+		campaign.dropped++
+	}
+	for sub := range campaign.subscribers {
+		sub.pending = append(sub.pending, result)
+		select {
+		case sub.notify <- struct{}{}:
+		default:
+			// The reader was already notified
+		}
 	}
 
 	return nil
 }
 
 func (im *inmemQueryResults) ReadChannel(ctx context.Context, query kolide.DistributedQueryCampaign) (<-chan interface{}, error) {
-	channel := im.getChannel(query.ID)
+	sub := im.subscribe(query.ID)
+	channel := make(chan interface{})
 	go func() {
-		<-ctx.Done()
-		close(channel)
-		im.channelMutex.Lock()
-		delete(im.resultChannels, query.ID)
-		im.channelMutex.Unlock()
+		defer close(channel)
+		defer im.unsubscribe(query.ID, sub)
+
+		for {
+			for _, res := range im.next(sub) {
+				select {
+				case channel <- res:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-sub.notify:
+			case <-ctx.Done():
+				return
+			}
+		}
 	}()
 	return channel, nil
 }
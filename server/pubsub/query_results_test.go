@@ -41,6 +41,8 @@ func functionName(f func(*testing.T, kolide.QueryResultStore)) string {
 var testFunctions = [...]func(*testing.T, kolide.QueryResultStore){
 	testQueryResultsStore,
 	testQueryResultsStoreErrors,
+	testQueryResultsStoreMultipleReaders,
+	testQueryResultsStoreHistoryLimit,
 }
 
 func TestRedis(t *testing.T) {
@@ -287,3 +289,167 @@ func testQueryResultsStore(t *testing.T, store kolide.QueryResultStore) {
 	assert.EqualValues(t, expected1, results1)
 	assert.EqualValues(t, expected2, results2)
 }
+
+func testQueryResultsStoreMultipleReaders(t *testing.T, store kolide.QueryResultStore) {
+	campaign := kolide.DistributedQueryCampaign{ID: 3}
+	result := func(hostID uint) kolide.DistributedQueryResult {
+		return kolide.DistributedQueryResult{
+			DistributedQueryCampaignID: campaign.ID,
+			Rows:                       []map[string]string{{"foo": "bar"}},
+			Host: kolide.Host{
+				ID: hostID,
+				UpdateCreateTimestamps: kolide.UpdateCreateTimestamps{
+					UpdateTimestamp: kolide.UpdateTimestamp{
+						UpdatedAt: time.Now().UTC(),
+					},
+					CreateTimestamp: kolide.CreateTimestamp{
+						CreatedAt: time.Now().UTC(),
+					},
+				},
+				DetailUpdateTime: time.Now().UTC(),
+				SeenTime:         time.Now().UTC(),
+			},
+		}
+	}
+	read := func(channel <-chan interface{}, n int) []kolide.DistributedQueryResult {
+		var results []kolide.DistributedQueryResult
+		for len(results) < n {
+			select {
+			case res := <-channel:
+				if res, ok := res.(kolide.DistributedQueryResult); ok {
+					results = append(results, res)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("Timed out waiting for results")
+			}
+		}
+		return results
+	}
+
+	// Results are not retained without readers
+	err := store.WriteResult(result(1))
+	require.NotNil(t, err)
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	defer cancel1()
+	channel1, err := store.ReadChannel(ctx1, campaign)
+	require.Nil(t, err)
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	defer cancel2()
+	channel2, err := store.ReadChannel(ctx2, campaign)
+	require.Nil(t, err)
+
+	// Every reader receives every result
+	expected := []kolide.DistributedQueryResult{result(2), result(3)}
+	for _, res := range expected {
+		require.Nil(t, store.WriteResult(res))
+	}
+	assert.EqualValues(t, expected, read(channel1, 2))
+	assert.EqualValues(t, expected, read(channel2, 2))
+
+	// A reader joining later first receives the results written so far
+	ctx3, cancel3 := context.WithCancel(context.Background())
+	defer cancel3()
+	channel3, err := store.ReadChannel(ctx3, campaign)
+	require.Nil(t, err)
+	late := result(4)
+	require.Nil(t, store.WriteResult(late))
+	expected = append(expected, late)
+	assert.EqualValues(t, expected, read(channel3, 3))
+	assert.EqualValues(t, expected[2:], read(channel1, 1))
+	assert.EqualValues(t, expected[2:], read(channel2, 1))
+
+	// Once all readers are gone, there is no subscriber left
+	cancel1()
+	cancel2()
+	cancel3()
+	time.Sleep(100 * time.Millisecond)
+	err = store.WriteResult(result(5))
+	require.NotNil(t, err)
+	castErr, ok := err.(Error)
+	if assert.True(t, ok, "err should be pubsub.Error") {
+		assert.True(t, castErr.NoSubscriber(), "NoSubscriber() should be true")
+	}
+}
+
+// setHistoryLimit changes the number of results the store retains for readers
+// joining later, returning the previous limit, or false if the store does not
+// limit its history.
+func setHistoryLimit(store kolide.QueryResultStore, limit int) (int, bool) {
+	switch store := store.(type) {
+	case *inmemQueryResults:
+		store.historyLimit, limit = limit, store.historyLimit
+	case *redisQueryResults:
+		store.historyLimit, limit = limit, store.historyLimit
+	default:
+		return 0, false
+	}
+	return limit, true
+}
+
+func testQueryResultsStoreHistoryLimit(t *testing.T, store kolide.QueryResultStore) {
+	prev, ok := setHistoryLimit(store, 2)
+	if !ok {
+		t.Skip("results are retained by the datastore")
+	}
+	defer setHistoryLimit(store, prev)
+
+	campaign := kolide.DistributedQueryCampaign{ID: 4}
+	result := func(hostID uint) kolide.DistributedQueryResult {
+		return kolide.DistributedQueryResult{
+			DistributedQueryCampaignID: campaign.ID,
+			Rows:                       []map[string]string{{"foo": "bar"}},
+			Host: kolide.Host{
+				ID: hostID,
+				UpdateCreateTimestamps: kolide.UpdateCreateTimestamps{
+					UpdateTimestamp: kolide.UpdateTimestamp{
+						UpdatedAt: time.Now().UTC(),
+					},
+					CreateTimestamp: kolide.CreateTimestamp{
+						CreatedAt: time.Now().UTC(),
+					},
+				},
+				DetailUpdateTime: time.Now().UTC(),
+				SeenTime:         time.Now().UTC(),
+			},
+		}
+	}
+	read := func(channel <-chan interface{}, n int) []interface{} {
+		var values []interface{}
+		for len(values) < n {
+			select {
+			case val := <-channel:
+				values = append(values, val)
+			case <-time.After(5 * time.Second):
+				t.Fatal("Timed out waiting for results")
+			}
+		}
+		return values
+	}
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	defer cancel1()
+	channel1, err := store.ReadChannel(ctx1, campaign)
+	require.Nil(t, err)
+
+	var written []interface{}
+	for i := uint(1); i <= 4; i++ {
+		res := result(i)
+		require.Nil(t, store.WriteResult(res))
+		written = append(written, res)
+	}
+	assert.EqualValues(t, written, read(channel1, 4))
+
+	// A reader joining later is told that the oldest results were dropped
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	defer cancel2()
+	channel2, err := store.ReadChannel(ctx2, campaign)
+	require.Nil(t, err)
+	expected := append([]interface{}{kolide.ResultHistoryTruncated{Dropped: 2}}, written[2:]...)
+	assert.EqualValues(t, expected, read(channel2, 3))
+
+	late := result(5)
+	require.Nil(t, store.WriteResult(late))
+	assert.EqualValues(t, []interface{}{late}, read(channel1, 1))
+	assert.EqualValues(t, []interface{}{late}, read(channel2, 1))
+}
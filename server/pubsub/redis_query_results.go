@@ -13,7 +13,8 @@ import (
 
 type redisQueryResults struct {
 	// connection pool
-	pool *redis.Pool
+	pool         *redis.Pool
+	historyLimit int
 }
 
 var _ kolide.QueryResultStore = &redisQueryResults{}
@@ -50,29 +51,78 @@ func NewRedisPool(server, password string) *redis.Pool {
 // NewRedisQueryResults creats a new Redis implementation of the
 // QueryResultStore interface using the provided Redis connection pool.
 func NewRedisQueryResults(pool *redis.Pool) *redisQueryResults {
-	return &redisQueryResults{pool: pool}
+	return &redisQueryResults{pool: pool, historyLimit: resultHistoryLimit}
 }
 
 func pubSubForID(id uint) string {
 	return fmt.Sprintf("results_%d", id)
 }
 
+// historyForID is the list retaining the last results of a campaign, so that
+// readers joining the campaign later also receive them.
+func historyForID(id uint) string {
+	return fmt.Sprintf("results_%d_history", id)
+}
+
+// seqForID is the counter of the results written for a campaign, including
+// the results trimmed from its history.
+func seqForID(id uint) string {
+	return fmt.Sprintf("results_%d_seq", id)
+}
+
+// resultHistoryTTL bounds how long the results of a campaign are retained.
+const resultHistoryTTL = 24 * time.Hour
+
+// redisResultMessage is the message published for each result. Seq is the
+// position of the result among the results of the campaign, which lets readers
+// skip the results they already received from the history.
+type redisResultMessage struct {
+	Seq    int64           `json:"seq"`
+	Result json.RawMessage `json:"result"`
+}
+
 func (r *redisQueryResults) WriteResult(result kolide.DistributedQueryResult) error {
 	conn := r.pool.Get()
 	defer conn.Close()
 
 	channelName := pubSubForID(result.DistributedQueryCampaignID)
+	historyName := historyForID(result.DistributedQueryCampaignID)
+	seqName := seqForID(result.DistributedQueryCampaignID)
 
 	jsonVal, err := json.Marshal(&result)
 	if err != nil {
 		return errors.Wrap(err, "marshalling JSON for result")
 	}
 
-	n, err := redis.Int(conn.Do("PUBLISH", channelName, string(jsonVal)))
+	conn.Send("MULTI")
+	conn.Send("RPUSH", historyName, jsonVal)
+	conn.Send("LTRIM", historyName, -r.historyLimit, -1)
+	conn.Send("INCR", seqName)
+	conn.Send("EXPIRE", historyName, int(resultHistoryTTL.Seconds()))
+	conn.Send("EXPIRE", seqName, int(resultHistoryTTL.Seconds()))
+	replies, err := redis.Values(conn.Do("EXEC"))
+	if err != nil {
+		return errors.Wrap(err, "RPUSH failed to list "+historyName)
+	}
+	seq, err := redis.Int64(replies[2], nil)
+	if err != nil {
+		return errors.Wrap(err, "INCR failed for "+seqName)
+	}
+
+	msg, err := json.Marshal(redisResultMessage{Seq: seq, Result: jsonVal})
+	if err != nil {
+		return errors.Wrap(err, "marshalling JSON for message")
+	}
+
+	n, err := redis.Int(conn.Do("PUBLISH", channelName, string(msg)))
 	if err != nil {
 		return errors.Wrap(err, "PUBLISH failed to channel "+channelName)
 	}
 	if n == 0 {
+		// Results are only retained while the campaign has readers
+		if _, err := conn.Do("DEL", historyName, seqName); err != nil {
+			return errors.Wrap(err, "DEL failed for list "+historyName)
+		}
 		return noSubscriberError{channelName}
 	}
 
@@ -113,7 +163,22 @@ func (r *redisQueryResults) ReadChannel(ctx context.Context, query kolide.Distri
 	conn := redis.PubSubConn{Conn: r.pool.Get()}
 
 	pubSubName := pubSubForID(query.ID)
-	conn.Subscribe(pubSubName)
+	if err := conn.Subscribe(pubSubName); err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "SUBSCRIBE failed to channel "+pubSubName)
+	}
+	// Wait for the subscription before reading the history, so that no
+	// result written in between is missed.
+	if err, ok := conn.Receive().(error); ok {
+		conn.Close()
+		return nil, errors.Wrap(err, "SUBSCRIBE failed to channel "+pubSubName)
+	}
+
+	history, seen, err := r.history(query.ID)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
 
 	msgChannel := make(chan interface{})
 	// Run a separate goroutine feeding redis messages into
@@ -124,9 +189,33 @@ func (r *redisQueryResults) ReadChannel(ctx context.Context, query kolide.Distri
 		defer close(outChannel)
 		defer conn.Close()
 
+		send := func(val interface{}) {
+			select {
+			case outChannel <- val:
+			case <-ctx.Done():
+			}
+		}
+		sendResult := func(data []byte) {
+			var res kolide.DistributedQueryResult
+			if err := json.Unmarshal(data, &res); err != nil {
+				send(errors.Wrap(err, "unmarshalling result"))
+				return
+			}
+			send(res)
+		}
+
+		if dropped := seen - int64(len(history)); dropped > 0 {
+			send(kolide.ResultHistoryTruncated{Dropped: int(dropped)})
+		}
+		for _, data := range history {
+			sendResult(data)
+		}
+
+		done := ctx.Done()
 		for {
 			// Loop reading messages from conn.Receive() (via
-			// msgChannel) until the context is cancelled.
+			// msgChannel) until the context is cancelled and the
+			// unsubscription completes.
 			select {
 			case msg, ok := <-msgChannel:
 				if !ok {
@@ -134,19 +223,23 @@ func (r *redisQueryResults) ReadChannel(ctx context.Context, query kolide.Distri
 				}
 				switch msg := msg.(type) {
 				case redis.Message:
-					var res kolide.DistributedQueryResult
-					err := json.Unmarshal(msg.Data, &res)
-					if err != nil {
-						outChannel <- err
+					var resMsg redisResultMessage
+					if err := json.Unmarshal(msg.Data, &resMsg); err != nil {
+						send(errors.Wrap(err, "unmarshalling message"))
+						continue
 					}
-					outChannel <- res
+					if resMsg.Seq <= seen {
+						// Already sent from the history
+						continue
+					}
+					sendResult(resMsg.Result)
 				case error:
-					outChannel <- errors.Wrap(msg, "reading from redis")
+					send(errors.Wrap(msg, "reading from redis"))
 				}
 
-			case <-ctx.Done():
+			case <-done:
 				conn.Unsubscribe()
-
+				done = nil
 			}
 		}
 
@@ -154,6 +247,33 @@ func (r *redisQueryResults) ReadChannel(ctx context.Context, query kolide.Distri
 	return outChannel, nil
 }
 
+// history returns the results retained for the campaign, and the number of
+// results written for the campaign so far.
+func (r *redisQueryResults) history(id uint) ([][]byte, int64, error) {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	historyName := historyForID(id)
+	seqName := seqForID(id)
+
+	conn.Send("MULTI")
+	conn.Send("LRANGE", historyName, 0, -1)
+	conn.Send("GET", seqName)
+	replies, err := redis.Values(conn.Do("EXEC"))
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "LRANGE failed for list "+historyName)
+	}
+	history, err := redis.ByteSlices(replies[0], nil)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "LRANGE failed for list "+historyName)
+	}
+	seq, err := redis.Int64(replies[1], nil)
+	if err != nil && err != redis.ErrNil {
+		return nil, 0, errors.Wrap(err, "GET failed for "+seqName)
+	}
+	return history, seq, nil
+}
+
 // HealthCheck verifies that the redis backend can be pinged, returning an error
 // otherwise.
 func (r *redisQueryResults) HealthCheck() error {
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/kolide/fleet/server/contexts/viewer"
+	"github.com/kolide/fleet/server/kolide"
+)
+
+// activityMiddleware records the administrative actions performed through
+// the service in the activity log.
+type activityMiddleware struct {
+	kolide.Service
+	ds kolide.ActivityStore
+	// writer receives a copy of each activity, it is nil when activities are
+	// only stored in the datastore.
+	writer kolide.JSONLogger
+	logger kitlog.Logger
+}
+
+// NewActivityService takes an existing service and adds a wrapper that
+// records the administrative actions in the activity log. The activities are
+// also written to writer if it is not nil.
+func NewActivityService(svc kolide.Service, ds kolide.ActivityStore, writer kolide.JSONLogger, logger kitlog.Logger) kolide.Service {
+	return activityMiddleware{Service: svc, ds: ds, writer: writer, logger: logger}
+}
+
+// record stores the activity, performed by the viewer of the context. The
+// action has already been performed, so failing to record it is logged
+// rather than returned.
+func (mw activityMiddleware) record(ctx context.Context, activity kolide.Activity, details interface{}) {
+	if vc, ok := viewer.FromContext(ctx); ok && vc.User != nil && activity.ActorID == nil {
+		activity.ActorID = uintPtr(vc.UserID())
+		activity.ActorName = vc.Username()
+	}
+	if details != nil {
+		raw, err := json.Marshal(details)
+		if err != nil {
+			level.Info(mw.logger).Log("msg", "marshal activity details", "type", activity.Type, "err", err)
+			return
+		}
+		activity.Details = (*json.RawMessage)(&raw)
+	}
+
+	saved, err := mw.ds.NewActivity(&activity)
+	if err != nil {
+		level.Info(mw.logger).Log("msg", "record activity", "type", activity.Type, "err", err)
+		return
+	}
+	if mw.writer == nil {
+		return
+	}
+	entry, err := json.Marshal(saved)
+	if err != nil {
+		level.Info(mw.logger).Log("msg", "marshal activity", "type", activity.Type, "err", err)
+		return
+	}
+	if err := mw.writer.Write(ctx, []json.RawMessage{entry}); err != nil {
+		level.Info(mw.logger).Log("msg", "write activity", "type", activity.Type, "err", err)
+	}
+}
+
+// activityCollectorKey is the context key of the activities added by the
+// service while handling a request.
+type activityCollectorKey struct{}
+
+// pendingActivity is an activity added by the service, not yet recorded.
+type pendingActivity struct {
+	activity kolide.Activity
+	details  interface{}
+}
+
+// withActivityCollector returns a context in which the activities added by
+// the service with addActivity are collected, for the activity middleware
+// to record them.
+func withActivityCollector(ctx context.Context) (context.Context, *[]pendingActivity) {
+	activities := &[]pendingActivity{}
+	return context.WithValue(ctx, activityCollectorKey{}, activities), activities
+}
+
+// addActivity adds an activity for an action that the service performs on
+// its own while handling a request (such as provisioning the user signing
+// on), which the activity middleware can not see from the request and
+// response. The activity is dropped if the context has no collector.
+func addActivity(ctx context.Context, activity kolide.Activity, details interface{}) {
+	if activities, ok := ctx.Value(activityCollectorKey{}).(*[]pendingActivity); ok {
+		*activities = append(*activities, pendingActivity{activity: activity, details: details})
+	}
+}
+
+// activityChange is the change of a field in the details of an activity.
+// Secret fields are recorded as changed without their values.
+type activityChange struct {
+	Old    interface{} `json:"old"`
+	New    interface{} `json:"new"`
+	Secret bool        `json:"secret,omitempty"`
+}
+
+// diffFields returns the changes between the fields of two structs of the
+// same type, keyed by their JSON (or else database) names. The values of the
+// secret fields are left out.
+func diffFields(old, new interface{}, secrets ...string) map[string]activityChange {
+	changes := map[string]activityChange{}
+	oldValue := reflect.Indirect(reflect.ValueOf(old))
+	newValue := reflect.Indirect(reflect.ValueOf(new))
+	if !oldValue.IsValid() || !newValue.IsValid() || oldValue.Type() != newValue.Type() {
+		return changes
+	}
+	for i := 0; i < oldValue.NumField(); i++ {
+		field := oldValue.Type().Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" || field.Anonymous || field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Tag.Get("db")
+		}
+		if name == "" {
+			name = field.Name
+		}
+		o, n := oldValue.Field(i).Interface(), newValue.Field(i).Interface()
+		if reflect.DeepEqual(o, n) {
+			continue
+		}
+		change := activityChange{Old: o, New: n}
+		for _, secret := range secrets {
+			if secret == name {
+				change = activityChange{Secret: true}
+			}
+		}
+		changes[name] = change
+	}
+	return changes
+}
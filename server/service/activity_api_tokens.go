@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (mw activityMiddleware) NewServiceAccount(ctx context.Context, p kolide.ServiceAccountPayload) (*kolide.User, error) {
+	user, err := mw.Service.NewServiceAccount(ctx, p)
+	if err == nil {
+		mw.record(ctx, userActivity("created_service_account", user), map[string]interface{}{"role": user.Role})
+	}
+	return user, err
+}
+
+func (mw activityMiddleware) NewAPIToken(ctx context.Context, userID uint, p kolide.APITokenPayload) (string, *kolide.APIToken, error) {
+	token, apiToken, err := mw.Service.NewAPIToken(ctx, userID, p)
+	if err == nil {
+		mw.record(ctx, kolide.Activity{
+			Type:       "created_api_token",
+			TargetType: "api_token",
+			TargetID:   uintPtr(apiToken.ID),
+			TargetName: apiToken.Name,
+		}, map[string]interface{}{
+			"user_id":    userID,
+			"role":       apiToken.Role,
+			"expires_at": apiToken.ExpiresAt,
+		})
+	}
+	return token, apiToken, err
+}
+
+func (mw activityMiddleware) DeleteAPIToken(ctx context.Context, userID, id uint) error {
+	err := mw.Service.DeleteAPIToken(ctx, userID, id)
+	if err == nil {
+		mw.record(ctx, kolide.Activity{
+			Type:       "deleted_api_token",
+			TargetType: "api_token",
+			TargetID:   uintPtr(id),
+		}, map[string]interface{}{"user_id": userID})
+	}
+	return err
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (mw activityMiddleware) NewAppConfig(ctx context.Context, p kolide.AppConfigPayload) (*kolide.AppConfig, error) {
+	config, err := mw.Service.NewAppConfig(ctx, p)
+	if err == nil {
+		mw.record(ctx, kolide.Activity{Type: "created_app_config", TargetType: "app_config"}, nil)
+	}
+	return config, err
+}
+
+func (mw activityMiddleware) ModifyAppConfig(ctx context.Context, p kolide.AppConfigPayload) (*kolide.AppConfig, error) {
+	var old kolide.AppConfig
+	if before, err := mw.Service.AppConfig(ctx); err == nil {
+		old = *before
+	}
+	config, err := mw.Service.ModifyAppConfig(ctx, p)
+	if err == nil {
+		mw.record(ctx, kolide.Activity{Type: "modified_app_config", TargetType: "app_config"}, diffFields(&old, config, "smtp_password", "oidc_client_secret"))
+	}
+	return config, err
+}
+
+func (mw activityMiddleware) ApplyEnrollSecretSpec(ctx context.Context, spec *kolide.EnrollSecretSpec) error {
+	err := mw.Service.ApplyEnrollSecretSpec(ctx, spec)
+	if err == nil {
+		// The secrets themselves are not recorded
+		active := map[string]bool{}
+		for _, secret := range spec.Secrets {
+			active[secret.Name] = secret.Active
+		}
+		mw.record(ctx, kolide.Activity{Type: "applied_enroll_secret_spec", TargetType: "enroll_secret"}, map[string]interface{}{"active": active})
+	}
+	return err
+}
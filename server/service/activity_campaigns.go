@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (mw activityMiddleware) NewDistributedQueryCampaign(ctx context.Context, queryString string, hosts []uint, labels []uint, tags []uint, opts kolide.CampaignOptions) (*kolide.DistributedQueryCampaign, error) {
+	campaign, err := mw.Service.NewDistributedQueryCampaign(ctx, queryString, hosts, labels, tags, opts)
+	if err == nil {
+		mw.record(ctx, campaignActivity("created_live_query", campaign), map[string]interface{}{
+			"query":     queryString,
+			"host_ids":  hosts,
+			"label_ids": labels,
+			"tag_ids":   tags,
+			"options":   opts,
+		})
+	}
+	return campaign, err
+}
+
+func (mw activityMiddleware) NewDistributedQueryCampaignByNames(ctx context.Context, queryString string, hosts []string, labels []string, tags []string, opts kolide.CampaignOptions) (*kolide.DistributedQueryCampaign, error) {
+	campaign, err := mw.Service.NewDistributedQueryCampaignByNames(ctx, queryString, hosts, labels, tags, opts)
+	if err == nil {
+		mw.record(ctx, campaignActivity("created_live_query", campaign), map[string]interface{}{
+			"query":   queryString,
+			"hosts":   hosts,
+			"labels":  labels,
+			"tags":    tags,
+			"options": opts,
+		})
+	}
+	return campaign, err
+}
+
+func (mw activityMiddleware) CancelDistributedQueryCampaign(ctx context.Context, campaignID uint) (*kolide.DistributedQueryCampaign, error) {
+	campaign, err := mw.Service.CancelDistributedQueryCampaign(ctx, campaignID)
+	if err == nil {
+		mw.record(ctx, campaignActivity("canceled_live_query", campaign), nil)
+	}
+	return campaign, err
+}
+
+func campaignActivity(activityType string, campaign *kolide.DistributedQueryCampaign) kolide.Activity {
+	return kolide.Activity{
+		Type:       activityType,
+		TargetType: "campaign",
+		TargetID:   uintPtr(campaign.ID),
+	}
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (mw activityMiddleware) DeleteHost(ctx context.Context, id uint) error {
+	activity := kolide.Activity{Type: "deleted_host", TargetType: "host", TargetID: uintPtr(id)}
+	if host, err := mw.Service.GetHost(ctx, id); err == nil {
+		activity.TargetName = host.HostName
+	}
+	err := mw.Service.DeleteHost(ctx, id)
+	if err == nil {
+		mw.record(ctx, activity, nil)
+	}
+	return err
+}
+
+func (mw activityMiddleware) DeleteHosts(ctx context.Context, opt kolide.BulkHostDeleteOptions) (*kolide.BulkHostDeleteResult, error) {
+	result, err := mw.Service.DeleteHosts(ctx, opt)
+	if err == nil && result.Deleted > 0 {
+		mw.record(ctx, kolide.Activity{Type: "deleted_hosts", TargetType: "host"}, map[string]interface{}{
+			"host_ids": opt.HostIDs,
+			"label_id": opt.LabelID,
+			"filter":   opt.Filter,
+			"deleted":  result.Deleted,
+		})
+	}
+	return result, err
+}
+
+func (mw activityMiddleware) MergeHosts(ctx context.Context, survivorID, duplicateID uint) (*kolide.Host, error) {
+	host, err := mw.Service.MergeHosts(ctx, survivorID, duplicateID)
+	if err == nil {
+		mw.record(ctx, kolide.Activity{
+			Type:       "merged_hosts",
+			TargetType: "host",
+			TargetID:   uintPtr(host.ID),
+			TargetName: host.HostName,
+		}, map[string]interface{}{"duplicate_id": duplicateID})
+	}
+	return host, err
+}
+
+func (mw activityMiddleware) ExpireHosts(ctx context.Context) ([]uint, error) {
+	hostIDs, err := mw.Service.ExpireHosts(ctx)
+	if err == nil && len(hostIDs) > 0 {
+		// Expiry is run by Fleet itself, so the activity has no actor.
+		mw.record(ctx, kolide.Activity{Type: "expired_hosts", TargetType: "host"}, map[string]interface{}{"host_ids": hostIDs})
+	}
+	return hostIDs, err
+}
+
+func (mw activityMiddleware) ModifyHostTags(ctx context.Context, hostID uint, tags map[string]*string) (map[string]string, error) {
+	result, err := mw.Service.ModifyHostTags(ctx, hostID, tags)
+	if err == nil {
+		mw.record(ctx, kolide.Activity{Type: "modified_host_tags", TargetType: "host", TargetID: uintPtr(hostID)}, map[string]interface{}{"tags": tags})
+	}
+	return result, err
+}
+
+func (mw activityMiddleware) ApplyHostTagSpecs(ctx context.Context, specs []*kolide.HostTagSpec) error {
+	err := mw.Service.ApplyHostTagSpecs(ctx, specs)
+	if err == nil {
+		mw.record(ctx, kolide.Activity{Type: "applied_host_tag_specs", TargetType: "host"}, map[string]interface{}{"specs": specs})
+	}
+	return err
+}
@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (mw activityMiddleware) InviteNewUser(ctx context.Context, payload kolide.InvitePayload) (*kolide.Invite, error) {
+	invite, err := mw.Service.InviteNewUser(ctx, payload)
+	if err == nil {
+		mw.record(ctx, kolide.Activity{
+			Type:       "invited_user",
+			TargetType: "invite",
+			TargetID:   uintPtr(invite.ID),
+			TargetName: invite.Email,
+		}, nil)
+	}
+	return invite, err
+}
+
+func (mw activityMiddleware) DeleteInvite(ctx context.Context, id uint) error {
+	err := mw.Service.DeleteInvite(ctx, id)
+	if err == nil {
+		mw.record(ctx, kolide.Activity{Type: "deleted_invite", TargetType: "invite", TargetID: uintPtr(id)}, nil)
+	}
+	return err
+}
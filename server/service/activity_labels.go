@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (mw activityMiddleware) ApplyLabelSpecs(ctx context.Context, specs []*kolide.LabelSpec) ([]string, error) {
+	warnings, err := mw.Service.ApplyLabelSpecs(ctx, specs)
+	if err == nil {
+		names := make([]string, 0, len(specs))
+		for _, spec := range specs {
+			names = append(names, spec.Name)
+		}
+		mw.record(ctx, kolide.Activity{Type: "applied_label_specs", TargetType: "label"}, map[string]interface{}{"names": names})
+	}
+	return warnings, err
+}
+
+func (mw activityMiddleware) NewLabel(ctx context.Context, p kolide.LabelPayload) (*kolide.Label, error) {
+	label, err := mw.Service.NewLabel(ctx, p)
+	if err == nil {
+		mw.record(ctx, labelActivity("created_label", label), map[string]interface{}{"query": label.Query})
+	}
+	return label, err
+}
+
+func (mw activityMiddleware) ModifyLabel(ctx context.Context, id uint, payload kolide.ModifyLabelPayload) (*kolide.Label, error) {
+	var old kolide.Label
+	if before, err := mw.Service.GetLabel(ctx, id); err == nil {
+		old = *before
+	}
+	label, err := mw.Service.ModifyLabel(ctx, id, payload)
+	if err == nil {
+		mw.record(ctx, labelActivity("modified_label", label), diffFields(&old, label))
+	}
+	return label, err
+}
+
+func (mw activityMiddleware) DeleteLabel(ctx context.Context, name string) error {
+	err := mw.Service.DeleteLabel(ctx, name)
+	if err == nil {
+		mw.record(ctx, kolide.Activity{Type: "deleted_label", TargetType: "label", TargetName: name}, nil)
+	}
+	return err
+}
+
+func (mw activityMiddleware) DeleteLabelByID(ctx context.Context, id uint) error {
+	activity := kolide.Activity{Type: "deleted_label", TargetType: "label", TargetID: uintPtr(id)}
+	if label, err := mw.Service.GetLabel(ctx, id); err == nil {
+		activity.TargetName = label.Name
+	}
+	err := mw.Service.DeleteLabelByID(ctx, id)
+	if err == nil {
+		mw.record(ctx, activity, nil)
+	}
+	return err
+}
+
+func labelActivity(activityType string, label *kolide.Label) kolide.Activity {
+	return kolide.Activity{
+		Type:       activityType,
+		TargetType: "label",
+		TargetID:   uintPtr(label.ID),
+		TargetName: label.Name,
+	}
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (mw activityMiddleware) ApplyOptionsSpec(ctx context.Context, spec *kolide.OptionsSpec) error {
+	err := mw.Service.ApplyOptionsSpec(ctx, spec)
+	if err == nil {
+		mw.record(ctx, kolide.Activity{Type: "applied_options_spec", TargetType: "osquery_options"}, spec)
+	}
+	return err
+}
+
+func (mw activityMiddleware) ModifyOptions(ctx context.Context, req kolide.OptionRequest) ([]kolide.Option, error) {
+	options, err := mw.Service.ModifyOptions(ctx, req)
+	if err == nil {
+		mw.record(ctx, kolide.Activity{Type: "modified_options", TargetType: "osquery_options"}, req)
+	}
+	return options, err
+}
+
+func (mw activityMiddleware) ResetOptions(ctx context.Context) ([]kolide.Option, error) {
+	options, err := mw.Service.ResetOptions(ctx)
+	if err == nil {
+		mw.record(ctx, kolide.Activity{Type: "reset_options", TargetType: "osquery_options"}, nil)
+	}
+	return options, err
+}
+
+func (mw activityMiddleware) ModifyFIM(ctx context.Context, fim kolide.FIMConfig) error {
+	err := mw.Service.ModifyFIM(ctx, fim)
+	if err == nil {
+		mw.record(ctx, kolide.Activity{Type: "modified_fim", TargetType: "fim"}, fim)
+	}
+	return err
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (mw activityMiddleware) ApplyPackSpecs(ctx context.Context, specs []*kolide.PackSpec) ([]string, error) {
+	warnings, err := mw.Service.ApplyPackSpecs(ctx, specs)
+	if err == nil {
+		names := make([]string, 0, len(specs))
+		for _, spec := range specs {
+			names = append(names, spec.Name)
+		}
+		mw.record(ctx, kolide.Activity{Type: "applied_pack_specs", TargetType: "pack"}, map[string]interface{}{"names": names})
+	}
+	return warnings, err
+}
+
+func (mw activityMiddleware) NewPack(ctx context.Context, p kolide.PackPayload) (*kolide.Pack, error) {
+	pack, err := mw.Service.NewPack(ctx, p)
+	if err == nil {
+		mw.record(ctx, packActivity("created_pack", pack), nil)
+	}
+	return pack, err
+}
+
+func (mw activityMiddleware) ModifyPack(ctx context.Context, id uint, p kolide.PackPayload) (*kolide.Pack, error) {
+	var old kolide.Pack
+	if before, err := mw.Service.GetPack(ctx, id); err == nil {
+		old = *before
+	}
+	pack, err := mw.Service.ModifyPack(ctx, id, p)
+	if err == nil {
+		mw.record(ctx, packActivity("modified_pack", pack), diffFields(&old, pack))
+	}
+	return pack, err
+}
+
+func (mw activityMiddleware) DeletePack(ctx context.Context, name string) error {
+	err := mw.Service.DeletePack(ctx, name)
+	if err == nil {
+		mw.record(ctx, kolide.Activity{Type: "deleted_pack", TargetType: "pack", TargetName: name}, nil)
+	}
+	return err
+}
+
+func (mw activityMiddleware) DeletePackByID(ctx context.Context, id uint) error {
+	activity := kolide.Activity{Type: "deleted_pack", TargetType: "pack", TargetID: uintPtr(id)}
+	if pack, err := mw.Service.GetPack(ctx, id); err == nil {
+		activity.TargetName = pack.Name
+	}
+	err := mw.Service.DeletePackByID(ctx, id)
+	if err == nil {
+		mw.record(ctx, activity, nil)
+	}
+	return err
+}
+
+func (mw activityMiddleware) AddLabelToPack(ctx context.Context, lid, pid uint) error {
+	err := mw.Service.AddLabelToPack(ctx, lid, pid)
+	if err == nil {
+		mw.record(ctx, kolide.Activity{Type: "added_label_to_pack", TargetType: "pack", TargetID: uintPtr(pid)}, map[string]interface{}{"label_id": lid})
+	}
+	return err
+}
+
+func (mw activityMiddleware) RemoveLabelFromPack(ctx context.Context, lid, pid uint) error {
+	err := mw.Service.RemoveLabelFromPack(ctx, lid, pid)
+	if err == nil {
+		mw.record(ctx, kolide.Activity{Type: "removed_label_from_pack", TargetType: "pack", TargetID: uintPtr(pid)}, map[string]interface{}{"label_id": lid})
+	}
+	return err
+}
+
+func (mw activityMiddleware) AddHostToPack(ctx context.Context, hid, pid uint) error {
+	err := mw.Service.AddHostToPack(ctx, hid, pid)
+	if err == nil {
+		mw.record(ctx, kolide.Activity{Type: "added_host_to_pack", TargetType: "pack", TargetID: uintPtr(pid)}, map[string]interface{}{"host_id": hid})
+	}
+	return err
+}
+
+func (mw activityMiddleware) RemoveHostFromPack(ctx context.Context, hid, pid uint) error {
+	err := mw.Service.RemoveHostFromPack(ctx, hid, pid)
+	if err == nil {
+		mw.record(ctx, kolide.Activity{Type: "removed_host_from_pack", TargetType: "pack", TargetID: uintPtr(pid)}, map[string]interface{}{"host_id": hid})
+	}
+	return err
+}
+
+func packActivity(activityType string, pack *kolide.Pack) kolide.Activity {
+	return kolide.Activity{
+		Type:       activityType,
+		TargetType: "pack",
+		TargetID:   uintPtr(pack.ID),
+		TargetName: pack.Name,
+	}
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (mw activityMiddleware) ApplyQuerySpecs(ctx context.Context, specs []*kolide.QuerySpec) ([]string, error) {
+	warnings, err := mw.Service.ApplyQuerySpecs(ctx, specs)
+	if err == nil {
+		names := make([]string, 0, len(specs))
+		for _, spec := range specs {
+			names = append(names, spec.Name)
+		}
+		mw.record(ctx, kolide.Activity{Type: "applied_query_specs", TargetType: "query"}, map[string]interface{}{"names": names})
+	}
+	return warnings, err
+}
+
+func (mw activityMiddleware) NewQuery(ctx context.Context, p kolide.QueryPayload) (*kolide.Query, error) {
+	query, err := mw.Service.NewQuery(ctx, p)
+	if err == nil {
+		mw.record(ctx, queryActivity("created_query", query), map[string]interface{}{"query": query.Query})
+	}
+	return query, err
+}
+
+func (mw activityMiddleware) ModifyQuery(ctx context.Context, id uint, p kolide.QueryPayload) (*kolide.Query, error) {
+	var old kolide.Query
+	if before, err := mw.Service.GetQuery(ctx, id); err == nil {
+		old = *before
+	}
+	query, err := mw.Service.ModifyQuery(ctx, id, p)
+	if err == nil {
+		mw.record(ctx, queryActivity("modified_query", query), diffFields(&old, query))
+	}
+	return query, err
+}
+
+func (mw activityMiddleware) DeleteQuery(ctx context.Context, name string) error {
+	err := mw.Service.DeleteQuery(ctx, name)
+	if err == nil {
+		mw.record(ctx, kolide.Activity{Type: "deleted_query", TargetType: "query", TargetName: name}, nil)
+	}
+	return err
+}
+
+func (mw activityMiddleware) DeleteQueryByID(ctx context.Context, id uint) error {
+	activity := kolide.Activity{Type: "deleted_query", TargetType: "query", TargetID: uintPtr(id)}
+	if query, err := mw.Service.GetQuery(ctx, id); err == nil {
+		activity.TargetName = query.Name
+	}
+	err := mw.Service.DeleteQueryByID(ctx, id)
+	if err == nil {
+		mw.record(ctx, activity, nil)
+	}
+	return err
+}
+
+func (mw activityMiddleware) DeleteQueries(ctx context.Context, ids []uint) (uint, error) {
+	n, err := mw.Service.DeleteQueries(ctx, ids)
+	if err == nil {
+		mw.record(ctx, kolide.Activity{Type: "deleted_queries", TargetType: "query"}, map[string]interface{}{"ids": ids, "deleted": n})
+	}
+	return n, err
+}
+
+func (mw activityMiddleware) ScheduleQuery(ctx context.Context, sq *kolide.ScheduledQuery) (*kolide.ScheduledQuery, error) {
+	query, err := mw.Service.ScheduleQuery(ctx, sq)
+	if err == nil {
+		mw.record(ctx, scheduledQueryActivity("scheduled_query", query), map[string]interface{}{
+			"pack_id":  query.PackID,
+			"query_id": query.QueryID,
+			"interval": query.Interval,
+		})
+	}
+	return query, err
+}
+
+func (mw activityMiddleware) ModifyScheduledQuery(ctx context.Context, id uint, p kolide.ScheduledQueryPayload) (*kolide.ScheduledQuery, error) {
+	var old kolide.ScheduledQuery
+	if before, err := mw.Service.GetScheduledQuery(ctx, id); err == nil {
+		old = *before
+	}
+	query, err := mw.Service.ModifyScheduledQuery(ctx, id, p)
+	if err == nil {
+		mw.record(ctx, scheduledQueryActivity("modified_scheduled_query", query), diffFields(&old, query))
+	}
+	return query, err
+}
+
+func (mw activityMiddleware) DeleteScheduledQuery(ctx context.Context, id uint) error {
+	err := mw.Service.DeleteScheduledQuery(ctx, id)
+	if err == nil {
+		mw.record(ctx, kolide.Activity{Type: "deleted_scheduled_query", TargetType: "scheduled_query", TargetID: uintPtr(id)}, nil)
+	}
+	return err
+}
+
+func queryActivity(activityType string, query *kolide.Query) kolide.Activity {
+	return kolide.Activity{
+		Type:       activityType,
+		TargetType: "query",
+		TargetID:   uintPtr(query.ID),
+		TargetName: query.Name,
+	}
+}
+
+func scheduledQueryActivity(activityType string, query *kolide.ScheduledQuery) kolide.Activity {
+	return kolide.Activity{
+		Type:       activityType,
+		TargetType: "scheduled_query",
+		TargetID:   uintPtr(query.ID),
+		TargetName: query.Name,
+	}
+}
@@ -0,0 +1,19 @@
+package service
+
+import (
+	"context"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (mw activityMiddleware) CallbackSSO(ctx context.Context, auth kolide.Auth) (*kolide.SSOSession, error) {
+	ctx, activities := withActivityCollector(ctx)
+	session, err := mw.Service.CallbackSSO(ctx, auth)
+	// Users are provisioned by Fleet itself, so the activities have no
+	// actor. They are recorded even if the sign on fails, as it does for a
+	// user disabled by provisioning.
+	for _, pending := range *activities {
+		mw.record(ctx, pending.activity, pending.details)
+	}
+	return session, err
+}
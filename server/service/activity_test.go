@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/kolide/fleet/server/contexts/viewer"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memJSONLogger struct {
+	logs []json.RawMessage
+}
+
+func (l *memJSONLogger) Write(ctx context.Context, logs []json.RawMessage) error {
+	l.logs = append(l.logs, logs...)
+	return nil
+}
+
+func TestActivityMiddleware(t *testing.T) {
+	ms := new(mock.Store)
+	ms.HostFunc = func(id uint) (*kolide.Host, error) {
+		return &kolide.Host{ID: id, HostName: "foo.local"}, nil
+	}
+	ms.DeleteHostFunc = func(id uint) error {
+		if id == 2 {
+			return errors.New("delete failed")
+		}
+		return nil
+	}
+	var activities []*kolide.Activity
+	ms.NewActivityFunc = func(activity *kolide.Activity) (*kolide.Activity, error) {
+		activity.ID = uint(len(activities) + 1)
+		activities = append(activities, activity)
+		return activity, nil
+	}
+	svc, err := newTestService(ms, nil)
+	require.Nil(t, err)
+	writer := &memJSONLogger{}
+	svc = NewActivityService(svc, ms, writer, kitlog.NewNopLogger())
+
+	admin := &kolide.User{ID: 1, Username: "admin", Enabled: true, Admin: true}
+	ctx := viewer.NewContext(context.Background(), viewer.Viewer{User: admin, Session: &kolide.Session{ID: 1}})
+
+	require.Nil(t, svc.DeleteHost(ctx, 1))
+	require.Len(t, activities, 1)
+	activity := activities[0]
+	require.NotNil(t, activity.ActorID)
+	assert.Equal(t, admin.ID, *activity.ActorID)
+	assert.Equal(t, "admin", activity.ActorName)
+	assert.Equal(t, "deleted_host", activity.Type)
+	assert.Equal(t, "host", activity.TargetType)
+	require.NotNil(t, activity.TargetID)
+	assert.Equal(t, uint(1), *activity.TargetID)
+	assert.Equal(t, "foo.local", activity.TargetName)
+
+	require.Len(t, writer.logs, 1)
+	var written kolide.Activity
+	require.Nil(t, json.Unmarshal(writer.logs[0], &written))
+	assert.Equal(t, "deleted_host", written.Type)
+
+	// Failed actions are not recorded
+	require.NotNil(t, svc.DeleteHost(ctx, 2))
+	assert.Len(t, activities, 1)
+}
+
+func TestActivityModifyAppConfig(t *testing.T) {
+	config := &kolide.AppConfig{OrgName: "Acme", SMTPPassword: "secret"}
+	ms := new(mock.Store)
+	ms.AppConfigFunc = func() (*kolide.AppConfig, error) {
+		return config, nil
+	}
+	ms.SaveAppConfigFunc = func(info *kolide.AppConfig) error {
+		config = info
+		return nil
+	}
+	var activity *kolide.Activity
+	ms.NewActivityFunc = func(a *kolide.Activity) (*kolide.Activity, error) {
+		activity = a
+		return a, nil
+	}
+	svc, err := newTestService(ms, nil)
+	require.Nil(t, err)
+	svc = NewActivityService(svc, ms, nil, kitlog.NewNopLogger())
+
+	admin := &kolide.User{ID: 1, Username: "admin", Enabled: true, Admin: true}
+	ctx := viewer.NewContext(context.Background(), viewer.Viewer{User: admin, Session: &kolide.Session{ID: 1}})
+
+	_, err = svc.ModifyAppConfig(ctx, kolide.AppConfigPayload{
+		OrgInfo: &kolide.OrgInfo{OrgName: stringPtr("Acme Corp")},
+		SMTPSettings: &kolide.SMTPSettingsPayload{
+			SMTPPassword: stringPtr("hunter2"),
+		},
+	})
+	require.Nil(t, err)
+	require.NotNil(t, activity)
+	assert.Equal(t, "modified_app_config", activity.Type)
+	require.NotNil(t, activity.Details)
+
+	var details map[string]activityChange
+	require.Nil(t, json.Unmarshal(*activity.Details, &details))
+	assert.Equal(t, activityChange{Old: "Acme", New: "Acme Corp"}, details["org_name"])
+	assert.Equal(t, activityChange{Secret: true}, details["smtp_password"])
+	assert.NotContains(t, string(*activity.Details), "hunter2")
+}
+
+func TestDiffFields(t *testing.T) {
+	old := &kolide.User{ID: 1, Username: "foo", Name: "Foo", Password: []byte("a")}
+	new := &kolide.User{ID: 1, Username: "foo", Name: "Bar", Password: []byte("b")}
+	changes := diffFields(old, new)
+	assert.Equal(t, map[string]activityChange{"name": {Old: "Foo", New: "Bar"}}, changes)
+}
+
+// provisioningService adds an activity in CallbackSSO, as provisioning does,
+// then fails the sign on.
+type provisioningService struct {
+	kolide.Service
+}
+
+func (svc provisioningService) CallbackSSO(ctx context.Context, auth kolide.Auth) (*kolide.SSOSession, error) {
+	addActivity(ctx, kolide.Activity{Type: "disabled_user", TargetType: "user", TargetID: uintPtr(2), TargetName: "alice"}, nil)
+	return nil, errors.New("user authorization failed")
+}
+
+func TestActivityCallbackSSO(t *testing.T) {
+	ms := new(mock.Store)
+	var activities []*kolide.Activity
+	ms.NewActivityFunc = func(activity *kolide.Activity) (*kolide.Activity, error) {
+		activities = append(activities, activity)
+		return activity, nil
+	}
+	svc := NewActivityService(provisioningService{}, ms, nil, kitlog.NewNopLogger())
+
+	_, err := svc.CallbackSSO(context.Background(), nil)
+	require.NotNil(t, err)
+	require.Len(t, activities, 1)
+	assert.Equal(t, "disabled_user", activities[0].Type)
+	assert.Nil(t, activities[0].ActorID)
+	assert.Equal(t, "alice", activities[0].TargetName)
+}
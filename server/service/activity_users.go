@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (mw activityMiddleware) NewUser(ctx context.Context, p kolide.UserPayload) (*kolide.User, error) {
+	user, err := mw.Service.NewUser(ctx, p)
+	if err == nil {
+		// Invited users create their own account
+		mw.record(ctx, kolide.Activity{
+			ActorID:    uintPtr(user.ID),
+			ActorName:  user.Username,
+			Type:       "created_user",
+			TargetType: "user",
+			TargetID:   uintPtr(user.ID),
+			TargetName: user.Username,
+		}, map[string]interface{}{"role": user.Role})
+	}
+	return user, err
+}
+
+func (mw activityMiddleware) NewAdminCreatedUser(ctx context.Context, p kolide.UserPayload) (*kolide.User, error) {
+	user, err := mw.Service.NewAdminCreatedUser(ctx, p)
+	if err == nil {
+		mw.record(ctx, userActivity("created_user", user), map[string]interface{}{"role": user.Role})
+	}
+	return user, err
+}
+
+func (mw activityMiddleware) ModifyUser(ctx context.Context, userID uint, p kolide.UserPayload) (*kolide.User, error) {
+	var old kolide.User
+	if before, err := mw.Service.User(ctx, userID); err == nil {
+		old = *before
+	}
+	user, err := mw.Service.ModifyUser(ctx, userID, p)
+	if err == nil {
+		mw.record(ctx, userActivity("modified_user", user), diffFields(&old, user))
+	}
+	return user, err
+}
+
+func (mw activityMiddleware) ChangeUserAdmin(ctx context.Context, id uint, isAdmin bool) (*kolide.User, error) {
+	user, err := mw.Service.ChangeUserAdmin(ctx, id, isAdmin)
+	if err == nil {
+		mw.record(ctx, userActivity("changed_user_role", user), map[string]interface{}{"role": user.Role})
+	}
+	return user, err
+}
+
+func (mw activityMiddleware) ChangeUserRole(ctx context.Context, id uint, role kolide.Role) (*kolide.User, error) {
+	user, err := mw.Service.ChangeUserRole(ctx, id, role)
+	if err == nil {
+		mw.record(ctx, userActivity("changed_user_role", user), map[string]interface{}{"role": user.Role})
+	}
+	return user, err
+}
+
+func (mw activityMiddleware) ApplyUserRolesSpec(ctx context.Context, spec *kolide.UserRolesSpec) error {
+	err := mw.Service.ApplyUserRolesSpec(ctx, spec)
+	if err == nil {
+		mw.record(ctx, kolide.Activity{Type: "applied_user_roles_spec", TargetType: "user"}, spec)
+	}
+	return err
+}
+
+func (mw activityMiddleware) ChangeUserEnabled(ctx context.Context, id uint, isEnabled bool) (*kolide.User, error) {
+	user, err := mw.Service.ChangeUserEnabled(ctx, id, isEnabled)
+	if err == nil {
+		activityType := "disabled_user"
+		if isEnabled {
+			activityType = "enabled_user"
+		}
+		mw.record(ctx, userActivity(activityType, user), nil)
+	}
+	return user, err
+}
+
+func (mw activityMiddleware) RequirePasswordReset(ctx context.Context, uid uint, require bool) (*kolide.User, error) {
+	user, err := mw.Service.RequirePasswordReset(ctx, uid, require)
+	if err == nil {
+		mw.record(ctx, userActivity("required_password_reset", user), map[string]interface{}{"require": require})
+	}
+	return user, err
+}
+
+func (mw activityMiddleware) DeleteSessionsForUser(ctx context.Context, id uint) error {
+	err := mw.Service.DeleteSessionsForUser(ctx, id)
+	if err == nil {
+		mw.record(ctx, kolide.Activity{Type: "deleted_user_sessions", TargetType: "user", TargetID: uintPtr(id)}, nil)
+	}
+	return err
+}
+
+func (mw activityMiddleware) DeleteSession(ctx context.Context, id uint) error {
+	err := mw.Service.DeleteSession(ctx, id)
+	if err == nil {
+		mw.record(ctx, kolide.Activity{Type: "deleted_session", TargetType: "session", TargetID: uintPtr(id)}, nil)
+	}
+	return err
+}
+
+func userActivity(activityType string, user *kolide.User) kolide.Activity {
+	return kolide.Activity{
+		Type:       activityType,
+		TargetType: "user",
+		TargetID:   uintPtr(user.ID),
+		TargetName: user.Username,
+	}
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+// campaignCacheTTL is how long the settings of a campaign are kept by the
+// campaign cache.
+const campaignCacheTTL = time.Minute
+
+// cachedCampaign holds the settings of a campaign that do not change once it
+// is created, along with what the instance learned about the campaign while
+// ingesting its results.
+type cachedCampaign struct {
+	campaign kolide.DistributedQueryCampaign
+	// resultsTruncated is set once the campaign was marked as truncated,
+	// so that it is only marked once.
+	resultsTruncated bool
+	// query and username are the details of the campaign added to the
+	// live query logs, set once logDetailsLoaded.
+	query            string
+	username         string
+	logDetailsLoaded bool
+	loadedAt         time.Time
+}
+
+func newCachedCampaign(campaign *kolide.DistributedQueryCampaign, now time.Time) *cachedCampaign {
+	return &cachedCampaign{
+		campaign:         *campaign,
+		resultsTruncated: campaign.ResultsTruncated,
+		loadedAt:         now,
+	}
+}
+
+// campaignCache keeps the campaigns receiving results, so that ingesting the
+// result of each host does not load the campaign from the datastore.
+type campaignCache struct {
+	mtx       sync.Mutex
+	campaigns map[uint]*cachedCampaign
+}
+
+func newCampaignCache() *campaignCache {
+	return &campaignCache{campaigns: make(map[uint]*cachedCampaign)}
+}
+
+// get returns the cached campaign, loading it with load if it is not cached
+// or was cached before the TTL. A nil cache always loads the campaign.
+func (c *campaignCache) get(id uint, now time.Time, load func(id uint) (*kolide.DistributedQueryCampaign, error)) (*cachedCampaign, error) {
+	if c == nil {
+		campaign, err := load(id)
+		if err != nil {
+			return nil, err
+		}
+		return newCachedCampaign(campaign, now), nil
+	}
+
+	c.mtx.Lock()
+	cached, ok := c.campaigns[id]
+	c.mtx.Unlock()
+	if ok && now.Sub(cached.loadedAt) < campaignCacheTTL {
+		return cached, nil
+	}
+
+	campaign, err := load(id)
+	if err != nil {
+		return nil, err
+	}
+	cached = newCachedCampaign(campaign, now)
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	// Drop the campaigns that are no longer receiving results
+	for cachedID, other := range c.campaigns {
+		if now.Sub(other.loadedAt) >= campaignCacheTTL {
+			delete(c.campaigns, cachedID)
+		}
+	}
+	c.campaigns[id] = cached
+	return cached, nil
+}
+
+// markTruncated records that the results of the campaign were truncated,
+// returning whether it was already recorded.
+func (c *campaignCache) markTruncated(cached *cachedCampaign) bool {
+	c.lock()
+	defer c.unlock()
+	marked := cached.resultsTruncated
+	cached.resultsTruncated = true
+	return marked
+}
+
+// liveQueryLogDetails returns the query text and the username of the
+// campaign, loading them with load once for the cached campaign.
+func (c *campaignCache) liveQueryLogDetails(cached *cachedCampaign, load func(campaign *kolide.DistributedQueryCampaign) (query, username string, err error)) (string, string, error) {
+	c.lock()
+	loaded, query, username := cached.logDetailsLoaded, cached.query, cached.username
+	c.unlock()
+	if loaded {
+		return query, username, nil
+	}
+
+	query, username, err := load(&cached.campaign)
+	if err != nil {
+		return "", "", err
+	}
+
+	c.lock()
+	defer c.unlock()
+	cached.query, cached.username, cached.logDetailsLoaded = query, username, true
+	return query, username, nil
+}
+
+// lock and unlock the cache, if there is one.
+func (c *campaignCache) lock() {
+	if c != nil {
+		c.mtx.Lock()
+	}
+}
+
+func (c *campaignCache) unlock() {
+	if c != nil {
+		c.mtx.Unlock()
+	}
+}
@@ -72,7 +72,7 @@ func NewClient(addr string, insecureSkipVerify bool, rootCA, urlPrefix string) (
 	}, nil
 }
 
-func (c *Client) doWithHeaders(verb, path string, params interface{}, headers map[string]string) (*http.Response, error) {
+func (c *Client) doWithHeaders(verb, path, rawQuery string, params interface{}, headers map[string]string) (*http.Response, error) {
 	var bodyBytes []byte
 	var err error
 	if params != nil {
@@ -84,7 +84,7 @@ func (c *Client) doWithHeaders(verb, path string, params interface{}, headers ma
 
 	request, err := http.NewRequest(
 		verb,
-		c.url(path).String(),
+		c.url(path, rawQuery).String(),
 		bytes.NewBuffer(bodyBytes),
 	)
 	if err != nil {
@@ -103,10 +103,16 @@ func (c *Client) Do(verb, path string, params interface{}) (*http.Response, erro
 		"Accept":       "application/json",
 	}
 
-	return c.doWithHeaders(verb, path, params, headers)
+	return c.doWithHeaders(verb, path, "", params, headers)
 }
 
 func (c *Client) AuthenticatedDo(verb, path string, params interface{}) (*http.Response, error) {
+	return c.AuthenticatedDoWithQuery(verb, path, "", params)
+}
+
+// AuthenticatedDoWithQuery is like AuthenticatedDo, additionally setting the
+// provided (already encoded) query string on the request URL.
+func (c *Client) AuthenticatedDoWithQuery(verb, path, rawQuery string, params interface{}) (*http.Response, error) {
 	if c.token == "" {
 		return nil, errors.New("authentication token is empty")
 	}
@@ -117,15 +123,16 @@ func (c *Client) AuthenticatedDo(verb, path string, params interface{}) (*http.R
 		"Authorization": fmt.Sprintf("Bearer %s", c.token),
 	}
 
-	return c.doWithHeaders(verb, path, params, headers)
+	return c.doWithHeaders(verb, path, rawQuery, params, headers)
 }
 
 func (c *Client) SetToken(t string) {
 	c.token = t
 }
 
-func (c *Client) url(path string) *url.URL {
+func (c *Client) url(path, rawQuery string) *url.URL {
 	u := *c.baseURL
 	u.Path = c.urlPrefix + path
+	u.RawQuery = rawQuery
 	return &u
 }
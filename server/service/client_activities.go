@@ -0,0 +1,58 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// GetActivities retrieves a page of the activity log, most recent first.
+func (c *Client) GetActivities(filter kolide.ActivityFilter, page, perPage uint) ([]*kolide.Activity, error) {
+	query := url.Values{}
+	query.Set("page", strconv.FormatUint(uint64(page), 10))
+	query.Set("per_page", strconv.FormatUint(uint64(perPage), 10))
+	if filter.ActorID != nil {
+		query.Set("actor_id", strconv.FormatUint(uint64(*filter.ActorID), 10))
+	}
+	if filter.Type != nil {
+		query.Set("type", *filter.Type)
+	}
+	if filter.TargetType != nil {
+		query.Set("target_type", *filter.TargetType)
+	}
+	if filter.TargetID != nil {
+		query.Set("target_id", strconv.FormatUint(uint64(*filter.TargetID), 10))
+	}
+	if filter.Since != nil {
+		query.Set("since", filter.Since.Format(time.RFC3339))
+	}
+	response, err := c.AuthenticatedDoWithQuery("GET", "/api/v1/kolide/activities", query.Encode(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "GET /api/v1/kolide/activities")
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.Errorf(
+			"get activities received status %d %s",
+			response.StatusCode,
+			extractServerErrorText(response.Body),
+		)
+	}
+
+	var responseBody listActivitiesResponse
+	err = json.NewDecoder(response.Body).Decode(&responseBody)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode list activities response")
+	}
+	if responseBody.Err != nil {
+		return nil, errors.Errorf("list activities: %s", responseBody.Err)
+	}
+
+	return responseBody.Activities, nil
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// CreateServiceAccount creates a service account user.
+func (c *Client) CreateServiceAccount(payload kolide.ServiceAccountPayload) (*kolide.User, error) {
+	verb, path := "POST", "/api/v1/kolide/service_accounts"
+	response, err := c.AuthenticatedDo(verb, path, payload)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s %s", verb, path)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.Errorf(
+			"create service account received status %d %s",
+			response.StatusCode,
+			extractServerErrorText(response.Body),
+		)
+	}
+
+	var responseBody createServiceAccountResponse
+	err = json.NewDecoder(response.Body).Decode(&responseBody)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode create service account response")
+	}
+	if responseBody.Err != nil {
+		return nil, errors.Errorf("create service account: %s", responseBody.Err)
+	}
+
+	return responseBody.User, nil
+}
+
+// CreateAPIToken mints an API token for the service account. The returned
+// secret token cannot be retrieved again.
+func (c *Client) CreateAPIToken(userID uint, payload kolide.APITokenPayload) (string, *kolide.APIToken, error) {
+	verb, path := "POST", fmt.Sprintf("/api/v1/kolide/users/%d/api_tokens", userID)
+	response, err := c.AuthenticatedDo(verb, path, payload)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "%s %s", verb, path)
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusNotFound:
+		return "", nil, notFoundErr{}
+	}
+	if response.StatusCode != http.StatusOK {
+		return "", nil, errors.Errorf(
+			"create api token received status %d %s",
+			response.StatusCode,
+			extractServerErrorText(response.Body),
+		)
+	}
+
+	var responseBody createAPITokenResponse
+	err = json.NewDecoder(response.Body).Decode(&responseBody)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "decode create api token response")
+	}
+	if responseBody.Err != nil {
+		return "", nil, errors.Errorf("create api token: %s", responseBody.Err)
+	}
+
+	return responseBody.Token, responseBody.APIToken, nil
+}
+
+// ListAPITokens returns the API tokens of the service account.
+func (c *Client) ListAPITokens(userID uint) ([]*kolide.APIToken, error) {
+	verb, path := "GET", fmt.Sprintf("/api/v1/kolide/users/%d/api_tokens", userID)
+	response, err := c.AuthenticatedDo(verb, path, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s %s", verb, path)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.Errorf(
+			"list api tokens received status %d %s",
+			response.StatusCode,
+			extractServerErrorText(response.Body),
+		)
+	}
+
+	var responseBody listAPITokensResponse
+	err = json.NewDecoder(response.Body).Decode(&responseBody)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode list api tokens response")
+	}
+	if responseBody.Err != nil {
+		return nil, errors.Errorf("list api tokens: %s", responseBody.Err)
+	}
+
+	return responseBody.APITokens, nil
+}
+
+// DeleteAPIToken revokes an API token of the service account.
+func (c *Client) DeleteAPIToken(userID, id uint) error {
+	verb, path := "DELETE", fmt.Sprintf("/api/v1/kolide/users/%d/api_tokens/%d", userID, id)
+	response, err := c.AuthenticatedDo(verb, path, nil)
+	if err != nil {
+		return errors.Wrapf(err, "%s %s", verb, path)
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusNotFound:
+		return notFoundErr{}
+	}
+	if response.StatusCode != http.StatusOK {
+		return errors.Errorf(
+			"delete api token received status %d %s",
+			response.StatusCode,
+			extractServerErrorText(response.Body),
+		)
+	}
+
+	var responseBody deleteAPITokenResponse
+	err = json.NewDecoder(response.Body).Decode(&responseBody)
+	if err != nil {
+		return errors.Wrap(err, "decode delete api token response")
+	}
+	if responseBody.Err != nil {
+		return errors.Errorf("delete api token: %s", responseBody.Err)
+	}
+
+	return nil
+}
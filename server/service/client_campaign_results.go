@@ -0,0 +1,50 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// GetCampaignResults retrieves a page of the persisted results of the campaign
+// with the given ID, and whether results were left out because the campaign
+// reached the stored rows limit.
+func (c *Client) GetCampaignResults(campaignID, page, perPage uint) ([]*kolide.CampaignResult, bool, error) {
+	path := fmt.Sprintf("/api/v1/kolide/campaigns/%d/results", campaignID)
+	query := url.Values{}
+	query.Set("page", strconv.FormatUint(uint64(page), 10))
+	query.Set("per_page", strconv.FormatUint(uint64(perPage), 10))
+	response, err := c.AuthenticatedDoWithQuery("GET", path, query.Encode(), nil)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "GET %s", path)
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusNotFound:
+		return nil, false, notFoundErr{}
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, false, errors.Errorf(
+			"get campaign results received status %d %s",
+			response.StatusCode,
+			extractServerErrorText(response.Body),
+		)
+	}
+
+	var responseBody listCampaignResultsResponse
+	err = json.NewDecoder(response.Body).Decode(&responseBody)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "decode list campaign results response")
+	}
+	if responseBody.Err != nil {
+		return nil, false, errors.Errorf("list campaign results: %s", responseBody.Err)
+	}
+
+	return responseBody.Results, responseBody.Truncated, nil
+}
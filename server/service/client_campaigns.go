@@ -0,0 +1,121 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// GetCampaigns retrieves a page of the distributed query campaigns matching
+// the filter, most recent first.
+func (c *Client) GetCampaigns(filter kolide.DistributedQueryCampaignFilter, page, perPage uint) ([]*kolide.DistributedQueryCampaignSummary, error) {
+	query := url.Values{}
+	query.Set("page", strconv.FormatUint(uint64(page), 10))
+	query.Set("per_page", strconv.FormatUint(uint64(perPage), 10))
+	if filter.UserID != nil {
+		query.Set("user_id", strconv.FormatUint(uint64(*filter.UserID), 10))
+	}
+	if filter.Status != nil {
+		query.Set("status", filter.Status.String())
+	}
+	response, err := c.AuthenticatedDoWithQuery("GET", "/api/v1/kolide/campaigns", query.Encode(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "GET /api/v1/kolide/campaigns")
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.Errorf(
+			"get campaigns received status %d %s",
+			response.StatusCode,
+			extractServerErrorText(response.Body),
+		)
+	}
+
+	var responseBody listDistributedQueryCampaignsResponse
+	err = json.NewDecoder(response.Body).Decode(&responseBody)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode list campaigns response")
+	}
+	if responseBody.Err != nil {
+		return nil, errors.Errorf("list campaigns: %s", responseBody.Err)
+	}
+
+	return responseBody.Campaigns, nil
+}
+
+// GetCampaignHosts retrieves a page of the targeted hosts of the campaign with
+// the given ID, with the execution state of the campaign on each host.
+func (c *Client) GetCampaignHosts(campaignID, page, perPage uint) ([]*kolide.DistributedQueryCampaignHost, error) {
+	path := fmt.Sprintf("/api/v1/kolide/campaigns/%d/hosts", campaignID)
+	query := url.Values{}
+	query.Set("page", strconv.FormatUint(uint64(page), 10))
+	query.Set("per_page", strconv.FormatUint(uint64(perPage), 10))
+	response, err := c.AuthenticatedDoWithQuery("GET", path, query.Encode(), nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "GET %s", path)
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusNotFound:
+		return nil, notFoundErr{}
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.Errorf(
+			"get campaign hosts received status %d %s",
+			response.StatusCode,
+			extractServerErrorText(response.Body),
+		)
+	}
+
+	var responseBody listDistributedQueryCampaignHostsResponse
+	err = json.NewDecoder(response.Body).Decode(&responseBody)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode list campaign hosts response")
+	}
+	if responseBody.Err != nil {
+		return nil, errors.Errorf("list campaign hosts: %s", responseBody.Err)
+	}
+
+	return responseBody.Hosts, nil
+}
+
+// CancelCampaign cancels the campaign with the given ID, so that its query is
+// no longer sent to hosts.
+func (c *Client) CancelCampaign(campaignID uint) (*kolide.DistributedQueryCampaign, error) {
+	verb, path := "POST", fmt.Sprintf("/api/v1/kolide/campaigns/%d/cancel", campaignID)
+	response, err := c.AuthenticatedDo(verb, path, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s %s", verb, path)
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusNotFound:
+		return nil, notFoundErr{}
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.Errorf(
+			"cancel campaign received status %d %s",
+			response.StatusCode,
+			extractServerErrorText(response.Body),
+		)
+	}
+
+	var responseBody cancelDistributedQueryCampaignResponse
+	err = json.NewDecoder(response.Body).Decode(&responseBody)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode cancel campaign response")
+	}
+	if responseBody.Err != nil {
+		return nil, errors.Errorf("cancel campaign: %s", responseBody.Err)
+	}
+
+	return responseBody.Campaign, nil
+}
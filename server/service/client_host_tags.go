@@ -0,0 +1,40 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// ApplyHostTags sends the list of host tag specs to be applied to the Fleet
+// instance. The tags of each named host are replaced with those in its spec.
+func (c *Client) ApplyHostTags(specs []*kolide.HostTagSpec) error {
+	req := applyHostTagSpecsRequest{Specs: specs}
+	response, err := c.AuthenticatedDo("POST", "/api/v1/kolide/spec/host_tags", req)
+	if err != nil {
+		return errors.Wrap(err, "POST /api/v1/kolide/spec/host_tags")
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return errors.Errorf(
+			"apply host tags received status %d %s",
+			response.StatusCode,
+			extractServerErrorText(response.Body),
+		)
+	}
+
+	var responseBody applyHostTagSpecsResponse
+	err = json.NewDecoder(response.Body).Decode(&responseBody)
+	if err != nil {
+		return errors.Wrap(err, "decode apply host tag spec response")
+	}
+
+	if responseBody.Err != nil {
+		return errors.Errorf("apply host tag spec: %s", responseBody.Err)
+	}
+
+	return nil
+}
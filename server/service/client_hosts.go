@@ -2,14 +2,24 @@ package service
 
 import (
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strings"
 
+	"github.com/kolide/fleet/server/kolide"
 	"github.com/pkg/errors"
 )
 
-// GetHosts retrieves the list of all Hosts
-func (c *Client) GetHosts() ([]HostResponse, error) {
-	response, err := c.AuthenticatedDo("GET", "/api/v1/kolide/hosts", nil)
+// GetHosts retrieves the list of Hosts matching the filter expression. An
+// empty filter retrieves all Hosts.
+func (c *Client) GetHosts(filter string) ([]HostResponse, error) {
+	query := url.Values{}
+	if filter != "" {
+		query.Set("filter", filter)
+	}
+	response, err := c.AuthenticatedDoWithQuery("GET", "/api/v1/kolide/hosts", query.Encode(), nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "GET /api/v1/kolide/hosts")
 	}
@@ -33,3 +43,111 @@ func (c *Client) GetHosts() ([]HostResponse, error) {
 
 	return responseBody.Hosts, nil
 }
+
+// ExportHosts streams the export of the hosts matching the filter expression
+// to w, in the given format ("csv" or "ndjson"). An empty filter exports all
+// hosts, and empty columns export the server's default column set.
+func (c *Client) ExportHosts(format string, columns []string, filter string, w io.Writer) error {
+	query := url.Values{}
+	if format != "" {
+		query.Set("format", format)
+	}
+	if len(columns) > 0 {
+		query.Set("columns", strings.Join(columns, ","))
+	}
+	if filter != "" {
+		query.Set("filter", filter)
+	}
+	response, err := c.AuthenticatedDoWithQuery("GET", "/api/v1/kolide/hosts/export", query.Encode(), nil)
+	if err != nil {
+		return errors.Wrap(err, "GET /api/v1/kolide/hosts/export")
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return errors.Errorf(
+			"export hosts received status %d %s",
+			response.StatusCode,
+			extractServerErrorText(response.Body),
+		)
+	}
+
+	if _, err := io.Copy(w, response.Body); err != nil {
+		return errors.Wrap(err, "copy host export")
+	}
+	return nil
+}
+
+// MergeHosts folds the duplicate host into the surviving host, returning the
+// updated survivor.
+func (c *Client) MergeHosts(survivorID, duplicateID uint) (*HostResponse, error) {
+	verb, path := "POST", fmt.Sprintf("/api/v1/kolide/hosts/%d/merge", survivorID)
+	params := mergeHostsRequest{DuplicateID: duplicateID}
+	response, err := c.AuthenticatedDo(verb, path, params)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s %s", verb, path)
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusNotFound:
+		return nil, notFoundErr{}
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.Errorf(
+			"merge hosts received status %d %s",
+			response.StatusCode,
+			extractServerErrorText(response.Body),
+		)
+	}
+
+	var responseBody mergeHostsResponse
+	err = json.NewDecoder(response.Body).Decode(&responseBody)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode merge hosts response")
+	}
+	if responseBody.Err != nil {
+		return nil, errors.Errorf("merge hosts: %s", responseBody.Err)
+	}
+
+	return responseBody.Host, nil
+}
+
+// DeleteHosts deletes the hosts selected by exactly one of the host IDs, the
+// label ID or the filter expression. Deleting more than
+// kolide.BulkHostDeleteConfirmationThreshold hosts requires the token of a
+// preview selecting the same hosts, otherwise nothing is deleted and the
+// result has ConfirmationRequired set along with the selected host IDs and
+// the token confirming their delete.
+func (c *Client) DeleteHosts(ids []uint, labelID *uint, filter string, confirmToken string) (*kolide.BulkHostDeleteResult, error) {
+	verb, path := "POST", "/api/v1/kolide/hosts/delete"
+	params := deleteHostsRequest{IDs: ids, LabelID: labelID, Filter: filter, ConfirmToken: confirmToken}
+	response, err := c.AuthenticatedDo(verb, path, params)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s %s", verb, path)
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusNotFound:
+		return nil, notFoundErr{}
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.Errorf(
+			"delete hosts received status %d %s",
+			response.StatusCode,
+			extractServerErrorText(response.Body),
+		)
+	}
+
+	var responseBody deleteHostsResponse
+	err = json.NewDecoder(response.Body).Decode(&responseBody)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode delete hosts response")
+	}
+	if responseBody.Err != nil {
+		return nil, errors.Errorf("delete hosts: %s", responseBody.Err)
+	}
+
+	return responseBody.BulkHostDeleteResult, nil
+}
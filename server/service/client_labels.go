@@ -10,17 +10,18 @@ import (
 )
 
 // ApplyLabels sends the list of Labels to be applied (upserted) to the
-// Fleet instance.
-func (c *Client) ApplyLabels(specs []*kolide.LabelSpec) error {
+// Fleet instance. The warnings found when validating their queries are
+// returned.
+func (c *Client) ApplyLabels(specs []*kolide.LabelSpec) ([]string, error) {
 	req := applyLabelSpecsRequest{Specs: specs}
 	response, err := c.AuthenticatedDo("POST", "/api/v1/kolide/spec/labels", req)
 	if err != nil {
-		return errors.Wrap(err, "POST /api/v1/kolide/spec/labels")
+		return nil, errors.Wrap(err, "POST /api/v1/kolide/spec/labels")
 	}
 	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusOK {
-		return errors.Errorf(
+		return nil, errors.Errorf(
 			"apply labels received status %d %s",
 			response.StatusCode,
 			extractServerErrorText(response.Body),
@@ -30,14 +31,14 @@ func (c *Client) ApplyLabels(specs []*kolide.LabelSpec) error {
 	var responseBody applyLabelSpecsResponse
 	err = json.NewDecoder(response.Body).Decode(&responseBody)
 	if err != nil {
-		return errors.Wrap(err, "decode apply label spec response")
+		return nil, errors.Wrap(err, "decode apply label spec response")
 	}
 
 	if responseBody.Err != nil {
-		return errors.Errorf("apply label spec: %s", responseBody.Err)
+		return nil, errors.Errorf("apply label spec: %s", responseBody.Err)
 	}
 
-	return nil
+	return responseBody.Warnings, nil
 }
 
 // GetLabel retrieves information about a label by name
@@ -1,24 +1,25 @@
 package service
 
 import (
-	"crypto/tls"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"sync/atomic"
-	"time"
 
 	"github.com/kolide/fleet/server/kolide"
-	ws "github.com/kolide/fleet/server/websocket"
-
-	"github.com/gorilla/websocket"
 	"github.com/pkg/errors"
 )
 
 // LiveQueryResultsHandler provides access to all of the information about an
 // incoming stream of live query results.
 type LiveQueryResultsHandler struct {
+	campaignID uint
+	warnings   []string
+
 	errors  chan error
 	results chan kolide.DistributedQueryResult
+	done    chan struct{}
 	totals  atomic.Value // real type: targetTotals
 	status  atomic.Value // real type: campaignStatus
 }
@@ -27,9 +28,21 @@ func NewLiveQueryResultsHandler() *LiveQueryResultsHandler {
 	return &LiveQueryResultsHandler{
 		errors:  make(chan error),
 		results: make(chan kolide.DistributedQueryResult),
+		done:    make(chan struct{}),
 	}
 }
 
+// CampaignID returns the ID of the campaign running the live query.
+func (h *LiveQueryResultsHandler) CampaignID() uint {
+	return h.campaignID
+}
+
+// Warnings returns the problems found by the server when validating the
+// query.
+func (h *LiveQueryResultsHandler) Warnings() []string {
+	return h.warnings
+}
+
 // Errors returns a read channel that includes any errors returned by the
 // server or receiving the results.
 func (h *LiveQueryResultsHandler) Errors() <-chan error {
@@ -41,6 +54,12 @@ func (h *LiveQueryResultsHandler) Results() <-chan kolide.DistributedQueryResult
 	return h.results
 }
 
+// Done returns a channel that is closed when the stream of results ends,
+// once all of the received results and errors were read.
+func (h *LiveQueryResultsHandler) Done() <-chan struct{} {
+	return h.done
+}
+
 // Totals returns the current metadata of hosts targeted by the query
 func (h *LiveQueryResultsHandler) Totals() *targetTotals {
 	t := h.totals.Load()
@@ -59,10 +78,11 @@ func (h *LiveQueryResultsHandler) Status() *campaignStatus {
 }
 
 // LiveQuery creates a new live query and begins streaming results.
-func (c *Client) LiveQuery(query string, labels []string, hosts []string) (*LiveQueryResultsHandler, error) {
+func (c *Client) LiveQuery(query string, labels []string, hosts []string, tags []string, opts kolide.CampaignOptions) (*LiveQueryResultsHandler, error) {
 	req := createDistributedQueryCampaignByNamesRequest{
-		Query:    query,
-		Selected: distributedQueryCampaignTargetsByNames{Labels: labels, Hosts: hosts},
+		Query:           query,
+		Selected:        distributedQueryCampaignTargetsByNames{Labels: labels, Hosts: hosts, Tags: tags},
+		CampaignOptions: opts,
 	}
 	response, err := c.AuthenticatedDo("POST", "/api/v1/kolide/queries/run_by_names", req)
 	if err != nil {
@@ -87,53 +107,41 @@ func (c *Client) LiveQuery(query string, labels []string, hosts []string) (*Live
 		return nil, errors.Errorf("create live query: %s", responseBody.Err)
 	}
 
-	// Copy default dialer but skip cert verification if set.
-	dialer := &websocket.Dialer{
-		Proxy:            http.ProxyFromEnvironment,
-		HandshakeTimeout: 45 * time.Second,
-		TLSClientConfig:  &tls.Config{InsecureSkipVerify: c.insecureSkipVerify},
-	}
-
-	wssURL := *c.baseURL
-	wssURL.Scheme = "wss"
-	wssURL.Path = c.urlPrefix + "/api/v1/kolide/results/websocket"
-	conn, _, err := dialer.Dial(wssURL.String(), nil)
+	campaignID := responseBody.Campaign.ID
+	path := fmt.Sprintf("/api/v1/kolide/campaigns/%d/stream", campaignID)
+	stream, err := c.AuthenticatedDoWithQuery("GET", path, "format=ndjson", nil)
 	if err != nil {
-		return nil, errors.Wrap(err, "upgrade live query result websocket")
+		return nil, errors.Wrap(err, "GET "+path)
 	}
-	// Cannot defer connection closing here because we need it to remain
-	// open for the goroutine below. Manually close for the couple of error
-	// cases below until we enter that goroutine.
-
-	err = conn.WriteJSON(ws.JSONMessage{
-		Type: "auth",
-		Data: map[string]interface{}{"token": c.token},
-	})
-	if err != nil {
-		_ = conn.Close()
-		return nil, errors.Wrap(err, "auth for results")
-	}
-
-	err = conn.WriteJSON(ws.JSONMessage{
-		Type: "select_campaign",
-		Data: map[string]interface{}{"campaign_id": responseBody.Campaign.ID},
-	})
-	if err != nil {
-		_ = conn.Close()
-		return nil, errors.Wrap(err, "auth for results")
+	// Cannot defer closing the response body here because we need it to
+	// remain open for the goroutine below.
+	if stream.StatusCode != http.StatusOK {
+		defer stream.Body.Close()
+		return nil, errors.Errorf(
+			"stream live query results received status %d %s",
+			stream.StatusCode,
+			extractServerErrorText(stream.Body),
+		)
 	}
 
 	resHandler := NewLiveQueryResultsHandler()
+	resHandler.campaignID = campaignID
+	resHandler.warnings = responseBody.Campaign.Warnings
 	go func() {
-		defer conn.Close()
+		defer close(resHandler.done)
+		defer stream.Body.Close()
+		dec := json.NewDecoder(stream.Body)
 		for {
 			msg := struct {
 				Type string          `json:"type"`
 				Data json.RawMessage `json:"data"`
 			}{}
-			err := conn.ReadJSON(&msg)
-			if err != nil {
-				resHandler.errors <- errors.Wrap(err, "receive ws message")
+			if err := dec.Decode(&msg); err != nil {
+				// The server closing the stream is not an error
+				if err != io.EOF {
+					resHandler.errors <- errors.Wrap(err, "receive stream message")
+				}
+				return
 			}
 
 			switch msg.Type {
@@ -158,6 +166,13 @@ func (c *Client) LiveQuery(query string, labels []string, hosts []string) (*Live
 				}
 				resHandler.status.Store(&status)
 
+			case "error":
+				var text string
+				if err := json.Unmarshal(msg.Data, &text); err != nil {
+					text = string(msg.Data)
+				}
+				resHandler.errors <- errors.New(text)
+
 			default:
 				resHandler.errors <- errors.Errorf("unknown msg type %s", msg.Type)
 			}
@@ -10,17 +10,18 @@ import (
 )
 
 // ApplyPacks sends the list of Packs to be applied (upserted) to the
-// Fleet instance.
-func (c *Client) ApplyPacks(specs []*kolide.PackSpec) error {
+// Fleet instance. The warnings found when validating their queries are
+// returned.
+func (c *Client) ApplyPacks(specs []*kolide.PackSpec) ([]string, error) {
 	req := applyPackSpecsRequest{Specs: specs}
 	response, err := c.AuthenticatedDo("POST", "/api/v1/kolide/spec/packs", req)
 	if err != nil {
-		return errors.Wrap(err, "POST /api/v1/kolide/spec/packs")
+		return nil, errors.Wrap(err, "POST /api/v1/kolide/spec/packs")
 	}
 	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusOK {
-		return errors.Errorf(
+		return nil, errors.Errorf(
 			"apply packs received status %d %s",
 			response.StatusCode,
 			extractServerErrorText(response.Body),
@@ -30,14 +31,14 @@ func (c *Client) ApplyPacks(specs []*kolide.PackSpec) error {
 	var responseBody applyPackSpecsResponse
 	err = json.NewDecoder(response.Body).Decode(&responseBody)
 	if err != nil {
-		return errors.Wrap(err, "decode apply pack spec response")
+		return nil, errors.Wrap(err, "decode apply pack spec response")
 	}
 
 	if responseBody.Err != nil {
-		return errors.Errorf("apply pack spec: %s", responseBody.Err)
+		return nil, errors.Errorf("apply pack spec: %s", responseBody.Err)
 	}
 
-	return nil
+	return responseBody.Warnings, nil
 }
 
 // GetPack retrieves information about a pack
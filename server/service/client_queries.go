@@ -10,17 +10,18 @@ import (
 )
 
 // ApplyQueries sends the list of Queries to be applied (upserted) to the
-// Fleet instance.
-func (c *Client) ApplyQueries(specs []*kolide.QuerySpec) error {
+// Fleet instance. The warnings found when validating their queries are
+// returned.
+func (c *Client) ApplyQueries(specs []*kolide.QuerySpec) ([]string, error) {
 	req := applyQuerySpecsRequest{Specs: specs}
 	response, err := c.AuthenticatedDo("POST", "/api/v1/kolide/spec/queries", req)
 	if err != nil {
-		return errors.Wrap(err, "POST /api/v1/kolide/spec/queries")
+		return nil, errors.Wrap(err, "POST /api/v1/kolide/spec/queries")
 	}
 	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusOK {
-		return errors.Errorf(
+		return nil, errors.Errorf(
 			"apply queries received status %d %s",
 			response.StatusCode,
 			extractServerErrorText(response.Body),
@@ -30,14 +31,14 @@ func (c *Client) ApplyQueries(specs []*kolide.QuerySpec) error {
 	var responseBody applyQuerySpecsResponse
 	err = json.NewDecoder(response.Body).Decode(&responseBody)
 	if err != nil {
-		return errors.Wrap(err, "decode apply query spec response")
+		return nil, errors.Wrap(err, "decode apply query spec response")
 	}
 
 	if responseBody.Err != nil {
-		return errors.Errorf("apply query spec: %s", responseBody.Err)
+		return nil, errors.Errorf("apply query spec: %s", responseBody.Err)
 	}
 
-	return nil
+	return responseBody.Warnings, nil
 }
 
 // GetQuery retrieves the list of all Queries.
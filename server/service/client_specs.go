@@ -0,0 +1,43 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// ValidateSpecs sends the queries, labels and packs to be validated against
+// the osquery schema of the Fleet instance, without applying them.
+func (c *Client) ValidateSpecs(queries []*kolide.QuerySpec, labels []*kolide.LabelSpec, packs []*kolide.PackSpec) (*kolide.QueryValidation, error) {
+	req := validateSpecsRequest{Queries: queries, Labels: labels, Packs: packs}
+	response, err := c.AuthenticatedDo("POST", "/api/v1/kolide/spec/validate", req)
+	if err != nil {
+		return nil, errors.Wrap(err, "POST /api/v1/kolide/spec/validate")
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.Errorf(
+			"validate specs received status %d %s",
+			response.StatusCode,
+			extractServerErrorText(response.Body),
+		)
+	}
+
+	var responseBody validateSpecsResponse
+	err = json.NewDecoder(response.Body).Decode(&responseBody)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode validate specs response")
+	}
+
+	if responseBody.Err != nil {
+		return nil, errors.Errorf("validate specs: %s", responseBody.Err)
+	}
+
+	if responseBody.QueryValidation == nil {
+		return &kolide.QueryValidation{}, nil
+	}
+	return responseBody.QueryValidation, nil
+}
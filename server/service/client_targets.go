@@ -15,6 +15,7 @@ func (c *Client) SearchTargets(query string, selectedHostIDs, selectedLabelIDs [
 		Selected: struct {
 			Labels []uint `json:"labels"`
 			Hosts  []uint `json:"hosts"`
+			Tags   []uint `json:"tags"`
 		}{
 			Labels: selectedLabelIDs,
 			Hosts:  selectedHostIDs,
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// ApplyUserRolesSpec sets the roles of the users named in the spec.
+func (c *Client) ApplyUserRolesSpec(spec *kolide.UserRolesSpec) error {
+	req := applyUserRolesSpecRequest{Spec: spec}
+	response, err := c.AuthenticatedDo("POST", "/api/v1/kolide/spec/user_roles", req)
+	if err != nil {
+		return errors.Wrap(err, "POST /api/v1/kolide/spec/user_roles")
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return errors.Errorf(
+			"apply user roles received status %d %s",
+			response.StatusCode,
+			extractServerErrorText(response.Body),
+		)
+	}
+
+	var responseBody applyUserRolesSpecResponse
+	err = json.NewDecoder(response.Body).Decode(&responseBody)
+	if err != nil {
+		return errors.Wrap(err, "decode apply user roles spec response")
+	}
+
+	if responseBody.Err != nil {
+		return errors.Errorf("apply user roles spec: %s", responseBody.Err)
+	}
+
+	return nil
+}
+
+// GetUserRolesSpec fetches the roles of all users.
+func (c *Client) GetUserRolesSpec() (*kolide.UserRolesSpec, error) {
+	response, err := c.AuthenticatedDo("GET", "/api/v1/kolide/spec/user_roles", nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "GET /api/v1/kolide/spec/user_roles")
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.Errorf(
+			"get user roles received status %d %s",
+			response.StatusCode,
+			extractServerErrorText(response.Body),
+		)
+	}
+
+	var responseBody getUserRolesSpecResponse
+	err = json.NewDecoder(response.Body).Decode(&responseBody)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode get user roles spec response")
+	}
+
+	if responseBody.Err != nil {
+		return nil, errors.Errorf("get user roles spec: %s", responseBody.Err)
+	}
+
+	return responseBody.Spec, nil
+}
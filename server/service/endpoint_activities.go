@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/kolide/fleet/server/kolide"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// List Activities
+////////////////////////////////////////////////////////////////////////////////
+
+type listActivitiesRequest struct {
+	ListOptions kolide.ListOptions
+	Filter      kolide.ActivityFilter
+}
+
+type listActivitiesResponse struct {
+	Activities []*kolide.Activity `json:"activities"`
+	Err        error              `json:"error,omitempty"`
+}
+
+func (r listActivitiesResponse) error() error { return r.Err }
+
+func makeListActivitiesEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listActivitiesRequest)
+		activities, err := svc.ListActivities(ctx, req.Filter, req.ListOptions)
+		if err != nil {
+			return listActivitiesResponse{Err: err}, nil
+		}
+		return listActivitiesResponse{Activities: activities}, nil
+	}
+}
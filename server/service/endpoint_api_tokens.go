@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/kolide/fleet/server/kolide"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Create Service Account
+////////////////////////////////////////////////////////////////////////////////
+
+type createServiceAccountRequest struct {
+	payload kolide.ServiceAccountPayload
+}
+
+type createServiceAccountResponse struct {
+	User *kolide.User `json:"user,omitempty"`
+	Err  error        `json:"error,omitempty"`
+}
+
+func (r createServiceAccountResponse) error() error { return r.Err }
+
+func makeCreateServiceAccountEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(createServiceAccountRequest)
+		user, err := svc.NewServiceAccount(ctx, req.payload)
+		if err != nil {
+			return createServiceAccountResponse{Err: err}, nil
+		}
+		return createServiceAccountResponse{User: user}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Create API Token
+////////////////////////////////////////////////////////////////////////////////
+
+type createAPITokenRequest struct {
+	UserID  uint
+	payload kolide.APITokenPayload
+}
+
+type createAPITokenResponse struct {
+	// Token is the secret API token, which is only returned on creation.
+	Token    string           `json:"token,omitempty"`
+	APIToken *kolide.APIToken `json:"api_token,omitempty"`
+	Err      error            `json:"error,omitempty"`
+}
+
+func (r createAPITokenResponse) error() error { return r.Err }
+
+func makeCreateAPITokenEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(createAPITokenRequest)
+		token, apiToken, err := svc.NewAPIToken(ctx, req.UserID, req.payload)
+		if err != nil {
+			return createAPITokenResponse{Err: err}, nil
+		}
+		return createAPITokenResponse{Token: token, APIToken: apiToken}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// List API Tokens
+////////////////////////////////////////////////////////////////////////////////
+
+type listAPITokensRequest struct {
+	UserID uint
+}
+
+type listAPITokensResponse struct {
+	APITokens []*kolide.APIToken `json:"api_tokens"`
+	Err       error              `json:"error,omitempty"`
+}
+
+func (r listAPITokensResponse) error() error { return r.Err }
+
+func makeListAPITokensEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listAPITokensRequest)
+		tokens, err := svc.ListAPITokens(ctx, req.UserID)
+		if err != nil {
+			return listAPITokensResponse{Err: err}, nil
+		}
+		return listAPITokensResponse{APITokens: tokens}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Delete API Token
+////////////////////////////////////////////////////////////////////////////////
+
+type deleteAPITokenRequest struct {
+	UserID uint
+	ID     uint
+}
+
+type deleteAPITokenResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r deleteAPITokenResponse) error() error { return r.Err }
+
+func makeDeleteAPITokenEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(deleteAPITokenRequest)
+		err := svc.DeleteAPIToken(ctx, req.UserID, req.ID)
+		if err != nil {
+			return deleteAPITokenResponse{Err: err}, nil
+		}
+		return deleteAPITokenResponse{}, nil
+	}
+}
@@ -44,15 +44,7 @@ func makeGetAppConfigEndpoint(svc kolide.Service) endpoint.Endpoint {
 			if smtpSettings.SMTPPassword != nil {
 				*smtpSettings.SMTPPassword = "********"
 			}
-			ssoSettings = &kolide.SSOSettingsPayload{
-				EntityID:    &config.EntityID,
-				IssuerURI:   &config.IssuerURI,
-				IDPImageURL: &config.IDPImageURL,
-				Metadata:    &config.Metadata,
-				MetadataURL: &config.MetadataURL,
-				IDPName:     &config.IDPName,
-				EnableSSO:   &config.EnableSSO,
-			}
+			ssoSettings = ssoSettingsFromAppConfig(config)
 			hostExpirySettings = &kolide.HostExpirySettings{
 				HostExpiryEnabled: &config.HostExpiryEnabled,
 				HostExpiryWindow:  &config.HostExpiryWindow,
@@ -95,15 +87,7 @@ func makeModifyAppConfigEndpoint(svc kolide.Service) endpoint.Endpoint {
 				LiveQueryDisabled: &config.LiveQueryDisabled,
 			},
 			SMTPSettings: smtpSettingsFromAppConfig(config),
-			SSOSettings: &kolide.SSOSettingsPayload{
-				EntityID:    &config.EntityID,
-				IssuerURI:   &config.IssuerURI,
-				IDPImageURL: &config.IDPImageURL,
-				Metadata:    &config.Metadata,
-				MetadataURL: &config.MetadataURL,
-				IDPName:     &config.IDPName,
-				EnableSSO:   &config.EnableSSO,
-			},
+			SSOSettings:  ssoSettingsFromAppConfig(config),
 			HostExpirySettings: &kolide.HostExpirySettings{
 				HostExpiryEnabled: &config.HostExpiryEnabled,
 				HostExpiryWindow:  &config.HostExpiryWindow,
@@ -116,6 +100,39 @@ func makeModifyAppConfigEndpoint(svc kolide.Service) endpoint.Endpoint {
 	}
 }
 
+// ssoSettingsFromAppConfig returns the SSO settings of the config, with the
+// OIDC client secret masked.
+func ssoSettingsFromAppConfig(config *kolide.AppConfig) *kolide.SSOSettingsPayload {
+	provider := config.SSOProvider
+	if provider == "" {
+		provider = kolide.SSOProviderSAML
+	}
+	clientSecret := config.OIDCClientSecret
+	if clientSecret != "" {
+		clientSecret = "********"
+	}
+	adminGroups := []string(config.JITAdminGroups)
+	userGroups := []string(config.JITUserGroups)
+	return &kolide.SSOSettingsPayload{
+		EntityID:              &config.EntityID,
+		IssuerURI:             &config.IssuerURI,
+		IDPImageURL:           &config.IDPImageURL,
+		Metadata:              &config.Metadata,
+		MetadataURL:           &config.MetadataURL,
+		IDPName:               &config.IDPName,
+		EnableSSO:             &config.EnableSSO,
+		Provider:              &provider,
+		OIDCIssuerURL:         &config.OIDCIssuerURL,
+		OIDCClientID:          &config.OIDCClientID,
+		OIDCClientSecret:      &clientSecret,
+		OIDCUserClaim:         &config.OIDCUserClaim,
+		EnableJITProvisioning: &config.EnableJITProvisioning,
+		JITGroupAttribute:     &config.JITGroupAttribute,
+		JITAdminGroups:        &adminGroups,
+		JITUserGroups:         &userGroups,
+	}
+}
+
 func smtpSettingsFromAppConfig(config *kolide.AppConfig) *kolide.SMTPSettingsPayload {
 	authType := config.SMTPAuthenticationType.String()
 	authMethod := config.SMTPAuthenticationMethod.String()
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/kolide/fleet/server/kolide"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// List Campaign Results
+////////////////////////////////////////////////////////////////////////////////
+
+type listCampaignResultsRequest struct {
+	ID          uint
+	ListOptions kolide.ListOptions
+}
+
+type listCampaignResultsResponse struct {
+	Results []*kolide.CampaignResult `json:"results"`
+	// Truncated is set when results were not stored because the campaign
+	// reached the stored rows limit.
+	Truncated bool  `json:"truncated"`
+	Err       error `json:"error,omitempty"`
+}
+
+func (r listCampaignResultsResponse) error() error { return r.Err }
+
+func makeListCampaignResultsEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listCampaignResultsRequest)
+		results, truncated, err := svc.ListCampaignResults(ctx, req.ID, req.ListOptions)
+		if err != nil {
+			return listCampaignResultsResponse{Err: err}, nil
+		}
+		return listCampaignResultsResponse{Results: results, Truncated: truncated}, nil
+	}
+}
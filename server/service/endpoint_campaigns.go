@@ -1,16 +1,25 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
+	"net/http/httputil"
+	"strings"
+	"time"
 
 	"github.com/go-kit/kit/endpoint"
 	kitlog "github.com/go-kit/kit/log"
 	"github.com/igm/sockjs-go/sockjs"
+	"github.com/kolide/fleet/server/contexts/token"
 	"github.com/kolide/fleet/server/contexts/viewer"
 	"github.com/kolide/fleet/server/kolide"
 	"github.com/kolide/fleet/server/websocket"
+	"github.com/pkg/errors"
 )
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -20,11 +29,13 @@ import (
 type createDistributedQueryCampaignRequest struct {
 	Query    string                          `json:"query"`
 	Selected distributedQueryCampaignTargets `json:"selected"`
+	kolide.CampaignOptions
 }
 
 type distributedQueryCampaignTargets struct {
 	Labels []uint `json:"labels"`
 	Hosts  []uint `json:"hosts"`
+	Tags   []uint `json:"tags"`
 }
 
 type createDistributedQueryCampaignResponse struct {
@@ -37,7 +48,7 @@ func (r createDistributedQueryCampaignResponse) error() error { return r.Err }
 func makeCreateDistributedQueryCampaignEndpoint(svc kolide.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(createDistributedQueryCampaignRequest)
-		campaign, err := svc.NewDistributedQueryCampaign(ctx, req.Query, req.Selected.Hosts, req.Selected.Labels)
+		campaign, err := svc.NewDistributedQueryCampaign(ctx, req.Query, req.Selected.Hosts, req.Selected.Labels, req.Selected.Tags, req.CampaignOptions)
 		if err != nil {
 			return createDistributedQueryCampaignResponse{Err: err}, nil
 		}
@@ -52,17 +63,19 @@ func makeCreateDistributedQueryCampaignEndpoint(svc kolide.Service) endpoint.End
 type createDistributedQueryCampaignByNamesRequest struct {
 	Query    string                                 `json:"query"`
 	Selected distributedQueryCampaignTargetsByNames `json:"selected"`
+	kolide.CampaignOptions
 }
 
 type distributedQueryCampaignTargetsByNames struct {
 	Labels []string `json:"labels"`
 	Hosts  []string `json:"hosts"`
+	Tags   []string `json:"tags"`
 }
 
 func makeCreateDistributedQueryCampaignByNamesEndpoint(svc kolide.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(createDistributedQueryCampaignByNamesRequest)
-		campaign, err := svc.NewDistributedQueryCampaignByNames(ctx, req.Query, req.Selected.Hosts, req.Selected.Labels)
+		campaign, err := svc.NewDistributedQueryCampaignByNames(ctx, req.Query, req.Selected.Hosts, req.Selected.Labels, req.Selected.Tags, req.CampaignOptions)
 		if err != nil {
 			return createDistributedQueryCampaignResponse{Err: err}, nil
 		}
@@ -131,3 +144,215 @@ func makeStreamDistributedQueryCampaignResultsHandler(svc kolide.Service, jwtKey
 
 	})
 }
+
+////////////////////////////////////////////////////////////////////////////////
+// Stream Distributed Query Campaign Results over HTTP
+////////////////////////////////////////////////////////////////////////////////
+
+const (
+	campaignStreamFormatNDJSON = "ndjson"
+	campaignStreamFormatSSE    = "sse"
+)
+
+// httpCampaignStream writes the messages of a campaign results stream to a
+// plain HTTP response, either as newline delimited JSON (one
+// websocket.JSONMessage per line) or as Server-Sent Events (with the message
+// type as the event name).
+type httpCampaignStream struct {
+	w      io.Writer
+	flush  func() error
+	format string
+}
+
+func (s *httpCampaignStream) WriteJSONMessage(typ string, data interface{}) error {
+	var buf bytes.Buffer
+	switch s.format {
+	case campaignStreamFormatSSE:
+		payload, err := json.Marshal(data)
+		if err != nil {
+			return errors.Wrap(err, "marshalling JSON")
+		}
+		fmt.Fprintf(&buf, "event: %s\ndata: %s\n\n", typ, payload)
+	default:
+		if err := json.NewEncoder(&buf).Encode(websocket.JSONMessage{Type: typ, Data: data}); err != nil {
+			return errors.Wrap(err, "marshalling JSON")
+		}
+	}
+
+	if _, err := s.w.Write(buf.Bytes()); err != nil {
+		return errors.Wrap(err, "sending")
+	}
+	return errors.Wrap(s.flush(), "flushing")
+}
+
+func (s *httpCampaignStream) WriteJSONError(data interface{}) error {
+	return s.WriteJSONMessage("error", data)
+}
+
+func campaignStreamFormatFromRequest(r *http.Request) (format string, contentType string, err error) {
+	format = r.URL.Query().Get("format")
+	if format == "" && strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		format = campaignStreamFormatSSE
+	}
+	switch format {
+	case "", campaignStreamFormatNDJSON:
+		return campaignStreamFormatNDJSON, "application/x-ndjson", nil
+	case campaignStreamFormatSSE:
+		return campaignStreamFormatSSE, "text/event-stream", nil
+	default:
+		return "", "", newInvalidArgumentError("format", "format must be one of ndjson or sse")
+	}
+}
+
+// makeStreamCampaignHandler streams the results of a campaign over a plain
+// HTTP response, with the same messages as the websocket stream. The request
+// is authenticated with the usual bearer token.
+func makeStreamCampaignHandler(svc kolide.Service, jwtKey string, logger kitlog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		vc, err := authViewer(ctx, jwtKey, token.FromHTTPRequest(r), svc)
+		if err != nil {
+			encodeError(ctx, err, w)
+			return
+		}
+		if !vc.CanPerformActions() {
+			encodeError(ctx, permissionError{message: "no read permissions"}, w)
+			return
+		}
+		ctx = viewer.NewContext(ctx, *vc)
+
+		id, err := idFromRequest(r, "id")
+		if err != nil {
+			encodeError(ctx, err, w)
+			return
+		}
+		format, contentType, err := campaignStreamFormatFromRequest(r)
+		if err != nil {
+			encodeError(ctx, err, w)
+			return
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		// Streams usually outlive the server read and write timeouts, so
+		// HTTP/1.x connections are taken over from the server (as is
+		// done for websockets) and the response is written by hand.
+		if hj, ok := w.(http.Hijacker); ok && r.ProtoMajor == 1 {
+			conn, bufrw, err := hj.Hijack()
+			if err != nil {
+				logger.Log("err", err, "msg", "hijack campaign stream connection")
+				return
+			}
+			defer conn.Close()
+			conn.SetDeadline(time.Time{})
+
+			// The client sends nothing more on the connection, so
+			// the read only returns once it has gone away.
+			go func() {
+				io.Copy(ioutil.Discard, conn)
+				cancel()
+			}()
+
+			fmt.Fprintf(bufrw, "HTTP/1.1 200 OK\r\nContent-Type: %s\r\nCache-Control: no-cache\r\nTransfer-Encoding: chunked\r\n\r\n", contentType)
+			chunked := httputil.NewChunkedWriter(bufrw)
+			svc.StreamCampaignResults(ctx, &httpCampaignStream{w: chunked, flush: bufrw.Flush, format: format}, id)
+			chunked.Close()
+			bufrw.WriteString("\r\n")
+			bufrw.Flush()
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		flush := func() error {
+			if f, ok := w.(http.Flusher); ok {
+				f.Flush()
+			}
+			return nil
+		}
+		svc.StreamCampaignResults(ctx, &httpCampaignStream{w: w, flush: flush, format: format}, id)
+	})
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// List Distributed Query Campaigns
+////////////////////////////////////////////////////////////////////////////////
+
+type listDistributedQueryCampaignsRequest struct {
+	ListOptions kolide.ListOptions
+	Filter      kolide.DistributedQueryCampaignFilter
+}
+
+type listDistributedQueryCampaignsResponse struct {
+	Campaigns []*kolide.DistributedQueryCampaignSummary `json:"campaigns"`
+	Err       error                                     `json:"error,omitempty"`
+}
+
+func (r listDistributedQueryCampaignsResponse) error() error { return r.Err }
+
+func makeListDistributedQueryCampaignsEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listDistributedQueryCampaignsRequest)
+		campaigns, err := svc.ListDistributedQueryCampaigns(ctx, req.ListOptions, req.Filter)
+		if err != nil {
+			return listDistributedQueryCampaignsResponse{Err: err}, nil
+		}
+		return listDistributedQueryCampaignsResponse{Campaigns: campaigns}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// List Distributed Query Campaign Hosts
+////////////////////////////////////////////////////////////////////////////////
+
+type listDistributedQueryCampaignHostsRequest struct {
+	ID          uint
+	ListOptions kolide.ListOptions
+}
+
+type listDistributedQueryCampaignHostsResponse struct {
+	Hosts []*kolide.DistributedQueryCampaignHost `json:"hosts"`
+	Err   error                                  `json:"error,omitempty"`
+}
+
+func (r listDistributedQueryCampaignHostsResponse) error() error { return r.Err }
+
+func makeListDistributedQueryCampaignHostsEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listDistributedQueryCampaignHostsRequest)
+		hosts, err := svc.ListDistributedQueryCampaignHosts(ctx, req.ID, req.ListOptions)
+		if err != nil {
+			return listDistributedQueryCampaignHostsResponse{Err: err}, nil
+		}
+		return listDistributedQueryCampaignHostsResponse{Hosts: hosts}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Cancel Distributed Query Campaign
+////////////////////////////////////////////////////////////////////////////////
+
+type cancelDistributedQueryCampaignRequest struct {
+	ID uint
+}
+
+type cancelDistributedQueryCampaignResponse struct {
+	Campaign *kolide.DistributedQueryCampaign `json:"campaign,omitempty"`
+	Err      error                            `json:"error,omitempty"`
+}
+
+func (r cancelDistributedQueryCampaignResponse) error() error { return r.Err }
+
+func makeCancelDistributedQueryCampaignEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(cancelDistributedQueryCampaignRequest)
+		campaign, err := svc.CancelDistributedQueryCampaign(ctx, req.ID)
+		if err != nil {
+			return cancelDistributedQueryCampaignResponse{Err: err}, nil
+		}
+		return cancelDistributedQueryCampaignResponse{Campaign: campaign}, nil
+	}
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/kolide/fleet/server/config"
+	"github.com/kolide/fleet/server/datastore/inmem"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPCampaignStreamFormats(t *testing.T) {
+	var buf bytes.Buffer
+	flushes := 0
+	flush := func() error {
+		flushes++
+		return nil
+	}
+
+	stream := &httpCampaignStream{w: &buf, flush: flush, format: campaignStreamFormatNDJSON}
+	require.Nil(t, stream.WriteJSONMessage("status", campaignStatus{ExpectedResults: 2, Status: campaignStatusPending}))
+	require.Nil(t, stream.WriteJSONError("campaign 3 not running"))
+	assert.Equal(t,
+		`{"type":"status","data":{"expected_results":2,"actual_results":0,"status":"pending"}}`+"\n"+
+			`{"type":"error","data":"campaign 3 not running"}`+"\n",
+		buf.String(),
+	)
+	assert.Equal(t, 2, flushes)
+
+	buf.Reset()
+	stream = &httpCampaignStream{w: &buf, flush: flush, format: campaignStreamFormatSSE}
+	require.Nil(t, stream.WriteJSONMessage("totals", targetTotals{Total: 3, Online: 2, Offline: 1}))
+	assert.Equal(t,
+		"event: totals\ndata: {\"count\":3,\"online\":2,\"offline\":1,\"missing_in_action\":0}\n\n",
+		buf.String(),
+	)
+	assert.Equal(t, 3, flushes)
+}
+
+func TestCampaignStreamFormatFromRequest(t *testing.T) {
+	var testCases = []struct {
+		url         string
+		accept      string
+		format      string
+		contentType string
+		err         bool
+	}{
+		{"/stream", "", campaignStreamFormatNDJSON, "application/x-ndjson", false},
+		{"/stream?format=ndjson", "text/event-stream", campaignStreamFormatNDJSON, "application/x-ndjson", false},
+		{"/stream", "text/event-stream", campaignStreamFormatSSE, "text/event-stream", false},
+		{"/stream?format=sse", "", campaignStreamFormatSSE, "text/event-stream", false},
+		{"/stream?format=csv", "", "", "", true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.url+" "+tt.accept, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tt.url, nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+			format, contentType, err := campaignStreamFormatFromRequest(r)
+			if tt.err {
+				require.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, tt.format, format)
+			assert.Equal(t, tt.contentType, contentType)
+		})
+	}
+}
+
+type streamCampaignService struct {
+	kolide.Service
+	campaignID uint
+}
+
+func (svc *streamCampaignService) StreamCampaignResults(ctx context.Context, conn kolide.CampaignStreamWriter, campaignID uint) {
+	svc.campaignID = campaignID
+	conn.WriteJSONMessage("totals", targetTotals{Total: 1, Online: 1})
+	conn.WriteJSONMessage("result", kolide.DistributedQueryResult{
+		DistributedQueryCampaignID: campaignID,
+		Host:                       kolide.Host{HostName: "foo"},
+		Rows:                       []map[string]string{{"bar": "baz"}},
+	})
+}
+
+func TestStreamCampaignHandler(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	require.Nil(t, ds.MigrateData())
+	_, err = ds.NewAppConfig(&kolide.AppConfig{})
+	require.Nil(t, err)
+	createTestUsers(t, ds)
+
+	testSvc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+	svc := &streamCampaignService{Service: testSvc}
+	jwtKey := "CHANGEME"
+	server := httptest.NewServer(MakeHandler(svc, config.KolideConfig{Auth: config.AuthConfig{JwtKey: jwtKey}}, kitlog.NewLogfmtLogger(os.Stdout)))
+	defer server.Close()
+
+	_, token, err := testSvc.Login(context.Background(), "user1", testUsers["user1"].PlaintextPassword)
+	require.Nil(t, err)
+
+	// Unauthenticated requests are rejected
+	resp, err := http.Get(server.URL + "/api/v1/kolide/campaigns/3/stream")
+	require.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	req, err := http.NewRequest("GET", server.URL+"/api/v1/kolide/campaigns/3/stream", nil)
+	require.Nil(t, err)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err = http.DefaultClient.Do(req)
+	require.Nil(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+
+	var types []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var msg struct {
+			Type string          `json:"type"`
+			Data json.RawMessage `json:"data"`
+		}
+		require.Nil(t, json.Unmarshal(scanner.Bytes(), &msg))
+		types = append(types, msg.Type)
+	}
+	require.Nil(t, scanner.Err())
+	assert.Equal(t, []string{"totals", "result"}, types)
+	assert.Equal(t, uint(3), svc.campaignID)
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/kolide/fleet/server/kolide"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Export Hosts
+////////////////////////////////////////////////////////////////////////////////
+
+// hostExportColumn extracts the value of an export column from a host.
+// Values are written as is in NDJSON exports and formatted with
+// formatHostExportValue in CSV exports.
+type hostExportColumn func(h *kolide.HostExport) interface{}
+
+// hostExportColumns maps the names of the columns available in host exports
+// to their value.
+var hostExportColumns = map[string]hostExportColumn{
+	"id":                 func(h *kolide.HostExport) interface{} { return h.Host.ID },
+	"hostname":           func(h *kolide.HostExport) interface{} { return h.Host.HostName },
+	"computer_name":      func(h *kolide.HostExport) interface{} { return h.Host.ComputerName },
+	"uuid":               func(h *kolide.HostExport) interface{} { return h.Host.UUID },
+	"platform":           func(h *kolide.HostExport) interface{} { return h.Host.Platform },
+	"os_version":         func(h *kolide.HostExport) interface{} { return h.Host.OSVersion },
+	"build":              func(h *kolide.HostExport) interface{} { return h.Host.Build },
+	"osquery_version":    func(h *kolide.HostExport) interface{} { return h.Host.OsqueryVersion },
+	"hardware_vendor":    func(h *kolide.HostExport) interface{} { return h.Host.HardwareVendor },
+	"hardware_model":     func(h *kolide.HostExport) interface{} { return h.Host.HardwareModel },
+	"hardware_serial":    func(h *kolide.HostExport) interface{} { return h.Host.HardwareSerial },
+	"cpu_brand":          func(h *kolide.HostExport) interface{} { return h.Host.CPUBrand },
+	"cpu_physical_cores": func(h *kolide.HostExport) interface{} { return h.Host.CPUPhysicalCores },
+	"cpu_logical_cores":  func(h *kolide.HostExport) interface{} { return h.Host.CPULogicalCores },
+	"memory":             func(h *kolide.HostExport) interface{} { return h.Host.PhysicalMemory },
+	"uptime":             func(h *kolide.HostExport) interface{} { return int64(h.Host.Uptime / time.Second) },
+	"status":             func(h *kolide.HostExport) interface{} { return h.Status },
+	"seen_time":          func(h *kolide.HostExport) interface{} { return h.Host.SeenTime },
+	"created_at":         func(h *kolide.HostExport) interface{} { return h.Host.CreatedAt },
+	"enroll_secret_name": func(h *kolide.HostExport) interface{} { return h.Host.EnrollSecretName },
+	"primary_ip": func(h *kolide.HostExport) interface{} {
+		if nic := primaryNetworkInterface(h.Host); nic != nil {
+			return nic.IPAddress
+		}
+		return ""
+	},
+	"primary_mac": func(h *kolide.HostExport) interface{} {
+		if nic := primaryNetworkInterface(h.Host); nic != nil {
+			return nic.MAC
+		}
+		return ""
+	},
+	"network_interfaces": func(h *kolide.HostExport) interface{} {
+		nics := make([]hostExportNetworkInterface, 0, len(h.Host.NetworkInterfaces))
+		for _, nic := range h.Host.NetworkInterfaces {
+			nics = append(nics, hostExportNetworkInterface{
+				Interface: nic.Interface,
+				IPAddress: nic.IPAddress,
+				MAC:       nic.MAC,
+			})
+		}
+		return nics
+	},
+	"labels": func(h *kolide.HostExport) interface{} { return h.Labels },
+	"additional": func(h *kolide.HostExport) interface{} {
+		if h.Host.Additional == nil {
+			return nil
+		}
+		return h.Host.Additional
+	},
+}
+
+// defaultHostExportColumns are the columns exported when none are requested.
+var defaultHostExportColumns = []string{
+	"id", "hostname", "uuid", "platform", "os_version", "osquery_version",
+	"hardware_vendor", "hardware_model", "hardware_serial", "status",
+	"seen_time", "primary_ip", "primary_mac", "network_interfaces", "labels",
+	"additional",
+}
+
+type hostExportNetworkInterface struct {
+	Interface string `json:"interface"`
+	IPAddress string `json:"address"`
+	MAC       string `json:"mac"`
+}
+
+func (nic hostExportNetworkInterface) String() string {
+	return fmt.Sprintf("%s=%s/%s", nic.Interface, nic.IPAddress, nic.MAC)
+}
+
+func primaryNetworkInterface(host *kolide.Host) *kolide.NetworkInterface {
+	if host.PrimaryNetworkInterfaceID == nil {
+		return nil
+	}
+	for _, nic := range host.NetworkInterfaces {
+		if nic.ID == *host.PrimaryNetworkInterfaceID {
+			return nic
+		}
+	}
+	return nil
+}
+
+// formatHostExportValue formats a column value for CSV exports. Lists are
+// joined with ";" and JSON values are written as JSON.
+func formatHostExportValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case time.Time:
+		return v.UTC().Format(time.RFC3339), nil
+	case []string:
+		return strings.Join(v, ";"), nil
+	case []hostExportNetworkInterface:
+		parts := make([]string, 0, len(v))
+		for _, nic := range v {
+			parts = append(parts, nic.String())
+		}
+		return strings.Join(parts, ";"), nil
+	case *json.RawMessage:
+		return string(*v), nil
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	}
+	return "", fmt.Errorf("unsupported export value type %T", value)
+}
+
+// escapeCSVFormula prefixes values that spreadsheet applications would
+// interpret as a formula with a single quote, so that host reported values
+// (e.g. a hostname of "=HYPERLINK(...)") are displayed as text.
+func escapeCSVFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+type exportHostsRequest struct {
+	Format  kolide.HostExportFormat
+	Columns []string
+	Filter  *kolide.HostFilter
+}
+
+type exportHostsResponse struct {
+	Err error `json:"error,omitempty"`
+
+	format  kolide.HostExportFormat
+	columns []string
+	export  func(fn func(*kolide.HostExport) error) error
+}
+
+func (r exportHostsResponse) error() error { return r.Err }
+
+// stream writes the export as the response body, paging through the hosts
+// as they are written. Once the body has been started the status can no
+// longer be changed, so errors are reported by truncating the export (and
+// with a final error object in NDJSON exports).
+func (r exportHostsResponse) stream(w http.ResponseWriter) error {
+	var (
+		write func(*kolide.HostExport) error
+		flush func() error
+	)
+	switch r.format {
+	case kolide.HostExportNDJSON:
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		enc := json.NewEncoder(w)
+		write = func(h *kolide.HostExport) error {
+			row := make(map[string]interface{}, len(r.columns))
+			for _, column := range r.columns {
+				row[column] = hostExportColumns[column](h)
+			}
+			return enc.Encode(row)
+		}
+		flush = func() error { return nil }
+
+	default:
+		w.Header().Set("Content-Type", "text/csv; charset=UTF-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="hosts.csv"`)
+		cw := csv.NewWriter(w)
+		if err := cw.Write(r.columns); err != nil {
+			return err
+		}
+		record := make([]string, len(r.columns))
+		write = func(h *kolide.HostExport) error {
+			for i, column := range r.columns {
+				value, err := formatHostExportValue(hostExportColumns[column](h))
+				if err != nil {
+					return err
+				}
+				record[i] = escapeCSVFormula(value)
+			}
+			return cw.Write(record)
+		}
+		flush = func() error {
+			cw.Flush()
+			return cw.Error()
+		}
+	}
+
+	err := r.export(write)
+	if flushErr := flush(); err == nil {
+		err = flushErr
+	}
+	if err != nil && r.format == kolide.HostExportNDJSON {
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+	}
+	return err
+}
+
+func makeExportHostsEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(exportHostsRequest)
+		return exportHostsResponse{
+			format:  req.Format,
+			columns: req.Columns,
+			export: func(fn func(*kolide.HostExport) error) error {
+				return svc.ExportHosts(ctx, kolide.HostListOptions{Filter: req.Filter}, fn)
+			},
+		}, nil
+	}
+}
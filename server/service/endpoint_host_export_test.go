@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportHostsResponseStream(t *testing.T) {
+	primary := uint(2)
+	seen := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	export := func(fn func(*kolide.HostExport) error) error {
+		return fn(&kolide.HostExport{
+			Host: &kolide.Host{
+				ID:                        1,
+				HostName:                  "web1",
+				SeenTime:                  seen,
+				PrimaryNetworkInterfaceID: &primary,
+				NetworkInterfaces: []*kolide.NetworkInterface{
+					{ID: 1, Interface: "lo0", IPAddress: "127.0.0.1"},
+					{ID: 2, Interface: "en0", IPAddress: "10.0.0.2", MAC: "aa:bb:cc:dd:ee:ff"},
+				},
+			},
+			Status: "online",
+			Labels: []string{"All Hosts", "macOS"},
+		})
+	}
+	columns := []string{"id", "hostname", "seen_time", "primary_ip", "labels", "network_interfaces"}
+
+	rec := httptest.NewRecorder()
+	err := encodeResponse(context.Background(), rec, exportHostsResponse{
+		format:  kolide.HostExportCSV,
+		columns: columns,
+		export:  export,
+	})
+	require.Nil(t, err)
+	assert.Equal(t, "text/csv; charset=UTF-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t,
+		"id,hostname,seen_time,primary_ip,labels,network_interfaces\n"+
+			"1,web1,2020-06-01T12:00:00Z,10.0.0.2,All Hosts;macOS,lo0=127.0.0.1/;en0=10.0.0.2/aa:bb:cc:dd:ee:ff\n",
+		rec.Body.String(),
+	)
+
+	rec = httptest.NewRecorder()
+	err = encodeResponse(context.Background(), rec, exportHostsResponse{
+		format:  kolide.HostExportNDJSON,
+		columns: columns,
+		export:  export,
+	})
+	require.Nil(t, err)
+	assert.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	require.Len(t, lines, 1)
+	var row map[string]interface{}
+	require.Nil(t, json.Unmarshal([]byte(lines[0]), &row))
+	assert.Equal(t, "web1", row["hostname"])
+	assert.Equal(t, []interface{}{"All Hosts", "macOS"}, row["labels"])
+	assert.Len(t, row["network_interfaces"], 2)
+}
+
+func TestExportHostsCSVFormulaEscaping(t *testing.T) {
+	rec := httptest.NewRecorder()
+	err := encodeResponse(context.Background(), rec, exportHostsResponse{
+		format:  kolide.HostExportCSV,
+		columns: []string{"hostname", "os_version", "hardware_serial", "computer_name", "labels"},
+		export: func(fn func(*kolide.HostExport) error) error {
+			return fn(&kolide.HostExport{
+				Host: &kolide.Host{
+					HostName:       `=HYPERLINK("http://example.com")`,
+					OSVersion:      "+1",
+					HardwareSerial: "-2",
+					ComputerName:   "@SUM(A1)",
+				},
+				Labels: []string{"All Hosts", "=1+1"},
+			})
+		},
+	})
+	require.Nil(t, err)
+	assert.Equal(t,
+		"hostname,os_version,hardware_serial,computer_name,labels\n"+
+			`"'=HYPERLINK(""http://example.com"")",'+1,'-2,'@SUM(A1),All Hosts;=1+1`+"\n",
+		rec.Body.String(),
+	)
+}
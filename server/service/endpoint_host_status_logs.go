@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/kolide/fleet/server/kolide"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// List Host Status Logs
+////////////////////////////////////////////////////////////////////////////////
+
+type listHostStatusLogsRequest struct {
+	ID          uint
+	ListOptions kolide.ListOptions
+}
+
+type listHostStatusLogsResponse struct {
+	StatusLogs []*kolide.HostStatusLog `json:"status_logs"`
+	Err        error                   `json:"error,omitempty"`
+}
+
+func (r listHostStatusLogsResponse) error() error { return r.Err }
+
+func makeListHostStatusLogsEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listHostStatusLogsRequest)
+		logs, err := svc.ListHostStatusLogs(ctx, req.ID, req.ListOptions)
+		if err != nil {
+			return listHostStatusLogsResponse{Err: err}, nil
+		}
+		return listHostStatusLogsResponse{StatusLogs: logs}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// List Scheduled Query Errors
+////////////////////////////////////////////////////////////////////////////////
+
+type listScheduledQueryErrorsResponse struct {
+	Errors []*kolide.ScheduledQueryErrors `json:"scheduled_query_errors"`
+	Err    error                          `json:"error,omitempty"`
+}
+
+func (r listScheduledQueryErrorsResponse) error() error { return r.Err }
+
+func makeListScheduledQueryErrorsEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		summaries, err := svc.ListScheduledQueryErrors(ctx)
+		if err != nil {
+			return listScheduledQueryErrorsResponse{Err: err}, nil
+		}
+		return listScheduledQueryErrorsResponse{Errors: summaries}, nil
+	}
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/kolide/fleet/server/kolide"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Modify Host Tags
+////////////////////////////////////////////////////////////////////////////////
+
+type modifyHostTagsRequest struct {
+	ID   uint               `json:"-"`
+	Tags map[string]*string `json:"tags"`
+}
+
+type modifyHostTagsResponse struct {
+	Tags map[string]string `json:"tags"`
+	Err  error             `json:"error,omitempty"`
+}
+
+func (r modifyHostTagsResponse) error() error { return r.Err }
+
+func makeModifyHostTagsEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(modifyHostTagsRequest)
+		tags, err := svc.ModifyHostTags(ctx, req.ID, req.Tags)
+		if err != nil {
+			return modifyHostTagsResponse{Err: err}, nil
+		}
+		return modifyHostTagsResponse{Tags: tags}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Apply Host Tag Specs
+////////////////////////////////////////////////////////////////////////////////
+
+type applyHostTagSpecsRequest struct {
+	Specs []*kolide.HostTagSpec `json:"specs"`
+}
+
+type applyHostTagSpecsResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r applyHostTagSpecsResponse) error() error { return r.Err }
+
+func makeApplyHostTagSpecsEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(applyHostTagSpecsRequest)
+		err := svc.ApplyHostTagSpecs(ctx, req.Specs)
+		if err != nil {
+			return applyHostTagSpecsResponse{Err: err}, nil
+		}
+		return applyHostTagSpecsResponse{}, nil
+	}
+}
@@ -64,7 +64,7 @@ func makeGetHostEndpoint(svc kolide.Service) endpoint.Endpoint {
 ////////////////////////////////////////////////////////////////////////////////
 
 type listHostsRequest struct {
-	ListOptions kolide.ListOptions
+	ListOptions kolide.HostListOptions
 }
 
 type listHostsResponse struct {
@@ -144,3 +144,163 @@ func makeDeleteHostEndpoint(svc kolide.Service) endpoint.Endpoint {
 		return deleteHostResponse{}, nil
 	}
 }
+
+////////////////////////////////////////////////////////////////////////////////
+// List Host History
+////////////////////////////////////////////////////////////////////////////////
+
+type listHostHistoryRequest struct {
+	ID          uint
+	ListOptions kolide.ListOptions
+}
+
+type listHostHistoryResponse struct {
+	History []*kolide.HostHistory `json:"history"`
+	Err     error                 `json:"error,omitempty"`
+}
+
+func (r listHostHistoryResponse) error() error { return r.Err }
+
+func makeListHostHistoryEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listHostHistoryRequest)
+		history, err := svc.ListHostHistory(ctx, req.ID, req.ListOptions)
+		if err != nil {
+			return listHostHistoryResponse{Err: err}, nil
+		}
+		return listHostHistoryResponse{History: history}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// List Host Labels
+////////////////////////////////////////////////////////////////////////////////
+
+type listHostLabelsRequest struct {
+	ID uint
+}
+
+type listHostLabelsResponse struct {
+	Labels []*kolide.HostLabelExecution `json:"labels"`
+	Err    error                        `json:"error,omitempty"`
+}
+
+func (r listHostLabelsResponse) error() error { return r.Err }
+
+func makeListHostLabelsEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listHostLabelsRequest)
+		labels, err := svc.ListHostLabels(ctx, req.ID)
+		if err != nil {
+			return listHostLabelsResponse{Err: err}, nil
+		}
+		return listHostLabelsResponse{Labels: labels}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Merge Hosts
+////////////////////////////////////////////////////////////////////////////////
+
+type mergeHostsRequest struct {
+	ID          uint
+	DuplicateID uint `json:"duplicate_id"`
+}
+
+type mergeHostsResponse struct {
+	Host *HostResponse `json:"host"`
+	Err  error         `json:"error,omitempty"`
+}
+
+func (r mergeHostsResponse) error() error { return r.Err }
+
+func makeMergeHostsEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(mergeHostsRequest)
+		host, err := svc.MergeHosts(ctx, req.ID, req.DuplicateID)
+		if err != nil {
+			return mergeHostsResponse{Err: err}, nil
+		}
+
+		resp, err := hostResponseForHost(ctx, svc, host)
+		if err != nil {
+			return mergeHostsResponse{Err: err}, nil
+		}
+
+		return mergeHostsResponse{Host: resp}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// List Expired Hosts
+////////////////////////////////////////////////////////////////////////////////
+
+type listExpiredHostsRequest struct {
+	Window int
+}
+
+type listExpiredHostsResponse struct {
+	Hosts []HostResponse `json:"hosts"`
+	Err   error          `json:"error,omitempty"`
+}
+
+func (r listExpiredHostsResponse) error() error { return r.Err }
+
+func makeListExpiredHostsEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listExpiredHostsRequest)
+		hosts, err := svc.ListExpiredHosts(ctx, req.Window)
+		if err != nil {
+			return listExpiredHostsResponse{Err: err}, nil
+		}
+
+		hostResponses := make([]HostResponse, len(hosts))
+		for i, host := range hosts {
+			h, err := hostResponseForHost(ctx, svc, host)
+			if err != nil {
+				return listExpiredHostsResponse{Err: err}, nil
+			}
+			hostResponses[i] = *h
+		}
+		return listExpiredHostsResponse{Hosts: hostResponses}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Delete Hosts
+////////////////////////////////////////////////////////////////////////////////
+
+type deleteHostsRequest struct {
+	IDs          []uint `json:"ids"`
+	LabelID      *uint  `json:"label_id"`
+	Filter       string `json:"filter"`
+	ConfirmToken string `json:"confirm_token"`
+}
+
+type deleteHostsResponse struct {
+	*kolide.BulkHostDeleteResult
+	Err error `json:"error,omitempty"`
+}
+
+func (r deleteHostsResponse) error() error { return r.Err }
+
+func makeDeleteHostsEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(deleteHostsRequest)
+		filter, err := kolide.ParseHostFilter(req.Filter)
+		if err != nil {
+			return deleteHostsResponse{Err: newInvalidArgumentError("filter", err.Error())}, nil
+		}
+
+		result, err := svc.DeleteHosts(ctx, kolide.BulkHostDeleteOptions{
+			HostIDs:      req.IDs,
+			LabelID:      req.LabelID,
+			Filter:       filter,
+			ConfirmToken: req.ConfirmToken,
+		})
+		if err != nil {
+			return deleteHostsResponse{Err: err}, nil
+		}
+		return deleteHostsResponse{BulkHostDeleteResult: result}, nil
+	}
+}
@@ -29,7 +29,7 @@ type getLabelResponse struct {
 func (r getLabelResponse) error() error { return r.Err }
 
 func labelResponseForLabel(ctx context.Context, svc kolide.Service, label *kolide.Label) (*labelResponse, error) {
-	metrics, err := svc.CountHostsInTargets(ctx, nil, []uint{label.ID})
+	metrics, err := svc.CountHostsInTargets(ctx, nil, []uint{label.ID}, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -227,7 +227,8 @@ type applyLabelSpecsRequest struct {
 }
 
 type applyLabelSpecsResponse struct {
-	Err error `json:"error,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+	Err      error    `json:"error,omitempty"`
 }
 
 func (r applyLabelSpecsResponse) error() error { return r.Err }
@@ -235,11 +236,11 @@ func (r applyLabelSpecsResponse) error() error { return r.Err }
 func makeApplyLabelSpecsEndpoint(svc kolide.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(applyLabelSpecsRequest)
-		err := svc.ApplyLabelSpecs(ctx, req.Specs)
+		warnings, err := svc.ApplyLabelSpecs(ctx, req.Specs)
 		if err != nil {
 			return applyLabelSpecsResponse{Err: err}, nil
 		}
-		return applyLabelSpecsResponse{}, nil
+		return applyLabelSpecsResponse{Warnings: warnings}, nil
 	}
 }
 
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"reflect"
 
 	jwt "github.com/dgrijalva/jwt-go"
@@ -83,8 +84,19 @@ func authenticatedUser(jwtKey string, svc kolide.Service, next endpoint.Endpoint
 	}
 }
 
-// authViewer creates an authenticated viewer by validating a JWT token.
+// authViewer creates an authenticated viewer by validating a JWT token or an
+// API token.
 func authViewer(ctx context.Context, jwtKey string, bearerToken token.Token, svc kolide.Service) (*viewer.Viewer, error) {
+	if kolide.IsAPIToken(string(bearerToken)) {
+		user, apiToken, err := svc.AuthenticateAPIToken(ctx, string(bearerToken))
+		if e, ok := err.(authError); ok {
+			return nil, e
+		}
+		if err != nil {
+			return nil, authError{reason: err.Error()}
+		}
+		return &viewer.Viewer{User: user, Token: apiToken}, nil
+	}
 	jwtToken, err := jwt.Parse(string(bearerToken), func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.Errorf("Unexpected signing method: %v", token.Header["alg"])
@@ -120,30 +132,40 @@ func authViewer(ctx context.Context, jwtKey string, bearerToken token.Token, svc
 	return &viewer.Viewer{User: user, Session: session}, nil
 }
 
-func mustBeAdmin(next endpoint.Endpoint) endpoint.Endpoint {
+// mustHaveRole wraps an endpoint, and requires that the viewer has at least the
+// permissions of the role. It is the policy check of all the endpoints that
+// are not specific to the requested user.
+func mustHaveRole(role kolide.Role, next endpoint.Endpoint) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		vc, ok := viewer.FromContext(ctx)
 		if !ok {
 			return nil, errNoContext
 		}
-		if !vc.CanPerformAdminActions() {
-			return nil, permissionError{message: "must be an admin"}
+		if !vc.CanPerformActions() {
+			return nil, permissionError{message: "no read permissions"}
+		}
+		if !vc.HasRole(role) {
+			switch role {
+			case kolide.RoleAdmin:
+				return nil, permissionError{message: "must be an admin"}
+			default:
+				return nil, permissionError{message: fmt.Sprintf("must be a %s or an admin", role)}
+			}
 		}
 		return next(ctx, request)
 	}
 }
 
+func mustBeAdmin(next endpoint.Endpoint) endpoint.Endpoint {
+	return mustHaveRole(kolide.RoleAdmin, next)
+}
+
+func mustBeMaintainer(next endpoint.Endpoint) endpoint.Endpoint {
+	return mustHaveRole(kolide.RoleMaintainer, next)
+}
+
 func canPerformActions(next endpoint.Endpoint) endpoint.Endpoint {
-	return func(ctx context.Context, request interface{}) (interface{}, error) {
-		vc, ok := viewer.FromContext(ctx)
-		if !ok {
-			return nil, errNoContext
-		}
-		if !vc.CanPerformActions() {
-			return nil, permissionError{message: "no read permissions"}
-		}
-		return next(ctx, request)
-	}
+	return mustHaveRole(kolide.RoleObserver, next)
 }
 
 func canReadUser(next endpoint.Endpoint) endpoint.Endpoint {
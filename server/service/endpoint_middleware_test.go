@@ -8,6 +8,7 @@ import (
 	"github.com/go-kit/kit/endpoint"
 	"github.com/kolide/fleet/server/config"
 	hostctx "github.com/kolide/fleet/server/contexts/host"
+	"github.com/kolide/fleet/server/contexts/token"
 	"github.com/kolide/fleet/server/contexts/viewer"
 	"github.com/kolide/fleet/server/datastore/inmem"
 	"github.com/kolide/fleet/server/kolide"
@@ -52,6 +53,9 @@ func TestEndpointPermissions(t *testing.T) {
 	assert.Nil(t, err)
 	user2.Enabled = false
 
+	observer := *user1
+	observer.SetRole(kolide.RoleObserver)
+
 	e := endpoint.Nop // a test endpoint
 	var endpointTests = []struct {
 		endpoint endpoint.Endpoint
@@ -85,6 +89,24 @@ func TestEndpointPermissions(t *testing.T) {
 			vc:       &viewer.Viewer{User: user1, Session: user1Session},
 			wantErr:  permissionError{message: "must be an admin"},
 		},
+		{
+			endpoint: mustBeMaintainer(e),
+			vc:       &viewer.Viewer{User: user1, Session: user1Session},
+		},
+		{
+			endpoint: mustBeMaintainer(e),
+			vc:       &viewer.Viewer{User: &observer, Session: user1Session},
+			wantErr:  permissionError{message: "must be a maintainer or an admin"},
+		},
+		{
+			endpoint: canPerformActions(e),
+			vc:       &viewer.Viewer{User: &observer, Session: user1Session},
+		},
+		{
+			endpoint: mustBeAdmin(e),
+			vc:       &viewer.Viewer{User: &observer, Session: user1Session},
+			wantErr:  permissionError{message: "must be an admin"},
+		},
 		{
 			endpoint: canModifyUser(e),
 			vc:       &viewer.Viewer{User: admin1, Session: admin1Session},
@@ -262,3 +284,41 @@ func TestAuthenticatedHost(t *testing.T) {
 	}
 
 }
+
+func TestAuthViewerAPIToken(t *testing.T) {
+	ds := new(mock.Store)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+
+	serviceAccount := &kolide.User{ID: 5, Username: "bot", Enabled: true, APIOnly: true}
+	apiToken := &kolide.APIToken{
+		ID:         1,
+		UserID:     serviceAccount.ID,
+		Hash:       kolide.HashAPIToken("fleet_secret"),
+		Role:       kolide.RoleObserver,
+		LastUsedAt: &time.Time{},
+	}
+	ds.APITokenByHashFunc = func(hash []byte) (*kolide.APIToken, error) {
+		if string(hash) != string(apiToken.Hash) {
+			return nil, notFoundError{}
+		}
+		return apiToken, nil
+	}
+	ds.UserByIDFunc = func(id uint) (*kolide.User, error) {
+		return serviceAccount, nil
+	}
+	ds.MarkAPITokenUsedFunc = func(id uint, usedAt time.Time) error {
+		return nil
+	}
+
+	v, err := authViewer(context.Background(), "CHANGEME", token.Token("fleet_secret"), svc)
+	require.Nil(t, err)
+	assert.Equal(t, serviceAccount, v.User)
+	assert.Equal(t, apiToken, v.Token)
+	assert.True(t, v.HasRole(kolide.RoleObserver))
+	assert.False(t, v.HasRole(kolide.RoleMaintainer))
+
+	_, err = authViewer(context.Background(), "CHANGEME", token.Token("fleet_wrong"), svc)
+	require.NotNil(t, err)
+	assert.IsType(t, authError{}, err)
+}
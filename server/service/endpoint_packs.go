@@ -41,7 +41,7 @@ func packResponseForPack(ctx context.Context, svc kolide.Service, pack kolide.Pa
 		return nil, err
 	}
 
-	hostMetrics, err := svc.CountHostsInTargets(ctx, hosts, labelIDs)
+	hostMetrics, err := svc.CountHostsInTargets(ctx, hosts, labelIDs, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -253,7 +253,8 @@ type applyPackSpecsRequest struct {
 }
 
 type applyPackSpecsResponse struct {
-	Err error `json:"error,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+	Err      error    `json:"error,omitempty"`
 }
 
 func (r applyPackSpecsResponse) error() error { return r.Err }
@@ -261,11 +262,11 @@ func (r applyPackSpecsResponse) error() error { return r.Err }
 func makeApplyPackSpecsEndpoint(svc kolide.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(applyPackSpecsRequest)
-		err := svc.ApplyPackSpecs(ctx, req.Specs)
+		warnings, err := svc.ApplyPackSpecs(ctx, req.Specs)
 		if err != nil {
 			return applyPackSpecsResponse{Err: err}, nil
 		}
-		return applyPackSpecsResponse{}, nil
+		return applyPackSpecsResponse{Warnings: warnings}, nil
 	}
 }
 
@@ -201,7 +201,8 @@ type applyQuerySpecsRequest struct {
 }
 
 type applyQuerySpecsResponse struct {
-	Err error `json:"error,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+	Err      error    `json:"error,omitempty"`
 }
 
 func (r applyQuerySpecsResponse) error() error { return r.Err }
@@ -209,11 +210,11 @@ func (r applyQuerySpecsResponse) error() error { return r.Err }
 func makeApplyQuerySpecsEndpoint(svc kolide.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(applyQuerySpecsRequest)
-		err := svc.ApplyQuerySpecs(ctx, req.Specs)
+		warnings, err := svc.ApplyQuerySpecs(ctx, req.Specs)
 		if err != nil {
 			return applyQuerySpecsResponse{Err: err}, nil
 		}
-		return applyQuerySpecsResponse{}, nil
+		return applyQuerySpecsResponse{Warnings: warnings}, nil
 	}
 }
 
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/kolide/fleet/server/kolide"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// List Host Software
+////////////////////////////////////////////////////////////////////////////////
+
+type listHostSoftwareRequest struct {
+	ID uint `json:"id"`
+}
+
+type listHostSoftwareResponse struct {
+	Software []kolide.Software `json:"software"`
+	Err      error             `json:"error,omitempty"`
+}
+
+func (r listHostSoftwareResponse) error() error { return r.Err }
+
+func makeListHostSoftwareEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listHostSoftwareRequest)
+		software, err := svc.ListHostSoftware(ctx, req.ID)
+		if err != nil {
+			return listHostSoftwareResponse{Err: err}, nil
+		}
+		return listHostSoftwareResponse{Software: software}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// List Software
+////////////////////////////////////////////////////////////////////////////////
+
+type listSoftwareRequest struct {
+	Name        string
+	Version     string
+	ListOptions kolide.ListOptions
+}
+
+type listSoftwareResponse struct {
+	Software []*kolide.Software `json:"software"`
+	Err      error              `json:"error,omitempty"`
+}
+
+func (r listSoftwareResponse) error() error { return r.Err }
+
+func makeListSoftwareEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listSoftwareRequest)
+		software, err := svc.ListSoftware(ctx, req.Name, req.Version, req.ListOptions)
+		if err != nil {
+			return listSoftwareResponse{Err: err}, nil
+		}
+		return listSoftwareResponse{Software: software}, nil
+	}
+}
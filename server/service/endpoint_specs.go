@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/kolide/fleet/server/kolide"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Validate Specs
+////////////////////////////////////////////////////////////////////////////////
+
+type validateSpecsRequest struct {
+	Queries []*kolide.QuerySpec `json:"queries"`
+	Labels  []*kolide.LabelSpec `json:"labels"`
+	Packs   []*kolide.PackSpec  `json:"packs"`
+}
+
+type validateSpecsResponse struct {
+	*kolide.QueryValidation
+	Err error `json:"error,omitempty"`
+}
+
+func (r validateSpecsResponse) error() error { return r.Err }
+
+func makeValidateSpecsEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(validateSpecsRequest)
+		validation, err := svc.ValidateSpecs(ctx, req.Queries, req.Labels, req.Packs)
+		if err != nil {
+			return validateSpecsResponse{Err: err}, nil
+		}
+		return validateSpecsResponse{QueryValidation: validation}, nil
+	}
+}
@@ -17,6 +17,7 @@ type searchTargetsRequest struct {
 	Selected struct {
 		Labels []uint `json:"labels"`
 		Hosts  []uint `json:"hosts"`
+		Tags   []uint `json:"tags"`
 	} `json:"selected"`
 }
 
@@ -77,7 +78,7 @@ func makeSearchTargetsEndpoint(svc kolide.Service) endpoint.Endpoint {
 		}
 
 		for _, label := range results.Labels {
-			metrics, err := svc.CountHostsInTargets(ctx, nil, []uint{label.ID})
+			metrics, err := svc.CountHostsInTargets(ctx, nil, []uint{label.ID}, nil)
 			if err != nil {
 				return searchTargetsResponse{Err: err}, nil
 			}
@@ -93,7 +94,7 @@ func makeSearchTargetsEndpoint(svc kolide.Service) endpoint.Endpoint {
 			)
 		}
 
-		metrics, err := svc.CountHostsInTargets(ctx, req.Selected.Hosts, req.Selected.Labels)
+		metrics, err := svc.CountHostsInTargets(ctx, req.Selected.Hosts, req.Selected.Labels, req.Selected.Tags)
 		if err != nil {
 			return searchTargetsResponse{Err: err}, nil
 		}
@@ -83,6 +83,29 @@ func makeAdminUserEndpoint(svc kolide.Service) endpoint.Endpoint {
 	}
 }
 
+type changeUserRoleRequest struct {
+	ID   uint        `json:"id"`
+	Role kolide.Role `json:"role"`
+}
+
+type changeUserRoleResponse struct {
+	User *kolide.User `json:"user,omitempty"`
+	Err  error        `json:"error,omitempty"`
+}
+
+func (r changeUserRoleResponse) error() error { return r.Err }
+
+func makeChangeUserRoleEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(changeUserRoleRequest)
+		user, err := svc.ChangeUserRole(ctx, req.ID, req.Role)
+		if err != nil {
+			return changeUserRoleResponse{Err: err}, nil
+		}
+		return changeUserRoleResponse{User: user}, nil
+	}
+}
+
 type enableUserRequest struct {
 	ID      uint `json:"id"`
 	Enabled bool `json:"enabled"`
@@ -300,3 +323,49 @@ func makeForgotPasswordEndpoint(svc kolide.Service) endpoint.Endpoint {
 		return forgotPasswordResponse{}, nil
 	}
 }
+
+////////////////////////////////////////////////////////////////////////////////
+// Apply User Roles Spec
+////////////////////////////////////////////////////////////////////////////////
+
+type applyUserRolesSpecRequest struct {
+	Spec *kolide.UserRolesSpec `json:"spec"`
+}
+
+type applyUserRolesSpecResponse struct {
+	Err error `json:"error,omitempty"`
+}
+
+func (r applyUserRolesSpecResponse) error() error { return r.Err }
+
+func makeApplyUserRolesSpecEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(applyUserRolesSpecRequest)
+		err := svc.ApplyUserRolesSpec(ctx, req.Spec)
+		if err != nil {
+			return applyUserRolesSpecResponse{Err: err}, nil
+		}
+		return applyUserRolesSpecResponse{}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Get User Roles Spec
+////////////////////////////////////////////////////////////////////////////////
+
+type getUserRolesSpecResponse struct {
+	Spec *kolide.UserRolesSpec `json:"specs"`
+	Err  error                 `json:"error,omitempty"`
+}
+
+func (r getUserRolesSpecResponse) error() error { return r.Err }
+
+func makeGetUserRolesSpecEndpoint(svc kolide.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		spec, err := svc.GetUserRolesSpec(ctx)
+		if err != nil {
+			return getUserRolesSpecResponse{Err: err}, nil
+		}
+		return getUserRolesSpecResponse{Spec: spec}, nil
+	}
+}
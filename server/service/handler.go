@@ -27,13 +27,20 @@ type KolideEndpoints struct {
 	ListUsers                             endpoint.Endpoint
 	ModifyUser                            endpoint.Endpoint
 	AdminUser                             endpoint.Endpoint
+	ChangeUserRole                        endpoint.Endpoint
+	ApplyUserRolesSpec                    endpoint.Endpoint
+	GetUserRolesSpec                      endpoint.Endpoint
 	EnableUser                            endpoint.Endpoint
 	RequirePasswordReset                  endpoint.Endpoint
 	PerformRequiredPasswordReset          endpoint.Endpoint
 	GetSessionsForUserInfo                endpoint.Endpoint
 	DeleteSessionsForUser                 endpoint.Endpoint
+	CreateAPIToken                        endpoint.Endpoint
+	ListAPITokens                         endpoint.Endpoint
+	DeleteAPIToken                        endpoint.Endpoint
 	GetSessionInfo                        endpoint.Endpoint
 	DeleteSession                         endpoint.Endpoint
+	CreateServiceAccount                  endpoint.Endpoint
 	GetAppConfig                          endpoint.Endpoint
 	ModifyAppConfig                       endpoint.Endpoint
 	ApplyEnrollSecretSpec                 endpoint.Endpoint
@@ -52,8 +59,14 @@ type KolideEndpoints struct {
 	ApplyQuerySpecs                       endpoint.Endpoint
 	GetQuerySpecs                         endpoint.Endpoint
 	GetQuerySpec                          endpoint.Endpoint
+	ValidateSpecs                         endpoint.Endpoint
 	CreateDistributedQueryCampaign        endpoint.Endpoint
 	CreateDistributedQueryCampaignByNames endpoint.Endpoint
+	ListCampaignResults                   endpoint.Endpoint
+	ListDistributedQueryCampaigns         endpoint.Endpoint
+	ListActivities                        endpoint.Endpoint
+	ListDistributedQueryCampaignHosts     endpoint.Endpoint
+	CancelDistributedQueryCampaign        endpoint.Endpoint
 	CreatePack                            endpoint.Endpoint
 	ModifyPack                            endpoint.Endpoint
 	GetPack                               endpoint.Endpoint
@@ -80,12 +93,24 @@ type KolideEndpoints struct {
 	DeleteLabel                           endpoint.Endpoint
 	DeleteLabelByID                       endpoint.Endpoint
 	ApplyLabelSpecs                       endpoint.Endpoint
+	ApplyHostTagSpecs                     endpoint.Endpoint
 	GetLabelSpecs                         endpoint.Endpoint
 	GetLabelSpec                          endpoint.Endpoint
 	GetHost                               endpoint.Endpoint
 	DeleteHost                            endpoint.Endpoint
+	DeleteHosts                           endpoint.Endpoint
+	MergeHosts                            endpoint.Endpoint
+	ListExpiredHosts                      endpoint.Endpoint
+	ExportHosts                           endpoint.Endpoint
+	ModifyHostTags                        endpoint.Endpoint
 	ListHosts                             endpoint.Endpoint
 	GetHostSummary                        endpoint.Endpoint
+	ListHostSoftware                      endpoint.Endpoint
+	ListHostHistory                       endpoint.Endpoint
+	ListHostLabels                        endpoint.Endpoint
+	ListHostStatusLogs                    endpoint.Endpoint
+	ListScheduledQueryErrors              endpoint.Endpoint
+	ListSoftware                          endpoint.Endpoint
 	SearchTargets                         endpoint.Endpoint
 	GetOptions                            endpoint.Endpoint
 	ModifyOptions                         endpoint.Endpoint
@@ -119,16 +144,23 @@ func MakeKolideServerEndpoints(svc kolide.Service, jwtKey, urlPrefix string) Kol
 		// Authenticated user endpoints
 		// Each of these endpoints should have exactly one
 		// authorization check around the make.*Endpoint method. At a
-		// minimum, canPerformActions. Some endpoints use
-		// stricter/different checks and should NOT also use
-		// canPerformActions (these other checks should also call
-		// canPerformActions if that is appropriate).
+		// minimum, canPerformActions, which allows observers to read.
+		// Endpoints that modify queries, packs, labels or hosts, or
+		// that run live queries use mustBeMaintainer, and the other
+		// endpoints that modify Fleet use mustBeAdmin. The user
+		// specific endpoints use checks that also allow users to act
+		// on themselves, and should NOT also use canPerformActions
+		// (these other checks should also call canPerformActions if
+		// that is appropriate).
 		Me:                   authenticatedUser(jwtKey, svc, canPerformActions(makeGetSessionUserEndpoint(svc))),
 		ChangePassword:       authenticatedUser(jwtKey, svc, canPerformActions(makeChangePasswordEndpoint(svc))),
 		GetUser:              authenticatedUser(jwtKey, svc, canReadUser(makeGetUserEndpoint(svc))),
 		ListUsers:            authenticatedUser(jwtKey, svc, canPerformActions(makeListUsersEndpoint(svc))),
 		ModifyUser:           authenticatedUser(jwtKey, svc, canModifyUser(makeModifyUserEndpoint(svc))),
 		AdminUser:            authenticatedUser(jwtKey, svc, mustBeAdmin(makeAdminUserEndpoint(svc))),
+		ChangeUserRole:       authenticatedUser(jwtKey, svc, mustBeAdmin(makeChangeUserRoleEndpoint(svc))),
+		ApplyUserRolesSpec:   authenticatedUser(jwtKey, svc, mustBeAdmin(makeApplyUserRolesSpecEndpoint(svc))),
+		GetUserRolesSpec:     authenticatedUser(jwtKey, svc, canPerformActions(makeGetUserRolesSpecEndpoint(svc))),
 		EnableUser:           authenticatedUser(jwtKey, svc, mustBeAdmin(makeEnableUserEndpoint(svc))),
 		RequirePasswordReset: authenticatedUser(jwtKey, svc, mustBeAdmin(makeRequirePasswordResetEndpoint(svc))),
 		// PerformRequiredPasswordReset needs only to authenticate the
@@ -136,8 +168,12 @@ func MakeKolideServerEndpoints(svc kolide.Service, jwtKey, urlPrefix string) Kol
 		PerformRequiredPasswordReset:          authenticatedUser(jwtKey, svc, canPerformPasswordReset(makePerformRequiredPasswordResetEndpoint(svc))),
 		GetSessionsForUserInfo:                authenticatedUser(jwtKey, svc, canReadUser(makeGetInfoAboutSessionsForUserEndpoint(svc))),
 		DeleteSessionsForUser:                 authenticatedUser(jwtKey, svc, canModifyUser(makeDeleteSessionsForUserEndpoint(svc))),
+		CreateAPIToken:                        authenticatedUser(jwtKey, svc, canModifyUser(makeCreateAPITokenEndpoint(svc))),
+		ListAPITokens:                         authenticatedUser(jwtKey, svc, canModifyUser(makeListAPITokensEndpoint(svc))),
+		DeleteAPIToken:                        authenticatedUser(jwtKey, svc, canModifyUser(makeDeleteAPITokenEndpoint(svc))),
 		GetSessionInfo:                        authenticatedUser(jwtKey, svc, mustBeAdmin(makeGetInfoAboutSessionEndpoint(svc))),
 		DeleteSession:                         authenticatedUser(jwtKey, svc, mustBeAdmin(makeDeleteSessionEndpoint(svc))),
+		CreateServiceAccount:                  authenticatedUser(jwtKey, svc, mustBeAdmin(makeCreateServiceAccountEndpoint(svc))),
 		GetAppConfig:                          authenticatedUser(jwtKey, svc, canPerformActions(makeGetAppConfigEndpoint(svc))),
 		ModifyAppConfig:                       authenticatedUser(jwtKey, svc, mustBeAdmin(makeModifyAppConfigEndpoint(svc))),
 		ApplyEnrollSecretSpec:                 authenticatedUser(jwtKey, svc, mustBeAdmin(makeApplyEnrollSecretSpecEndpoint(svc))),
@@ -145,59 +181,77 @@ func MakeKolideServerEndpoints(svc kolide.Service, jwtKey, urlPrefix string) Kol
 		CreateInvite:                          authenticatedUser(jwtKey, svc, mustBeAdmin(makeCreateInviteEndpoint(svc))),
 		ListInvites:                           authenticatedUser(jwtKey, svc, mustBeAdmin(makeListInvitesEndpoint(svc))),
 		DeleteInvite:                          authenticatedUser(jwtKey, svc, mustBeAdmin(makeDeleteInviteEndpoint(svc))),
-		GetQuery:                              authenticatedUser(jwtKey, svc, makeGetQueryEndpoint(svc)),
-		ListQueries:                           authenticatedUser(jwtKey, svc, makeListQueriesEndpoint(svc)),
-		CreateQuery:                           authenticatedUser(jwtKey, svc, makeCreateQueryEndpoint(svc)),
-		ModifyQuery:                           authenticatedUser(jwtKey, svc, makeModifyQueryEndpoint(svc)),
-		DeleteQuery:                           authenticatedUser(jwtKey, svc, makeDeleteQueryEndpoint(svc)),
-		DeleteQueryByID:                       authenticatedUser(jwtKey, svc, makeDeleteQueryByIDEndpoint(svc)),
-		DeleteQueries:                         authenticatedUser(jwtKey, svc, makeDeleteQueriesEndpoint(svc)),
-		ApplyQuerySpecs:                       authenticatedUser(jwtKey, svc, makeApplyQuerySpecsEndpoint(svc)),
-		GetQuerySpecs:                         authenticatedUser(jwtKey, svc, makeGetQuerySpecsEndpoint(svc)),
-		GetQuerySpec:                          authenticatedUser(jwtKey, svc, makeGetQuerySpecEndpoint(svc)),
-		CreateDistributedQueryCampaign:        authenticatedUser(jwtKey, svc, makeCreateDistributedQueryCampaignEndpoint(svc)),
-		CreateDistributedQueryCampaignByNames: authenticatedUser(jwtKey, svc, makeCreateDistributedQueryCampaignByNamesEndpoint(svc)),
-		CreatePack:                            authenticatedUser(jwtKey, svc, makeCreatePackEndpoint(svc)),
-		ModifyPack:                            authenticatedUser(jwtKey, svc, makeModifyPackEndpoint(svc)),
-		GetPack:                               authenticatedUser(jwtKey, svc, makeGetPackEndpoint(svc)),
-		ListPacks:                             authenticatedUser(jwtKey, svc, makeListPacksEndpoint(svc)),
-		DeletePack:                            authenticatedUser(jwtKey, svc, makeDeletePackEndpoint(svc)),
-		DeletePackByID:                        authenticatedUser(jwtKey, svc, makeDeletePackByIDEndpoint(svc)),
-		GetScheduledQueriesInPack:             authenticatedUser(jwtKey, svc, makeGetScheduledQueriesInPackEndpoint(svc)),
-		ScheduleQuery:                         authenticatedUser(jwtKey, svc, makeScheduleQueryEndpoint(svc)),
-		GetScheduledQuery:                     authenticatedUser(jwtKey, svc, makeGetScheduledQueryEndpoint(svc)),
-		ModifyScheduledQuery:                  authenticatedUser(jwtKey, svc, makeModifyScheduledQueryEndpoint(svc)),
-		DeleteScheduledQuery:                  authenticatedUser(jwtKey, svc, makeDeleteScheduledQueryEndpoint(svc)),
-		ApplyPackSpecs:                        authenticatedUser(jwtKey, svc, makeApplyPackSpecsEndpoint(svc)),
-		GetPackSpecs:                          authenticatedUser(jwtKey, svc, makeGetPackSpecsEndpoint(svc)),
-		GetPackSpec:                           authenticatedUser(jwtKey, svc, makeGetPackSpecEndpoint(svc)),
-		GetHost:                               authenticatedUser(jwtKey, svc, makeGetHostEndpoint(svc)),
-		ListHosts:                             authenticatedUser(jwtKey, svc, makeListHostsEndpoint(svc)),
-		GetHostSummary:                        authenticatedUser(jwtKey, svc, makeGetHostSummaryEndpoint(svc)),
-		DeleteHost:                            authenticatedUser(jwtKey, svc, makeDeleteHostEndpoint(svc)),
-		CreateLabel:                           authenticatedUser(jwtKey, svc, makeCreateLabelEndpoint(svc)),
-		ModifyLabel:                           authenticatedUser(jwtKey, svc, makeModifyLabelEndpoint(svc)),
-		GetLabel:                              authenticatedUser(jwtKey, svc, makeGetLabelEndpoint(svc)),
-		ListLabels:                            authenticatedUser(jwtKey, svc, makeListLabelsEndpoint(svc)),
-		DeleteLabel:                           authenticatedUser(jwtKey, svc, makeDeleteLabelEndpoint(svc)),
-		DeleteLabelByID:                       authenticatedUser(jwtKey, svc, makeDeleteLabelByIDEndpoint(svc)),
-		ApplyLabelSpecs:                       authenticatedUser(jwtKey, svc, makeApplyLabelSpecsEndpoint(svc)),
-		GetLabelSpecs:                         authenticatedUser(jwtKey, svc, makeGetLabelSpecsEndpoint(svc)),
-		GetLabelSpec:                          authenticatedUser(jwtKey, svc, makeGetLabelSpecEndpoint(svc)),
-		SearchTargets:                         authenticatedUser(jwtKey, svc, makeSearchTargetsEndpoint(svc)),
+		GetQuery:                              authenticatedUser(jwtKey, svc, canPerformActions(makeGetQueryEndpoint(svc))),
+		ListQueries:                           authenticatedUser(jwtKey, svc, canPerformActions(makeListQueriesEndpoint(svc))),
+		CreateQuery:                           authenticatedUser(jwtKey, svc, mustBeMaintainer(makeCreateQueryEndpoint(svc))),
+		ModifyQuery:                           authenticatedUser(jwtKey, svc, mustBeMaintainer(makeModifyQueryEndpoint(svc))),
+		DeleteQuery:                           authenticatedUser(jwtKey, svc, mustBeMaintainer(makeDeleteQueryEndpoint(svc))),
+		DeleteQueryByID:                       authenticatedUser(jwtKey, svc, mustBeMaintainer(makeDeleteQueryByIDEndpoint(svc))),
+		DeleteQueries:                         authenticatedUser(jwtKey, svc, mustBeMaintainer(makeDeleteQueriesEndpoint(svc))),
+		ApplyQuerySpecs:                       authenticatedUser(jwtKey, svc, mustBeMaintainer(makeApplyQuerySpecsEndpoint(svc))),
+		GetQuerySpecs:                         authenticatedUser(jwtKey, svc, canPerformActions(makeGetQuerySpecsEndpoint(svc))),
+		GetQuerySpec:                          authenticatedUser(jwtKey, svc, canPerformActions(makeGetQuerySpecEndpoint(svc))),
+		ValidateSpecs:                         authenticatedUser(jwtKey, svc, canPerformActions(makeValidateSpecsEndpoint(svc))),
+		CreateDistributedQueryCampaign:        authenticatedUser(jwtKey, svc, mustBeMaintainer(makeCreateDistributedQueryCampaignEndpoint(svc))),
+		CreateDistributedQueryCampaignByNames: authenticatedUser(jwtKey, svc, mustBeMaintainer(makeCreateDistributedQueryCampaignByNamesEndpoint(svc))),
+		ListCampaignResults:                   authenticatedUser(jwtKey, svc, canPerformActions(makeListCampaignResultsEndpoint(svc))),
+		ListDistributedQueryCampaigns:         authenticatedUser(jwtKey, svc, canPerformActions(makeListDistributedQueryCampaignsEndpoint(svc))),
+		ListActivities:                        authenticatedUser(jwtKey, svc, mustBeAdmin(makeListActivitiesEndpoint(svc))),
+		ListDistributedQueryCampaignHosts:     authenticatedUser(jwtKey, svc, canPerformActions(makeListDistributedQueryCampaignHostsEndpoint(svc))),
+		CancelDistributedQueryCampaign:        authenticatedUser(jwtKey, svc, mustBeMaintainer(makeCancelDistributedQueryCampaignEndpoint(svc))),
+		CreatePack:                            authenticatedUser(jwtKey, svc, mustBeMaintainer(makeCreatePackEndpoint(svc))),
+		ModifyPack:                            authenticatedUser(jwtKey, svc, mustBeMaintainer(makeModifyPackEndpoint(svc))),
+		GetPack:                               authenticatedUser(jwtKey, svc, canPerformActions(makeGetPackEndpoint(svc))),
+		ListPacks:                             authenticatedUser(jwtKey, svc, canPerformActions(makeListPacksEndpoint(svc))),
+		DeletePack:                            authenticatedUser(jwtKey, svc, mustBeMaintainer(makeDeletePackEndpoint(svc))),
+		DeletePackByID:                        authenticatedUser(jwtKey, svc, mustBeMaintainer(makeDeletePackByIDEndpoint(svc))),
+		GetScheduledQueriesInPack:             authenticatedUser(jwtKey, svc, canPerformActions(makeGetScheduledQueriesInPackEndpoint(svc))),
+		ScheduleQuery:                         authenticatedUser(jwtKey, svc, mustBeMaintainer(makeScheduleQueryEndpoint(svc))),
+		GetScheduledQuery:                     authenticatedUser(jwtKey, svc, canPerformActions(makeGetScheduledQueryEndpoint(svc))),
+		ModifyScheduledQuery:                  authenticatedUser(jwtKey, svc, mustBeMaintainer(makeModifyScheduledQueryEndpoint(svc))),
+		DeleteScheduledQuery:                  authenticatedUser(jwtKey, svc, mustBeMaintainer(makeDeleteScheduledQueryEndpoint(svc))),
+		ApplyPackSpecs:                        authenticatedUser(jwtKey, svc, mustBeMaintainer(makeApplyPackSpecsEndpoint(svc))),
+		GetPackSpecs:                          authenticatedUser(jwtKey, svc, canPerformActions(makeGetPackSpecsEndpoint(svc))),
+		GetPackSpec:                           authenticatedUser(jwtKey, svc, canPerformActions(makeGetPackSpecEndpoint(svc))),
+		GetHost:                               authenticatedUser(jwtKey, svc, canPerformActions(makeGetHostEndpoint(svc))),
+		ListHosts:                             authenticatedUser(jwtKey, svc, canPerformActions(makeListHostsEndpoint(svc))),
+		GetHostSummary:                        authenticatedUser(jwtKey, svc, canPerformActions(makeGetHostSummaryEndpoint(svc))),
+		ListHostSoftware:                      authenticatedUser(jwtKey, svc, canPerformActions(makeListHostSoftwareEndpoint(svc))),
+		ListHostHistory:                       authenticatedUser(jwtKey, svc, canPerformActions(makeListHostHistoryEndpoint(svc))),
+		ListHostLabels:                        authenticatedUser(jwtKey, svc, canPerformActions(makeListHostLabelsEndpoint(svc))),
+		ListHostStatusLogs:                    authenticatedUser(jwtKey, svc, canPerformActions(makeListHostStatusLogsEndpoint(svc))),
+		ListScheduledQueryErrors:              authenticatedUser(jwtKey, svc, canPerformActions(makeListScheduledQueryErrorsEndpoint(svc))),
+		ListSoftware:                          authenticatedUser(jwtKey, svc, canPerformActions(makeListSoftwareEndpoint(svc))),
+		DeleteHost:                            authenticatedUser(jwtKey, svc, mustBeMaintainer(makeDeleteHostEndpoint(svc))),
+		DeleteHosts:                           authenticatedUser(jwtKey, svc, mustBeAdmin(makeDeleteHostsEndpoint(svc))),
+		MergeHosts:                            authenticatedUser(jwtKey, svc, mustBeAdmin(makeMergeHostsEndpoint(svc))),
+		ListExpiredHosts:                      authenticatedUser(jwtKey, svc, mustBeAdmin(makeListExpiredHostsEndpoint(svc))),
+		ExportHosts:                           authenticatedUser(jwtKey, svc, canPerformActions(makeExportHostsEndpoint(svc))),
+		ModifyHostTags:                        authenticatedUser(jwtKey, svc, mustBeMaintainer(makeModifyHostTagsEndpoint(svc))),
+		CreateLabel:                           authenticatedUser(jwtKey, svc, mustBeMaintainer(makeCreateLabelEndpoint(svc))),
+		ModifyLabel:                           authenticatedUser(jwtKey, svc, mustBeMaintainer(makeModifyLabelEndpoint(svc))),
+		GetLabel:                              authenticatedUser(jwtKey, svc, canPerformActions(makeGetLabelEndpoint(svc))),
+		ListLabels:                            authenticatedUser(jwtKey, svc, canPerformActions(makeListLabelsEndpoint(svc))),
+		DeleteLabel:                           authenticatedUser(jwtKey, svc, mustBeMaintainer(makeDeleteLabelEndpoint(svc))),
+		DeleteLabelByID:                       authenticatedUser(jwtKey, svc, mustBeMaintainer(makeDeleteLabelByIDEndpoint(svc))),
+		ApplyLabelSpecs:                       authenticatedUser(jwtKey, svc, mustBeMaintainer(makeApplyLabelSpecsEndpoint(svc))),
+		ApplyHostTagSpecs:                     authenticatedUser(jwtKey, svc, mustBeMaintainer(makeApplyHostTagSpecsEndpoint(svc))),
+		GetLabelSpecs:                         authenticatedUser(jwtKey, svc, canPerformActions(makeGetLabelSpecsEndpoint(svc))),
+		GetLabelSpec:                          authenticatedUser(jwtKey, svc, canPerformActions(makeGetLabelSpecEndpoint(svc))),
+		SearchTargets:                         authenticatedUser(jwtKey, svc, canPerformActions(makeSearchTargetsEndpoint(svc))),
 		GetOptions:                            authenticatedUser(jwtKey, svc, mustBeAdmin(makeGetOptionsEndpoint(svc))),
 		ModifyOptions:                         authenticatedUser(jwtKey, svc, mustBeAdmin(makeModifyOptionsEndpoint(svc))),
 		ResetOptions:                          authenticatedUser(jwtKey, svc, mustBeAdmin(makeResetOptionsEndpoint(svc))),
-		ApplyOsqueryOptionsSpec:               authenticatedUser(jwtKey, svc, makeApplyOsqueryOptionsSpecEndpoint(svc)),
-		GetOsqueryOptionsSpec:                 authenticatedUser(jwtKey, svc, makeGetOsqueryOptionsSpecEndpoint(svc)),
-		GetCertificate:                        authenticatedUser(jwtKey, svc, makeCertificateEndpoint(svc)),
-		ChangeEmail:                           authenticatedUser(jwtKey, svc, makeChangeEmailEndpoint(svc)),
-		GetFIM:                                authenticatedUser(jwtKey, svc, makeGetFIMEndpoint(svc)),
-		ModifyFIM:                             authenticatedUser(jwtKey, svc, makeModifyFIMEndpoint(svc)),
+		ApplyOsqueryOptionsSpec:               authenticatedUser(jwtKey, svc, mustBeAdmin(makeApplyOsqueryOptionsSpecEndpoint(svc))),
+		GetOsqueryOptionsSpec:                 authenticatedUser(jwtKey, svc, canPerformActions(makeGetOsqueryOptionsSpecEndpoint(svc))),
+		GetCertificate:                        authenticatedUser(jwtKey, svc, canPerformActions(makeCertificateEndpoint(svc))),
+		ChangeEmail:                           authenticatedUser(jwtKey, svc, canPerformActions(makeChangeEmailEndpoint(svc))),
+		GetFIM:                                authenticatedUser(jwtKey, svc, canPerformActions(makeGetFIMEndpoint(svc))),
+		ModifyFIM:                             authenticatedUser(jwtKey, svc, mustBeAdmin(makeModifyFIMEndpoint(svc))),
 
 		// Authenticated status endpoints
-		StatusResultStore: authenticatedUser(jwtKey, svc, makeStatusResultStoreEndpoint(svc)),
-		StatusLiveQuery:   authenticatedUser(jwtKey, svc, makeStatusLiveQueryEndpoint(svc)),
+		StatusResultStore: authenticatedUser(jwtKey, svc, canPerformActions(makeStatusResultStoreEndpoint(svc))),
+		StatusLiveQuery:   authenticatedUser(jwtKey, svc, canPerformActions(makeStatusLiveQueryEndpoint(svc))),
 
 		// Osquery endpoints
 		EnrollAgent:                   makeEnrollAgentEndpoint(svc),
@@ -220,13 +274,20 @@ type kolideHandlers struct {
 	ListUsers                             http.Handler
 	ModifyUser                            http.Handler
 	AdminUser                             http.Handler
+	ChangeUserRole                        http.Handler
+	ApplyUserRolesSpec                    http.Handler
+	GetUserRolesSpec                      http.Handler
 	EnableUser                            http.Handler
 	RequirePasswordReset                  http.Handler
 	PerformRequiredPasswordReset          http.Handler
 	GetSessionsForUserInfo                http.Handler
 	DeleteSessionsForUser                 http.Handler
+	CreateAPIToken                        http.Handler
+	ListAPITokens                         http.Handler
+	DeleteAPIToken                        http.Handler
 	GetSessionInfo                        http.Handler
 	DeleteSession                         http.Handler
+	CreateServiceAccount                  http.Handler
 	GetAppConfig                          http.Handler
 	ModifyAppConfig                       http.Handler
 	ApplyEnrollSecretSpec                 http.Handler
@@ -245,8 +306,14 @@ type kolideHandlers struct {
 	ApplyQuerySpecs                       http.Handler
 	GetQuerySpecs                         http.Handler
 	GetQuerySpec                          http.Handler
+	ValidateSpecs                         http.Handler
 	CreateDistributedQueryCampaign        http.Handler
 	CreateDistributedQueryCampaignByNames http.Handler
+	ListCampaignResults                   http.Handler
+	ListDistributedQueryCampaigns         http.Handler
+	ListActivities                        http.Handler
+	ListDistributedQueryCampaignHosts     http.Handler
+	CancelDistributedQueryCampaign        http.Handler
 	CreatePack                            http.Handler
 	ModifyPack                            http.Handler
 	GetPack                               http.Handler
@@ -273,12 +340,24 @@ type kolideHandlers struct {
 	DeleteLabel                           http.Handler
 	DeleteLabelByID                       http.Handler
 	ApplyLabelSpecs                       http.Handler
+	ApplyHostTagSpecs                     http.Handler
 	GetLabelSpecs                         http.Handler
 	GetLabelSpec                          http.Handler
 	GetHost                               http.Handler
 	DeleteHost                            http.Handler
+	DeleteHosts                           http.Handler
+	MergeHosts                            http.Handler
+	ListExpiredHosts                      http.Handler
+	ExportHosts                           http.Handler
+	ModifyHostTags                        http.Handler
 	ListHosts                             http.Handler
 	GetHostSummary                        http.Handler
+	ListHostSoftware                      http.Handler
+	ListHostHistory                       http.Handler
+	ListHostLabels                        http.Handler
+	ListHostStatusLogs                    http.Handler
+	ListScheduledQueryErrors              http.Handler
+	ListSoftware                          http.Handler
 	SearchTargets                         http.Handler
 	GetOptions                            http.Handler
 	ModifyOptions                         http.Handler
@@ -315,10 +394,17 @@ func makeKolideKitHandlers(e KolideEndpoints, opts []kithttp.ServerOption) *koli
 		PerformRequiredPasswordReset:          newServer(e.PerformRequiredPasswordReset, decodePerformRequiredPasswordResetRequest),
 		EnableUser:                            newServer(e.EnableUser, decodeEnableUserRequest),
 		AdminUser:                             newServer(e.AdminUser, decodeAdminUserRequest),
+		ChangeUserRole:                        newServer(e.ChangeUserRole, decodeChangeUserRoleRequest),
+		ApplyUserRolesSpec:                    newServer(e.ApplyUserRolesSpec, decodeApplyUserRolesSpecRequest),
+		GetUserRolesSpec:                      newServer(e.GetUserRolesSpec, decodeNoParamsRequest),
 		GetSessionsForUserInfo:                newServer(e.GetSessionsForUserInfo, decodeGetInfoAboutSessionsForUserRequest),
 		DeleteSessionsForUser:                 newServer(e.DeleteSessionsForUser, decodeDeleteSessionsForUserRequest),
+		CreateAPIToken:                        newServer(e.CreateAPIToken, decodeCreateAPITokenRequest),
+		ListAPITokens:                         newServer(e.ListAPITokens, decodeListAPITokensRequest),
+		DeleteAPIToken:                        newServer(e.DeleteAPIToken, decodeDeleteAPITokenRequest),
 		GetSessionInfo:                        newServer(e.GetSessionInfo, decodeGetInfoAboutSessionRequest),
 		DeleteSession:                         newServer(e.DeleteSession, decodeDeleteSessionRequest),
+		CreateServiceAccount:                  newServer(e.CreateServiceAccount, decodeCreateServiceAccountRequest),
 		GetAppConfig:                          newServer(e.GetAppConfig, decodeNoParamsRequest),
 		ModifyAppConfig:                       newServer(e.ModifyAppConfig, decodeModifyAppConfigRequest),
 		ApplyEnrollSecretSpec:                 newServer(e.ApplyEnrollSecretSpec, decodeApplyEnrollSecretSpecRequest),
@@ -337,8 +423,14 @@ func makeKolideKitHandlers(e KolideEndpoints, opts []kithttp.ServerOption) *koli
 		ApplyQuerySpecs:                       newServer(e.ApplyQuerySpecs, decodeApplyQuerySpecsRequest),
 		GetQuerySpecs:                         newServer(e.GetQuerySpecs, decodeNoParamsRequest),
 		GetQuerySpec:                          newServer(e.GetQuerySpec, decodeGetGenericSpecRequest),
+		ValidateSpecs:                         newServer(e.ValidateSpecs, decodeValidateSpecsRequest),
 		CreateDistributedQueryCampaign:        newServer(e.CreateDistributedQueryCampaign, decodeCreateDistributedQueryCampaignRequest),
 		CreateDistributedQueryCampaignByNames: newServer(e.CreateDistributedQueryCampaignByNames, decodeCreateDistributedQueryCampaignByNamesRequest),
+		ListCampaignResults:                   newServer(e.ListCampaignResults, decodeListCampaignResultsRequest),
+		ListDistributedQueryCampaigns:         newServer(e.ListDistributedQueryCampaigns, decodeListDistributedQueryCampaignsRequest),
+		ListActivities:                        newServer(e.ListActivities, decodeListActivitiesRequest),
+		ListDistributedQueryCampaignHosts:     newServer(e.ListDistributedQueryCampaignHosts, decodeListDistributedQueryCampaignHostsRequest),
+		CancelDistributedQueryCampaign:        newServer(e.CancelDistributedQueryCampaign, decodeCancelDistributedQueryCampaignRequest),
 		CreatePack:                            newServer(e.CreatePack, decodeCreatePackRequest),
 		ModifyPack:                            newServer(e.ModifyPack, decodeModifyPackRequest),
 		GetPack:                               newServer(e.GetPack, decodeGetPackRequest),
@@ -365,12 +457,24 @@ func makeKolideKitHandlers(e KolideEndpoints, opts []kithttp.ServerOption) *koli
 		DeleteLabel:                           newServer(e.DeleteLabel, decodeDeleteLabelRequest),
 		DeleteLabelByID:                       newServer(e.DeleteLabelByID, decodeDeleteLabelByIDRequest),
 		ApplyLabelSpecs:                       newServer(e.ApplyLabelSpecs, decodeApplyLabelSpecsRequest),
+		ApplyHostTagSpecs:                     newServer(e.ApplyHostTagSpecs, decodeApplyHostTagSpecsRequest),
 		GetLabelSpecs:                         newServer(e.GetLabelSpecs, decodeNoParamsRequest),
 		GetLabelSpec:                          newServer(e.GetLabelSpec, decodeGetGenericSpecRequest),
 		GetHost:                               newServer(e.GetHost, decodeGetHostRequest),
 		DeleteHost:                            newServer(e.DeleteHost, decodeDeleteHostRequest),
+		DeleteHosts:                           newServer(e.DeleteHosts, decodeDeleteHostsRequest),
+		MergeHosts:                            newServer(e.MergeHosts, decodeMergeHostsRequest),
+		ListExpiredHosts:                      newServer(e.ListExpiredHosts, decodeListExpiredHostsRequest),
+		ExportHosts:                           newServer(e.ExportHosts, decodeExportHostsRequest),
+		ModifyHostTags:                        newServer(e.ModifyHostTags, decodeModifyHostTagsRequest),
 		ListHosts:                             newServer(e.ListHosts, decodeListHostsRequest),
 		GetHostSummary:                        newServer(e.GetHostSummary, decodeNoParamsRequest),
+		ListHostSoftware:                      newServer(e.ListHostSoftware, decodeListHostSoftwareRequest),
+		ListHostHistory:                       newServer(e.ListHostHistory, decodeListHostHistoryRequest),
+		ListHostLabels:                        newServer(e.ListHostLabels, decodeListHostLabelsRequest),
+		ListHostStatusLogs:                    newServer(e.ListHostStatusLogs, decodeListHostStatusLogsRequest),
+		ListScheduledQueryErrors:              newServer(e.ListScheduledQueryErrors, decodeNoParamsRequest),
+		ListSoftware:                          newServer(e.ListSoftware, decodeListSoftwareRequest),
 		SearchTargets:                         newServer(e.SearchTargets, decodeSearchTargetsRequest),
 		GetOptions:                            newServer(e.GetOptions, decodeNoParamsRequest),
 		ModifyOptions:                         newServer(e.ModifyOptions, decodeModifyOptionsRequest),
@@ -413,6 +517,9 @@ func MakeHandler(svc kolide.Service, config config.KolideConfig, logger kitlog.L
 	r.PathPrefix("/api/v1/kolide/results/").
 		Handler(makeStreamDistributedQueryCampaignResultsHandler(svc, config.Auth.JwtKey, logger)).
 		Name("distributed_query_results")
+	r.Handle("/api/v1/kolide/campaigns/{id}/stream", makeStreamCampaignHandler(svc, config.Auth.JwtKey, logger)).
+		Methods("GET").
+		Name("stream_distributed_query_campaign")
 
 	return r
 }
@@ -438,18 +545,26 @@ func attachKolideAPIRoutes(r *mux.Router, h *kolideHandlers) {
 	r.Handle("/api/v1/kolide/sso", h.InitiateSSO).Methods("POST").Name("intiate_sso")
 	r.Handle("/api/v1/kolide/sso", h.SettingsSSO).Methods("GET").Name("sso_config")
 	r.Handle("/api/v1/kolide/sso/callback", h.CallbackSSO).Methods("POST").Name("callback_sso")
+	r.Handle("/api/v1/kolide/sso/callback", h.CallbackSSO).Methods("GET").Name("callback_sso_oidc")
 	r.Handle("/api/v1/kolide/users", h.ListUsers).Methods("GET").Name("list_users")
 	r.Handle("/api/v1/kolide/users", h.CreateUser).Methods("POST").Name("create_user")
 	r.Handle("/api/v1/kolide/users/{id}", h.GetUser).Methods("GET").Name("get_user")
 	r.Handle("/api/v1/kolide/users/{id}", h.ModifyUser).Methods("PATCH").Name("modify_user")
 	r.Handle("/api/v1/kolide/users/{id}/enable", h.EnableUser).Methods("POST").Name("enable_user")
 	r.Handle("/api/v1/kolide/users/{id}/admin", h.AdminUser).Methods("POST").Name("admin_user")
+	r.Handle("/api/v1/kolide/users/{id}/role", h.ChangeUserRole).Methods("POST").Name("change_user_role")
 	r.Handle("/api/v1/kolide/users/{id}/require_password_reset", h.RequirePasswordReset).Methods("POST").Name("require_password_reset")
 	r.Handle("/api/v1/kolide/users/{id}/sessions", h.GetSessionsForUserInfo).Methods("GET").Name("get_session_for_user")
 	r.Handle("/api/v1/kolide/users/{id}/sessions", h.DeleteSessionsForUser).Methods("DELETE").Name("delete_session_for_user")
+	r.Handle("/api/v1/kolide/users/{id}/api_tokens", h.CreateAPIToken).Methods("POST").Name("create_api_token")
+	r.Handle("/api/v1/kolide/users/{id}/api_tokens", h.ListAPITokens).Methods("GET").Name("list_api_tokens")
+	r.Handle("/api/v1/kolide/users/{id}/api_tokens/{token_id}", h.DeleteAPIToken).Methods("DELETE").Name("delete_api_token")
+	r.Handle("/api/v1/kolide/spec/user_roles", h.ApplyUserRolesSpec).Methods("POST").Name("apply_user_roles_spec")
+	r.Handle("/api/v1/kolide/spec/user_roles", h.GetUserRolesSpec).Methods("GET").Name("get_user_roles_spec")
 
 	r.Handle("/api/v1/kolide/sessions/{id}", h.GetSessionInfo).Methods("GET").Name("get_session_info")
 	r.Handle("/api/v1/kolide/sessions/{id}", h.DeleteSession).Methods("DELETE").Name("delete_session")
+	r.Handle("/api/v1/kolide/service_accounts", h.CreateServiceAccount).Methods("POST").Name("create_service_account")
 
 	r.Handle("/api/v1/kolide/config/certificate", h.GetCertificate).Methods("GET").Name("get_certificate")
 	r.Handle("/api/v1/kolide/config", h.GetAppConfig).Methods("GET").Name("get_app_config")
@@ -473,8 +588,14 @@ func attachKolideAPIRoutes(r *mux.Router, h *kolideHandlers) {
 	r.Handle("/api/v1/kolide/spec/queries", h.ApplyQuerySpecs).Methods("POST").Name("apply_query_specs")
 	r.Handle("/api/v1/kolide/spec/queries", h.GetQuerySpecs).Methods("GET").Name("get_query_specs")
 	r.Handle("/api/v1/kolide/spec/queries/{name}", h.GetQuerySpec).Methods("GET").Name("get_query_spec")
+	r.Handle("/api/v1/kolide/spec/validate", h.ValidateSpecs).Methods("POST").Name("validate_specs")
 	r.Handle("/api/v1/kolide/queries/run", h.CreateDistributedQueryCampaign).Methods("POST").Name("create_distributed_query_campaign")
 	r.Handle("/api/v1/kolide/queries/run_by_names", h.CreateDistributedQueryCampaignByNames).Methods("POST").Name("create_distributed_query_campaign_by_names")
+	r.Handle("/api/v1/kolide/campaigns/{id}/results", h.ListCampaignResults).Methods("GET").Name("list_campaign_results")
+	r.Handle("/api/v1/kolide/campaigns", h.ListDistributedQueryCampaigns).Methods("GET").Name("list_distributed_query_campaigns")
+	r.Handle("/api/v1/kolide/activities", h.ListActivities).Methods("GET").Name("list_activities")
+	r.Handle("/api/v1/kolide/campaigns/{id}/hosts", h.ListDistributedQueryCampaignHosts).Methods("GET").Name("list_distributed_query_campaign_hosts")
+	r.Handle("/api/v1/kolide/campaigns/{id}/cancel", h.CancelDistributedQueryCampaign).Methods("POST").Name("cancel_distributed_query_campaign")
 
 	r.Handle("/api/v1/kolide/packs", h.CreatePack).Methods("POST").Name("create_pack")
 	r.Handle("/api/v1/kolide/packs/{id}", h.ModifyPack).Methods("PATCH").Name("modify_pack")
@@ -498,13 +619,25 @@ func attachKolideAPIRoutes(r *mux.Router, h *kolideHandlers) {
 	r.Handle("/api/v1/kolide/labels/{name}", h.DeleteLabel).Methods("DELETE").Name("delete_label")
 	r.Handle("/api/v1/kolide/labels/id/{id}", h.DeleteLabelByID).Methods("DELETE").Name("delete_label_by_id")
 	r.Handle("/api/v1/kolide/spec/labels", h.ApplyLabelSpecs).Methods("POST").Name("apply_label_specs")
+	r.Handle("/api/v1/kolide/spec/host_tags", h.ApplyHostTagSpecs).Methods("POST").Name("apply_host_tag_specs")
 	r.Handle("/api/v1/kolide/spec/labels", h.GetLabelSpecs).Methods("GET").Name("get_label_specs")
 	r.Handle("/api/v1/kolide/spec/labels/{name}", h.GetLabelSpec).Methods("GET").Name("get_label_spec")
 
 	r.Handle("/api/v1/kolide/hosts", h.ListHosts).Methods("GET").Name("list_hosts")
 	r.Handle("/api/v1/kolide/host_summary", h.GetHostSummary).Methods("GET").Name("get_host_summary")
+	r.Handle("/api/v1/kolide/hosts/expired", h.ListExpiredHosts).Methods("GET").Name("list_expired_hosts")
+	r.Handle("/api/v1/kolide/hosts/export", h.ExportHosts).Methods("GET").Name("export_hosts")
 	r.Handle("/api/v1/kolide/hosts/{id}", h.GetHost).Methods("GET").Name("get_host")
 	r.Handle("/api/v1/kolide/hosts/{id}", h.DeleteHost).Methods("DELETE").Name("delete_host")
+	r.Handle("/api/v1/kolide/hosts/delete", h.DeleteHosts).Methods("POST").Name("delete_hosts")
+	r.Handle("/api/v1/kolide/hosts/{id}/merge", h.MergeHosts).Methods("POST").Name("merge_hosts")
+	r.Handle("/api/v1/kolide/hosts/{id}/tags", h.ModifyHostTags).Methods("PATCH").Name("modify_host_tags")
+	r.Handle("/api/v1/kolide/hosts/{id}/software", h.ListHostSoftware).Methods("GET").Name("list_host_software")
+	r.Handle("/api/v1/kolide/hosts/{id}/history", h.ListHostHistory).Methods("GET").Name("list_host_history")
+	r.Handle("/api/v1/kolide/hosts/{id}/labels", h.ListHostLabels).Methods("GET").Name("list_host_labels")
+	r.Handle("/api/v1/kolide/hosts/{id}/status_logs", h.ListHostStatusLogs).Methods("GET").Name("list_host_status_logs")
+	r.Handle("/api/v1/kolide/status_logs/scheduled_query_errors", h.ListScheduledQueryErrors).Methods("GET").Name("list_scheduled_query_errors")
+	r.Handle("/api/v1/kolide/software", h.ListSoftware).Methods("GET").Name("list_software")
 
 	r.Handle("/api/v1/kolide/fim", h.GetFIM).Methods("GET").Name("get_fim")
 	r.Handle("/api/v1/kolide/fim", h.ModifyFIM).Methods("PATCH").Name("post_fim")
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/kolide/fleet/server/contexts/viewer"
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (mw loggingMiddleware) NewServiceAccount(ctx context.Context, p kolide.ServiceAccountPayload) (*kolide.User, error) {
+	var (
+		user         *kolide.User
+		err          error
+		loggedInUser = "unauthenticated"
+	)
+
+	vc, ok := viewer.FromContext(ctx)
+	if ok {
+		loggedInUser = vc.Username()
+	}
+
+	defer func(begin time.Time) {
+		_ = mw.loggerInfo(err).Log(
+			"method", "NewServiceAccount",
+			"user", p.Username,
+			"created_by", loggedInUser,
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	user, err = mw.Service.NewServiceAccount(ctx, p)
+	return user, err
+}
+
+func (mw loggingMiddleware) NewAPIToken(ctx context.Context, userID uint, p kolide.APITokenPayload) (string, *kolide.APIToken, error) {
+	var (
+		token        string
+		apiToken     *kolide.APIToken
+		err          error
+		role         kolide.Role
+		loggedInUser = "unauthenticated"
+	)
+
+	vc, ok := viewer.FromContext(ctx)
+	if ok {
+		loggedInUser = vc.Username()
+	}
+
+	defer func(begin time.Time) {
+		_ = mw.loggerInfo(err).Log(
+			"method", "NewAPIToken",
+			"user_id", userID,
+			"name", p.Name,
+			"role", role,
+			"created_by", loggedInUser,
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	token, apiToken, err = mw.Service.NewAPIToken(ctx, userID, p)
+	if apiToken != nil {
+		role = apiToken.Role
+	}
+	return token, apiToken, err
+}
+
+func (mw loggingMiddleware) DeleteAPIToken(ctx context.Context, userID, id uint) error {
+	var (
+		err          error
+		loggedInUser = "unauthenticated"
+	)
+
+	vc, ok := viewer.FromContext(ctx)
+	if ok {
+		loggedInUser = vc.Username()
+	}
+
+	defer func(begin time.Time) {
+		_ = mw.loggerInfo(err).Log(
+			"method", "DeleteAPIToken",
+			"user_id", userID,
+			"id", id,
+			"deleted_by", loggedInUser,
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	err = mw.Service.DeleteAPIToken(ctx, userID, id)
+	return err
+}
@@ -6,10 +6,9 @@ import (
 
 	"github.com/kolide/fleet/server/contexts/viewer"
 	"github.com/kolide/fleet/server/kolide"
-	"github.com/kolide/fleet/server/websocket"
 )
 
-func (mw loggingMiddleware) NewDistributedQueryCampaign(ctx context.Context, queryString string, hosts []uint, labels []uint) (*kolide.DistributedQueryCampaign, error) {
+func (mw loggingMiddleware) NewDistributedQueryCampaign(ctx context.Context, queryString string, hosts []uint, labels []uint, tags []uint, opts kolide.CampaignOptions) (*kolide.DistributedQueryCampaign, error) {
 	var (
 		loggedInUser = "unauthenticated"
 		campaign     *kolide.DistributedQueryCampaign
@@ -33,11 +32,11 @@ func (mw loggingMiddleware) NewDistributedQueryCampaign(ctx context.Context, que
 			"took", time.Since(begin),
 		)
 	}(time.Now())
-	campaign, err = mw.Service.NewDistributedQueryCampaign(ctx, queryString, hosts, labels)
+	campaign, err = mw.Service.NewDistributedQueryCampaign(ctx, queryString, hosts, labels, tags, opts)
 	return campaign, err
 }
 
-func (mw loggingMiddleware) NewDistributedQueryCampaignByNames(ctx context.Context, queryString string, hosts []string, labels []string) (*kolide.DistributedQueryCampaign, error) {
+func (mw loggingMiddleware) NewDistributedQueryCampaignByNames(ctx context.Context, queryString string, hosts []string, labels []string, tags []string, opts kolide.CampaignOptions) (*kolide.DistributedQueryCampaign, error) {
 	var (
 		loggedInUser = "unauthenticated"
 		campaign     *kolide.DistributedQueryCampaign
@@ -60,11 +59,33 @@ func (mw loggingMiddleware) NewDistributedQueryCampaignByNames(ctx context.Conte
 			"took", time.Since(begin),
 		)
 	}(time.Now())
-	campaign, err = mw.Service.NewDistributedQueryCampaignByNames(ctx, queryString, hosts, labels)
+	campaign, err = mw.Service.NewDistributedQueryCampaignByNames(ctx, queryString, hosts, labels, tags, opts)
 	return campaign, err
 }
 
-func (mw loggingMiddleware) StreamCampaignResults(ctx context.Context, conn *websocket.Conn, campaignID uint) {
+func (mw loggingMiddleware) CancelDistributedQueryCampaign(ctx context.Context, campaignID uint) (*kolide.DistributedQueryCampaign, error) {
+	var (
+		loggedInUser = "unauthenticated"
+		campaign     *kolide.DistributedQueryCampaign
+		err          error
+	)
+	if vc, ok := viewer.FromContext(ctx); ok {
+		loggedInUser = vc.Username()
+	}
+	defer func(begin time.Time) {
+		_ = mw.loggerInfo(err).Log(
+			"method", "CancelDistributedQueryCampaign",
+			"err", err,
+			"user", loggedInUser,
+			"campaignID", campaignID,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	campaign, err = mw.Service.CancelDistributedQueryCampaign(ctx, campaignID)
+	return campaign, err
+}
+
+func (mw loggingMiddleware) StreamCampaignResults(ctx context.Context, conn kolide.CampaignStreamWriter, campaignID uint) {
 	var (
 		loggedInUser = "unauthenticated"
 		err          error
@@ -4,10 +4,11 @@ import (
 	"context"
 	"time"
 
+	"github.com/kolide/fleet/server/contexts/viewer"
 	"github.com/kolide/fleet/server/kolide"
 )
 
-func (mw loggingMiddleware) ListHosts(ctx context.Context, opt kolide.ListOptions) ([]*kolide.Host, error) {
+func (mw loggingMiddleware) ListHosts(ctx context.Context, opt kolide.HostListOptions) ([]*kolide.Host, error) {
 	var (
 		hosts []*kolide.Host
 		err   error
@@ -77,3 +78,155 @@ func (mw loggingMiddleware) DeleteHost(ctx context.Context, id uint) error {
 	err = mw.Service.DeleteHost(ctx, id)
 	return err
 }
+
+func (mw loggingMiddleware) MergeHosts(ctx context.Context, survivorID, duplicateID uint) (*kolide.Host, error) {
+	var (
+		host *kolide.Host
+		err  error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.loggerInfo(err).Log(
+			"method", "MergeHosts",
+			"survivor_id", survivorID,
+			"duplicate_id", duplicateID,
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	host, err = mw.Service.MergeHosts(ctx, survivorID, duplicateID)
+	return host, err
+}
+
+func (mw loggingMiddleware) ModifyHostTags(ctx context.Context, hostID uint, tags map[string]*string) (map[string]string, error) {
+	var (
+		result map[string]string
+		err    error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.loggerInfo(err).Log(
+			"method", "ModifyHostTags",
+			"host_id", hostID,
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	result, err = mw.Service.ModifyHostTags(ctx, hostID, tags)
+	return result, err
+}
+
+func (mw loggingMiddleware) ApplyHostTagSpecs(ctx context.Context, specs []*kolide.HostTagSpec) (err error) {
+	defer func(begin time.Time) {
+		_ = mw.loggerInfo(err).Log(
+			"method", "ApplyHostTagSpecs",
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	err = mw.Service.ApplyHostTagSpecs(ctx, specs)
+	return err
+}
+
+func (mw loggingMiddleware) ListExpiredHosts(ctx context.Context, window int) ([]*kolide.Host, error) {
+	var (
+		hosts []*kolide.Host
+		err   error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.loggerDebug(err).Log(
+			"method", "ListExpiredHosts",
+			"window", window,
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	hosts, err = mw.Service.ListExpiredHosts(ctx, window)
+	return hosts, err
+}
+
+func (mw loggingMiddleware) ExpireHosts(ctx context.Context) ([]uint, error) {
+	var (
+		hostIDs []uint
+		err     error
+	)
+
+	defer func(begin time.Time) {
+		for _, id := range hostIDs {
+			_ = mw.loggerInfo(err).Log(
+				"method", "ExpireHosts",
+				"msg", "expired host",
+				"host_id", id,
+			)
+		}
+		_ = mw.loggerInfo(err).Log(
+			"method", "ExpireHosts",
+			"expired", len(hostIDs),
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	hostIDs, err = mw.Service.ExpireHosts(ctx)
+	return hostIDs, err
+}
+
+func (mw loggingMiddleware) DeleteHosts(ctx context.Context, opt kolide.BulkHostDeleteOptions) (*kolide.BulkHostDeleteResult, error) {
+	var (
+		loggedInUser = "unauthenticated"
+		result       *kolide.BulkHostDeleteResult
+		err          error
+	)
+
+	if vc, ok := viewer.FromContext(ctx); ok {
+		loggedInUser = vc.Username()
+	}
+
+	defer func(begin time.Time) {
+		var matched, deleted uint
+		if result != nil {
+			matched, deleted = result.Matched, result.Deleted
+		}
+		_ = mw.loggerInfo(err).Log(
+			"method", "DeleteHosts",
+			"user", loggedInUser,
+			"matched", matched,
+			"deleted", deleted,
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	result, err = mw.Service.DeleteHosts(ctx, opt)
+	return result, err
+}
+
+func (mw loggingMiddleware) ExportHosts(ctx context.Context, opt kolide.HostListOptions, fn func(*kolide.HostExport) error) error {
+	var (
+		exported int
+		err      error
+	)
+
+	defer func(begin time.Time) {
+		_ = mw.loggerDebug(err).Log(
+			"method", "ExportHosts",
+			"exported", exported,
+			"err", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	err = mw.Service.ExportHosts(ctx, opt, func(h *kolide.HostExport) error {
+		if err := fn(h); err != nil {
+			return err
+		}
+		exported++
+		return nil
+	})
+	return err
+}
@@ -142,7 +142,7 @@ func (mw loggingMiddleware) GetLabelSpecs(ctx context.Context) (specs []*kolide.
 	return specs, err
 }
 
-func (mw loggingMiddleware) ApplyLabelSpecs(ctx context.Context, specs []*kolide.LabelSpec) (err error) {
+func (mw loggingMiddleware) ApplyLabelSpecs(ctx context.Context, specs []*kolide.LabelSpec) (warnings []string, err error) {
 	var (
 		loggedInUser = "unauthenticated"
 	)
@@ -156,10 +156,11 @@ func (mw loggingMiddleware) ApplyLabelSpecs(ctx context.Context, specs []*kolide
 		_ = mw.loggerInfo(err).Log(
 			"method", "ApplyLabelSpecs",
 			"err", err,
+			"warnings", len(warnings),
 			"user", loggedInUser,
 			"took", time.Since(begin),
 		)
 	}(time.Now())
-	err = mw.Service.ApplyLabelSpecs(ctx, specs)
-	return err
+	warnings, err = mw.Service.ApplyLabelSpecs(ctx, specs)
+	return warnings, err
 }
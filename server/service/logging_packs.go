@@ -263,7 +263,7 @@ func (mw loggingMiddleware) GetPackSpecs(ctx context.Context) (specs []*kolide.P
 	return specs, err
 }
 
-func (mw loggingMiddleware) ApplyPackSpecs(ctx context.Context, specs []*kolide.PackSpec) (err error) {
+func (mw loggingMiddleware) ApplyPackSpecs(ctx context.Context, specs []*kolide.PackSpec) (warnings []string, err error) {
 	var (
 		loggedInUser = "unauthenticated"
 	)
@@ -277,10 +277,11 @@ func (mw loggingMiddleware) ApplyPackSpecs(ctx context.Context, specs []*kolide.
 		_ = mw.loggerInfo(err).Log(
 			"method", "ApplyPackSpecs",
 			"err", err,
+			"warnings", len(warnings),
 			"user", loggedInUser,
 			"took", time.Since(begin),
 		)
 	}(time.Now())
-	err = mw.Service.ApplyPackSpecs(ctx, specs)
-	return err
+	warnings, err = mw.Service.ApplyPackSpecs(ctx, specs)
+	return warnings, err
 }
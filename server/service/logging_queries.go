@@ -32,16 +32,17 @@ func (mw loggingMiddleware) GetQuerySpecs(ctx context.Context) (specs []*kolide.
 	return specs, err
 }
 
-func (mw loggingMiddleware) ApplyQuerySpecs(ctx context.Context, specs []*kolide.QuerySpec) (err error) {
+func (mw loggingMiddleware) ApplyQuerySpecs(ctx context.Context, specs []*kolide.QuerySpec) (warnings []string, err error) {
 	defer func(begin time.Time) {
 		_ = mw.loggerDebug(err).Log(
 			"method", "ApplyQuerySpecs",
 			"err", err,
+			"warnings", len(warnings),
 			"took", time.Since(begin),
 		)
 	}(time.Now())
-	err = mw.Service.ApplyQuerySpecs(ctx, specs)
-	return err
+	warnings, err = mw.Service.ApplyQuerySpecs(ctx, specs)
+	return warnings, err
 }
 
 func (mw loggingMiddleware) ListQueries(ctx context.Context, opt kolide.ListOptions) ([]*kolide.Query, error) {
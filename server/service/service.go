@@ -29,6 +29,11 @@ func NewService(ds kolide.Datastore, resultStore kolide.QueryResultStore,
 		return nil, errors.Wrap(err, "initializing osquery logging")
 	}
 
+	osquerySchema, err := loadOsquerySchema(logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading osquery schema")
+	}
+
 	svc = service{
 		ds:               ds,
 		resultStore:      resultStore,
@@ -36,6 +41,8 @@ func NewService(ds kolide.Datastore, resultStore kolide.QueryResultStore,
 		config:           config,
 		clock:            c,
 		osqueryLogWriter: osqueryLogger,
+		osquerySchema:    osquerySchema,
+		campaigns:        newCampaignCache(),
 		mailService:      mailService,
 		ssoSessionStore:  sso,
 		metaDataClient: &http.Client{
@@ -54,6 +61,12 @@ type service struct {
 	clock       clock.Clock
 
 	osqueryLogWriter *logging.OsqueryLogger
+	// osquerySchema is used to validate query SQL, it is nil when Fleet is
+	// used as a library and the schema asset is not available.
+	osquerySchema *kolide.OsquerySchema
+	// campaigns caches the campaigns receiving results, it is nil when the
+	// service is built without NewService.
+	campaigns *campaignCache
 
 	mailService     kolide.MailService
 	ssoSessionStore sso.SessionStore
@@ -0,0 +1,11 @@
+package service
+
+import (
+	"context"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (svc service) ListActivities(ctx context.Context, filter kolide.ActivityFilter, opt kolide.ListOptions) ([]*kolide.Activity, error) {
+	return svc.ds.ListActivities(filter, opt)
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kolide/fleet/server/contexts/viewer"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// apiTokenUsedInterval limits how often the last used time of an API token is
+// written to the datastore, since it is updated on every request.
+const apiTokenUsedInterval = time.Minute
+
+func (svc service) NewServiceAccount(ctx context.Context, p kolide.ServiceAccountPayload) (*kolide.User, error) {
+	role := kolide.RoleObserver
+	if p.Role != nil {
+		role = *p.Role
+	}
+	if !role.Valid() {
+		return nil, newInvalidArgumentError("role", fmt.Sprintf("unknown role %q", role))
+	}
+
+	// Service accounts never log in with a password, so they are given a
+	// random one that is not shared with anyone.
+	password, err := generateRandomText(svc.config.Auth.SaltKeySize)
+	if err != nil {
+		return nil, errors.Wrap(err, "generate service account password")
+	}
+	user := &kolide.User{
+		Username: p.Username,
+		Name:     p.Name,
+		Email:    p.Email,
+		Enabled:  true,
+		APIOnly:  true,
+	}
+	user.SetRole(role)
+	if err := user.SetPassword(password, svc.config.Auth.SaltKeySize, svc.config.Auth.BcryptCost); err != nil {
+		return nil, errors.Wrap(err, "set service account password")
+	}
+	return svc.ds.NewUser(user)
+}
+
+func (svc service) NewAPIToken(ctx context.Context, userID uint, p kolide.APITokenPayload) (string, *kolide.APIToken, error) {
+	user, err := svc.ds.UserByID(userID)
+	if err != nil {
+		return "", nil, err
+	}
+	if !user.APIOnly {
+		return "", nil, newInvalidArgumentError("user", "API tokens can only be created for service accounts")
+	}
+
+	role := user.EffectiveRole()
+	if p.Role != nil {
+		if !p.Role.Valid() {
+			return "", nil, newInvalidArgumentError("role", fmt.Sprintf("unknown role %q", *p.Role))
+		}
+		if !role.Includes(*p.Role) {
+			return "", nil, newInvalidArgumentError("role", "cannot exceed the role of the service account")
+		}
+		role = *p.Role
+	}
+	// A token must not grant more than the permissions of whoever mints it.
+	vc, ok := viewer.FromContext(ctx)
+	if !ok || !vc.HasRole(role) {
+		return "", nil, permissionError{message: fmt.Sprintf("cannot create a token with the %s role", role)}
+	}
+	if p.ExpiresAt != nil && !p.ExpiresAt.After(svc.clock.Now()) {
+		return "", nil, newInvalidArgumentError("expires_at", "must be in the future")
+	}
+
+	token, err := kolide.NewAPITokenSecret()
+	if err != nil {
+		return "", nil, errors.Wrap(err, "generate api token")
+	}
+	apiToken := &kolide.APIToken{
+		UserID:    user.ID,
+		Name:      p.Name,
+		Hash:      kolide.HashAPIToken(token),
+		Role:      role,
+		ExpiresAt: p.ExpiresAt,
+	}
+	apiToken, err = svc.ds.NewAPIToken(apiToken)
+	if err != nil {
+		if _, ok := err.(kolide.AlreadyExistsError); ok {
+			return "", nil, newInvalidArgumentError("name", fmt.Sprintf("a token named %q already exists", p.Name))
+		}
+		return "", nil, errors.Wrap(err, "save api token")
+	}
+	return token, apiToken, nil
+}
+
+func (svc service) ListAPITokens(ctx context.Context, userID uint) ([]*kolide.APIToken, error) {
+	return svc.ds.ListAPITokensForUser(userID)
+}
+
+func (svc service) DeleteAPIToken(ctx context.Context, userID, id uint) error {
+	return svc.ds.DeleteAPIToken(userID, id)
+}
+
+func (svc service) AuthenticateAPIToken(ctx context.Context, token string) (*kolide.User, *kolide.APIToken, error) {
+	apiToken, err := svc.ds.APITokenByHash(kolide.HashAPIToken(token))
+	if err != nil {
+		if _, ok := err.(kolide.NotFoundError); ok {
+			return nil, nil, authError{reason: "unknown api token", clientReason: "invalid api token"}
+		}
+		return nil, nil, errors.Wrap(err, "find api token")
+	}
+	now := svc.clock.Now()
+	if apiToken.Expired(now) {
+		return nil, nil, authError{reason: "expired api token", clientReason: "api token expired"}
+	}
+	user, err := svc.ds.UserByID(apiToken.UserID)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "find api token user")
+	}
+	if !user.APIOnly {
+		return nil, nil, authError{reason: "api token for user that is not a service account", clientReason: "invalid api token"}
+	}
+	if !user.Enabled {
+		return nil, nil, authError{reason: "account disabled", clientReason: "account disabled"}
+	}
+
+	if apiToken.LastUsedAt == nil || now.Sub(*apiToken.LastUsedAt) >= apiTokenUsedInterval {
+		if err := svc.ds.MarkAPITokenUsed(apiToken.ID, now); err != nil {
+			return nil, nil, errors.Wrap(err, "mark api token used")
+		}
+		apiToken.LastUsedAt = &now
+	}
+	return user, apiToken, nil
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/WatchBeam/clock"
+	"github.com/kolide/fleet/server/contexts/viewer"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewServiceAccount(t *testing.T) {
+	ms := new(mock.Store)
+	ms.NewUserFunc = func(u *kolide.User) (*kolide.User, error) {
+		u.ID = 5
+		return u, nil
+	}
+	svc, err := newTestService(ms, nil)
+	require.Nil(t, err)
+
+	_, err = svc.NewServiceAccount(context.Background(), kolide.ServiceAccountPayload{Username: "bot@example"})
+	require.NotNil(t, err)
+	assert.IsType(t, &invalidArgumentError{}, err)
+	assert.False(t, ms.NewUserFuncInvoked)
+
+	user, err := svc.NewServiceAccount(context.Background(), kolide.ServiceAccountPayload{
+		Username: "bot",
+		Email:    "automation@example.com",
+	})
+	require.Nil(t, err)
+	assert.True(t, user.APIOnly)
+	assert.True(t, user.Enabled)
+	assert.Equal(t, kolide.RoleObserver, user.Role)
+	assert.NotEmpty(t, user.Password)
+}
+
+func TestNewAPIToken(t *testing.T) {
+	serviceAccount := &kolide.User{ID: 5, Username: "bot", Enabled: true, APIOnly: true}
+	serviceAccount.SetRole(kolide.RoleMaintainer)
+	regular := &kolide.User{ID: 6, Username: "user", Enabled: true}
+	ms := new(mock.Store)
+	ms.UserByIDFunc = func(id uint) (*kolide.User, error) {
+		if id == serviceAccount.ID {
+			return serviceAccount, nil
+		}
+		return regular, nil
+	}
+	var saved *kolide.APIToken
+	ms.NewAPITokenFunc = func(token *kolide.APIToken) (*kolide.APIToken, error) {
+		token.ID = 1
+		saved = token
+		return token, nil
+	}
+	c := clock.NewMockClock()
+	svc, err := newTestServiceWithClock(ms, nil, c)
+	require.Nil(t, err)
+
+	admin := &kolide.User{ID: 1, Username: "admin", Enabled: true, Admin: true}
+	ctx := viewer.NewContext(context.Background(), viewer.Viewer{User: admin, Session: &kolide.Session{ID: 1}})
+
+	_, _, err = svc.NewAPIToken(ctx, regular.ID, kolide.APITokenPayload{Name: "ci"})
+	require.NotNil(t, err)
+	assert.IsType(t, &invalidArgumentError{}, err)
+
+	_, _, err = svc.NewAPIToken(ctx, serviceAccount.ID, kolide.APITokenPayload{})
+	require.NotNil(t, err)
+	assert.IsType(t, &invalidArgumentError{}, err)
+
+	adminRole := kolide.RoleAdmin
+	_, _, err = svc.NewAPIToken(ctx, serviceAccount.ID, kolide.APITokenPayload{Name: "ci", Role: &adminRole})
+	require.NotNil(t, err)
+	assert.IsType(t, &invalidArgumentError{}, err)
+
+	past := c.Now().Add(-time.Hour)
+	_, _, err = svc.NewAPIToken(ctx, serviceAccount.ID, kolide.APITokenPayload{Name: "ci", ExpiresAt: &past})
+	require.NotNil(t, err)
+	assert.IsType(t, &invalidArgumentError{}, err)
+	assert.False(t, ms.NewAPITokenFuncInvoked)
+
+	token, apiToken, err := svc.NewAPIToken(ctx, serviceAccount.ID, kolide.APITokenPayload{Name: "ci"})
+	require.Nil(t, err)
+	assert.True(t, kolide.IsAPIToken(token))
+	assert.Equal(t, kolide.RoleMaintainer, apiToken.Role)
+	assert.Equal(t, kolide.HashAPIToken(token), saved.Hash)
+	assert.NotContains(t, string(saved.Hash), token)
+
+	// An observer cannot mint a token with more permissions than their own
+	observer := &kolide.User{ID: 2, Username: "observer", Enabled: true, Role: kolide.RoleObserver}
+	observerCtx := viewer.NewContext(context.Background(), viewer.Viewer{User: observer, Session: &kolide.Session{ID: 2}})
+	_, _, err = svc.NewAPIToken(observerCtx, serviceAccount.ID, kolide.APITokenPayload{Name: "other"})
+	require.NotNil(t, err)
+	assert.IsType(t, permissionError{}, err)
+}
+
+func TestAuthenticateAPIToken(t *testing.T) {
+	c := clock.NewMockClock()
+	serviceAccount := &kolide.User{ID: 5, Username: "bot", Enabled: true, APIOnly: true}
+	expiresAt := c.Now().Add(time.Hour)
+	apiToken := &kolide.APIToken{
+		ID:        1,
+		UserID:    serviceAccount.ID,
+		Hash:      kolide.HashAPIToken("fleet_secret"),
+		Role:      kolide.RoleObserver,
+		ExpiresAt: &expiresAt,
+	}
+	ms := new(mock.Store)
+	ms.APITokenByHashFunc = func(hash []byte) (*kolide.APIToken, error) {
+		if string(hash) != string(apiToken.Hash) {
+			return nil, notFoundError{}
+		}
+		return apiToken, nil
+	}
+	ms.UserByIDFunc = func(id uint) (*kolide.User, error) {
+		return serviceAccount, nil
+	}
+	var usedAt time.Time
+	ms.MarkAPITokenUsedFunc = func(id uint, t time.Time) error {
+		usedAt = t
+		return nil
+	}
+	svc, err := newTestServiceWithClock(ms, nil, c)
+	require.Nil(t, err)
+
+	_, _, err = svc.AuthenticateAPIToken(context.Background(), "fleet_wrong")
+	require.NotNil(t, err)
+	assert.IsType(t, authError{}, err)
+
+	user, token, err := svc.AuthenticateAPIToken(context.Background(), "fleet_secret")
+	require.Nil(t, err)
+	assert.Equal(t, serviceAccount, user)
+	assert.Equal(t, apiToken, token)
+	assert.Equal(t, c.Now(), usedAt)
+
+	// The last used time is not written on every request
+	ms.MarkAPITokenUsedFuncInvoked = false
+	c.AddTime(time.Second)
+	_, _, err = svc.AuthenticateAPIToken(context.Background(), "fleet_secret")
+	require.Nil(t, err)
+	assert.False(t, ms.MarkAPITokenUsedFuncInvoked)
+
+	serviceAccount.Enabled = false
+	_, _, err = svc.AuthenticateAPIToken(context.Background(), "fleet_secret")
+	require.NotNil(t, err)
+	assert.IsType(t, authError{}, err)
+
+	serviceAccount.Enabled = true
+	c.AddTime(time.Hour)
+	_, _, err = svc.AuthenticateAPIToken(context.Background(), "fleet_secret")
+	require.NotNil(t, err)
+	assert.IsType(t, authError{}, err)
+}
+
+func TestServiceAccountLogin(t *testing.T) {
+	serviceAccount := &kolide.User{ID: 5, Username: "bot", Enabled: true, APIOnly: true}
+	require.Nil(t, serviceAccount.SetPassword("p4ssw0rd.", 24, 10))
+	ms := new(mock.Store)
+	ms.UserFunc = func(username string) (*kolide.User, error) {
+		return serviceAccount, nil
+	}
+	svc, err := newTestService(ms, nil)
+	require.Nil(t, err)
+
+	_, _, err = svc.Login(context.Background(), "bot", "p4ssw0rd.")
+	require.NotNil(t, err)
+	assert.IsType(t, authError{}, err)
+	assert.False(t, ms.NewSessionFuncInvoked)
+}
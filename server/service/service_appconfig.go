@@ -156,6 +156,33 @@ func appConfigFromAppConfigPayload(p kolide.AppConfigPayload, config kolide.AppC
 		if p.SSOSettings.MetadataURL != nil {
 			config.MetadataURL = *p.SSOSettings.MetadataURL
 		}
+		if p.SSOSettings.Provider != nil {
+			config.SSOProvider = *p.SSOSettings.Provider
+		}
+		if p.SSOSettings.OIDCIssuerURL != nil {
+			config.OIDCIssuerURL = cleanupURL(*p.SSOSettings.OIDCIssuerURL)
+		}
+		if p.SSOSettings.OIDCClientID != nil {
+			config.OIDCClientID = *p.SSOSettings.OIDCClientID
+		}
+		if p.SSOSettings.OIDCClientSecret != nil && *p.SSOSettings.OIDCClientSecret != "********" {
+			config.OIDCClientSecret = *p.SSOSettings.OIDCClientSecret
+		}
+		if p.SSOSettings.OIDCUserClaim != nil {
+			config.OIDCUserClaim = *p.SSOSettings.OIDCUserClaim
+		}
+		if p.SSOSettings.EnableJITProvisioning != nil {
+			config.EnableJITProvisioning = *p.SSOSettings.EnableJITProvisioning
+		}
+		if p.SSOSettings.JITGroupAttribute != nil {
+			config.JITGroupAttribute = *p.SSOSettings.JITGroupAttribute
+		}
+		if p.SSOSettings.JITAdminGroups != nil {
+			config.JITAdminGroups = kolide.StringList(*p.SSOSettings.JITAdminGroups)
+		}
+		if p.SSOSettings.JITUserGroups != nil {
+			config.JITUserGroups = kolide.StringList(*p.SSOSettings.JITUserGroups)
+		}
 	}
 
 	if p.HostExpirySettings != nil {
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (svc service) ListCampaignResults(ctx context.Context, campaignID uint, opt kolide.ListOptions) ([]*kolide.CampaignResult, bool, error) {
+	// Load the campaign first so that a missing campaign is reported as
+	// such rather than as an empty list of results.
+	campaign, err := svc.ds.DistributedQueryCampaign(campaignID)
+	if err != nil {
+		return nil, false, err
+	}
+	if !campaign.PersistResults {
+		return nil, false, newInvalidArgumentError("id", "campaign results were not persisted")
+	}
+	results, err := svc.ds.ListCampaignResults(campaignID, opt)
+	if err != nil {
+		return nil, false, err
+	}
+	return results, campaign.ResultsTruncated, nil
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListCampaignResults(t *testing.T) {
+	ds := new(mock.Store)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+
+	ds.DistributedQueryCampaignFunc = func(id uint) (*kolide.DistributedQueryCampaign, error) {
+		switch id {
+		case 1:
+			return &kolide.DistributedQueryCampaign{ID: id, PersistResults: true}, nil
+		case 2:
+			return &kolide.DistributedQueryCampaign{ID: id, PersistResults: true, ResultsTruncated: true}, nil
+		case 4:
+			return &kolide.DistributedQueryCampaign{ID: id}, nil
+		}
+		return nil, notFoundError{}
+	}
+	ds.ListCampaignResultsFunc = func(campaignID uint, opt kolide.ListOptions) ([]*kolide.CampaignResult, error) {
+		return []*kolide.CampaignResult{
+			{DistributedQueryCampaignID: campaignID, HostID: 3, Rows: []map[string]string{{"foo": "bar"}}},
+		}, nil
+	}
+
+	results, truncated, err := svc.ListCampaignResults(context.Background(), 1, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, uint(3), results[0].HostID)
+	assert.False(t, truncated)
+
+	_, truncated, err = svc.ListCampaignResults(context.Background(), 2, kolide.ListOptions{})
+	require.Nil(t, err)
+	assert.True(t, truncated)
+
+	// Campaigns without persisted results are rejected
+	ds.ListCampaignResultsFuncInvoked = false
+	_, _, err = svc.ListCampaignResults(context.Background(), 4, kolide.ListOptions{})
+	assert.NotNil(t, err)
+	_, _, err = svc.ListCampaignResults(context.Background(), 3, kolide.ListOptions{})
+	assert.True(t, kolide.IsNotFound(err))
+	assert.False(t, ds.ListCampaignResultsFuncInvoked)
+}
@@ -7,11 +7,10 @@ import (
 
 	"github.com/kolide/fleet/server/contexts/viewer"
 	"github.com/kolide/fleet/server/kolide"
-	"github.com/kolide/fleet/server/websocket"
 	"github.com/pkg/errors"
 )
 
-func (svc service) NewDistributedQueryCampaignByNames(ctx context.Context, queryString string, hosts []string, labels []string) (*kolide.DistributedQueryCampaign, error) {
+func (svc service) NewDistributedQueryCampaignByNames(ctx context.Context, queryString string, hosts []string, labels []string, tags []string, opts kolide.CampaignOptions) (*kolide.DistributedQueryCampaign, error) {
 	hostIDs, err := svc.ds.HostIDsByName(hosts)
 	if err != nil {
 		return nil, errors.Wrap(err, "finding host IDs")
@@ -22,14 +21,24 @@ func (svc service) NewDistributedQueryCampaignByNames(ctx context.Context, query
 		return nil, errors.Wrap(err, "finding label IDs")
 	}
 
-	return svc.NewDistributedQueryCampaign(ctx, queryString, hostIDs, labelIDs)
+	for _, name := range tags {
+		if _, err := kolide.ParseHostTagName(name); err != nil {
+			return nil, newInvalidArgumentError("tags", err.Error())
+		}
+	}
+	tagIDs, err := svc.ds.TagIDsByName(tags)
+	if err != nil {
+		return nil, errors.Wrap(err, "finding tag IDs")
+	}
+
+	return svc.NewDistributedQueryCampaign(ctx, queryString, hostIDs, labelIDs, tagIDs, opts)
 }
 
 func uintPtr(n uint) *uint {
 	return &n
 }
 
-func (svc service) NewDistributedQueryCampaign(ctx context.Context, queryString string, hosts []uint, labels []uint) (*kolide.DistributedQueryCampaign, error) {
+func (svc service) NewDistributedQueryCampaign(ctx context.Context, queryString string, hosts []uint, labels []uint, tags []uint, opts kolide.CampaignOptions) (*kolide.DistributedQueryCampaign, error) {
 	if err := svc.StatusLiveQuery(ctx); err != nil {
 		return nil, err
 	}
@@ -39,6 +48,15 @@ func (svc service) NewDistributedQueryCampaign(ctx context.Context, queryString
 		return nil, errNoContext
 	}
 
+	if opts.TTLDays > 0 && !opts.Deferred {
+		return nil, newInvalidArgumentError("ttl_days", "a TTL can only be set for deferred campaigns")
+	}
+
+	validation := svc.osquerySchema.ValidateQuery(queryString, "")
+	if err := queryValidationError("query", validation); err != nil {
+		return nil, err
+	}
+
 	query, err := svc.ds.NewQuery(&kolide.Query{
 		Name:     fmt.Sprintf("distributed_%s_%d", vc.Username(), time.Now().Unix()),
 		Query:    queryString,
@@ -49,11 +67,38 @@ func (svc service) NewDistributedQueryCampaign(ctx context.Context, queryString
 		return nil, errors.Wrap(err, "new query")
 	}
 
-	campaign, err := svc.ds.NewDistributedQueryCampaign(&kolide.DistributedQueryCampaign{
-		QueryID: query.ID,
-		Status:  kolide.QueryWaiting,
-		UserID:  vc.UserID(),
-	})
+	metrics, err := svc.ds.CountHostsInTargets(hosts, labels, tags, time.Now())
+	if err != nil {
+		return nil, errors.Wrap(err, "counting hosts")
+	}
+
+	campaign := &kolide.DistributedQueryCampaign{
+		QueryID:         query.ID,
+		Status:          kolide.QueryWaiting,
+		UserID:          vc.UserID(),
+		PersistResults:  opts.PersistResults,
+		ExpectedResults: metrics.TotalHosts,
+	}
+	if opts.Deferred {
+		// Deferred campaigns are sent to hosts as they check in, without
+		// waiting for a result stream, and their results are collected
+		// on the server.
+		ttl := time.Duration(opts.TTLDays) * 24 * time.Hour
+		if ttl == 0 {
+			ttl = svc.config.Osquery.DeferredCampaignTTL
+		}
+		expiresAt := svc.clock.Now().Add(ttl)
+		campaign.Status = kolide.QueryRunning
+		campaign.PersistResults = true
+		campaign.Deferred = true
+		campaign.ExpiresAt = &expiresAt
+	} else if campaign.PersistResults {
+		// Campaigns with persisted results keep running without a result
+		// stream, until they expire.
+		expiresAt := svc.clock.Now().Add(svc.config.Osquery.PersistedCampaignTTL)
+		campaign.ExpiresAt = &expiresAt
+	}
+	campaign, err = svc.ds.NewDistributedQueryCampaign(campaign)
 	if err != nil {
 		return nil, errors.Wrap(err, "new campaign")
 	}
@@ -81,10 +126,20 @@ func (svc service) NewDistributedQueryCampaign(ctx context.Context, queryString
 			return nil, errors.Wrap(err, "adding label target")
 		}
 	}
-	campaign.Metrics, err = svc.ds.CountHostsInTargets(hosts, labels, time.Now())
-	if err != nil {
-		return nil, errors.Wrap(err, "counting hosts")
+
+	// Add tag targets
+	for _, tid := range tags {
+		_, err = svc.ds.NewDistributedQueryCampaignTarget(&kolide.DistributedQueryCampaignTarget{
+			Type:                       kolide.TargetTag,
+			DistributedQueryCampaignID: campaign.ID,
+			TargetID:                   tid,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "adding tag target")
+		}
 	}
+	campaign.Metrics = metrics
+	campaign.Warnings = validation.Warnings
 	return campaign, nil
 }
 
@@ -106,7 +161,13 @@ type campaignStatus struct {
 	Status          string `json:"status"`
 }
 
-func (svc service) StreamCampaignResults(ctx context.Context, conn *websocket.Conn, campaignID uint) {
+func (svc service) StreamCampaignResults(ctx context.Context, conn kolide.CampaignStreamWriter, campaignID uint) {
+	vc, ok := viewer.FromContext(ctx)
+	if !ok {
+		conn.WriteJSONError("unauthorized")
+		return
+	}
+
 	// Find the campaign and ensure it is active
 	campaign, err := svc.ds.DistributedQueryCampaign(campaignID)
 	if err != nil {
@@ -114,30 +175,74 @@ func (svc service) StreamCampaignResults(ctx context.Context, conn *websocket.Co
 		return
 	}
 
-	if campaign.Status != kolide.QueryWaiting {
-		conn.WriteJSONError(fmt.Sprintf("campaign %d not running", campaignID))
-		return
+	// Deferred campaigns are already running, and their results can be
+	// followed while they wait for hosts to check in.
+	if campaign.Deferred {
+		if campaign.Status != kolide.QueryRunning {
+			conn.WriteJSONError(fmt.Sprintf("campaign %d not running", campaignID))
+			return
+		}
+	} else {
+		switch campaign.Status {
+		case kolide.QueryWaiting:
+			// Following a waiting campaign starts it, which only
+			// maintainers and the user that created it may do.
+			// Observers can follow campaigns that are already
+			// running.
+			if !vc.HasRole(kolide.RoleMaintainer) && !vc.IsUserID(campaign.UserID) {
+				conn.WriteJSONError(fmt.Sprintf("not allowed to start campaign %d", campaignID))
+				return
+			}
+			// Setting status to running will cause the query to be
+			// returned to the targets when they check in for their
+			// queries
+			campaign.Status = kolide.QueryRunning
+			if err := svc.ds.SaveDistributedQueryCampaign(campaign); err != nil {
+				conn.WriteJSONError("error saving campaign state")
+				return
+			}
+		case kolide.QueryRunning:
+			// Join the streams already following the campaign
+		default:
+			conn.WriteJSONError(fmt.Sprintf("campaign %d not running", campaignID))
+			return
+		}
 	}
 
-	// Setting status to running will cause the query to be returned to the
-	// targets when they check in for their queries
-	campaign.Status = kolide.QueryRunning
-	if err := svc.ds.SaveDistributedQueryCampaign(campaign); err != nil {
+	viewerID, err := svc.ds.NewDistributedQueryCampaignViewer(campaign.ID)
+	if err != nil {
 		conn.WriteJSONError("error saving campaign state")
 		return
 	}
 
 	// Setting the status to completed stops the query from being sent to
-	// targets. If this fails, there is a background job that will clean up
-	// this campaign.
+	// targets once the last stream following the campaign ends. Streams
+	// that stopped touching their viewer (as when their Fleet instance
+	// stopped) are not counted. If this fails, there is a background job
+	// that will clean up this campaign. Campaigns with persisted results
+	// keep running until they expire, so that hosts checking in later
+	// still add their results.
 	defer func() {
+		activeSince := svc.clock.Now().Add(-kolide.CampaignViewerTimeout)
+		remaining, err := svc.ds.DeleteDistributedQueryCampaignViewer(viewerID, activeSince)
+		if err != nil {
+			svc.logger.Log("msg", "error removing campaign viewer", "err", err)
+			return
+		}
+		if remaining > 0 || campaign.PersistResults {
+			return
+		}
 		campaign.Status = kolide.QueryComplete
 		svc.ds.SaveDistributedQueryCampaign(campaign)
 	}()
 
 	// Open the channel from which we will receive incoming query results
-	// (probably from the redis pubsub implementation)
-	readChan, err := svc.resultStore.ReadChannel(context.Background(), *campaign)
+	// (probably from the redis pubsub implementation), starting with the
+	// results the campaign already received if other streams are
+	// following it.
+	readCtx, cancelRead := context.WithCancel(ctx)
+	defer cancelRead()
+	readChan, err := svc.resultStore.ReadChannel(readCtx, *campaign)
 	if err != nil {
 		conn.WriteJSONError(fmt.Sprintf("cannot open read channel for campaign %d ", campaignID))
 		return
@@ -150,22 +255,29 @@ func (svc service) StreamCampaignResults(ctx context.Context, conn *websocket.Co
 	lastTotals := targetTotals{}
 
 	// to improve performance of the frontend rendering the results table, we
-	// add the "host_hostname" field to every row.
-	mapHostnameRows := func(hostname string, rows []map[string]string) {
-		for _, row := range rows {
-			row["host_hostname"] = hostname
+	// add the "host_hostname" field to every row. The rows are copied as
+	// the result is shared with the other streams following the campaign.
+	mapHostnameRows := func(hostname string, rows []map[string]string) []map[string]string {
+		mapped := make([]map[string]string, len(rows))
+		for i, row := range rows {
+			mapped[i] = make(map[string]string, len(row)+1)
+			for k, v := range row {
+				mapped[i][k] = v
+			}
+			mapped[i]["host_hostname"] = hostname
 		}
+		return mapped
 	}
 
 	updateStatus := func() error {
-		hostIDs, labelIDs, err := svc.ds.DistributedQueryCampaignTargetIDs(campaign.ID)
+		hostIDs, labelIDs, tagIDs, err := svc.ds.DistributedQueryCampaignTargetIDs(campaign.ID)
 		if err != nil {
 			if err = conn.WriteJSONError("error retrieving campaign targets"); err != nil {
 				return errors.New("retrieve campaign targets")
 			}
 		}
 
-		metrics, err := svc.CountHostsInTargets(context.Background(), hostIDs, labelIDs)
+		metrics, err := svc.CountHostsInTargets(context.Background(), hostIDs, labelIDs, tagIDs)
 		if err != nil {
 			if err = conn.WriteJSONError("error retrieving target counts"); err != nil {
 				return errors.New("retrieve target counts")
@@ -214,19 +326,47 @@ func (svc service) StreamCampaignResults(ctx context.Context, conn *websocket.Co
 		// any results are written, to avoid the frontend showing "x of
 		// 0 Hosts Returning y Records")
 		select {
+		case <-ctx.Done():
+			// The client went away
+			return
+
 		case res := <-readChan:
 			// Receive a result and push it over the websocket
 			switch res := res.(type) {
 			case kolide.DistributedQueryResult:
-				mapHostnameRows(res.Host.HostName, res.Rows)
+				res.Rows = mapHostnameRows(res.Host.HostName, res.Rows)
 				err = conn.WriteJSONMessage("result", res)
 				if err != nil {
 					svc.logger.Log("msg", "error writing to channel", "err", err)
 				}
 				status.ActualResults++
+			case kolide.ResultHistoryTruncated:
+				// Results received before this stream joined the
+				// campaign are no longer retained, they still count
+				// towards the campaign status.
+				err = conn.WriteJSONError(fmt.Sprintf("%d results received before joining campaign %d are no longer available", res.Dropped, campaignID))
+				if err != nil {
+					svc.logger.Log("msg", "error writing to channel", "err", err)
+				}
+				status.ActualResults += uint(res.Dropped)
 			}
 
 		case <-ticker.C:
+			if err := svc.ds.TouchDistributedQueryCampaignViewer(viewerID); err != nil {
+				svc.logger.Log("msg", "error touching campaign viewer", "err", err)
+			}
+
+			// End the stream if the campaign was canceled (or
+			// expired) in the meantime
+			current, err := svc.ds.DistributedQueryCampaign(campaign.ID)
+			if err == nil && current.Status == kolide.QueryComplete {
+				status.Status = campaignStatusFinished
+				if err := conn.WriteJSONMessage("status", status); err != nil {
+					svc.logger.Log("msg", "error writing status", "err", err)
+				}
+				return
+			}
+
 			// Update status
 			if err := updateStatus(); err != nil {
 				svc.logger.Log("msg", "error updating status", "err", err)
@@ -236,3 +376,42 @@ func (svc service) StreamCampaignResults(ctx context.Context, conn *websocket.Co
 	}
 
 }
+
+func (svc service) ListDistributedQueryCampaigns(ctx context.Context, opt kolide.ListOptions, filter kolide.DistributedQueryCampaignFilter) ([]*kolide.DistributedQueryCampaignSummary, error) {
+	return svc.ds.ListDistributedQueryCampaigns(opt, filter)
+}
+
+func (svc service) ListDistributedQueryCampaignHosts(ctx context.Context, campaignID uint, opt kolide.ListOptions) ([]*kolide.DistributedQueryCampaignHost, error) {
+	// Load the campaign first so that a missing campaign is reported as
+	// such rather than as an empty list of hosts.
+	if _, err := svc.ds.DistributedQueryCampaign(campaignID); err != nil {
+		return nil, err
+	}
+	return svc.ds.ListDistributedQueryCampaignHosts(campaignID, opt)
+}
+
+func (svc service) CancelDistributedQueryCampaign(ctx context.Context, campaignID uint) (*kolide.DistributedQueryCampaign, error) {
+	vc, ok := viewer.FromContext(ctx)
+	if !ok {
+		return nil, errNoContext
+	}
+
+	campaign, err := svc.ds.DistributedQueryCampaign(campaignID)
+	if err != nil {
+		return nil, err
+	}
+	if !vc.CanPerformAdminActions() && !vc.IsUserID(campaign.UserID) {
+		return nil, newPermissionError("id", "only the user that created the campaign or an admin can cancel it")
+	}
+
+	// Completed campaigns are no longer sent to hosts, and result streams
+	// of the campaign end when they see the new status.
+	if campaign.Status != kolide.QueryComplete {
+		campaign.Status = kolide.QueryComplete
+		if err := svc.ds.SaveDistributedQueryCampaign(campaign); err != nil {
+			return nil, errors.Wrap(err, "cancel campaign")
+		}
+	}
+
+	return campaign, nil
+}
@@ -0,0 +1,380 @@
+package service
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/WatchBeam/clock"
+	"github.com/kolide/fleet/server/config"
+	"github.com/kolide/fleet/server/contexts/viewer"
+	"github.com/kolide/fleet/server/datastore/inmem"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/mock"
+	"github.com/kolide/fleet/server/pubsub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCancelDistributedQueryCampaign(t *testing.T) {
+	ds := new(mock.Store)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+
+	var saved *kolide.DistributedQueryCampaign
+	ds.DistributedQueryCampaignFunc = func(id uint) (*kolide.DistributedQueryCampaign, error) {
+		if id != 1 {
+			return nil, notFoundError{}
+		}
+		return &kolide.DistributedQueryCampaign{ID: id, UserID: 5, Status: kolide.QueryRunning}, nil
+	}
+	ds.SaveDistributedQueryCampaignFunc = func(campaign *kolide.DistributedQueryCampaign) error {
+		saved = campaign
+		return nil
+	}
+
+	viewerCtx := func(user *kolide.User) context.Context {
+		user.Enabled = true
+		return viewer.NewContext(context.Background(), viewer.Viewer{User: user, Session: &kolide.Session{ID: 1}})
+	}
+
+	_, err = svc.CancelDistributedQueryCampaign(viewerCtx(&kolide.User{ID: 5}), 2)
+	require.NotNil(t, err)
+	assert.IsType(t, notFoundError{}, err)
+
+	// Other users cannot cancel the campaign
+	_, err = svc.CancelDistributedQueryCampaign(viewerCtx(&kolide.User{ID: 6}), 1)
+	require.NotNil(t, err)
+	assert.IsType(t, permissionError{}, err)
+	assert.Nil(t, saved)
+
+	for _, user := range []*kolide.User{{ID: 5}, {ID: 6, Admin: true}} {
+		saved = nil
+		campaign, err := svc.CancelDistributedQueryCampaign(viewerCtx(user), 1)
+		require.Nil(t, err)
+		assert.Equal(t, kolide.QueryComplete, campaign.Status)
+		require.NotNil(t, saved)
+		assert.Equal(t, kolide.QueryComplete, saved.Status)
+	}
+}
+
+func TestListDistributedQueryCampaignHosts(t *testing.T) {
+	ds := new(mock.Store)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+
+	ds.DistributedQueryCampaignFunc = func(id uint) (*kolide.DistributedQueryCampaign, error) {
+		if id != 1 {
+			return nil, notFoundError{}
+		}
+		return &kolide.DistributedQueryCampaign{ID: id}, nil
+	}
+	ds.ListDistributedQueryCampaignHostsFunc = func(campaignID uint, opt kolide.ListOptions) ([]*kolide.DistributedQueryCampaignHost, error) {
+		return []*kolide.DistributedQueryCampaignHost{
+			{HostID: 3, HostName: "foo", Status: kolide.ExecutionFailed, Error: "no such table: foo"},
+		}, nil
+	}
+
+	hosts, err := svc.ListDistributedQueryCampaignHosts(context.Background(), 1, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, hosts, 1)
+	assert.Equal(t, "no such table: foo", hosts[0].Error)
+
+	ds.ListDistributedQueryCampaignHostsFuncInvoked = false
+	_, err = svc.ListDistributedQueryCampaignHosts(context.Background(), 2, kolide.ListOptions{})
+	require.NotNil(t, err)
+	assert.IsType(t, notFoundError{}, err)
+	assert.False(t, ds.ListDistributedQueryCampaignHostsFuncInvoked)
+}
+
+// testCampaignStream records the types of the messages written to a campaign
+// results stream.
+type testCampaignStream struct {
+	messages chan string
+}
+
+func (s *testCampaignStream) WriteJSONMessage(typ string, data interface{}) error {
+	s.messages <- typ
+	return nil
+}
+
+func (s *testCampaignStream) WriteJSONError(data interface{}) error {
+	s.messages <- "error"
+	return nil
+}
+
+func (s *testCampaignStream) waitFor(t *testing.T, typ string) {
+	for {
+		select {
+		case msg := <-s.messages:
+			if msg == typ {
+				return
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for %s message", typ)
+		}
+	}
+}
+
+// viewerCountingDatastore counts the campaign viewers registered with the
+// inmem datastore.
+type viewerCountingDatastore struct {
+	*inmem.Datastore
+	viewers int32
+}
+
+func (d *viewerCountingDatastore) NewDistributedQueryCampaignViewer(campaignID uint) (uint, error) {
+	id, err := d.Datastore.NewDistributedQueryCampaignViewer(campaignID)
+	if err == nil {
+		atomic.AddInt32(&d.viewers, 1)
+	}
+	return id, err
+}
+
+func (d *viewerCountingDatastore) DeleteDistributedQueryCampaignViewer(id uint, activeSince time.Time) (uint, error) {
+	remaining, err := d.Datastore.DeleteDistributedQueryCampaignViewer(id, activeSince)
+	if err == nil {
+		atomic.AddInt32(&d.viewers, -1)
+	}
+	return remaining, err
+}
+
+func (d *viewerCountingDatastore) waitForViewers(t *testing.T, campaignID uint, n int32) *kolide.DistributedQueryCampaign {
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		if atomic.LoadInt32(&d.viewers) == n {
+			current, err := d.DistributedQueryCampaign(campaignID)
+			require.Nil(t, err)
+			return current
+		}
+	}
+	t.Fatalf("Timed out waiting for %d viewers", n)
+	return nil
+}
+
+func TestStreamCampaignResultsMultipleViewers(t *testing.T) {
+	inmemDS, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	ds := &viewerCountingDatastore{Datastore: inmemDS}
+	_, err = ds.NewAppConfig(&kolide.AppConfig{})
+	require.Nil(t, err)
+	rs := pubsub.NewInmemQueryResults()
+	svc, err := newTestService(ds, rs)
+	require.Nil(t, err)
+
+	query, err := ds.NewQuery(&kolide.Query{Name: "test", Query: "select * from time"})
+	require.Nil(t, err)
+	campaign, err := ds.NewDistributedQueryCampaign(&kolide.DistributedQueryCampaign{
+		QueryID: query.ID,
+		Status:  kolide.QueryWaiting,
+	})
+	require.Nil(t, err)
+	maintainer := &kolide.User{ID: 1, Username: "maintainer", Enabled: true, Role: kolide.RoleMaintainer}
+	maintainerCtx := viewer.NewContext(context.Background(), viewer.Viewer{User: maintainer, Session: &kolide.Session{ID: 1}})
+
+	view := func() (*testCampaignStream, context.CancelFunc, <-chan struct{}) {
+		stream := &testCampaignStream{messages: make(chan string, 100)}
+		ctx, cancel := context.WithCancel(maintainerCtx)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			svc.StreamCampaignResults(ctx, stream, campaign.ID)
+		}()
+		return stream, cancel, done
+	}
+
+	stream1, cancel1, done1 := view()
+	defer cancel1()
+	ds.waitForViewers(t, campaign.ID, 1)
+	stream2, cancel2, done2 := view()
+	defer cancel2()
+	current := ds.waitForViewers(t, campaign.ID, 2)
+	assert.Equal(t, kolide.QueryRunning, current.Status)
+
+	// Both viewers receive the results
+	require.Nil(t, rs.WriteResult(kolide.DistributedQueryResult{
+		DistributedQueryCampaignID: campaign.ID,
+		Host:                       kolide.Host{ID: 1, HostName: "foo"},
+		Rows:                       []map[string]string{{"bar": "baz"}},
+	}))
+	stream1.waitFor(t, "result")
+	stream2.waitFor(t, "result")
+
+	// The campaign keeps running while a viewer remains
+	cancel1()
+	<-done1
+	current = ds.waitForViewers(t, campaign.ID, 1)
+	assert.Equal(t, kolide.QueryRunning, current.Status)
+
+	cancel2()
+	<-done2
+	current = ds.waitForViewers(t, campaign.ID, 0)
+	assert.Equal(t, kolide.QueryComplete, current.Status)
+}
+
+func TestStreamCampaignResultsStaleViewer(t *testing.T) {
+	inmemDS, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	ds := &viewerCountingDatastore{Datastore: inmemDS}
+	_, err = ds.NewAppConfig(&kolide.AppConfig{})
+	require.Nil(t, err)
+	rs := pubsub.NewInmemQueryResults()
+	// The service runs past the timeout of the viewers registered now
+	mockClock := clock.NewMockClock(time.Now().Add(kolide.CampaignViewerTimeout + time.Minute))
+	svc, err := newTestServiceWithClock(ds, rs, mockClock)
+	require.Nil(t, err)
+
+	query, err := ds.NewQuery(&kolide.Query{Name: "test", Query: "select * from time"})
+	require.Nil(t, err)
+	campaign, err := ds.NewDistributedQueryCampaign(&kolide.DistributedQueryCampaign{
+		QueryID: query.ID,
+		Status:  kolide.QueryRunning,
+	})
+	require.Nil(t, err)
+
+	// A viewer of a Fleet instance that stopped is never deleted
+	_, err = ds.NewDistributedQueryCampaignViewer(campaign.ID)
+	require.Nil(t, err)
+
+	observer := &kolide.User{ID: 2, Username: "observer", Enabled: true, Role: kolide.RoleObserver}
+	observerCtx := viewer.NewContext(context.Background(), viewer.Viewer{User: observer, Session: &kolide.Session{ID: 2}})
+	stream := &testCampaignStream{messages: make(chan string, 100)}
+	ctx, cancel := context.WithCancel(observerCtx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		svc.StreamCampaignResults(ctx, stream, campaign.ID)
+	}()
+	ds.waitForViewers(t, campaign.ID, 2)
+
+	// The campaign is completed when the last active viewer leaves
+	cancel()
+	<-done
+	current := ds.waitForViewers(t, campaign.ID, 1)
+	assert.Equal(t, kolide.QueryComplete, current.Status)
+}
+
+func TestStreamCampaignResultsHistoryTruncated(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	_, err = ds.NewAppConfig(&kolide.AppConfig{})
+	require.Nil(t, err)
+	rs := &mock.QueryResultStore{
+		HealthCheckFunc: func() error {
+			return nil
+		},
+		// The oldest results of the campaign are no longer retained
+		ReadChannelFunc: func(ctx context.Context, query kolide.DistributedQueryCampaign) (<-chan interface{}, error) {
+			channel := make(chan interface{}, 1)
+			channel <- kolide.ResultHistoryTruncated{Dropped: 3}
+			return channel, nil
+		},
+	}
+	svc, err := newTestService(ds, rs)
+	require.Nil(t, err)
+
+	query, err := ds.NewQuery(&kolide.Query{Name: "test", Query: "select * from time"})
+	require.Nil(t, err)
+	campaign, err := ds.NewDistributedQueryCampaign(&kolide.DistributedQueryCampaign{
+		QueryID: query.ID,
+		Status:  kolide.QueryRunning,
+	})
+	require.Nil(t, err)
+
+	observer := &kolide.User{ID: 2, Username: "observer", Enabled: true, Role: kolide.RoleObserver}
+	ctx, cancel := context.WithCancel(viewer.NewContext(context.Background(), viewer.Viewer{User: observer, Session: &kolide.Session{ID: 2}}))
+	stream := &testCampaignStream{messages: make(chan string, 100)}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		svc.StreamCampaignResults(ctx, stream, campaign.ID)
+	}()
+
+	// The stream is told that results are missing
+	stream.waitFor(t, "error")
+	cancel()
+	<-done
+}
+
+func TestStreamCampaignResultsStartPermissions(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	_, err = ds.NewAppConfig(&kolide.AppConfig{})
+	require.Nil(t, err)
+	svc, err := newTestService(ds, pubsub.NewInmemQueryResults())
+	require.Nil(t, err)
+
+	query, err := ds.NewQuery(&kolide.Query{Name: "test", Query: "select * from time"})
+	require.Nil(t, err)
+	campaign, err := ds.NewDistributedQueryCampaign(&kolide.DistributedQueryCampaign{
+		QueryID: query.ID,
+		UserID:  1,
+		Status:  kolide.QueryWaiting,
+	})
+	require.Nil(t, err)
+
+	// An observer cannot start the waiting campaign of another user
+	observer := &kolide.User{ID: 2, Username: "observer", Enabled: true, Role: kolide.RoleObserver}
+	ctx := viewer.NewContext(context.Background(), viewer.Viewer{User: observer, Session: &kolide.Session{ID: 2}})
+	stream := &testCampaignStream{messages: make(chan string, 100)}
+	svc.StreamCampaignResults(ctx, stream, campaign.ID)
+	stream.waitFor(t, "error")
+	current, err := ds.DistributedQueryCampaign(campaign.ID)
+	require.Nil(t, err)
+	assert.Equal(t, kolide.QueryWaiting, current.Status)
+
+	// but can start their own
+	campaign.UserID = observer.ID
+	require.Nil(t, ds.SaveDistributedQueryCampaign(campaign))
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		svc.StreamCampaignResults(ctx, stream, campaign.ID)
+	}()
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		current, err = ds.DistributedQueryCampaign(campaign.ID)
+		require.Nil(t, err)
+		if current.Status == kolide.QueryRunning {
+			break
+		}
+	}
+	assert.Equal(t, kolide.QueryRunning, current.Status)
+	cancel()
+	<-done
+}
+
+func TestPersistedCampaignExpiry(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	_, err = ds.NewAppConfig(&kolide.AppConfig{})
+	require.Nil(t, err)
+	rs := &mock.QueryResultStore{
+		HealthCheckFunc: func() error {
+			return nil
+		},
+	}
+	mockClock := clock.NewMockClock()
+	svc, err := newTestServiceWithClock(ds, rs, mockClock)
+	require.Nil(t, err)
+
+	viewerCtx := viewer.NewContext(context.Background(), viewer.Viewer{
+		User: &kolide.User{ID: 1, Username: "admin"},
+	})
+	campaign, err := svc.NewDistributedQueryCampaign(viewerCtx, "select * from time", []uint{1}, nil, nil,
+		kolide.CampaignOptions{PersistResults: true})
+	require.Nil(t, err)
+	assert.False(t, campaign.Deferred)
+	require.NotNil(t, campaign.ExpiresAt)
+	assert.Equal(t, mockClock.Now().Add(config.TestConfig().Osquery.PersistedCampaignTTL), *campaign.ExpiresAt)
+
+	// The campaign keeps running without a result stream until it expires
+	campaign.Status = kolide.QueryRunning
+	require.Nil(t, ds.SaveDistributedQueryCampaign(campaign))
+	expired, _, err := ds.CleanupDistributedQueryCampaigns(mockClock.Now().Add(30 * time.Minute))
+	require.Nil(t, err)
+	assert.Equal(t, uint(0), expired)
+	expired, _, err = ds.CleanupDistributedQueryCampaigns(mockClock.Now().Add(2 * time.Hour))
+	require.Nil(t, err)
+	assert.Equal(t, uint(1), expired)
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (svc service) ListHostStatusLogs(ctx context.Context, hostID uint, opt kolide.ListOptions) ([]*kolide.HostStatusLog, error) {
+	// Load the host first so that a missing host is reported as such
+	// rather than as an empty list of logs.
+	if _, err := svc.ds.Host(hostID); err != nil {
+		return nil, err
+	}
+	return svc.ds.ListHostStatusLogs(hostID, opt)
+}
+
+func (svc service) ListScheduledQueryErrors(ctx context.Context) ([]*kolide.ScheduledQueryErrors, error) {
+	return svc.ds.ListScheduledQueryErrors()
+}
+
+// retainStatusLogs saves the warning and error status logs of the host, up to
+// the configured retention.
+func (svc service) retainStatusLogs(host kolide.Host, logs []json.RawMessage) error {
+	if svc.config.Osquery.StatusLogRetention <= 0 {
+		return nil
+	}
+
+	retained := []*kolide.HostStatusLog{}
+	for _, raw := range logs {
+		log, ok := parseStatusLog(raw, svc.clock.Now())
+		if !ok || log.Severity < kolide.StatusLogWarning {
+			continue
+		}
+		retained = append(retained, log)
+	}
+	if len(retained) == 0 {
+		return nil
+	}
+
+	return svc.ds.SaveHostStatusLogs(host.ID, retained, uint(svc.config.Osquery.StatusLogRetention))
+}
+
+// osqueryStatusLog is the format of the status logs sent by osquery. Numbers
+// are sent as strings by osquery but are accepted in both forms.
+type osqueryStatusLog struct {
+	Severity json.Number `json:"severity"`
+	Filename string      `json:"filename"`
+	Line     json.Number `json:"line"`
+	Message  string      `json:"message"`
+	UnixTime json.Number `json:"unixTime"`
+}
+
+// parseStatusLog parses an osquery status log, returning false if the log is
+// not in the expected format. The log time defaults to now if osquery did not
+// provide it.
+func parseStatusLog(raw json.RawMessage, now time.Time) (*kolide.HostStatusLog, bool) {
+	var status osqueryStatusLog
+	if err := json.Unmarshal(raw, &status); err != nil {
+		return nil, false
+	}
+	severity, err := strconv.Atoi(status.Severity.String())
+	if err != nil {
+		return nil, false
+	}
+
+	log := &kolide.HostStatusLog{
+		Severity:  kolide.StatusLogSeverity(severity),
+		Filename:  status.Filename,
+		Message:   status.Message,
+		QueryName: scheduledQueryNameFromStatus(status.Message),
+		CreatedAt: now,
+	}
+	if line, err := strconv.ParseUint(status.Line.String(), 10, 32); err == nil {
+		log.Line = uint(line)
+	}
+	if unixTime, err := strconv.ParseInt(status.UnixTime.String(), 10, 64); err == nil && unixTime > 0 {
+		log.CreatedAt = time.Unix(unixTime, 0).UTC()
+	}
+
+	return log, true
+}
+
+// scheduledQueryStatusPrefixes are the prefixes of the osquery status log
+// messages that refer to a scheduled query, followed by the query name.
+var scheduledQueryStatusPrefixes = []string{
+	"Error executing scheduled query ",
+	"Scheduled query may have failed: ",
+	"Denylisting query: ",
+	"Blacklisting query: ",
+}
+
+// scheduledQueryNameFromStatus returns the name of the scheduled query a
+// status log message refers to, or nil if it does not refer to one.
+func scheduledQueryNameFromStatus(message string) *string {
+	for _, prefix := range scheduledQueryStatusPrefixes {
+		if !strings.HasPrefix(message, prefix) {
+			continue
+		}
+		name := strings.TrimPrefix(message, prefix)
+		// Execution errors are followed by ": <error>"
+		if i := strings.Index(name, ": "); i >= 0 {
+			name = name[:i]
+		}
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil
+		}
+		return &name
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (svc service) ModifyHostTags(ctx context.Context, hostID uint, tags map[string]*string) (map[string]string, error) {
+	for key, value := range tags {
+		var v string
+		if value != nil {
+			v = *value
+		}
+		if err := kolide.ValidateHostTag(key, v); err != nil {
+			return nil, newInvalidArgumentError("tags", err.Error())
+		}
+	}
+
+	// Load the host first so that a missing host is reported as such.
+	if _, err := svc.ds.Host(hostID); err != nil {
+		return nil, err
+	}
+
+	current, err := svc.ds.ListTagsForHost(hostID)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range tags {
+		if value == nil {
+			delete(current, key)
+			continue
+		}
+		current[key] = *value
+	}
+
+	if err := svc.ds.SetHostTags(hostID, current); err != nil {
+		return nil, err
+	}
+	return current, nil
+}
+
+func (svc service) ApplyHostTagSpecs(ctx context.Context, specs []*kolide.HostTagSpec) error {
+	// Resolve and validate all of the specs before modifying any host, so
+	// that an invalid spec does not result in a partial apply.
+	hostIDs := make([][]uint, len(specs))
+	for i, spec := range specs {
+		if spec.Hostname == "" {
+			return newInvalidArgumentError("hostname", "host tag spec hostname must not be empty")
+		}
+		for key, value := range spec.Tags {
+			if err := kolide.ValidateHostTag(key, value); err != nil {
+				return newInvalidArgumentError("tags", err.Error())
+			}
+		}
+
+		ids, err := svc.ds.HostIDsByName([]string{spec.Hostname})
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return newInvalidArgumentError("hostname", fmt.Sprintf("no host with hostname %q", spec.Hostname))
+		}
+		hostIDs[i] = ids
+	}
+
+	for i, spec := range specs {
+		for _, id := range hostIDs[i] {
+			if err := svc.ds.SetHostTags(id, spec.Tags); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
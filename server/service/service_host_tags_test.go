@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModifyHostTags(t *testing.T) {
+	ds := new(mock.Store)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+
+	ds.HostFunc = func(id uint) (*kolide.Host, error) {
+		if id != 1 {
+			return nil, notFoundError{}
+		}
+		return &kolide.Host{ID: id}, nil
+	}
+	ds.ListTagsForHostFunc = func(hostID uint) (map[string]string, error) {
+		return map[string]string{"env": "dev", "owner": "infra"}, nil
+	}
+	var saved map[string]string
+	ds.SetHostTagsFunc = func(hostID uint, tags map[string]string) error {
+		saved = tags
+		return nil
+	}
+
+	tags, err := svc.ModifyHostTags(context.Background(), 1, map[string]*string{
+		"env":   stringPtr("prod"),
+		"owner": nil,
+		"team":  stringPtr("web"),
+	})
+	require.Nil(t, err)
+	expected := map[string]string{"env": "prod", "team": "web"}
+	assert.Equal(t, expected, tags)
+	assert.Equal(t, expected, saved)
+
+	ds.SetHostTagsFuncInvoked = false
+	_, err = svc.ModifyHostTags(context.Background(), 2, map[string]*string{"env": stringPtr("prod")})
+	assert.True(t, kolide.IsNotFound(err))
+
+	for _, key := range []string{"", "a=b", strings.Repeat("k", 256)} {
+		_, err = svc.ModifyHostTags(context.Background(), 1, map[string]*string{key: stringPtr("v")})
+		assert.NotNil(t, err, key)
+	}
+	assert.False(t, ds.SetHostTagsFuncInvoked)
+}
+
+func TestApplyHostTagSpecs(t *testing.T) {
+	ds := new(mock.Store)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+
+	ds.HostIDsByNameFunc = func(hostnames []string) ([]uint, error) {
+		switch hostnames[0] {
+		case "foo.local":
+			return []uint{1}, nil
+		case "bar.local":
+			return []uint{2, 3}, nil
+		}
+		return []uint{}, nil
+	}
+	saved := map[uint]map[string]string{}
+	ds.SetHostTagsFunc = func(hostID uint, tags map[string]string) error {
+		saved[hostID] = tags
+		return nil
+	}
+
+	err = svc.ApplyHostTagSpecs(context.Background(), []*kolide.HostTagSpec{
+		{Hostname: "foo.local", Tags: map[string]string{"env": "prod"}},
+		{Hostname: "bar.local", Tags: map[string]string{"env": "dev"}},
+	})
+	require.Nil(t, err)
+	assert.Equal(t, map[uint]map[string]string{
+		1: {"env": "prod"},
+		2: {"env": "dev"},
+		3: {"env": "dev"},
+	}, saved)
+
+	// An unknown host fails the whole apply
+	ds.SetHostTagsFuncInvoked = false
+	err = svc.ApplyHostTagSpecs(context.Background(), []*kolide.HostTagSpec{
+		{Hostname: "foo.local", Tags: map[string]string{"env": "prod"}},
+		{Hostname: "baz.local", Tags: map[string]string{"env": "dev"}},
+	})
+	require.NotNil(t, err)
+	assert.False(t, ds.SetHostTagsFuncInvoked)
+}
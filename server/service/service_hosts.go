@@ -2,11 +2,17 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
 
 	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
 )
 
-func (svc service) ListHosts(ctx context.Context, opt kolide.ListOptions) ([]*kolide.Host, error) {
+func (svc service) ListHosts(ctx context.Context, opt kolide.HostListOptions) ([]*kolide.Host, error) {
 	return svc.ds.ListHosts(opt)
 }
 
@@ -30,3 +36,227 @@ func (svc service) GetHostSummary(ctx context.Context) (*kolide.HostSummary, err
 func (svc service) DeleteHost(ctx context.Context, id uint) error {
 	return svc.ds.DeleteHost(id)
 }
+
+func (svc service) DeleteHosts(ctx context.Context, opt kolide.BulkHostDeleteOptions) (*kolide.BulkHostDeleteResult, error) {
+	ids, err := svc.bulkDeleteHostIDs(opt)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &kolide.BulkHostDeleteResult{Matched: uint(len(ids))}
+	if len(ids) > kolide.BulkHostDeleteConfirmationThreshold {
+		token := bulkDeleteToken(ids)
+		if opt.ConfirmToken == "" {
+			result.ConfirmationRequired = true
+			result.HostIDs = ids
+			result.Token = token
+			return result, nil
+		}
+		if opt.ConfirmToken != token {
+			return nil, newInvalidArgumentError("confirm_token", "the selected hosts changed since the delete was previewed")
+		}
+	}
+
+	tx, err := svc.ds.Begin()
+	if err != nil {
+		return nil, errors.Wrap(err, "begin bulk host delete")
+	}
+	result.Deleted, err = svc.ds.DeleteHosts(ids, kolide.HasTransaction(tx))
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "commit bulk host delete")
+	}
+
+	return result, nil
+}
+
+// bulkDeleteToken returns the token confirming the delete of exactly the
+// hosts with the provided (sorted) IDs.
+func bulkDeleteToken(ids []uint) string {
+	hash := sha256.New()
+	for _, id := range ids {
+		fmt.Fprintf(hash, "%d,", id)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// bulkDeleteHostIDs returns the sorted and deduplicated IDs of the existing
+// hosts selected by the bulk delete options.
+func (svc service) bulkDeleteHostIDs(opt kolide.BulkHostDeleteOptions) ([]uint, error) {
+	selectors := 0
+	if len(opt.HostIDs) > 0 {
+		selectors++
+	}
+	if opt.LabelID != nil {
+		selectors++
+	}
+	if opt.Filter != nil {
+		selectors++
+	}
+	if selectors != 1 {
+		return nil, newInvalidArgumentError("ids", "exactly one of ids, label_id or filter must be provided")
+	}
+
+	var ids []uint
+	switch {
+	case len(opt.HostIDs) > 0:
+		existing, err := svc.ds.ExistingHostIDs(opt.HostIDs)
+		if err != nil {
+			return nil, err
+		}
+		ids = existing
+
+	case opt.LabelID != nil:
+		// Load the label first so that a missing label is reported as
+		// such rather than as an empty selection.
+		if _, err := svc.ds.Label(*opt.LabelID); err != nil {
+			return nil, err
+		}
+		labelHostIDs, err := svc.HostIDsForLabel(*opt.LabelID)
+		if err != nil {
+			return nil, err
+		}
+		ids = labelHostIDs
+
+	case opt.Filter != nil:
+		hosts, err := svc.ds.ListHosts(kolide.HostListOptions{Filter: opt.Filter})
+		if err != nil {
+			return nil, err
+		}
+		for _, h := range hosts {
+			ids = append(ids, h.ID)
+		}
+	}
+
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	sort.Slice(unique, func(i, j int) bool { return unique[i] < unique[j] })
+	return unique, nil
+}
+
+// hostExportPageSize is the number of hosts loaded at a time by ExportHosts.
+const hostExportPageSize = 500
+
+func (svc service) ExportHosts(ctx context.Context, opt kolide.HostListOptions, fn func(*kolide.HostExport) error) error {
+	opt.ListOptions = kolide.ListOptions{
+		PerPage:  hostExportPageSize,
+		OrderKey: "id",
+	}
+	now := svc.clock.Now()
+	for {
+		hosts, err := svc.ds.ListHosts(opt)
+		if err != nil {
+			return err
+		}
+
+		hostIDs := make([]uint, 0, len(hosts))
+		for _, host := range hosts {
+			hostIDs = append(hostIDs, host.ID)
+		}
+		hostLabels, err := svc.ds.ListLabelsForHosts(hostIDs)
+		if err != nil {
+			return err
+		}
+
+		for _, host := range hosts {
+			labels := hostLabels[host.ID]
+			export := &kolide.HostExport{
+				Host:   host,
+				Status: host.Status(now),
+				Labels: make([]string, 0, len(labels)),
+			}
+			for _, label := range labels {
+				export.Labels = append(export.Labels, label.Name)
+			}
+			if err := fn(export); err != nil {
+				return err
+			}
+		}
+
+		if uint(len(hosts)) < opt.PerPage {
+			return nil
+		}
+		opt.Page++
+	}
+}
+
+func (svc service) ListHostLabels(ctx context.Context, hostID uint) ([]*kolide.HostLabelExecution, error) {
+	host, err := svc.ds.Host(hostID)
+	if err != nil {
+		return nil, err
+	}
+	return svc.ds.ListLabelExecutionsForHost(host)
+}
+
+func (svc service) ListHostHistory(ctx context.Context, hostID uint, opt kolide.ListOptions) ([]*kolide.HostHistory, error) {
+	// Load the host first so that a missing host is reported as such
+	// rather than as an empty history.
+	if _, err := svc.ds.Host(hostID); err != nil {
+		return nil, err
+	}
+	return svc.ds.ListHostHistory(hostID, opt)
+}
+
+func (svc service) MergeHosts(ctx context.Context, survivorID, duplicateID uint) (*kolide.Host, error) {
+	if survivorID == duplicateID {
+		return nil, newInvalidArgumentError("duplicate_id", "cannot merge a host into itself")
+	}
+
+	// Load both hosts first so that a missing host is reported as such.
+	if _, err := svc.ds.Host(duplicateID); err != nil {
+		return nil, err
+	}
+	if _, err := svc.ds.Host(survivorID); err != nil {
+		return nil, err
+	}
+
+	if err := svc.ds.MergeHosts(survivorID, duplicateID); err != nil {
+		return nil, err
+	}
+
+	return svc.ds.Host(survivorID)
+}
+
+func (svc service) ListExpiredHosts(ctx context.Context, window int) ([]*kolide.Host, error) {
+	if window == 0 {
+		config, err := svc.ds.AppConfig()
+		if err != nil {
+			return nil, err
+		}
+		window = config.HostExpiryWindow
+	}
+	if window <= 0 {
+		return nil, newInvalidArgumentError("window", "host expiry window must be a positive number of days")
+	}
+
+	return svc.ds.ListExpiredHosts(svc.clock.Now(), hostExpiryWindowDuration(window))
+}
+
+func (svc service) ExpireHosts(ctx context.Context) ([]uint, error) {
+	config, err := svc.ds.AppConfig()
+	if err != nil {
+		return nil, err
+	}
+	// A non-positive window would expire every host, so it is treated as
+	// disabled.
+	if !config.HostExpiryEnabled || config.HostExpiryWindow <= 0 {
+		return nil, nil
+	}
+
+	return svc.ds.ExpireHosts(svc.clock.Now(), hostExpiryWindowDuration(config.HostExpiryWindow))
+}
+
+// hostExpiryWindowDuration converts a host expiry window in days to a
+// duration.
+func hostExpiryWindowDuration(days int) time.Duration {
+	return time.Duration(days) * 24 * time.Hour
+}
@@ -3,11 +3,16 @@ package service
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/WatchBeam/clock"
 	"github.com/kolide/fleet/server/config"
 	"github.com/kolide/fleet/server/datastore/inmem"
 	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/mock"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestListHosts(t *testing.T) {
@@ -19,7 +24,7 @@ func TestListHosts(t *testing.T) {
 
 	ctx := context.Background()
 
-	hosts, err := svc.ListHosts(ctx, kolide.ListOptions{})
+	hosts, err := svc.ListHosts(ctx, kolide.HostListOptions{})
 	assert.Nil(t, err)
 	assert.Len(t, hosts, 0)
 
@@ -28,11 +33,53 @@ func TestListHosts(t *testing.T) {
 	})
 	assert.Nil(t, err)
 
-	hosts, err = svc.ListHosts(ctx, kolide.ListOptions{})
+	hosts, err = svc.ListHosts(ctx, kolide.HostListOptions{})
 	assert.Nil(t, err)
 	assert.Len(t, hosts, 1)
 }
 
+func TestListHostsFilter(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	assert.Nil(t, err)
+
+	svc, err := newTestService(ds, nil)
+	assert.Nil(t, err)
+
+	ctx := context.Background()
+
+	_, err = ds.NewHost(&kolide.Host{NodeKey: "1", UUID: "1", HostName: "web1", Platform: "darwin", OsqueryVersion: "4.2.0"})
+	assert.Nil(t, err)
+	_, err = ds.NewHost(&kolide.Host{NodeKey: "2", UUID: "2", HostName: "web2", Platform: "ubuntu", OsqueryVersion: "3.3.2"})
+	assert.Nil(t, err)
+	_, err = ds.NewHost(&kolide.Host{NodeKey: "3", UUID: "3", HostName: "db1", Platform: "ubuntu", OsqueryVersion: "4.10.0"})
+	assert.Nil(t, err)
+
+	var testCases = []struct {
+		filter   string
+		expected []string
+	}{
+		{"", []string{"web1", "web2", "db1"}},
+		{"platform:ubuntu", []string{"web2", "db1"}},
+		{"hostname:WEB* -platform:darwin", []string{"web2"}},
+		{"osquery_version>=4.2", []string{"web1", "db1"}},
+		{"osquery_version<4", []string{"web2"}},
+		{"label:nonexistent", []string{}},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.filter, func(t *testing.T) {
+			filter, err := kolide.ParseHostFilter(tt.filter)
+			require.Nil(t, err)
+			hosts, err := svc.ListHosts(ctx, kolide.HostListOptions{Filter: filter})
+			require.Nil(t, err)
+			names := []string{}
+			for _, h := range hosts {
+				names = append(names, h.HostName)
+			}
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+}
+
 func TestGetHost(t *testing.T) {
 	ds, err := inmem.New(config.TestConfig())
 	assert.Nil(t, err)
@@ -72,8 +119,274 @@ func TestDeleteHost(t *testing.T) {
 	err = svc.DeleteHost(ctx, host.ID)
 	assert.Nil(t, err)
 
-	hosts, err := ds.ListHosts(kolide.ListOptions{})
+	hosts, err := ds.ListHosts(kolide.HostListOptions{})
 	assert.Nil(t, err)
 	assert.Len(t, hosts, 0)
 
 }
+
+func TestListHostHistory(t *testing.T) {
+	ds := new(mock.Store)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+
+	ds.HostFunc = func(id uint) (*kolide.Host, error) {
+		if id != 1 {
+			return nil, notFoundError{}
+		}
+		return &kolide.Host{ID: id}, nil
+	}
+	ds.ListHostHistoryFunc = func(hostID uint, opt kolide.ListOptions) ([]*kolide.HostHistory, error) {
+		return []*kolide.HostHistory{
+			{HostID: hostID, Field: kolide.HostHistoryOSVersion, OldValue: "10.14.6", NewValue: "10.15.4"},
+		}, nil
+	}
+
+	history, err := svc.ListHostHistory(context.Background(), 1, kolide.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, kolide.HostHistoryOSVersion, history[0].Field)
+
+	ds.ListHostHistoryFuncInvoked = false
+	_, err = svc.ListHostHistory(context.Background(), 2, kolide.ListOptions{})
+	require.NotNil(t, err)
+	assert.False(t, ds.ListHostHistoryFuncInvoked)
+}
+
+func TestListHostLabels(t *testing.T) {
+	ds := new(mock.Store)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+
+	ds.HostFunc = func(id uint) (*kolide.Host, error) {
+		if id != 1 {
+			return nil, notFoundError{}
+		}
+		return &kolide.Host{ID: id, Platform: "darwin"}, nil
+	}
+	ds.ListLabelExecutionsForHostFunc = func(host *kolide.Host) ([]*kolide.HostLabelExecution, error) {
+		assert.Equal(t, "darwin", host.Platform)
+		return []*kolide.HostLabelExecution{
+			{LabelID: 1, LabelName: "All Hosts", Matches: true},
+		}, nil
+	}
+
+	labels, err := svc.ListHostLabels(context.Background(), 1)
+	require.Nil(t, err)
+	require.Len(t, labels, 1)
+	assert.True(t, labels[0].Matches)
+
+	ds.ListLabelExecutionsForHostFuncInvoked = false
+	_, err = svc.ListHostLabels(context.Background(), 2)
+	require.NotNil(t, err)
+	assert.False(t, ds.ListLabelExecutionsForHostFuncInvoked)
+}
+
+func TestMergeHosts(t *testing.T) {
+	ds := new(mock.Store)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+
+	ds.HostFunc = func(id uint) (*kolide.Host, error) {
+		if id > 2 {
+			return nil, notFoundError{}
+		}
+		return &kolide.Host{ID: id}, nil
+	}
+	var merged [2]uint
+	ds.MergeHostsFunc = func(survivorID, duplicateID uint) error {
+		merged = [2]uint{survivorID, duplicateID}
+		return nil
+	}
+
+	host, err := svc.MergeHosts(context.Background(), 1, 2)
+	require.Nil(t, err)
+	assert.Equal(t, uint(1), host.ID)
+	assert.Equal(t, [2]uint{1, 2}, merged)
+
+	ds.MergeHostsFuncInvoked = false
+	_, err = svc.MergeHosts(context.Background(), 1, 1)
+	require.NotNil(t, err)
+	_, err = svc.MergeHosts(context.Background(), 1, 3)
+	require.NotNil(t, err)
+	assert.False(t, ds.MergeHostsFuncInvoked)
+}
+
+func TestExpireHosts(t *testing.T) {
+	ds := new(mock.Store)
+	mockClock := clock.NewMockClock()
+	svc, err := newTestServiceWithClock(ds, nil, mockClock)
+	require.Nil(t, err)
+
+	appConfig := &kolide.AppConfig{HostExpiryEnabled: false, HostExpiryWindow: 30}
+	ds.AppConfigFunc = func() (*kolide.AppConfig, error) {
+		return appConfig, nil
+	}
+	var gotNow time.Time
+	var gotWindow time.Duration
+	ds.ExpireHostsFunc = func(now time.Time, window time.Duration) ([]uint, error) {
+		gotNow, gotWindow = now, window
+		return []uint{3, 4}, nil
+	}
+	ds.ListExpiredHostsFunc = func(now time.Time, window time.Duration) ([]*kolide.Host, error) {
+		gotNow, gotWindow = now, window
+		return []*kolide.Host{{ID: 3}, {ID: 4}}, nil
+	}
+
+	// Disabled expiry does not remove hosts
+	ids, err := svc.ExpireHosts(context.Background())
+	require.Nil(t, err)
+	assert.Empty(t, ids)
+	assert.False(t, ds.ExpireHostsFuncInvoked)
+
+	appConfig.HostExpiryEnabled = true
+	ids, err = svc.ExpireHosts(context.Background())
+	require.Nil(t, err)
+	assert.Equal(t, []uint{3, 4}, ids)
+	assert.Equal(t, mockClock.Now(), gotNow)
+	assert.Equal(t, 30*24*time.Hour, gotWindow)
+
+	// Dry run uses the configured window unless one is provided
+	hosts, err := svc.ListExpiredHosts(context.Background(), 0)
+	require.Nil(t, err)
+	assert.Len(t, hosts, 2)
+	assert.Equal(t, 30*24*time.Hour, gotWindow)
+
+	_, err = svc.ListExpiredHosts(context.Background(), 7)
+	require.Nil(t, err)
+	assert.Equal(t, 7*24*time.Hour, gotWindow)
+
+	appConfig.HostExpiryWindow = 0
+	_, err = svc.ListExpiredHosts(context.Background(), 0)
+	assert.NotNil(t, err)
+}
+
+func TestDeleteHosts(t *testing.T) {
+	ds := new(mock.Store)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+
+	var deletedIDs []uint
+	ds.DeleteHostsFunc = func(ids []uint, opts ...kolide.OptionalArg) (uint, error) {
+		deletedIDs = ids
+		return uint(len(ids)), nil
+	}
+	ds.LabelFunc = func(lid uint) (*kolide.Label, error) {
+		if lid != 1 {
+			return nil, notFoundError{}
+		}
+		return &kolide.Label{ID: lid}, nil
+	}
+	labelHosts := kolide.BulkHostDeleteConfirmationThreshold + 1
+	ds.ListHostsInLabelFunc = func(lid uint) ([]kolide.Host, error) {
+		hosts := make([]kolide.Host, labelHosts)
+		for i := range hosts {
+			hosts[i].ID = uint(len(hosts) - i)
+		}
+		return hosts, nil
+	}
+	ds.ExistingHostIDsFunc = func(ids []uint) ([]uint, error) {
+		var existing []uint
+		for _, id := range ids {
+			if id != 3 {
+				existing = append(existing, id)
+			}
+		}
+		return existing, nil
+	}
+
+	// Explicit IDs are deduplicated and only existing hosts are matched
+	result, err := svc.DeleteHosts(context.Background(), kolide.BulkHostDeleteOptions{HostIDs: []uint{2, 1, 2, 3}})
+	require.Nil(t, err)
+	assert.Equal(t, &kolide.BulkHostDeleteResult{Matched: 2, Deleted: 2}, result)
+	assert.Equal(t, []uint{1, 2}, deletedIDs)
+
+	// Deletes above the threshold must be confirmed
+	labelID := uint(1)
+	ds.DeleteHostsFuncInvoked = false
+	preview, err := svc.DeleteHosts(context.Background(), kolide.BulkHostDeleteOptions{LabelID: &labelID})
+	require.Nil(t, err)
+	assert.True(t, preview.ConfirmationRequired)
+	assert.Equal(t, uint(labelHosts), preview.Matched)
+	assert.Equal(t, uint(0), preview.Deleted)
+	require.Len(t, preview.HostIDs, labelHosts)
+	assert.Equal(t, uint(1), preview.HostIDs[0])
+	assert.NotEmpty(t, preview.Token)
+	assert.False(t, ds.DeleteHostsFuncInvoked)
+
+	// The confirmation is refused once the selection changed
+	labelHosts++
+	_, err = svc.DeleteHosts(context.Background(), kolide.BulkHostDeleteOptions{LabelID: &labelID, ConfirmToken: preview.Token})
+	require.NotNil(t, err)
+	assert.False(t, ds.DeleteHostsFuncInvoked)
+
+	// Confirming with the previewed hosts deletes exactly those
+	ds.ExistingHostIDsFunc = func(ids []uint) ([]uint, error) { return ids, nil }
+	result, err = svc.DeleteHosts(context.Background(), kolide.BulkHostDeleteOptions{HostIDs: preview.HostIDs, ConfirmToken: preview.Token})
+	require.Nil(t, err)
+	assert.False(t, result.ConfirmationRequired)
+	assert.Equal(t, uint(labelHosts-1), result.Deleted)
+	assert.Equal(t, preview.HostIDs, deletedIDs)
+
+	missingLabelID := uint(2)
+	_, err = svc.DeleteHosts(context.Background(), kolide.BulkHostDeleteOptions{LabelID: &missingLabelID})
+	assert.True(t, kolide.IsNotFound(err))
+
+	// Exactly one selector must be provided
+	_, err = svc.DeleteHosts(context.Background(), kolide.BulkHostDeleteOptions{})
+	assert.NotNil(t, err)
+	_, err = svc.DeleteHosts(context.Background(), kolide.BulkHostDeleteOptions{HostIDs: []uint{1}, LabelID: &labelID})
+	assert.NotNil(t, err)
+}
+
+func TestExportHosts(t *testing.T) {
+	ds := new(mock.Store)
+	mockClock := clock.NewMockClock()
+	svc, err := newTestServiceWithClock(ds, nil, mockClock)
+	require.Nil(t, err)
+
+	total := hostExportPageSize + 2
+	var pages []kolide.ListOptions
+	ds.ListHostsFunc = func(opt kolide.HostListOptions) ([]*kolide.Host, error) {
+		pages = append(pages, opt.ListOptions)
+		var hosts []*kolide.Host
+		start := int(opt.Page * opt.PerPage)
+		for i := start; i < total && i < start+int(opt.PerPage); i++ {
+			hosts = append(hosts, &kolide.Host{ID: uint(i + 1), SeenTime: mockClock.Now()})
+		}
+		return hosts, nil
+	}
+	var labelQueries int
+	ds.ListLabelsForHostsFunc = func(hids []uint) (map[uint][]kolide.Label, error) {
+		labelQueries++
+		labels := map[uint][]kolide.Label{}
+		for _, hid := range hids {
+			labels[hid] = []kolide.Label{{Name: "All Hosts"}}
+		}
+		return labels, nil
+	}
+
+	var exported []*kolide.HostExport
+	err = svc.ExportHosts(context.Background(), kolide.HostListOptions{}, func(h *kolide.HostExport) error {
+		exported = append(exported, h)
+		return nil
+	})
+	require.Nil(t, err)
+	require.Len(t, exported, total)
+	assert.Equal(t, uint(total), exported[total-1].Host.ID)
+	assert.Equal(t, "online", exported[0].Status)
+	assert.Equal(t, []string{"All Hosts"}, exported[0].Labels)
+	require.Len(t, pages, 2)
+	// Labels are loaded once per page
+	assert.Equal(t, 2, labelQueries)
+	assert.Equal(t, uint(1), pages[1].Page)
+	assert.Equal(t, "id", pages[1].OrderKey)
+
+	// Errors from the callback stop the export
+	pages = nil
+	err = svc.ExportHosts(context.Background(), kolide.HostListOptions{}, func(h *kolide.HostExport) error {
+		return errors.New("write failed")
+	})
+	assert.NotNil(t, err)
+	assert.Len(t, pages, 1)
+}
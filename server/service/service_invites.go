@@ -34,10 +34,20 @@ func (svc service) InviteNewUser(ctx context.Context, payload kolide.InvitePaylo
 
 	invite := &kolide.Invite{
 		Email:     *payload.Email,
-		Admin:     *payload.Admin,
 		InvitedBy: inviter.ID,
 		Token:     token,
 	}
+	// The role of the invited user defaults to the one of the admin flag,
+	// as for users created before roles were introduced
+	switch {
+	case payload.Admin != nil && *payload.Admin:
+		invite.Role = kolide.RoleAdmin
+	case payload.Role != nil:
+		invite.Role = *payload.Role
+	default:
+		invite.Role = kolide.RoleMaintainer
+	}
+	invite.Admin = invite.Role == kolide.RoleAdmin
 	if payload.Position != nil {
 		invite.Position = *payload.Position
 	}
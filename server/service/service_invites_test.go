@@ -36,6 +36,51 @@ func TestInviteNewUserMock(t *testing.T) {
 	require.NotNil(t, err, "should err if the user we're inviting already exists")
 }
 
+func TestInviteNewUserRole(t *testing.T) {
+	svc, mockStore, _ := setupInviteTest(t)
+	ctx := context.Background()
+	var saved *kolide.Invite
+	mockStore.NewInviteFunc = func(i *kolide.Invite) (*kolide.Invite, error) {
+		saved = i
+		return i, nil
+	}
+
+	var inviteTests = []struct {
+		admin     *bool
+		role      *kolide.Role
+		wantRole  kolide.Role
+		wantAdmin bool
+		wantErr   bool
+	}{
+		{admin: boolPtr(false), wantRole: kolide.RoleMaintainer},
+		{admin: boolPtr(true), wantRole: kolide.RoleAdmin, wantAdmin: true},
+		{role: rolePtr(kolide.RoleObserver), wantRole: kolide.RoleObserver},
+		{admin: boolPtr(false), role: rolePtr(kolide.RoleAdmin), wantRole: kolide.RoleAdmin, wantAdmin: true},
+		{role: rolePtr("superuser"), wantErr: true},
+		{wantErr: true},
+	}
+	for _, tt := range inviteTests {
+		t.Run("", func(t *testing.T) {
+			saved = nil
+			_, err := svc.InviteNewUser(ctx, kolide.InvitePayload{
+				Email:     stringPtr("user@acme.co"),
+				InvitedBy: &adminUser.ID,
+				Admin:     tt.admin,
+				Role:      tt.role,
+			})
+			if tt.wantErr {
+				require.NotNil(t, err)
+				assert.Nil(t, saved)
+				return
+			}
+			require.Nil(t, err)
+			require.NotNil(t, saved)
+			assert.Equal(t, tt.wantRole, saved.Role)
+			assert.Equal(t, tt.wantAdmin, saved.Admin)
+		})
+	}
+}
+
 func TestVerifyInvite(t *testing.T) {
 	ms := new(mock.Store)
 	svc := service{
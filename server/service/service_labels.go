@@ -6,8 +6,16 @@ import (
 	"github.com/kolide/fleet/server/kolide"
 )
 
-func (svc service) ApplyLabelSpecs(ctx context.Context, specs []*kolide.LabelSpec) error {
-	return svc.ds.ApplyLabelSpecs(specs)
+func (svc service) ApplyLabelSpecs(ctx context.Context, specs []*kolide.LabelSpec) ([]string, error) {
+	validation := svc.validateLabelSpecs(specs)
+	if err := queryValidationError("query", validation); err != nil {
+		return nil, err
+	}
+
+	if err := svc.ds.ApplyLabelSpecs(specs); err != nil {
+		return nil, err
+	}
+	return validation.Warnings, nil
 }
 
 func (svc service) GetLabelSpecs(ctx context.Context) ([]*kolide.LabelSpec, error) {
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
 	hostctx "github.com/kolide/fleet/server/contexts/host"
 	"github.com/kolide/fleet/server/kolide"
 	"github.com/kolide/fleet/server/pubsub"
@@ -88,7 +89,7 @@ func (svc service) EnrollAgent(ctx context.Context, enrollSecret, hostIdentifier
 		}
 	}
 
-	host, err := svc.ds.EnrollHost(hostIdentifier, nodeKey, secretName)
+	host, err := svc.enrollHost(hostIdentifier, nodeKey, secretName, hostDetails)
 	if err != nil {
 		return "", osqueryError{message: "save enroll failed: " + err.Error(), nodeInvalid: true}
 	}
@@ -116,6 +117,42 @@ func (svc service) EnrollAgent(ctx context.Context, enrollSecret, hostIdentifier
 	return host.NodeKey, nil
 }
 
+// enrollHost enrolls the host. If a host identity strategy is configured and
+// an existing host matches the identity reported in the enrollment details,
+// the agent is re-attached to that host rather than creating a new one.
+func (svc service) enrollHost(hostIdentifier, nodeKey, secretName string, hostDetails map[string](map[string]string)) (*kolide.Host, error) {
+	strategy := kolide.HostIdentityStrategy(svc.config.Osquery.HostIdentityStrategy)
+	if strategy == "" || strategy == kolide.HostIdentityOsqueryHostID {
+		return svc.ds.EnrollHost(hostIdentifier, nodeKey, secretName)
+	}
+
+	systemInfo := hostDetails["system_info"]
+	identity := kolide.HostIdentity{
+		UUID:           systemInfo["uuid"],
+		HardwareSerial: systemInfo["hardware_serial"],
+		HostName:       systemInfo["hostname"],
+	}
+	existing, err := svc.ds.FindHostByIdentity(strategy, identity)
+	if kolide.IsNotFound(err) {
+		return svc.ds.EnrollHost(hostIdentifier, nodeKey, secretName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if existing.OsqueryHostID != hostIdentifier {
+		level.Info(svc.logger).Log(
+			"msg", "re-attaching enrolling host to existing host",
+			"strategy", strategy,
+			"host_id", existing.ID,
+			"old_osquery_host_id", existing.OsqueryHostID,
+			"new_osquery_host_id", hostIdentifier,
+		)
+	}
+
+	return svc.ds.ReenrollHost(existing.ID, hostIdentifier, nodeKey, secretName)
+}
+
 func (svc service) GetClientConfig(ctx context.Context) (map[string]interface{}, error) {
 	host, ok := hostctx.FromContext(ctx)
 	if !ok {
@@ -221,6 +258,14 @@ func (svc service) SubmitStatusLogs(ctx context.Context, logs []json.RawMessage)
 	if err := svc.osqueryLogWriter.Status.Write(ctx, logs); err != nil {
 		return osqueryError{message: "error writing status logs: " + err.Error()}
 	}
+
+	// The logs were already written, so failing to retain them is not
+	// reported to osquery (which would send them again).
+	if host, ok := hostctx.FromContext(ctx); ok {
+		if err := svc.retainStatusLogs(host, logs); err != nil {
+			svc.logger.Log("msg", "error retaining status logs", "host_id", host.ID, "err", err)
+		}
+	}
 	return nil
 }
 
@@ -251,9 +296,16 @@ const hostDistributedQueryPrefix = "kolide_distributed_query_"
 // detailQueries defines the detail queries that should be run on the host, as
 // well as how the results of those queries should be ingested into the
 // kolide.Host data model. This map should not be modified at runtime.
+//
+// Platforms optionally restricts the query to hosts on the given platforms
+// (see detailQueryRunsOnPlatform).
+// DirectIngestFunc may be set instead of IngestFunc for detail queries whose
+// results are stored outside of the kolide.Host data model.
 var detailQueries = map[string]struct {
-	Query      string
-	IngestFunc func(logger log.Logger, host *kolide.Host, rows []map[string]string) error
+	Query            string
+	Platforms        []string
+	IngestFunc       func(logger log.Logger, host *kolide.Host, rows []map[string]string) error
+	DirectIngestFunc func(logger log.Logger, host *kolide.Host, ds kolide.Datastore, rows []map[string]string) error
 }{
 	"network_interface": {
 		Query: `select ia.interface, address, mask, broadcast, point_to_point,
@@ -481,6 +533,70 @@ var detailQueries = map[string]struct {
 			return nil
 		},
 	},
+	"software_macos": {
+		Query: `select name, bundle_short_version as version, 'apps' as source from apps
+                        union
+                        select name, version, 'homebrew_packages' as source from homebrew_packages`,
+		Platforms:        []string{"darwin"},
+		DirectIngestFunc: ingestSoftware,
+	},
+	"software_linux": {
+		Query: `select name, version, 'deb_packages' as source from deb_packages
+                        union
+                        select name, version, 'rpm_packages' as source from rpm_packages`,
+		Platforms:        []string{"linux"},
+		DirectIngestFunc: ingestSoftware,
+	},
+	"software_windows": {
+		Query:            `select name, version, 'programs' as source from programs`,
+		Platforms:        []string{"windows"},
+		DirectIngestFunc: ingestSoftware,
+	},
+}
+
+// ingestSoftware replaces the software inventory of the host with the
+// results of a software detail query.
+func ingestSoftware(logger log.Logger, host *kolide.Host, ds kolide.Datastore, rows []map[string]string) error {
+	type softwareKey struct{ name, version, source string }
+	seen := map[softwareKey]bool{}
+	software := []kolide.Software{}
+	for _, row := range rows {
+		s := kolide.Software{
+			Name:    row["name"],
+			Version: row["version"],
+			Source:  row["source"],
+		}
+		if s.Name == "" || s.Source == "" {
+			logger.Log("component", "service", "method", "ingestSoftware", "err",
+				"software row missing name or source")
+			continue
+		}
+		// Applications installed in multiple locations are reported
+		// once per location.
+		key := softwareKey{s.Name, s.Version, s.Source}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		software = append(software, s)
+	}
+
+	return ds.SaveHostSoftware(host.ID, software)
+}
+
+// detailQueryRunsOnPlatform returns whether a detail query restricted to the
+// provided platforms should run on a host with the given platform. The linux
+// platform matches hosts on any Linux distribution.
+func detailQueryRunsOnPlatform(platforms []string, platform string) bool {
+	if len(platforms) == 0 {
+		return true
+	}
+	for _, p := range platforms {
+		if p == platform || (p == "linux" && kolide.IsLinuxPlatform(platform)) {
+			return true
+		}
+	}
+	return false
 }
 
 // hostDetailQueries returns the map of queries that should be executed by
@@ -493,6 +609,9 @@ func (svc service) hostDetailQueries(host kolide.Host) (map[string]string, error
 	}
 
 	for name, query := range detailQueries {
+		if !detailQueryRunsOnPlatform(query.Platforms, host.Platform) {
+			continue
+		}
 		queries[hostDetailQueryPrefix+name] = query.Query
 	}
 
@@ -545,8 +664,20 @@ func (svc service) GetDistributedQueries(ctx context.Context) (map[string]string
 		return nil, 0, osqueryError{message: "retrieving query campaigns: " + err.Error()}
 	}
 
+	campaignIDs := make([]uint, 0, len(distributedQueries))
 	for id, query := range distributedQueries {
 		queries[hostDistributedQueryPrefix+strconv.Itoa(int(id))] = query
+		campaignIDs = append(campaignIDs, id)
+	}
+
+	// Record when the queries were sent, to measure how long the host takes
+	// to return the results. Deferred campaigns are only sent once to each
+	// host, as the host may take longer than a check in interval to return
+	// the results.
+	if len(campaignIDs) > 0 {
+		if err := svc.ds.MarkDistributedQueriesRequested(host.ID, campaignIDs, svc.clock.Now()); err != nil {
+			return nil, 0, osqueryError{message: "marking query campaigns requested: " + err.Error()}
+		}
 	}
 
 	accelerate := uint(0)
@@ -562,14 +693,25 @@ func (svc service) GetDistributedQueries(ctx context.Context) (map[string]string
 
 // ingestDetailQuery takes the results of a detail query and modifies the
 // provided kolide.Host appropriately.
-func (svc service) ingestDetailQuery(host *kolide.Host, name string, rows []map[string]string) error {
+func (svc service) ingestDetailQuery(host *kolide.Host, name string, rows []map[string]string, failed bool) error {
 	trimmedQuery := strings.TrimPrefix(name, hostDetailQueryPrefix)
 	query, ok := detailQueries[trimmedQuery]
 	if !ok {
 		return osqueryError{message: "unknown detail query " + trimmedQuery}
 	}
 
-	err := query.IngestFunc(svc.logger, host, rows)
+	var err error
+	if query.DirectIngestFunc != nil {
+		if failed {
+			// Keep the previously stored results rather than
+			// replacing them with the empty results of a failed
+			// query.
+			return nil
+		}
+		err = query.DirectIngestFunc(svc.logger, host, svc.ds, rows)
+	} else {
+		err = query.IngestFunc(svc.logger, host, rows)
+	}
 	if err != nil {
 		return osqueryError{
 			message: fmt.Sprintf("ingesting query %s: %s", name, err.Error()),
@@ -580,21 +722,43 @@ func (svc service) ingestDetailQuery(host *kolide.Host, name string, rows []map[
 }
 
 // ingestLabelQuery records the results of label queries run by a host
-func (svc service) ingestLabelQuery(host kolide.Host, query string, rows []map[string]string, results map[uint]bool) error {
+func (svc service) ingestLabelQuery(host kolide.Host, query string, rows []map[string]string, status kolide.OsqueryStatus, message string, results map[uint]bool, failures map[uint]string) error {
 	trimmedQuery := strings.TrimPrefix(query, hostLabelQueryPrefix)
 	trimmedQueryNum, err := strconv.Atoi(emptyToZero(trimmedQuery))
 	if err != nil {
 		return errors.Wrap(err, "converting query from string to int")
 	}
+	// A failed label query does not match, but the failure is recorded
+	// separately so that it can be told apart from a negative result.
+	if status != kolide.StatusOK {
+		if message == "" {
+			message = fmt.Sprintf("query failed with status %d", status)
+		}
+		failures[uint(trimmedQueryNum)] = message
+		return nil
+	}
 	// A label query matches if there is at least one result for that
 	// query. We must also store negative results.
 	results[uint(trimmedQueryNum)] = len(rows) > 0
 	return nil
 }
 
+// maxExecutionErrorLength is the size of the error column of distributed
+// query executions.
+const maxExecutionErrorLength = 1024
+
+// truncateString returns s truncated to at most n runes.
+func truncateString(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
+
 // ingestDistributedQuery takes the results of a distributed query and modifies the
 // provided kolide.Host appropriately.
-func (svc service) ingestDistributedQuery(host kolide.Host, name string, rows []map[string]string, failed bool) error {
+func (svc service) ingestDistributedQuery(ctx context.Context, host kolide.Host, name string, rows []map[string]string, failed bool, message string) error {
 	trimmedQuery := strings.TrimPrefix(name, hostDistributedQueryPrefix)
 
 	campaignID, err := strconv.Atoi(emptyToZero(trimmedQuery))
@@ -609,12 +773,54 @@ func (svc service) ingestDistributedQuery(host kolide.Host, name string, rows []
 		Rows:                       rows,
 	}
 	if failed {
-		// osquery errors are not currently helpful, but we should fix
-		// them to be better in the future
+		// Older osquery versions do not report the reason the query
+		// failed.
 		errString := "failed"
+		if message != "" {
+			errString = message
+		}
 		res.Error = &errString
 	}
 
+	cached, err := svc.campaigns.get(uint(campaignID), svc.clock.Now(), svc.ds.DistributedQueryCampaign)
+	if err != nil {
+		return osqueryError{message: "loading campaign: " + err.Error()}
+	}
+	campaign := &cached.campaign
+
+	if campaign.PersistResults {
+		result := &kolide.CampaignResult{
+			DistributedQueryCampaignID: campaign.ID,
+			HostID:                     host.ID,
+			HostName:                   host.HostName,
+			Rows:                       rows,
+			Error:                      res.Error,
+		}
+		saved, err := svc.ds.SaveCampaignResult(result, uint(svc.config.Osquery.CampaignResultRows))
+		if err != nil {
+			return osqueryError{message: "persisting results: " + err.Error()}
+		}
+		// Results over the rows limit are still streamed, but the stored
+		// results are flagged as incomplete.
+		if (!saved || result.Truncated) && !svc.campaigns.markTruncated(cached) {
+			if err := svc.ds.MarkCampaignResultsTruncated(campaign.ID); err != nil {
+				return osqueryError{message: "marking truncated results: " + err.Error()}
+			}
+		}
+	}
+
+	// The results were already received, so failing to forward them to
+	// the live query log is not reported to osquery.
+	if svc.osqueryLogWriter.LiveQuery != nil {
+		query, username, err := svc.campaigns.liveQueryLogDetails(cached, svc.loadLiveQueryLogDetails)
+		if err == nil {
+			err = svc.logLiveQueryResult(ctx, campaign.ID, query, username, host, rows, res.Error)
+		}
+		if err != nil {
+			svc.logger.Log("msg", "error logging live query results", "campaign_id", campaign.ID, "host_id", host.ID, "err", err)
+		}
+	}
+
 	err = svc.resultStore.WriteResult(res)
 	if err != nil {
 		nErr, ok := err.(pubsub.Error)
@@ -624,15 +830,17 @@ func (svc service) ingestDistributedQuery(host kolide.Host, name string, rows []
 
 		// If there are no subscribers, the campaign is "orphaned"
 		// and should be closed so that we don't continue trying to
-		// execute that query when we can't write to any subscriber
-		campaign, err := svc.ds.DistributedQueryCampaign(uint(campaignID))
-		if err != nil {
-			return osqueryError{message: "loading orphaned campaign: " + err.Error()}
-		}
-
-		campaign.Status = kolide.QueryComplete
-		if err := svc.ds.SaveDistributedQueryCampaign(campaign); err != nil {
-			return osqueryError{message: "closing orphaned campaign: " + err.Error()}
+		// execute that query when we can't write to any subscriber.
+		// Campaigns with persisted results do not need a subscriber.
+		if !campaign.PersistResults {
+			orphaned, err := svc.ds.DistributedQueryCampaign(campaign.ID)
+			if err != nil {
+				return osqueryError{message: "loading orphaned campaign: " + err.Error()}
+			}
+			orphaned.Status = kolide.QueryComplete
+			if err := svc.ds.SaveDistributedQueryCampaign(orphaned); err != nil {
+				return osqueryError{message: "closing orphaned campaign: " + err.Error()}
+			}
 		}
 	}
 
@@ -645,6 +853,10 @@ func (svc service) ingestDistributedQuery(host kolide.Host, name string, rows []
 		HostID:                     host.ID,
 		DistributedQueryCampaignID: uint(campaignID),
 		Status:                     status,
+		ReceivedAt:                 svc.clock.Now(),
+	}
+	if res.Error != nil {
+		exec.Error = truncateString(*res.Error, maxExecutionErrorLength)
 	}
 
 	_, err = svc.ds.NewDistributedQueryExecution(exec)
@@ -655,7 +867,70 @@ func (svc service) ingestDistributedQuery(host kolide.Host, name string, rows []
 	return nil
 }
 
-func (svc service) SubmitDistributedQueryResults(ctx context.Context, results kolide.OsqueryDistributedQueryResults, statuses map[string]kolide.OsqueryStatus) error {
+// liveQueryResultLog is the format of the live query results written to the
+// live query log plugin. It follows the osquery result log format, with the
+// campaign details added.
+type liveQueryResultLog struct {
+	Name           string            `json:"name"`
+	HostIdentifier string            `json:"hostIdentifier"`
+	CalendarTime   string            `json:"calendarTime"`
+	UnixTime       int64             `json:"unixTime"`
+	Columns        map[string]string `json:"columns,omitempty"`
+	Error          *string           `json:"error,omitempty"`
+	CampaignID     uint              `json:"campaign_id"`
+	Query          string            `json:"query"`
+	User           string            `json:"user"`
+}
+
+// loadLiveQueryLogDetails loads the query text and the username of the
+// campaign added to the live query logs.
+func (svc service) loadLiveQueryLogDetails(campaign *kolide.DistributedQueryCampaign) (string, string, error) {
+	query, err := svc.ds.Query(campaign.QueryID)
+	if err != nil {
+		return "", "", errors.Wrap(err, "loading campaign query")
+	}
+	user, err := svc.ds.UserByID(campaign.UserID)
+	if err != nil {
+		return "", "", errors.Wrap(err, "loading campaign user")
+	}
+	return query.Query, user.Username, nil
+}
+
+// logLiveQueryResult writes one log per result row (or a single log for a
+// failed query) to the live query log plugin.
+func (svc service) logLiveQueryResult(ctx context.Context, campaignID uint, query, username string, host kolide.Host, rows []map[string]string, errString *string) error {
+	now := svc.clock.Now().UTC()
+	entry := liveQueryResultLog{
+		Name:           "live_query",
+		HostIdentifier: host.HostName,
+		CalendarTime:   now.Format(time.ANSIC) + " UTC",
+		UnixTime:       now.Unix(),
+		Error:          errString,
+		CampaignID:     campaignID,
+		Query:          query,
+		User:           username,
+	}
+
+	var logs []json.RawMessage
+	if len(rows) == 0 && errString != nil {
+		rows = []map[string]string{nil}
+	}
+	for _, row := range rows {
+		entry.Columns = row
+		log, err := json.Marshal(entry)
+		if err != nil {
+			return errors.Wrap(err, "marshal live query result")
+		}
+		logs = append(logs, log)
+	}
+	if len(logs) == 0 {
+		return nil
+	}
+
+	return svc.osqueryLogWriter.LiveQuery.Write(ctx, logs)
+}
+
+func (svc service) SubmitDistributedQueryResults(ctx context.Context, results kolide.OsqueryDistributedQueryResults, statuses map[string]kolide.OsqueryStatus, messages map[string]string) error {
 	host, ok := hostctx.FromContext(ctx)
 
 	if !ok {
@@ -666,23 +941,26 @@ func (svc service) SubmitDistributedQueryResults(ctx context.Context, results ko
 	detailUpdated := false // Whether detail or additional was updated
 	additionalResults := make(kolide.OsqueryDistributedQueryResults)
 	labelResults := map[uint]bool{}
+	labelFailures := map[uint]string{}
 	for query, rows := range results {
 		switch {
 		case strings.HasPrefix(query, hostDetailQueryPrefix):
-			err = svc.ingestDetailQuery(&host, query, rows)
+			status, ok := statuses[query]
+			failed := (ok && status != kolide.StatusOK)
+			err = svc.ingestDetailQuery(&host, query, rows, failed)
 			detailUpdated = true
 		case strings.HasPrefix(query, hostAdditionalQueryPrefix):
 			name := strings.TrimPrefix(query, hostAdditionalQueryPrefix)
 			additionalResults[name] = rows
 			detailUpdated = true
 		case strings.HasPrefix(query, hostLabelQueryPrefix):
-			err = svc.ingestLabelQuery(host, query, rows, labelResults)
+			err = svc.ingestLabelQuery(host, query, rows, statuses[query], messages[query], labelResults, labelFailures)
 		case strings.HasPrefix(query, hostDistributedQueryPrefix):
 			// osquery docs say any nonzero (string) value for
 			// status indicates a query error
 			status, ok := statuses[query]
 			failed := (ok && status != kolide.StatusOK)
-			err = svc.ingestDistributedQuery(host, query, rows, failed)
+			err = svc.ingestDistributedQuery(ctx, host, query, rows, failed, messages[query])
 		default:
 			err = osqueryError{message: "unknown query prefix: " + query}
 		}
@@ -700,6 +978,13 @@ func (svc service) SubmitDistributedQueryResults(ctx context.Context, results ko
 		}
 	}
 
+	if len(labelFailures) > 0 {
+		err = svc.ds.RecordLabelQueryErrors(&host, labelFailures, svc.clock.Now())
+		if err != nil {
+			return osqueryError{message: "failed to save label errors: " + err.Error()}
+		}
+	}
+
 	if detailUpdated {
 		host.DetailUpdateTime = svc.clock.Now()
 		additionalJSON, err := json.Marshal(additionalResults)
@@ -710,7 +995,7 @@ func (svc service) SubmitDistributedQueryResults(ctx context.Context, results ko
 		host.Additional = &additional
 	}
 
-	if len(labelResults) > 0 || detailUpdated {
+	if len(labelResults) > 0 || len(labelFailures) > 0 || detailUpdated {
 		err = svc.ds.SaveHost(&host)
 		if err != nil {
 			return osqueryError{message: "failed to update host details: " + err.Error()}
@@ -5,12 +5,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/WatchBeam/clock"
+	kitlog "github.com/go-kit/kit/log"
 	"github.com/kolide/fleet/server/config"
 	hostctx "github.com/kolide/fleet/server/contexts/host"
 	"github.com/kolide/fleet/server/contexts/viewer"
@@ -47,6 +49,53 @@ func TestEnrollAgent(t *testing.T) {
 	assert.NotEmpty(t, nodeKey)
 }
 
+func TestEnrollAgentHostIdentity(t *testing.T) {
+	ds := new(mock.Store)
+	ds.VerifyEnrollSecretFunc = func(secret string) (string, error) {
+		return "valid", nil
+	}
+	ds.EnrollHostFunc = func(osqueryHostId, nodeKey, secretName string) (*kolide.Host, error) {
+		return &kolide.Host{ID: 2, OsqueryHostID: osqueryHostId, NodeKey: nodeKey}, nil
+	}
+	ds.FindHostByIdentityFunc = func(strategy kolide.HostIdentityStrategy, identity kolide.HostIdentity) (*kolide.Host, error) {
+		assert.Equal(t, kolide.HostIdentitySerial, strategy)
+		if identity.HardwareSerial == "C02XL0GYJGH5" {
+			return &kolide.Host{ID: 1, OsqueryHostID: "old_host123"}, nil
+		}
+		return nil, notFoundError{}
+	}
+	var reenrolled uint
+	ds.ReenrollHostFunc = func(hostID uint, osqueryHostID, nodeKey, secretName string) (*kolide.Host, error) {
+		reenrolled = hostID
+		return &kolide.Host{ID: hostID, OsqueryHostID: osqueryHostID, NodeKey: nodeKey}, nil
+	}
+	ds.SaveHostFunc = func(host *kolide.Host) error { return nil }
+
+	conf := config.TestConfig()
+	conf.Osquery.HostIdentityStrategy = "serial"
+	mailer := &mockMailService{SendEmailFn: func(e kolide.Email) error { return nil }}
+	svc, err := NewService(ds, nil, kitlog.NewNopLogger(), conf, mailer, clock.C, nil)
+	require.Nil(t, err)
+
+	// Matching serial re-attaches to the existing host
+	details := map[string](map[string]string){
+		"system_info": {"hostname": "zwass.local", "hardware_serial": "C02XL0GYJGH5"},
+	}
+	nodeKey, err := svc.EnrollAgent(context.Background(), "", "host123", details)
+	require.Nil(t, err)
+	assert.NotEmpty(t, nodeKey)
+	assert.Equal(t, uint(1), reenrolled)
+	assert.False(t, ds.EnrollHostFuncInvoked)
+
+	// Unknown serial enrolls a new host
+	reenrolled = 0
+	details["system_info"]["hardware_serial"] = "unknown"
+	_, err = svc.EnrollAgent(context.Background(), "", "host456", details)
+	require.Nil(t, err)
+	assert.Equal(t, uint(0), reenrolled)
+	assert.True(t, ds.EnrollHostFuncInvoked)
+}
+
 func TestEnrollAgentIncorrectEnrollSecret(t *testing.T) {
 	ds := new(mock.Store)
 	ds.VerifyEnrollSecretFunc = func(secret string) (string, error) {
@@ -164,9 +213,17 @@ func TestSubmitStatusLogs(t *testing.T) {
 	testLogger := &testJSONLogger{}
 	serv.osqueryLogWriter = &logging.OsqueryLogger{Status: testLogger}
 
+	var gotHostID, gotKeep uint
+	var gotLogs []*kolide.HostStatusLog
+	ds.SaveHostStatusLogsFunc = func(hostID uint, logs []*kolide.HostStatusLog, keep uint) error {
+		gotHostID, gotLogs, gotKeep = hostID, logs, keep
+		return nil
+	}
+
 	logs := []string{
 		`{"severity":"0","filename":"tls.cpp","line":"216","message":"some message","version":"1.8.2","decorations":{"host_uuid":"uuid_foobar","username":"zwass"}}`,
 		`{"severity":"1","filename":"buffered.cpp","line":"122","message":"warning!","version":"1.8.2","decorations":{"host_uuid":"uuid_foobar","username":"zwass"}}`,
+		`{"severity":2,"filename":"scheduler.cpp","line":"93","message":"Error executing scheduled query pack/it/users: no such table: userz","unixTime":"1590969600","version":"4.3.0"}`,
 	}
 	logJSON := fmt.Sprintf("[%s]", strings.Join(logs, ","))
 
@@ -174,12 +231,49 @@ func TestSubmitStatusLogs(t *testing.T) {
 	err = json.Unmarshal([]byte(logJSON), &status)
 	require.Nil(t, err)
 
-	host := kolide.Host{}
+	host := kolide.Host{ID: 7}
 	ctx := hostctx.NewContext(context.Background(), host)
 	err = serv.SubmitStatusLogs(ctx, status)
 	assert.Nil(t, err)
 
 	assert.Equal(t, status, testLogger.logs)
+
+	// Only warnings and errors are retained
+	assert.Equal(t, uint(7), gotHostID)
+	assert.Equal(t, uint(serv.config.Osquery.StatusLogRetention), gotKeep)
+	require.Len(t, gotLogs, 2)
+	assert.Equal(t, kolide.StatusLogWarning, gotLogs[0].Severity)
+	assert.Nil(t, gotLogs[0].QueryName)
+	assert.Equal(t, kolide.StatusLogError, gotLogs[1].Severity)
+	assert.Equal(t, uint(93), gotLogs[1].Line)
+	assert.Equal(t, time.Unix(1590969600, 0).UTC(), gotLogs[1].CreatedAt)
+	require.NotNil(t, gotLogs[1].QueryName)
+	assert.Equal(t, "pack/it/users", *gotLogs[1].QueryName)
+}
+
+func TestScheduledQueryNameFromStatus(t *testing.T) {
+	var testCases = []struct {
+		message  string
+		expected string
+	}{
+		{"Error executing scheduled query pack/it/users: no such table: userz", "pack/it/users"},
+		{"Scheduled query may have failed: pack_it_processes", "pack_it_processes"},
+		{"Denylisting query: pack_it_processes", "pack_it_processes"},
+		{"Blacklisting query: pack_it_processes", "pack_it_processes"},
+		{"Error executing scheduled query : oops", ""},
+		{"osquery worker initialized", ""},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.message, func(t *testing.T) {
+			name := scheduledQueryNameFromStatus(tt.message)
+			if tt.expected == "" {
+				assert.Nil(t, name)
+				return
+			}
+			require.NotNil(t, name)
+			assert.Equal(t, tt.expected, *name)
+		})
+	}
 }
 
 func TestSubmitResultLogs(t *testing.T) {
@@ -251,7 +345,7 @@ func TestHostDetailQueries(t *testing.T) {
 
 	queries, err = svc.hostDetailQueries(host)
 	assert.Nil(t, err)
-	assert.Len(t, queries, len(detailQueries)+2)
+	assert.Len(t, queries, expectedDetailQueriesForPlatform(host.Platform)+2)
 	for name, _ := range queries {
 		assert.True(t,
 			strings.HasPrefix(name, hostDetailQueryPrefix) || strings.HasPrefix(name, hostAdditionalQueryPrefix),
@@ -261,6 +355,102 @@ func TestHostDetailQueries(t *testing.T) {
 	assert.Equal(t, "select foo", queries[hostAdditionalQueryPrefix+"foobar"])
 }
 
+// expectedDetailQueriesForPlatform returns the number of detail queries
+// that should be sent to a host on the given platform.
+func expectedDetailQueriesForPlatform(platform string) int {
+	count := 0
+	for _, query := range detailQueries {
+		if detailQueryRunsOnPlatform(query.Platforms, platform) {
+			count++
+		}
+	}
+	return count
+}
+
+func TestHostDetailQueriesPlatform(t *testing.T) {
+	mockClock := clock.NewMockClock()
+	ds := new(mock.Store)
+	ds.AppConfigFunc = func() (*kolide.AppConfig, error) {
+		return &kolide.AppConfig{}, nil
+	}
+	svc := service{clock: mockClock, config: config.TestConfig(), ds: ds}
+
+	var testCases = []struct {
+		platform string
+		included string
+		excluded string
+	}{
+		{"darwin", "software_macos", "software_windows"},
+		{"ubuntu", "software_linux", "software_macos"},
+		{"centos", "software_linux", "software_windows"},
+		{"manjaro", "software_linux", "software_macos"},
+		{"rocky", "software_linux", "software_windows"},
+		{"windows", "software_windows", "software_linux"},
+		{"", "system_info", "software_macos"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.platform, func(t *testing.T) {
+			host := kolide.Host{Platform: tt.platform}
+			queries, err := svc.hostDetailQueries(host)
+			require.Nil(t, err)
+			assert.Len(t, queries, expectedDetailQueriesForPlatform(tt.platform))
+			assert.Contains(t, queries, hostDetailQueryPrefix+tt.included)
+			assert.NotContains(t, queries, hostDetailQueryPrefix+tt.excluded)
+		})
+	}
+}
+
+func TestDetailQueriesSoftware(t *testing.T) {
+	ds := new(mock.Store)
+	mockClock := clock.NewMockClock()
+	svc, err := newTestServiceWithClock(ds, nil, mockClock)
+	require.Nil(t, err)
+
+	host := kolide.Host{ID: 3, Platform: "darwin"}
+	ctx := hostctx.NewContext(context.Background(), host)
+
+	var gotHostID uint
+	var gotSoftware []kolide.Software
+	ds.SaveHostSoftwareFunc = func(hostID uint, software []kolide.Software) error {
+		gotHostID = hostID
+		gotSoftware = software
+		return nil
+	}
+	ds.SaveHostFunc = func(host *kolide.Host) error {
+		return nil
+	}
+
+	results := kolide.OsqueryDistributedQueryResults{
+		hostDetailQueryPrefix + "software_macos": {
+			{"name": "Safari.app", "version": "13.1", "source": "apps"},
+			{"name": "Safari.app", "version": "13.1", "source": "apps"},
+			{"name": "openssl", "version": "1.0.2t", "source": "homebrew_packages"},
+			{"name": "", "version": "1.0", "source": "apps"},
+		},
+	}
+	err = svc.SubmitDistributedQueryResults(ctx, results, map[string]kolide.OsqueryStatus{}, nil)
+	require.Nil(t, err)
+
+	assert.Equal(t, host.ID, gotHostID)
+	assert.Equal(t, []kolide.Software{
+		{Name: "Safari.app", Version: "13.1", Source: "apps"},
+		{Name: "openssl", Version: "1.0.2t", Source: "homebrew_packages"},
+	}, gotSoftware)
+
+	// A failed software query should not replace the stored software
+	ds.SaveHostSoftwareFuncInvoked = false
+	results = kolide.OsqueryDistributedQueryResults{
+		hostDetailQueryPrefix + "software_macos": {},
+	}
+	statuses := map[string]kolide.OsqueryStatus{
+		hostDetailQueryPrefix + "software_macos": 1,
+	}
+	err = svc.SubmitDistributedQueryResults(ctx, results, statuses, nil)
+	require.Nil(t, err)
+	assert.False(t, ds.SaveHostSoftwareFuncInvoked)
+}
+
 func TestGetDistributedQueriesMissingHost(t *testing.T) {
 	svc, err := newTestService(&mock.Store{}, nil)
 	require.Nil(t, err)
@@ -296,7 +486,7 @@ func TestLabelQueries(t *testing.T) {
 	// should be turned on so that we can quickly fill labels)
 	queries, acc, err := svc.GetDistributedQueries(ctx)
 	assert.Nil(t, err)
-	assert.Len(t, queries, len(detailQueries))
+	assert.Len(t, queries, expectedDetailQueriesForPlatform(host.Platform))
 	assert.NotZero(t, acc)
 
 	// Simulate the detail queries being added
@@ -341,6 +531,7 @@ func TestLabelQueries(t *testing.T) {
 			hostLabelQueryPrefix + "1": {{"col1": "val1"}},
 		},
 		map[string]kolide.OsqueryStatus{},
+		nil,
 	)
 	assert.Nil(t, err)
 	assert.Equal(t, host, gotHost)
@@ -359,6 +550,7 @@ func TestLabelQueries(t *testing.T) {
 			hostLabelQueryPrefix + "3": {},
 		},
 		map[string]kolide.OsqueryStatus{},
+		nil,
 	)
 	assert.Nil(t, err)
 	assert.Equal(t, host, gotHost)
@@ -367,6 +559,37 @@ func TestLabelQueries(t *testing.T) {
 		assert.Equal(t, true, gotResults[2])
 		assert.Equal(t, false, gotResults[3])
 	}
+
+	var gotErrors map[uint]string
+	ds.RecordLabelQueryErrorsFunc = func(host *kolide.Host, errs map[uint]string, t time.Time) error {
+		gotErrors = errs
+		gotTime = t
+		return nil
+	}
+
+	// Failed executions are recorded as errors rather than results
+	ds.RecordLabelQueryExecutionsFuncInvoked = false
+	err = svc.SubmitDistributedQueryResults(
+		ctx,
+		map[string][]map[string]string{
+			hostLabelQueryPrefix + "4": {},
+			hostLabelQueryPrefix + "5": {},
+		},
+		map[string]kolide.OsqueryStatus{
+			hostLabelQueryPrefix + "4": 1,
+			hostLabelQueryPrefix + "5": 1,
+		},
+		map[string]string{
+			hostLabelQueryPrefix + "4": "no such table: foo",
+		},
+	)
+	assert.Nil(t, err)
+	assert.False(t, ds.RecordLabelQueryExecutionsFuncInvoked)
+	assert.Equal(t, mockClock.Now(), gotTime)
+	assert.Equal(t, map[uint]string{
+		4: "no such table: foo",
+		5: "query failed with status 1",
+	}, gotErrors)
 }
 
 func TestGetClientConfig(t *testing.T) {
@@ -533,7 +756,7 @@ func TestDetailQueriesWithEmptyStrings(t *testing.T) {
 	// queries)
 	queries, acc, err := svc.GetDistributedQueries(ctx)
 	assert.Nil(t, err)
-	assert.Len(t, queries, len(detailQueries))
+	assert.Len(t, queries, expectedDetailQueriesForPlatform(host.Platform))
 	assert.NotZero(t, acc)
 
 	resultJSON := `
@@ -635,7 +858,7 @@ func TestDetailQueriesWithEmptyStrings(t *testing.T) {
 	}
 
 	// Verify that results are ingested properly
-	svc.SubmitDistributedQueryResults(ctx, results, map[string]kolide.OsqueryStatus{})
+	svc.SubmitDistributedQueryResults(ctx, results, map[string]kolide.OsqueryStatus{}, nil)
 
 	// osquery_info
 	assert.Equal(t, "darwin", gotHost.Platform)
@@ -673,7 +896,7 @@ func TestDetailQueriesWithEmptyStrings(t *testing.T) {
 
 	queries, acc, err = svc.GetDistributedQueries(ctx)
 	assert.Nil(t, err)
-	assert.Len(t, queries, len(detailQueries))
+	assert.Len(t, queries, expectedDetailQueriesForPlatform(host.Platform))
 	assert.Zero(t, acc)
 }
 
@@ -700,7 +923,7 @@ func TestDetailQueries(t *testing.T) {
 	// queries)
 	queries, acc, err := svc.GetDistributedQueries(ctx)
 	assert.Nil(t, err)
-	assert.Len(t, queries, len(detailQueries))
+	assert.Len(t, queries, expectedDetailQueriesForPlatform(host.Platform))
 	assert.NotZero(t, acc)
 
 	resultJSON := `
@@ -805,7 +1028,7 @@ func TestDetailQueries(t *testing.T) {
 		return nil
 	}
 	// Verify that results are ingested properly
-	svc.SubmitDistributedQueryResults(ctx, results, map[string]kolide.OsqueryStatus{})
+	svc.SubmitDistributedQueryResults(ctx, results, map[string]kolide.OsqueryStatus{}, nil)
 
 	// osquery_info
 	assert.Equal(t, "darwin", gotHost.Platform)
@@ -843,7 +1066,7 @@ func TestDetailQueries(t *testing.T) {
 
 	queries, acc, err = svc.GetDistributedQueries(ctx)
 	assert.Nil(t, err)
-	assert.Len(t, queries, len(detailQueries))
+	assert.Len(t, queries, expectedDetailQueriesForPlatform(host.Platform))
 	assert.Zero(t, acc)
 }
 
@@ -892,8 +1115,8 @@ func TestNewDistributedQueryCampaign(t *testing.T) {
 		return target, nil
 	}
 
-	ds.CountHostsInTargetsFunc = func(hostIDs, labelIDs []uint, now time.Time) (kolide.TargetMetrics, error) {
-		return kolide.TargetMetrics{}, nil
+	ds.CountHostsInTargetsFunc = func(hostIDs, labelIDs, tagIDs []uint, now time.Time) (kolide.TargetMetrics, error) {
+		return kolide.TargetMetrics{TotalHosts: 3, OnlineHosts: 2, OfflineHosts: 1}, nil
 	}
 	viewerCtx := viewer.NewContext(context.Background(), viewer.Viewer{
 		User: &kolide.User{
@@ -901,9 +1124,11 @@ func TestNewDistributedQueryCampaign(t *testing.T) {
 		},
 	})
 	q := "select year, month, day, hour, minutes, seconds from time"
-	campaign, err := svc.NewDistributedQueryCampaign(viewerCtx, q, []uint{2}, []uint{1})
+	campaign, err := svc.NewDistributedQueryCampaign(viewerCtx, q, []uint{2}, []uint{1}, []uint{3}, kolide.CampaignOptions{})
 	require.Nil(t, err)
 	assert.Equal(t, gotQuery.ID, gotCampaign.QueryID)
+	assert.Equal(t, uint(3), gotCampaign.ExpectedResults)
+	assert.Equal(t, uint(3), campaign.Metrics.TotalHosts)
 	assert.Equal(t, []*kolide.DistributedQueryCampaignTarget{
 		&kolide.DistributedQueryCampaignTarget{
 			Type:                       kolide.TargetHost,
@@ -915,6 +1140,11 @@ func TestNewDistributedQueryCampaign(t *testing.T) {
 			DistributedQueryCampaignID: campaign.ID,
 			TargetID:                   1,
 		},
+		&kolide.DistributedQueryCampaignTarget{
+			Type:                       kolide.TargetTag,
+			DistributedQueryCampaignID: campaign.ID,
+			TargetID:                   3,
+		},
 	}, gotTargets,
 	)
 }
@@ -926,7 +1156,15 @@ func TestDistributedQueryResults(t *testing.T) {
 	svc, err := newTestServiceWithClock(ds, rs, mockClock)
 	require.Nil(t, err)
 
-	campaign := &kolide.DistributedQueryCampaign{ID: 42}
+	// The executions are recorded in an inmem datastore to measure their
+	// duration
+	execStore, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	campaign, err := execStore.NewDistributedQueryCampaign(&kolide.DistributedQueryCampaign{Status: kolide.QueryRunning})
+	require.Nil(t, err)
+	ds.DistributedQueryCampaignFunc = func(id uint) (*kolide.DistributedQueryCampaign, error) {
+		return campaign, nil
+	}
 
 	ds.LabelQueriesForHostFunc = func(host *kolide.Host, cutoff time.Time) (map[string]string, error) {
 		return map[string]string{}, nil
@@ -937,10 +1175,11 @@ func TestDistributedQueryResults(t *testing.T) {
 	ds.DistributedQueriesForHostFunc = func(host *kolide.Host) (map[uint]string, error) {
 		return map[uint]string{campaign.ID: "select * from time"}, nil
 	}
+	ds.MarkDistributedQueriesRequestedFunc = execStore.MarkDistributedQueriesRequested
 	var gotExecution *kolide.DistributedQueryExecution
 	ds.NewDistributedQueryExecutionFunc = func(exec *kolide.DistributedQueryExecution) (*kolide.DistributedQueryExecution, error) {
 		gotExecution = exec
-		return exec, nil
+		return execStore.NewDistributedQueryExecution(exec)
 	}
 	ds.AppConfigFunc = func() (*kolide.AppConfig, error) {
 		return &kolide.AppConfig{}, nil
@@ -952,7 +1191,7 @@ func TestDistributedQueryResults(t *testing.T) {
 	// Now we should get the active distributed query
 	queries, acc, err := svc.GetDistributedQueries(hostCtx)
 	require.Nil(t, err)
-	assert.Len(t, queries, len(detailQueries)+1)
+	assert.Len(t, queries, expectedDetailQueriesForPlatform(host.Platform)+1)
 	queryKey := fmt.Sprintf("%s%d", hostDistributedQueryPrefix, campaign.ID)
 	assert.Equal(t, "select * from time", queries[queryKey])
 	assert.NotZero(t, acc)
@@ -1007,11 +1246,15 @@ func TestDistributedQueryResults(t *testing.T) {
 	// this test.
 	time.Sleep(10 * time.Millisecond)
 
-	err = svc.SubmitDistributedQueryResults(hostCtx, results, map[string]kolide.OsqueryStatus{})
+	// The host takes some time to run the query
+	mockClock.AddTime(3 * time.Second)
+
+	err = svc.SubmitDistributedQueryResults(hostCtx, results, map[string]kolide.OsqueryStatus{}, nil)
 	require.Nil(t, err)
 	assert.Equal(t, campaign.ID, gotExecution.DistributedQueryCampaignID)
 	assert.Equal(t, host.ID, gotExecution.HostID)
 	assert.Equal(t, kolide.ExecutionSucceeded, gotExecution.Status)
+	assert.Equal(t, 3*time.Second, gotExecution.ExecutionDuration)
 }
 
 func TestOrphanedQueryCampaign(t *testing.T) {
@@ -1051,7 +1294,7 @@ func TestOrphanedQueryCampaign(t *testing.T) {
 
 	ctx := context.Background()
 	ctx = hostctx.NewContext(context.Background(), host)
-	err = svc.SubmitDistributedQueryResults(ctx, results, map[string]kolide.OsqueryStatus{})
+	err = svc.SubmitDistributedQueryResults(ctx, results, map[string]kolide.OsqueryStatus{}, nil)
 	require.Nil(t, err)
 
 	// Ensure that status is changed to completed when there is no listener for
@@ -1060,6 +1303,253 @@ func TestOrphanedQueryCampaign(t *testing.T) {
 	assert.Equal(t, kolide.QueryComplete, savedCampaign.Status)
 }
 
+func TestPersistedQueryCampaign(t *testing.T) {
+	ds := new(mock.Store)
+	rs := pubsub.NewInmemQueryResults()
+
+	svc, err := newTestService(ds, rs)
+	require.Nil(t, err)
+
+	ds.DistributedQueryCampaignFunc = func(id uint) (*kolide.DistributedQueryCampaign, error) {
+		return &kolide.DistributedQueryCampaign{ID: id, Status: kolide.QueryRunning, PersistResults: true}, nil
+	}
+	var gotExecution *kolide.DistributedQueryExecution
+	ds.NewDistributedQueryExecutionFunc = func(exec *kolide.DistributedQueryExecution) (*kolide.DistributedQueryExecution, error) {
+		gotExecution = exec
+		return exec, nil
+	}
+	ds.SaveDistributedQueryCampaignFunc = func(campaign *kolide.DistributedQueryCampaign) error {
+		return nil
+	}
+	var gotResult *kolide.CampaignResult
+	var gotMaxRows uint
+	ds.SaveCampaignResultFunc = func(result *kolide.CampaignResult, maxRows uint) (bool, error) {
+		gotResult, gotMaxRows = result, maxRows
+		return true, nil
+	}
+
+	host := kolide.Host{ID: 3, HostName: "the fooer"}
+	rows := []map[string]string{{"foo": "bar"}}
+	ctx := hostctx.NewContext(context.Background(), host)
+	err = svc.SubmitDistributedQueryResults(
+		ctx,
+		map[string][]map[string]string{hostDistributedQueryPrefix + "1": rows},
+		map[string]kolide.OsqueryStatus{},
+		nil,
+	)
+	require.Nil(t, err)
+
+	// Results are persisted and the campaign keeps running without a
+	// listener.
+	require.NotNil(t, gotResult)
+	assert.Equal(t, uint(1), gotResult.DistributedQueryCampaignID)
+	assert.Equal(t, uint(3), gotResult.HostID)
+	assert.Equal(t, "the fooer", gotResult.HostName)
+	assert.Equal(t, rows, gotResult.Rows)
+	assert.Nil(t, gotResult.Error)
+	assert.Equal(t, uint(10000), gotMaxRows)
+	assert.False(t, ds.SaveDistributedQueryCampaignFuncInvoked)
+	require.NotNil(t, gotExecution)
+	assert.Equal(t, kolide.ExecutionSucceeded, gotExecution.Status)
+
+	// The failure message reported by osquery is kept
+	err = svc.SubmitDistributedQueryResults(
+		ctx,
+		map[string][]map[string]string{hostDistributedQueryPrefix + "1": {}},
+		map[string]kolide.OsqueryStatus{hostDistributedQueryPrefix + "1": 1},
+		map[string]string{hostDistributedQueryPrefix + "1": "no such table: foo"},
+	)
+	require.Nil(t, err)
+	require.NotNil(t, gotResult.Error)
+	assert.Equal(t, "no such table: foo", *gotResult.Error)
+	assert.Equal(t, kolide.ExecutionFailed, gotExecution.Status)
+	assert.Equal(t, "no such table: foo", gotExecution.Error)
+}
+
+func TestPersistedQueryCampaignTruncated(t *testing.T) {
+	ds := new(mock.Store)
+	rs := pubsub.NewInmemQueryResults()
+
+	svc, err := newTestService(ds, rs)
+	require.Nil(t, err)
+
+	loaded := 0
+	ds.DistributedQueryCampaignFunc = func(id uint) (*kolide.DistributedQueryCampaign, error) {
+		loaded++
+		return &kolide.DistributedQueryCampaign{ID: id, Status: kolide.QueryRunning, PersistResults: true}, nil
+	}
+	ds.NewDistributedQueryExecutionFunc = func(exec *kolide.DistributedQueryExecution) (*kolide.DistributedQueryExecution, error) {
+		return exec, nil
+	}
+	// The first result is cut to the rows limit, after which the campaign
+	// stores no more results
+	saves := 0
+	ds.SaveCampaignResultFunc = func(result *kolide.CampaignResult, maxRows uint) (bool, error) {
+		saves++
+		if saves > 1 {
+			return false, nil
+		}
+		result.Truncated = true
+		return true, nil
+	}
+	truncated := 0
+	ds.MarkCampaignResultsTruncatedFunc = func(campaignID uint) error {
+		assert.Equal(t, uint(1), campaignID)
+		truncated++
+		return nil
+	}
+
+	for i := 1; i <= 3; i++ {
+		host := kolide.Host{ID: uint(i), HostName: fmt.Sprintf("host%d", i)}
+		err = svc.SubmitDistributedQueryResults(
+			hostctx.NewContext(context.Background(), host),
+			map[string][]map[string]string{hostDistributedQueryPrefix + "1": {{"foo": "bar"}}},
+			map[string]kolide.OsqueryStatus{},
+			nil,
+		)
+		require.Nil(t, err)
+	}
+
+	// The campaign is loaded and marked as truncated once, rather than
+	// for every result
+	assert.Equal(t, 3, saves)
+	assert.Equal(t, 1, loaded)
+	assert.Equal(t, 1, truncated)
+}
+
+func TestLiveQueryResultLogs(t *testing.T) {
+	ds := new(mock.Store)
+	rs := pubsub.NewInmemQueryResults()
+	mockClock := clock.NewMockClock()
+	svc, err := newTestServiceWithClock(ds, rs, mockClock)
+	require.Nil(t, err)
+
+	// Hack to get at the service internals and modify the writer
+	serv := ((svc.(validationMiddleware)).Service).(service)
+	testLogger := &testJSONLogger{}
+	serv.osqueryLogWriter = &logging.OsqueryLogger{LiveQuery: testLogger}
+
+	ds.DistributedQueryCampaignFunc = func(id uint) (*kolide.DistributedQueryCampaign, error) {
+		return &kolide.DistributedQueryCampaign{ID: id, QueryID: 4, UserID: 5, Status: kolide.QueryRunning}, nil
+	}
+	var loadedQueries, loadedUsers int
+	ds.QueryFunc = func(id uint) (*kolide.Query, error) {
+		loadedQueries++
+		return &kolide.Query{ID: id, Query: "select * from foo"}, nil
+	}
+	ds.UserByIDFunc = func(id uint) (*kolide.User, error) {
+		loadedUsers++
+		return &kolide.User{ID: id, Username: "zwass"}, nil
+	}
+	ds.SaveDistributedQueryCampaignFunc = func(campaign *kolide.DistributedQueryCampaign) error {
+		return nil
+	}
+	ds.NewDistributedQueryExecutionFunc = func(exec *kolide.DistributedQueryExecution) (*kolide.DistributedQueryExecution, error) {
+		return exec, nil
+	}
+
+	host := kolide.Host{ID: 3, HostName: "the fooer"}
+	ctx := hostctx.NewContext(context.Background(), host)
+	err = serv.SubmitDistributedQueryResults(
+		ctx,
+		map[string][]map[string]string{hostDistributedQueryPrefix + "1": {{"foo": "bar"}, {"foo": "baz"}}},
+		map[string]kolide.OsqueryStatus{},
+		nil,
+	)
+	require.Nil(t, err)
+
+	// One log is written per row, tagged with the campaign details
+	unix := mockClock.Now().Unix()
+	calendar := mockClock.Now().UTC().Format(time.ANSIC) + " UTC"
+	require.Len(t, testLogger.logs, 2)
+	assert.JSONEq(t,
+		fmt.Sprintf(`{"name":"live_query","hostIdentifier":"the fooer","calendarTime":%q,"unixTime":%d,"columns":{"foo":"bar"},"campaign_id":1,"query":"select * from foo","user":"zwass"}`, calendar, unix),
+		string(testLogger.logs[0]),
+	)
+	assert.JSONEq(t,
+		fmt.Sprintf(`{"name":"live_query","hostIdentifier":"the fooer","calendarTime":%q,"unixTime":%d,"columns":{"foo":"baz"},"campaign_id":1,"query":"select * from foo","user":"zwass"}`, calendar, unix),
+		string(testLogger.logs[1]),
+	)
+
+	// A failed query is logged once with its error
+	err = serv.SubmitDistributedQueryResults(
+		ctx,
+		map[string][]map[string]string{hostDistributedQueryPrefix + "1": {}},
+		map[string]kolide.OsqueryStatus{hostDistributedQueryPrefix + "1": 1},
+		map[string]string{hostDistributedQueryPrefix + "1": "no such table: foo"},
+	)
+	require.Nil(t, err)
+	require.Len(t, testLogger.logs, 1)
+	assert.JSONEq(t,
+		fmt.Sprintf(`{"name":"live_query","hostIdentifier":"the fooer","calendarTime":%q,"unixTime":%d,"error":"no such table: foo","campaign_id":1,"query":"select * from foo","user":"zwass"}`, calendar, unix),
+		string(testLogger.logs[0]),
+	)
+
+	// The query and the user are loaded once for the campaign
+	assert.Equal(t, 1, loadedQueries)
+	assert.Equal(t, 1, loadedUsers)
+}
+
+func TestDeferredQueryCampaign(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	_, err = ds.NewAppConfig(&kolide.AppConfig{})
+	require.Nil(t, err)
+	rs := &mock.QueryResultStore{
+		HealthCheckFunc: func() error {
+			return nil
+		},
+	}
+	mockClock := clock.NewMockClock()
+	svc, err := newTestServiceWithClock(ds, rs, mockClock)
+	require.Nil(t, err)
+
+	host, err := ds.NewHost(&kolide.Host{
+		OsqueryHostID:    "1",
+		NodeKey:          "1",
+		HostName:         "offline laptop",
+		Platform:         "darwin",
+		DetailUpdateTime: mockClock.Now(),
+	})
+	require.Nil(t, err)
+
+	viewerCtx := viewer.NewContext(context.Background(), viewer.Viewer{
+		User: &kolide.User{ID: 1, Username: "admin"},
+	})
+	q := "select * from time"
+
+	_, err = svc.NewDistributedQueryCampaign(viewerCtx, q, []uint{host.ID}, nil, nil, kolide.CampaignOptions{TTLDays: 3})
+	require.NotNil(t, err)
+
+	campaign, err := svc.NewDistributedQueryCampaign(viewerCtx, q, []uint{host.ID}, nil, nil,
+		kolide.CampaignOptions{Deferred: true, TTLDays: 14})
+	require.Nil(t, err)
+	assert.True(t, campaign.Deferred)
+	assert.True(t, campaign.PersistResults)
+	assert.Equal(t, kolide.QueryRunning, campaign.Status)
+	require.NotNil(t, campaign.ExpiresAt)
+	assert.Equal(t, mockClock.Now().Add(14*24*time.Hour), *campaign.ExpiresAt)
+
+	// The query is sent once when the host checks in
+	name := hostDistributedQueryPrefix + strconv.Itoa(int(campaign.ID))
+	hostCtx := hostctx.NewContext(context.Background(), *host)
+	queries, _, err := svc.GetDistributedQueries(hostCtx)
+	require.Nil(t, err)
+	assert.Equal(t, q, queries[name])
+
+	queries, _, err = svc.GetDistributedQueries(hostCtx)
+	require.Nil(t, err)
+	assert.NotContains(t, queries, name)
+
+	// The campaign expires after its TTL rather than after a day
+	expired, _, err := ds.CleanupDistributedQueryCampaigns(mockClock.Now().Add(13 * 24 * time.Hour))
+	require.Nil(t, err)
+	assert.Equal(t, uint(0), expired)
+	expired, _, err = ds.CleanupDistributedQueryCampaigns(mockClock.Now().Add(15 * 24 * time.Hour))
+	require.Nil(t, err)
+	assert.Equal(t, uint(1), expired)
+}
+
 func TestUpdateHostIntervals(t *testing.T) {
 	ds := new(mock.Store)
 
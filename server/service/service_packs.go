@@ -6,8 +6,19 @@ import (
 	"github.com/kolide/fleet/server/kolide"
 )
 
-func (svc service) ApplyPackSpecs(ctx context.Context, specs []*kolide.PackSpec) error {
-	return svc.ds.ApplyPackSpecs(specs)
+func (svc service) ApplyPackSpecs(ctx context.Context, specs []*kolide.PackSpec) ([]string, error) {
+	validation, err := svc.validatePackSpecs(specs, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := queryValidationError("queries", validation); err != nil {
+		return nil, err
+	}
+
+	if err := svc.ds.ApplyPackSpecs(specs); err != nil {
+		return nil, err
+	}
+	return validation.Warnings, nil
 }
 
 func (svc service) GetPackSpecs(ctx context.Context) ([]*kolide.PackSpec, error) {
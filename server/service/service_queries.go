@@ -24,10 +24,15 @@ func specFromQuery(query *kolide.Query) *kolide.QuerySpec {
 	}
 }
 
-func (svc service) ApplyQuerySpecs(ctx context.Context, specs []*kolide.QuerySpec) error {
+func (svc service) ApplyQuerySpecs(ctx context.Context, specs []*kolide.QuerySpec) ([]string, error) {
 	vc, ok := viewer.FromContext(ctx)
 	if !ok {
-		return errors.New("user must be authenticated to apply queries")
+		return nil, errors.New("user must be authenticated to apply queries")
+	}
+
+	validation := svc.validateQuerySpecs(specs)
+	if err := queryValidationError("query", validation); err != nil {
+		return nil, err
 	}
 
 	queries := []*kolide.Query{}
@@ -35,8 +40,10 @@ func (svc service) ApplyQuerySpecs(ctx context.Context, specs []*kolide.QuerySpe
 		queries = append(queries, queryFromSpec(spec))
 	}
 
-	err := svc.ds.ApplyQueries(vc.UserID(), queries)
-	return errors.Wrap(err, "applying queries")
+	if err := svc.ds.ApplyQueries(vc.UserID(), queries); err != nil {
+		return nil, errors.Wrap(err, "applying queries")
+	}
+	return validation.Warnings, nil
 }
 
 func (svc service) GetQuerySpecs(ctx context.Context) ([]*kolide.QuerySpec, error) {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"fmt"
 	"net/url"
 	"strings"
 	"time"
@@ -34,6 +35,18 @@ func (svc service) InitiateSSO(ctx context.Context, redirectURL string) (string,
 		return "", errors.Wrap(err, "InitiateSSO getting app config")
 	}
 
+	if appConfig.SSOProvider == kolide.SSOProviderOIDC {
+		settings, err := svc.getOIDCSettings(appConfig, redirectURL)
+		if err != nil {
+			return "", errors.Wrap(err, "InitiateSSO getting oidc settings")
+		}
+		idpURL, err := sso.CreateOIDCAuthorizationRequest(settings)
+		if err != nil {
+			return "", errors.Wrap(err, "InitiateSSO creating oidc authorization")
+		}
+		return idpURL, nil
+	}
+
 	metadata, err := svc.getMetadata(appConfig)
 	if err != nil {
 		return "", errors.Wrap(err, "InitiateSSO getting metadata")
@@ -42,7 +55,7 @@ func (svc service) InitiateSSO(ctx context.Context, redirectURL string) (string,
 	settings := sso.Settings{
 		Metadata: metadata,
 		// Construct call back url to send to idp
-		AssertionConsumerServiceURL: appConfig.KolideServerURL + svc.config.Server.URLPrefix + "/api/v1/kolide/sso/callback",
+		AssertionConsumerServiceURL: svc.ssoCallbackURL(appConfig),
 		SessionStore:                svc.ssoSessionStore,
 		OriginalURL:                 redirectURL,
 	}
@@ -66,6 +79,29 @@ func (svc service) InitiateSSO(ctx context.Context, redirectURL string) (string,
 	return idpURL, nil
 }
 
+// ssoCallbackURL is the URL the IDP sends the user back to, for both SAML
+// and OIDC.
+func (svc service) ssoCallbackURL(config *kolide.AppConfig) string {
+	return config.KolideServerURL + svc.config.Server.URLPrefix + "/api/v1/kolide/sso/callback"
+}
+
+func (svc service) getOIDCSettings(config *kolide.AppConfig, originalURL string) (*sso.OIDCSettings, error) {
+	configuration, err := sso.GetOIDCConfiguration(config.OIDCIssuerURL, svc.metaDataClient)
+	if err != nil {
+		return nil, err
+	}
+	return &sso.OIDCSettings{
+		Configuration: configuration,
+		ClientID:      config.OIDCClientID,
+		ClientSecret:  config.OIDCClientSecret,
+		UserClaim:     config.OIDCUserClaim,
+		RedirectURL:   svc.ssoCallbackURL(config),
+		SessionStore:  svc.ssoSessionStore,
+		OriginalURL:   originalURL,
+		Client:        svc.metaDataClient,
+	}, nil
+}
+
 func (svc service) getMetadata(config *kolide.AppConfig) (*sso.Metadata, error) {
 	if config.MetadataURL != "" {
 		metadata, err := sso.GetMetadata(config.MetadataURL, svc.metaDataClient)
@@ -96,9 +132,36 @@ func (svc service) CallbackSSO(ctx context.Context, auth kolide.Auth) (*kolide.S
 	if err != nil {
 		return nil, errors.Wrap(err, "expiring sso session in callback")
 	}
-	user, err := svc.userByEmailOrUsername(auth.UserID())
+	appConfig, err := svc.ds.AppConfig()
 	if err != nil {
-		return nil, errors.Wrap(err, "finding user in sso callback")
+		return nil, errors.Wrap(err, "getting app config in sso callback")
+	}
+	// The user of an OIDC response is only known once its code has been
+	// redeemed for a validated ID token.
+	if oidcAuth, ok := auth.(*sso.OIDCAuthResponse); ok {
+		if appConfig.SSOProvider != kolide.SSOProviderOIDC {
+			return nil, errors.New("oidc is not enabled")
+		}
+		settings, err := svc.getOIDCSettings(appConfig, sess.OriginalURL)
+		if err != nil {
+			return nil, errors.Wrap(err, "getting oidc settings in sso callback")
+		}
+		auth, err = sso.ExchangeOIDCCode(ctx, settings, sess, oidcAuth)
+		if err != nil {
+			return nil, errors.Wrap(err, "validating oidc response")
+		}
+	}
+	var user *kolide.User
+	if appConfig.EnableJITProvisioning && appConfig.SSOProvider != kolide.SSOProviderOIDC {
+		user, err = svc.provisionSSOUser(ctx, appConfig, auth)
+		if err != nil {
+			return nil, errors.Wrap(err, "provisioning user in sso callback")
+		}
+	} else {
+		user, err = svc.userByEmailOrUsername(auth.UserID())
+		if err != nil {
+			return nil, errors.Wrap(err, "finding user in sso callback")
+		}
 	}
 	// if user is not active they are not authorized to use the application
 	if !user.Enabled || user.Deleted {
@@ -108,6 +171,9 @@ func (svc service) CallbackSSO(ctx context.Context, auth kolide.Auth) (*kolide.S
 	if !user.SSOEnabled {
 		return nil, errors.New("user not configured to use sso")
 	}
+	if user.APIOnly {
+		return nil, errors.New("service accounts cannot log in")
+	}
 	token, err := svc.makeSession(user.ID)
 	if err != nil {
 		return nil, errors.Wrap(err, "making user session in sso callback")
@@ -122,6 +188,164 @@ func (svc service) CallbackSSO(ctx context.Context, auth kolide.Auth) (*kolide.S
 	return result, nil
 }
 
+// Attributes commonly used by identity providers for the email and the name
+// of the user in SAML assertions.
+var (
+	ssoEmailAttributes = []string{
+		"email",
+		"mail",
+		"emailAddress",
+		"http://schemas.xmlsoap.org/ws/2005/05/identity/claims/emailaddress",
+		"urn:oid:0.9.2342.19200300.100.1.3",
+	}
+	ssoNameAttributes = []string{
+		"name",
+		"displayName",
+		"http://schemas.microsoft.com/identity/claims/displayname",
+		"urn:oid:2.16.840.1.113730.3.1.241",
+	}
+)
+
+func firstAttributeValue(auth kolide.Auth, names []string) string {
+	for _, name := range names {
+		for _, value := range auth.AttributeValues(name) {
+			if value != "" {
+				return value
+			}
+		}
+	}
+	return ""
+}
+
+// provisionSSOUser returns the user of a SAML assertion, creating it on the
+// first login. The name, email, admin and enabled state of users created
+// through SSO are updated from the assertion on every login.
+// The creation, role and enabled state changes are added to the activities
+// of the context.
+func (svc service) provisionSSOUser(ctx context.Context, config *kolide.AppConfig, auth kolide.Auth) (*kolide.User, error) {
+	email := firstAttributeValue(auth, ssoEmailAttributes)
+	if email == "" && strings.Contains(auth.UserID(), "@") {
+		email = auth.UserID()
+	}
+	name := firstAttributeValue(auth, ssoNameAttributes)
+	var groups []string
+	if config.JITGroupAttribute != "" {
+		groups = auth.AttributeValues(config.JITGroupAttribute)
+	}
+	isAdmin := config.JITAdminGroups.Contains(groups...)
+	isEnabled := len(config.JITUserGroups) == 0 || isAdmin || config.JITUserGroups.Contains(groups...)
+
+	user, err := svc.userByEmailOrUsername(auth.UserID())
+	if _, ok := err.(kolide.NotFoundError); ok && email != "" && email != auth.UserID() {
+		user, err = svc.ds.UserByEmail(email)
+	}
+	if _, ok := err.(kolide.NotFoundError); ok {
+		if !isEnabled {
+			return nil, errors.New("user is not a member of the jit user groups")
+		}
+		user, err := svc.createSSOUser(email, name, isAdmin)
+		if err != nil {
+			return nil, err
+		}
+		addActivity(ctx, userActivity("created_user", user), map[string]interface{}{"role": user.Role, "jit_provisioned": true})
+		return user, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Users set up with a password, and service accounts, are not managed by
+	// the identity provider.
+	if !user.SSOEnabled || user.APIOnly {
+		return user, nil
+	}
+
+	changed := false
+	role := user.EffectiveRole()
+	if name != "" && name != user.Name {
+		user.Name = name
+		changed = true
+	}
+	if email != "" && email != user.Email {
+		user.Email = email
+		changed = true
+	}
+	if len(config.JITAdminGroups) > 0 {
+		if isAdmin {
+			user.SetRole(kolide.RoleAdmin)
+		} else if role == kolide.RoleAdmin {
+			user.SetRole(kolide.RoleMaintainer)
+		}
+		changed = changed || role != user.EffectiveRole()
+	}
+	enabledChanged := len(config.JITUserGroups) > 0 && user.Enabled != isEnabled
+	if enabledChanged {
+		user.Enabled = isEnabled
+		changed = true
+	}
+	if !changed {
+		return user, nil
+	}
+	if err := svc.saveUser(user); err != nil {
+		return nil, errors.Wrap(err, "updating provisioned user")
+	}
+	if role != user.EffectiveRole() {
+		addActivity(ctx, userActivity("changed_user_role", user), map[string]interface{}{"role": user.Role, "jit_provisioned": true})
+	}
+	if enabledChanged && user.Enabled {
+		addActivity(ctx, userActivity("enabled_user", user), map[string]interface{}{"jit_provisioned": true})
+	}
+	if enabledChanged && !user.Enabled {
+		addActivity(ctx, userActivity("disabled_user", user), map[string]interface{}{"jit_provisioned": true})
+		if err := svc.ds.DestroyAllSessionsForUser(user.ID); err != nil {
+			return nil, errors.Wrap(err, "destroying sessions of disabled user")
+		}
+	}
+	return user, nil
+}
+
+// createSSOUser creates a user signing on with SSO. The username is the local
+// part of the email, with a numeric suffix if it is already taken.
+func (svc service) createSSOUser(email, name string, isAdmin bool) (*kolide.User, error) {
+	if email == "" {
+		return nil, errors.New("sso response has no email to provision the user")
+	}
+	base := strings.Map(func(r rune) rune {
+		if r == '@' || r == ' ' {
+			return '_'
+		}
+		return r
+	}, strings.SplitN(email, "@", 2)[0])
+	role, ssoInvite := kolide.RoleMaintainer, true
+	if isAdmin {
+		role = kolide.RoleAdmin
+	}
+	for i := 0; i < 10; i++ {
+		username := base
+		if i > 0 {
+			username = fmt.Sprintf("%s%d", base, i+1)
+		}
+		_, err := svc.ds.User(username)
+		if err == nil {
+			continue
+		}
+		if _, ok := err.(kolide.NotFoundError); !ok {
+			return nil, errors.Wrap(err, "checking username of provisioned user")
+		}
+		user, err := svc.newUser(kolide.UserPayload{
+			Username:  &username,
+			Email:     &email,
+			Name:      &name,
+			Role:      &role,
+			SSOInvite: &ssoInvite,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "creating provisioned user")
+		}
+		return user, nil
+	}
+	return nil, errors.Errorf("no username available for %s", email)
+}
+
 func (svc service) Login(ctx context.Context, username, password string) (*kolide.User, string, error) {
 	user, err := svc.userByEmailOrUsername(username)
 	if _, ok := err.(kolide.NotFoundError); ok {
@@ -137,6 +361,10 @@ func (svc service) Login(ctx context.Context, username, password string) (*kolid
 		const errMessage = "password login not allowed for single sign on users"
 		return nil, "", authError{reason: errMessage, clientReason: errMessage}
 	}
+	if user.APIOnly {
+		const errMessage = "service accounts cannot log in"
+		return nil, "", authError{reason: errMessage, clientReason: errMessage}
+	}
 	if err = user.ValidatePassword(password); err != nil {
 		return nil, "", authError{reason: "bad password"}
 	}
@@ -87,3 +87,154 @@ func (authViewerService) GetSessionByKey(ctx context.Context, key string) (*koli
 func (authViewerService) User(ctx context.Context, uid uint) (*kolide.User, error) {
 	return &kolide.User{}, nil
 }
+
+type testSSOAuth struct {
+	userID     string
+	attributes map[string][]string
+}
+
+func (a testSSOAuth) UserID() string {
+	return a.userID
+}
+
+func (a testSSOAuth) RequestID() string {
+	return "request"
+}
+
+func (a testSSOAuth) AttributeValues(name string) []string {
+	return a.attributes[name]
+}
+
+func TestProvisionSSOUser(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	svc := service{ds: ds, config: config.TestConfig()}
+	appConfig := &kolide.AppConfig{
+		EnableJITProvisioning: true,
+		JITGroupAttribute:     "groups",
+		JITAdminGroups:        kolide.StringList{"fleet-admins"},
+		JITUserGroups:         kolide.StringList{"fleet-users"},
+	}
+
+	auth := testSSOAuth{
+		userID: "alice@example.com",
+		attributes: map[string][]string{
+			"displayName": {"Alice"},
+			"groups":      {"engineering", "fleet-admins"},
+		},
+	}
+	ctx, activities := withActivityCollector(context.Background())
+	user, err := svc.provisionSSOUser(ctx, appConfig, auth)
+	require.Nil(t, err)
+	assert.NotZero(t, user.ID)
+	assert.Equal(t, "alice", user.Username)
+	assert.Equal(t, "alice@example.com", user.Email)
+	assert.Equal(t, "Alice", user.Name)
+	assert.Equal(t, kolide.RoleAdmin, user.EffectiveRole())
+	assert.True(t, user.Enabled)
+	assert.True(t, user.SSOEnabled)
+	require.Len(t, *activities, 1)
+	assert.Equal(t, "created_user", (*activities)[0].activity.Type)
+	assert.Equal(t, map[string]interface{}{"role": kolide.RoleAdmin, "jit_provisioned": true}, (*activities)[0].details)
+
+	// The next login updates the user from the assertion.
+	_, err = ds.NewSession(&kolide.Session{UserID: user.ID, Key: "key"})
+	require.Nil(t, err)
+	auth.attributes = map[string][]string{
+		"displayName": {"Alice Smith"},
+		"groups":      {"fleet-users"},
+	}
+	user, err = svc.provisionSSOUser(ctx, appConfig, auth)
+	require.Nil(t, err)
+	assert.Equal(t, "Alice Smith", user.Name)
+	assert.Equal(t, kolide.RoleMaintainer, user.EffectiveRole())
+	assert.False(t, user.Admin)
+	assert.True(t, user.Enabled)
+	require.Len(t, *activities, 2)
+	assert.Equal(t, "changed_user_role", (*activities)[1].activity.Type)
+	assert.Equal(t, map[string]interface{}{"role": kolide.RoleMaintainer, "jit_provisioned": true}, (*activities)[1].details)
+
+	// Logins without changes add no activity.
+	_, err = svc.provisionSSOUser(ctx, appConfig, auth)
+	require.Nil(t, err)
+	assert.Len(t, *activities, 2)
+
+	auth.attributes = map[string][]string{"groups": {"engineering"}}
+	user, err = svc.provisionSSOUser(ctx, appConfig, auth)
+	require.Nil(t, err)
+	assert.False(t, user.Enabled)
+	sessions, err := ds.ListSessionsForUser(user.ID)
+	require.Nil(t, err)
+	assert.Empty(t, sessions)
+	require.Len(t, *activities, 3)
+	assert.Equal(t, "disabled_user", (*activities)[2].activity.Type)
+	require.NotNil(t, (*activities)[2].activity.TargetID)
+	assert.Equal(t, user.ID, *(*activities)[2].activity.TargetID)
+}
+
+func TestProvisionSSOUserCreation(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	svc := service{ds: ds, config: config.TestConfig()}
+	appConfig := &kolide.AppConfig{
+		EnableJITProvisioning: true,
+		JITGroupAttribute:     "groups",
+		JITUserGroups:         kolide.StringList{"fleet-users"},
+	}
+
+	// Users outside of the user groups are not created.
+	_, err = svc.provisionSSOUser(context.Background(), appConfig, testSSOAuth{userID: "bob@example.com"})
+	require.NotNil(t, err)
+	_, err = ds.UserByEmail("bob@example.com")
+	_, ok := err.(kolide.NotFoundError)
+	assert.True(t, ok)
+
+	// Taken usernames get a suffix.
+	_, err = ds.NewUser(&kolide.User{Username: "bob", Email: "bob@example.org"})
+	require.Nil(t, err)
+	user, err := svc.provisionSSOUser(context.Background(), appConfig, testSSOAuth{
+		userID: "0056A000000Q6Rl",
+		attributes: map[string][]string{
+			"email":  {"bob@example.com"},
+			"groups": {"fleet-users"},
+		},
+	})
+	require.Nil(t, err)
+	assert.Equal(t, "bob2", user.Username)
+	assert.Equal(t, "bob@example.com", user.Email)
+	assert.Equal(t, kolide.RoleMaintainer, user.EffectiveRole())
+
+	// The assertion has to identify the user by email.
+	_, err = svc.provisionSSOUser(context.Background(), appConfig, testSSOAuth{
+		userID:     "carol",
+		attributes: map[string][]string{"groups": {"fleet-users"}},
+	})
+	assert.NotNil(t, err)
+}
+
+func TestProvisionSSOUserSkipsPasswordUsers(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	svc := service{ds: ds, config: config.TestConfig()}
+	appConfig := &kolide.AppConfig{
+		EnableJITProvisioning: true,
+		JITGroupAttribute:     "groups",
+		JITUserGroups:         kolide.StringList{"fleet-users"},
+	}
+	existing, err := ds.NewUser(&kolide.User{
+		Username: "dave",
+		Email:    "dave@example.com",
+		Name:     "Dave",
+		Enabled:  true,
+	})
+	require.Nil(t, err)
+
+	user, err := svc.provisionSSOUser(context.Background(), appConfig, testSSOAuth{
+		userID:     "dave@example.com",
+		attributes: map[string][]string{"name": {"David"}},
+	})
+	require.Nil(t, err)
+	assert.Equal(t, existing.ID, user.ID)
+	assert.Equal(t, "Dave", user.Name)
+	assert.True(t, user.Enabled)
+}
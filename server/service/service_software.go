@@ -0,0 +1,20 @@
+package service
+
+import (
+	"context"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (svc service) ListHostSoftware(ctx context.Context, hostID uint) ([]kolide.Software, error) {
+	// Load the host first so that a missing host is reported as such
+	// rather than as an empty software list.
+	if _, err := svc.ds.Host(hostID); err != nil {
+		return nil, err
+	}
+	return svc.ds.ListSoftwareForHost(hostID)
+}
+
+func (svc service) ListSoftware(ctx context.Context, name, version string, opt kolide.ListOptions) ([]*kolide.Software, error) {
+	return svc.ds.ListSoftware(name, version, opt)
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/kolide/fleet/server/bindata"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+)
+
+// loadOsquerySchema loads the osquery schema embedded in the Fleet binary. The
+// placeholder assets used when Fleet is included as a library panic, in which
+// case a warning is logged, a nil schema is returned and only the query syntax
+// is validated.
+func loadOsquerySchema(logger kitlog.Logger) (schema *kolide.OsquerySchema, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			level.Warn(logger).Log(
+				"msg", "osquery schema not available, query tables and columns will not be validated",
+				"err", r,
+			)
+			schema, err = nil, nil
+		}
+	}()
+
+	data, err := bindata.Asset(kolide.OsquerySchemaAsset)
+	if err != nil {
+		return nil, err
+	}
+	return kolide.ParseOsquerySchema(data)
+}
+
+func (svc service) validateQuerySpecs(specs []*kolide.QuerySpec) kolide.QueryValidation {
+	var v kolide.QueryValidation
+	for _, spec := range specs {
+		v.Append(fmt.Sprintf("query %q: ", spec.Name), svc.osquerySchema.ValidateQuery(spec.Query, ""))
+	}
+	return v
+}
+
+func (svc service) validateLabelSpecs(specs []*kolide.LabelSpec) kolide.QueryValidation {
+	var v kolide.QueryValidation
+	for _, spec := range specs {
+		v.Append(fmt.Sprintf("label %q: ", spec.Name), svc.osquerySchema.ValidateQuery(spec.Query, spec.Platform))
+	}
+	return v
+}
+
+// validatePackSpecs validates the queries scheduled in packs for the pack (or
+// scheduled query) platform. The SQL of scheduled queries is looked up in
+// queries before the datastore, so that queries and packs can be validated
+// together before being applied.
+func (svc service) validatePackSpecs(specs []*kolide.PackSpec, queries map[string]string) (kolide.QueryValidation, error) {
+	var v kolide.QueryValidation
+	for _, spec := range specs {
+		for _, q := range spec.Queries {
+			prefix := fmt.Sprintf("pack %q query %q: ", spec.Name, q.Name)
+			sql, ok := queries[q.QueryName]
+			if !ok {
+				query, err := svc.ds.QueryByName(q.QueryName)
+				if kolide.IsNotFound(err) {
+					v.Errors = append(v.Errors, prefix+fmt.Sprintf("query %q does not exist", q.QueryName))
+					continue
+				}
+				if err != nil {
+					return v, errors.Wrap(err, "getting pack query")
+				}
+				sql = query.Query
+			}
+
+			platform := spec.Platform
+			if q.Platform != nil && *q.Platform != "" {
+				platform = *q.Platform
+			}
+			v.Append(prefix, svc.osquerySchema.ValidateQuery(sql, platform))
+		}
+	}
+	return v, nil
+}
+
+// queryValidationError returns an invalid argument error for the errors found
+// when validating queries, nil if there are none.
+func queryValidationError(name string, v kolide.QueryValidation) error {
+	if len(v.Errors) == 0 {
+		return nil
+	}
+	var invalid invalidArgumentError
+	for _, e := range v.Errors {
+		invalid.Append(name, e)
+	}
+	return &invalid
+}
+
+func (svc service) ValidateSpecs(ctx context.Context, queries []*kolide.QuerySpec, labels []*kolide.LabelSpec, packs []*kolide.PackSpec) (*kolide.QueryValidation, error) {
+	v := svc.validateQuerySpecs(queries)
+	v.Append("", svc.validateLabelSpecs(labels))
+
+	specQueries := map[string]string{}
+	for _, spec := range queries {
+		specQueries[spec.Name] = spec.Query
+	}
+	packValidation, err := svc.validatePackSpecs(packs, specQueries)
+	if err != nil {
+		return nil, err
+	}
+	v.Append("", packValidation)
+
+	return &v, nil
+}
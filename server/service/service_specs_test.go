@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/kolide/fleet/server/contexts/viewer"
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/kolide/fleet/server/mock"
+	"github.com/kolide/fleet/server/pubsub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSchemaService(t *testing.T, ds kolide.Datastore) service {
+	data, err := ioutil.ReadFile("../../" + kolide.OsquerySchemaAsset)
+	require.Nil(t, err)
+	schema, err := kolide.ParseOsquerySchema(data)
+	require.Nil(t, err)
+	return service{ds: ds, osquerySchema: schema}
+}
+
+func TestApplySpecsValidation(t *testing.T) {
+	ds := new(mock.Store)
+	svc := newTestSchemaService(t, ds)
+	ds.ApplyQueriesFunc = func(authorID uint, queries []*kolide.Query) error {
+		return nil
+	}
+	ds.ApplyLabelSpecsFunc = func(specs []*kolide.LabelSpec) error {
+		return nil
+	}
+	ds.ApplyPackSpecsFunc = func(specs []*kolide.PackSpec) error {
+		return nil
+	}
+	ds.QueryByNameFunc = func(name string, opts ...kolide.OptionalArg) (*kolide.Query, error) {
+		switch name {
+		case "registry":
+			return &kolide.Query{Name: name, Query: "select * from registry"}, nil
+		case "extension":
+			return &kolide.Query{Name: name, Query: "select * from kolide_launcher_info"}, nil
+		}
+		return nil, notFoundError{}
+	}
+	ctx := viewer.NewContext(context.Background(), viewer.Viewer{User: &kolide.User{ID: 1}})
+
+	warnings, err := svc.ApplyQuerySpecs(ctx, []*kolide.QuerySpec{
+		{Name: "users", Query: "select username from users"},
+		{Name: "typo", Query: "select * from usrs"},
+	})
+	require.Nil(t, err)
+	assert.True(t, ds.ApplyQueriesFuncInvoked)
+	assert.Equal(t, []string{
+		`query "typo": table "usrs" is not in the osquery 3.3 schema, it may be provided by an extension`,
+	}, warnings)
+
+	ds.ApplyQueriesFuncInvoked = false
+	_, err = svc.ApplyQuerySpecs(ctx, []*kolide.QuerySpec{{Name: "broken", Query: "select (1"}})
+	require.NotNil(t, err)
+	assert.IsType(t, &invalidArgumentError{}, err)
+	assert.False(t, ds.ApplyQueriesFuncInvoked)
+
+	_, err = svc.ApplyLabelSpecs(ctx, []*kolide.LabelSpec{
+		{Name: "mac registry", Query: "select 1 from registry", Platform: "darwin"},
+	})
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), `label "mac registry": table "registry" is not available on darwin`)
+	assert.False(t, ds.ApplyLabelSpecsFuncInvoked)
+
+	warnings, err = svc.ApplyLabelSpecs(ctx, []*kolide.LabelSpec{
+		{Name: "windows registry", Query: "select 1 from registry", Platform: "windows"},
+	})
+	require.Nil(t, err)
+	assert.Empty(t, warnings)
+	assert.True(t, ds.ApplyLabelSpecsFuncInvoked)
+
+	// The pack platform is overridden by the scheduled query platform
+	windows := "windows"
+	_, err = svc.ApplyPackSpecs(ctx, []*kolide.PackSpec{{
+		Name:     "mac",
+		Platform: "darwin",
+		Queries: []kolide.PackSpecQuery{
+			{QueryName: "registry", Name: "registry"},
+			{QueryName: "registry", Name: "windows registry", Platform: &windows},
+		},
+	}})
+	require.NotNil(t, err)
+	invalid := err.(*invalidArgumentError)
+	require.Len(t, *invalid, 1)
+	assert.Equal(t, `pack "mac" query "registry": table "registry" is not available on darwin`, (*invalid)[0].reason)
+	assert.False(t, ds.ApplyPackSpecsFuncInvoked)
+
+	warnings, err = svc.ApplyPackSpecs(ctx, []*kolide.PackSpec{{
+		Name:    "all",
+		Queries: []kolide.PackSpecQuery{{QueryName: "extension", Name: "launcher"}},
+	}})
+	require.Nil(t, err)
+	assert.Len(t, warnings, 1)
+	assert.True(t, ds.ApplyPackSpecsFuncInvoked)
+}
+
+func TestValidateSpecs(t *testing.T) {
+	ds := new(mock.Store)
+	svc := newTestSchemaService(t, ds)
+	ds.QueryByNameFunc = func(name string, opts ...kolide.OptionalArg) (*kolide.Query, error) {
+		return nil, notFoundError{}
+	}
+
+	// Pack queries are found in the validated query specs first
+	validation, err := svc.ValidateSpecs(context.Background(),
+		[]*kolide.QuerySpec{{Name: "registry", Query: "select * from registry"}},
+		[]*kolide.LabelSpec{{Name: "users", Query: "select nme from users"}},
+		[]*kolide.PackSpec{{
+			Name:     "mac",
+			Platform: "darwin",
+			Queries: []kolide.PackSpecQuery{
+				{QueryName: "registry", Name: "registry"},
+				{QueryName: "missing", Name: "missing"},
+			},
+		}},
+	)
+	require.Nil(t, err)
+	assert.Equal(t, []string{
+		`pack "mac" query "registry": table "registry" is not available on darwin`,
+		`pack "mac" query "missing": query "missing" does not exist`,
+	}, validation.Errors)
+	assert.Equal(t, []string{`label "users": column "nme" is not in table "users"`}, validation.Warnings)
+	assert.False(t, ds.ApplyQueriesFuncInvoked)
+}
+
+func TestValidateCampaignQuery(t *testing.T) {
+	ds := new(mock.Store)
+	svc := newTestSchemaService(t, ds)
+	svc.resultStore = pubsub.NewInmemQueryResults()
+	ds.AppConfigFunc = func() (*kolide.AppConfig, error) {
+		return &kolide.AppConfig{}, nil
+	}
+	ctx := viewer.NewContext(context.Background(), viewer.Viewer{User: &kolide.User{ID: 1}})
+
+	_, err := svc.NewDistributedQueryCampaign(ctx, "select * from 'users", nil, nil, nil, kolide.CampaignOptions{})
+	require.NotNil(t, err)
+	assert.IsType(t, &invalidArgumentError{}, err)
+	assert.False(t, ds.NewQueryFuncInvoked)
+}
@@ -27,8 +27,8 @@ func (svc service) SearchTargets(ctx context.Context, query string, selectedHost
 	return results, nil
 }
 
-func (svc service) CountHostsInTargets(ctx context.Context, hostIDs []uint, labelIDs []uint) (*kolide.TargetMetrics, error) {
-	metrics, err := svc.ds.CountHostsInTargets(hostIDs, labelIDs, svc.clock.Now())
+func (svc service) CountHostsInTargets(ctx context.Context, hostIDs []uint, labelIDs []uint, tagIDs []uint) (*kolide.TargetMetrics, error) {
+	metrics, err := svc.ds.CountHostsInTargets(hostIDs, labelIDs, tagIDs, svc.clock.Now())
 	if err != nil {
 		return nil, err
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"fmt"
 	"html/template"
 	"time"
 
@@ -19,8 +20,13 @@ func (svc service) NewUser(ctx context.Context, p kolide.UserPayload) (*kolide.U
 		return nil, err
 	}
 
-	// set the payload Admin property based on an existing invite.
+	// set the payload Admin and Role properties based on an existing
+	// invite. The role cannot be chosen by the invited user.
 	p.Admin = &invite.Admin
+	p.Role = nil
+	if invite.Role != "" {
+		p.Role = &invite.Role
+	}
 
 	user, err := svc.newUser(p)
 	if err != nil {
@@ -39,6 +45,10 @@ func (svc service) NewAdminCreatedUser(ctx context.Context, p kolide.UserPayload
 }
 
 func (svc service) newUser(p kolide.UserPayload) (*kolide.User, error) {
+	if p.Role != nil && !p.Role.Valid() {
+		return nil, newInvalidArgumentError("role", fmt.Sprintf("unknown role %q", *p.Role))
+	}
+
 	var ssoEnabled bool
 	// if user is SSO generate a fake password
 	if p.SSOInvite != nil && *p.SSOInvite {
@@ -66,13 +76,75 @@ func (svc service) ChangeUserAdmin(ctx context.Context, id uint, isAdmin bool) (
 	if err != nil {
 		return nil, err
 	}
-	user.Admin = isAdmin
+	if isAdmin {
+		user.SetRole(kolide.RoleAdmin)
+	} else if user.EffectiveRole() == kolide.RoleAdmin {
+		user.SetRole(kolide.RoleMaintainer)
+	}
+	if err = svc.saveUser(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (svc service) ChangeUserRole(ctx context.Context, id uint, role kolide.Role) (*kolide.User, error) {
+	if !role.Valid() {
+		return nil, newInvalidArgumentError("role", fmt.Sprintf("unknown role %q", role))
+	}
+	user, err := svc.ds.UserByID(id)
+	if err != nil {
+		return nil, err
+	}
+	user.SetRole(role)
 	if err = svc.saveUser(user); err != nil {
 		return nil, err
 	}
 	return user, nil
 }
 
+func (svc service) ApplyUserRolesSpec(ctx context.Context, spec *kolide.UserRolesSpec) error {
+	if spec == nil {
+		return newInvalidArgumentError("spec", "missing required argument")
+	}
+
+	// Resolve and validate all of the users before modifying any, so that
+	// an invalid spec does not result in a partial apply.
+	var users []*kolide.User
+	for username, role := range spec.Roles {
+		if !role.Valid() {
+			return newInvalidArgumentError("roles", fmt.Sprintf("unknown role %q for user %q", role, username))
+		}
+		user, err := svc.ds.User(username)
+		if kolide.IsNotFound(err) {
+			return newInvalidArgumentError("roles", fmt.Sprintf("no user with username %q", username))
+		}
+		if err != nil {
+			return err
+		}
+		users = append(users, user)
+	}
+
+	for _, user := range users {
+		user.SetRole(spec.Roles[user.Username])
+		if err := svc.saveUser(user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (svc service) GetUserRolesSpec(ctx context.Context) (*kolide.UserRolesSpec, error) {
+	users, err := svc.ds.ListUsers(kolide.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	spec := &kolide.UserRolesSpec{Roles: map[string]kolide.Role{}}
+	for _, user := range users {
+		spec.Roles[user.Username] = user.EffectiveRole()
+	}
+	return spec, nil
+}
+
 func (svc service) ChangeUserEnabled(ctx context.Context, id uint, isEnabled bool) (*kolide.User, error) {
 	user, err := svc.ds.UserByID(id)
 	if err != nil {
@@ -91,6 +163,18 @@ func (svc service) ModifyUser(ctx context.Context, userID uint, p kolide.UserPay
 		return nil, err
 	}
 
+	if p.Role != nil {
+		// Users may modify themselves, but only admins may change roles
+		vc, ok := viewer.FromContext(ctx)
+		if !ok || !vc.CanPerformAdminActions() {
+			return nil, permissionError{message: "must be an admin to change roles"}
+		}
+		if !p.Role.Valid() {
+			return nil, newInvalidArgumentError("role", fmt.Sprintf("unknown role %q", *p.Role))
+		}
+		user.SetRole(*p.Role)
+	}
+
 	// the method assumes that the correct authorization
 	// has been validated higher up the stack
 	if p.Username != nil {
@@ -276,6 +360,9 @@ func (svc service) PerformRequiredPasswordReset(ctx context.Context, password st
 	if user.SSOEnabled {
 		return nil, errors.New("password reset for single sign on user not allowed")
 	}
+	if user.APIOnly {
+		return nil, errors.New("password reset for service account not allowed")
+	}
 	if !user.AdminForcedPasswordReset {
 		return nil, errors.New("user does not require password reset")
 	}
@@ -305,6 +392,11 @@ func (svc service) RequirePasswordReset(ctx context.Context, uid uint, require b
 	if user.SSOEnabled {
 		return nil, errors.New("password reset for single sign on user not allowed")
 	}
+	// Service accounts cannot log in to perform the reset, and the flag
+	// would disable their API tokens. Clearing it is allowed.
+	if user.APIOnly && require {
+		return nil, errors.New("password reset for service account not allowed")
+	}
 	// Require reset on next login
 	user.AdminForcedPasswordReset = require
 	if err := svc.saveUser(user); err != nil {
@@ -432,6 +432,28 @@ func TestCreateUser(t *testing.T) {
 	}
 }
 
+func TestCreateUserFromInviteRole(t *testing.T) {
+	ds, _ := inmem.New(config.TestConfig())
+	svc, _ := newTestService(ds, nil)
+	invites := setupInvites(t, ds, []string{"observer@example.com"})
+	invite := invites["observer@example.com"]
+	invite.Role = kolide.RoleObserver
+	require.Nil(t, ds.SaveInvite(invite))
+
+	// The role of the invite is applied, whatever the payload asks for
+	user, err := svc.NewUser(context.Background(), kolide.UserPayload{
+		Username:    stringPtr("observer"),
+		Password:    stringPtr("foobarbaz1234!"),
+		Email:       stringPtr("observer@example.com"),
+		Admin:       boolPtr(true),
+		Role:        rolePtr(kolide.RoleAdmin),
+		InviteToken: &invite.Token,
+	})
+	require.Nil(t, err)
+	assert.Equal(t, kolide.RoleObserver, user.Role)
+	assert.False(t, user.Admin)
+}
+
 func setupInvites(t *testing.T, ds kolide.Datastore, emails []string) map[string]*kolide.Invite {
 	invites := make(map[string]*kolide.Invite)
 	users := createTestUsers(t, ds)
@@ -650,6 +672,30 @@ func TestRequirePasswordReset(t *testing.T) {
 	}
 }
 
+func TestRequirePasswordResetServiceAccount(t *testing.T) {
+	ds, err := inmem.New(config.TestConfig())
+	require.Nil(t, err)
+	svc, err := newTestService(ds, nil)
+	require.Nil(t, err)
+
+	user, err := ds.NewUser(&kolide.User{
+		Username:                 "ci",
+		Email:                    "ci@example.com",
+		Enabled:                  true,
+		APIOnly:                  true,
+		AdminForcedPasswordReset: true,
+	})
+	require.Nil(t, err)
+
+	_, err = svc.RequirePasswordReset(context.Background(), user.ID, true)
+	require.NotNil(t, err)
+
+	// A service account stuck with the flag can still be cleared
+	retUser, err := svc.RequirePasswordReset(context.Background(), user.ID, false)
+	require.Nil(t, err)
+	assert.False(t, retUser.AdminForcedPasswordReset)
+}
+
 func TestPerformRequiredPasswordReset(t *testing.T) {
 	ds, err := inmem.New(config.TestConfig())
 	require.Nil(t, err)
@@ -743,3 +789,111 @@ func TestUserPasswordRequirements(t *testing.T) {
 		})
 	}
 }
+
+func TestChangeUserRole(t *testing.T) {
+	user := &kolide.User{ID: 3, Username: "foo", Enabled: true}
+	ms := new(mock.Store)
+	ms.UserByIDFunc = func(id uint) (*kolide.User, error) {
+		return user, nil
+	}
+	ms.SaveUserFunc = func(u *kolide.User) error {
+		return nil
+	}
+	svc, err := newTestService(ms, nil)
+	require.Nil(t, err)
+
+	_, err = svc.ChangeUserRole(context.Background(), 3, kolide.Role("superuser"))
+	require.NotNil(t, err)
+	assert.IsType(t, &invalidArgumentError{}, err)
+	assert.False(t, ms.SaveUserFuncInvoked)
+
+	changed, err := svc.ChangeUserRole(context.Background(), 3, kolide.RoleAdmin)
+	require.Nil(t, err)
+	assert.Equal(t, kolide.RoleAdmin, changed.Role)
+	assert.True(t, changed.Admin)
+
+	// Removing admin keeps the other permissions of the user
+	changed, err = svc.ChangeUserAdmin(context.Background(), 3, false)
+	require.Nil(t, err)
+	assert.Equal(t, kolide.RoleMaintainer, changed.Role)
+	assert.False(t, changed.Admin)
+}
+
+func TestModifyUserRole(t *testing.T) {
+	user := &kolide.User{ID: 3, Username: "foo", Enabled: true}
+	user.SetRole(kolide.RoleObserver)
+	admin := &kolide.User{ID: 1, Username: "admin", Enabled: true, Admin: true}
+	ms := new(mock.Store)
+	ms.UserByIDFunc = func(id uint) (*kolide.User, error) {
+		return user, nil
+	}
+	ms.SaveUserFunc = func(u *kolide.User) error {
+		return nil
+	}
+	svc, err := newTestService(ms, nil)
+	require.Nil(t, err)
+
+	// Users cannot change their own role
+	ctx := viewer.NewContext(context.Background(), viewer.Viewer{User: user, Session: &kolide.Session{ID: 1}})
+	role := kolide.RoleAdmin
+	_, err = svc.ModifyUser(ctx, 3, kolide.UserPayload{Role: &role})
+	require.NotNil(t, err)
+	assert.IsType(t, permissionError{}, err)
+	assert.False(t, ms.SaveUserFuncInvoked)
+	assert.Equal(t, kolide.RoleObserver, user.Role)
+
+	ctx = viewer.NewContext(context.Background(), viewer.Viewer{User: admin, Session: &kolide.Session{ID: 2}})
+	role = kolide.RoleMaintainer
+	modified, err := svc.ModifyUser(ctx, 3, kolide.UserPayload{Role: &role})
+	require.Nil(t, err)
+	assert.Equal(t, kolide.RoleMaintainer, modified.Role)
+}
+
+func TestApplyUserRolesSpec(t *testing.T) {
+	users := map[string]*kolide.User{
+		"foo": {ID: 1, Username: "foo", Admin: true},
+		"bar": {ID: 2, Username: "bar"},
+	}
+	ms := new(mock.Store)
+	ms.UserFunc = func(username string) (*kolide.User, error) {
+		user, ok := users[username]
+		if !ok {
+			return nil, notFoundError{}
+		}
+		return user, nil
+	}
+	ms.ListUsersFunc = func(opt kolide.ListOptions) ([]*kolide.User, error) {
+		return []*kolide.User{users["foo"], users["bar"]}, nil
+	}
+	ms.SaveUserFunc = func(u *kolide.User) error {
+		return nil
+	}
+	svc, err := newTestService(ms, nil)
+	require.Nil(t, err)
+
+	// Users without a role get one according to their admin flag
+	spec, err := svc.GetUserRolesSpec(context.Background())
+	require.Nil(t, err)
+	assert.Equal(t, map[string]kolide.Role{"foo": kolide.RoleAdmin, "bar": kolide.RoleMaintainer}, spec.Roles)
+
+	// Invalid specs are not partially applied
+	err = svc.ApplyUserRolesSpec(context.Background(), &kolide.UserRolesSpec{
+		Roles: map[string]kolide.Role{"foo": kolide.RoleObserver, "baz": kolide.RoleObserver},
+	})
+	require.NotNil(t, err)
+	assert.IsType(t, &invalidArgumentError{}, err)
+	err = svc.ApplyUserRolesSpec(context.Background(), &kolide.UserRolesSpec{
+		Roles: map[string]kolide.Role{"foo": kolide.RoleObserver, "bar": kolide.Role("root")},
+	})
+	require.NotNil(t, err)
+	assert.False(t, ms.SaveUserFuncInvoked)
+
+	err = svc.ApplyUserRolesSpec(context.Background(), &kolide.UserRolesSpec{
+		Roles: map[string]kolide.Role{"foo": kolide.RoleObserver, "bar": kolide.RoleAdmin},
+	})
+	require.Nil(t, err)
+	spec, err = svc.GetUserRolesSpec(context.Background())
+	require.Nil(t, err)
+	assert.Equal(t, map[string]kolide.Role{"foo": kolide.RoleObserver, "bar": kolide.RoleAdmin}, spec.Roles)
+	assert.False(t, users["foo"].Admin)
+}
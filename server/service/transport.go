@@ -32,6 +32,10 @@ func encodeResponse(ctx context.Context, w http.ResponseWriter, response interfa
 		return nil
 	}
 
+	if s, ok := response.(streamer); ok {
+		return s.stream(w)
+	}
+
 	if e, ok := response.(statuser); ok {
 		w.WriteHeader(e.status())
 		if e.status() == http.StatusNoContent {
@@ -50,6 +54,12 @@ type statuser interface {
 	status() int
 }
 
+// streamer allows response types to write their own body, for responses that
+// are too large to be built in memory before being encoded
+type streamer interface {
+	stream(w http.ResponseWriter) error
+}
+
 // loads a html page
 type htmlPage interface {
 	html() string
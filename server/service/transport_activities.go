@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+func decodeListActivitiesRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	opt, err := listOptionsFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	req := listActivitiesRequest{ListOptions: opt}
+
+	query := r.URL.Query()
+	if actorIDString := query.Get("actor_id"); actorIDString != "" {
+		actorID, err := strconv.ParseUint(actorIDString, 10, 32)
+		if err != nil {
+			return nil, newInvalidArgumentError("actor_id", "actor_id must be a user ID")
+		}
+		req.Filter.ActorID = uintPtr(uint(actorID))
+	}
+	if activityType := query.Get("type"); activityType != "" {
+		req.Filter.Type = &activityType
+	}
+	if targetType := query.Get("target_type"); targetType != "" {
+		req.Filter.TargetType = &targetType
+	}
+	if targetIDString := query.Get("target_id"); targetIDString != "" {
+		targetID, err := strconv.ParseUint(targetIDString, 10, 32)
+		if err != nil {
+			return nil, newInvalidArgumentError("target_id", "target_id must be an ID")
+		}
+		req.Filter.TargetID = uintPtr(uint(targetID))
+	}
+	if sinceString := query.Get("since"); sinceString != "" {
+		since, err := time.Parse(time.RFC3339, sinceString)
+		if err != nil {
+			return nil, newInvalidArgumentError("since", "since must be an RFC3339 time")
+		}
+		req.Filter.Since = &since
+	}
+
+	return req, nil
+}
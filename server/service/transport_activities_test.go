@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeListActivitiesRequest(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/kolide/activities", func(writer http.ResponseWriter, request *http.Request) {
+		r, err := decodeListActivitiesRequest(context.Background(), request)
+		require.Nil(t, err)
+
+		params := r.(listActivitiesRequest)
+		assert.Equal(t, uint(1), params.ListOptions.Page)
+		assert.Equal(t, uint(10), params.ListOptions.PerPage)
+		require.NotNil(t, params.Filter.ActorID)
+		assert.Equal(t, uint(3), *params.Filter.ActorID)
+		require.NotNil(t, params.Filter.Type)
+		assert.Equal(t, "deleted_pack", *params.Filter.Type)
+		require.NotNil(t, params.Filter.TargetType)
+		assert.Equal(t, "pack", *params.Filter.TargetType)
+		require.NotNil(t, params.Filter.TargetID)
+		assert.Equal(t, uint(7), *params.Filter.TargetID)
+		require.NotNil(t, params.Filter.Since)
+		assert.True(t, time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC).Equal(*params.Filter.Since))
+	}).Methods("GET")
+
+	router.ServeHTTP(
+		httptest.NewRecorder(),
+		httptest.NewRequest("GET", "/api/v1/kolide/activities?page=1&per_page=10&actor_id=3&type=deleted_pack&target_type=pack&target_id=7&since=2020-06-01T12:00:00Z", nil),
+	)
+}
+
+func TestDecodeListActivitiesRequestInvalid(t *testing.T) {
+	for _, query := range []string{"actor_id=foo", "target_id=-1", "since=yesterday"} {
+		request := httptest.NewRequest("GET", "/api/v1/kolide/activities?"+query, nil)
+		_, err := decodeListActivitiesRequest(context.Background(), request)
+		require.NotNil(t, err, query)
+		assert.IsType(t, &invalidArgumentError{}, err, query)
+	}
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+func decodeCreateServiceAccountRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req createServiceAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req.payload); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func decodeCreateAPITokenRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	var req createAPITokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req.payload); err != nil {
+		return nil, err
+	}
+	req.UserID = id
+	return req, nil
+}
+
+func decodeListAPITokensRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	return listAPITokensRequest{UserID: id}, nil
+}
+
+func decodeDeleteAPITokenRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	userID, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	id, err := idFromRequest(r, "token_id")
+	if err != nil {
+		return nil, err
+	}
+	return deleteAPITokenRequest{UserID: userID, ID: id}, nil
+}
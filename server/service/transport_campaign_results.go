@@ -0,0 +1,18 @@
+package service
+
+import (
+	"context"
+	"net/http"
+)
+
+func decodeListCampaignResultsRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	opt, err := listOptionsFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	return listCampaignResultsRequest{ID: id, ListOptions: opt}, nil
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"strconv"
+
+	"github.com/kolide/fleet/server/kolide"
 )
 
 func decodeCreateDistributedQueryCampaignRequest(ctx context.Context, r *http.Request) (interface{}, error) {
@@ -21,3 +24,48 @@ func decodeCreateDistributedQueryCampaignByNamesRequest(ctx context.Context, r *
 	}
 	return req, nil
 }
+
+func decodeListDistributedQueryCampaignsRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	opt, err := listOptionsFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	req := listDistributedQueryCampaignsRequest{ListOptions: opt}
+
+	if userIDString := r.URL.Query().Get("user_id"); userIDString != "" {
+		userID, err := strconv.ParseUint(userIDString, 10, 32)
+		if err != nil {
+			return nil, newInvalidArgumentError("user_id", "user_id must be a user ID")
+		}
+		req.Filter.UserID = uintPtr(uint(userID))
+	}
+	if statusString := r.URL.Query().Get("status"); statusString != "" {
+		status, err := kolide.ParseDistributedQueryStatus(statusString)
+		if err != nil {
+			return nil, newInvalidArgumentError("status", "status must be one of waiting, running or complete")
+		}
+		req.Filter.Status = &status
+	}
+
+	return req, nil
+}
+
+func decodeListDistributedQueryCampaignHostsRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	opt, err := listOptionsFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	return listDistributedQueryCampaignHostsRequest{ID: id, ListOptions: opt}, nil
+}
+
+func decodeCancelDistributedQueryCampaignRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	return cancelDistributedQueryCampaignRequest{ID: id}, nil
+}
@@ -2,7 +2,13 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/kolide/fleet/server/kolide"
 )
 
 func decodeGetHostRequest(ctx context.Context, r *http.Request) (interface{}, error) {
@@ -26,5 +32,98 @@ func decodeListHostsRequest(ctx context.Context, r *http.Request) (interface{},
 	if err != nil {
 		return nil, err
 	}
-	return listHostsRequest{ListOptions: opt}, nil
+
+	filter, err := kolide.ParseHostFilter(r.URL.Query().Get("filter"))
+	if err != nil {
+		return nil, newInvalidArgumentError("filter", err.Error())
+	}
+
+	return listHostsRequest{
+		ListOptions: kolide.HostListOptions{ListOptions: opt, Filter: filter},
+	}, nil
+}
+
+func decodeListHostHistoryRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	opt, err := listOptionsFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	return listHostHistoryRequest{ID: id, ListOptions: opt}, nil
+}
+
+func decodeListHostLabelsRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	return listHostLabelsRequest{ID: id}, nil
+}
+
+func decodeMergeHostsRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	var req mergeHostsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	req.ID = id
+	return req, nil
+}
+
+func decodeListExpiredHostsRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req listExpiredHostsRequest
+	if windowString := r.URL.Query().Get("window"); windowString != "" {
+		window, err := strconv.Atoi(windowString)
+		if err != nil || window <= 0 {
+			return nil, newInvalidArgumentError("window", "window must be a positive number of days")
+		}
+		req.Window = window
+	}
+	return req, nil
+}
+
+func decodeDeleteHostsRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req deleteHostsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func decodeExportHostsRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	query := r.URL.Query()
+	req := exportHostsRequest{
+		Format:  kolide.HostExportCSV,
+		Columns: defaultHostExportColumns,
+	}
+
+	if format := query.Get("format"); format != "" {
+		req.Format = kolide.HostExportFormat(strings.ToLower(format))
+		if !req.Format.Valid() {
+			return nil, newInvalidArgumentError("format", "format must be one of csv or ndjson")
+		}
+	}
+
+	if columns := query.Get("columns"); columns != "" {
+		req.Columns = strings.Split(columns, ",")
+		for _, column := range req.Columns {
+			if _, ok := hostExportColumns[column]; !ok {
+				return nil, newInvalidArgumentError("columns", fmt.Sprintf("unknown column %q", column))
+			}
+		}
+	}
+
+	filter, err := kolide.ParseHostFilter(query.Get("filter"))
+	if err != nil {
+		return nil, newInvalidArgumentError("filter", err.Error())
+	}
+	req.Filter = filter
+
+	return req, nil
 }
@@ -56,6 +56,7 @@ func decodeSubmitDistributedQueryResultsRequest(ctx context.Context, r *http.Req
 		NodeKey  string                     `json:"node_key"`
 		Results  map[string]json.RawMessage `json:"queries"`
 		Statuses map[string]interface{}     `json:"statuses"`
+		Messages map[string]string          `json:"messages"`
 	}
 
 	var shim distributedQueryResultsShim
@@ -97,6 +98,7 @@ func decodeSubmitDistributedQueryResultsRequest(ctx context.Context, r *http.Req
 		NodeKey:  shim.NodeKey,
 		Results:  results,
 		Statuses: statuses,
+		Messages: shim.Messages,
 	}
 
 	return req, nil
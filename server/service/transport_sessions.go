@@ -61,6 +61,15 @@ func decodeInitiateSSORequest(ctx context.Context, r *http.Request) (interface{}
 }
 
 func decodeCallbackSSORequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	// OIDC providers redirect with the authorization code in the query,
+	// while SAML responses are posted.
+	if r.Method == http.MethodGet {
+		authResponse, err := sso.DecodeOIDCAuthResponse(r.URL.Query())
+		if err != nil {
+			return nil, errors.Wrap(err, "decoding oidc sso callback")
+		}
+		return authResponse, nil
+	}
 	err := r.ParseForm()
 	if err != nil {
 		return nil, errors.Wrap(err, "decode sso callback")
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/gorilla/mux"
+	"github.com/kolide/fleet/server/sso"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -111,3 +112,16 @@ func TestDecodeLoginRequest(t *testing.T) {
 	})
 
 }
+
+func TestDecodeCallbackSSORequestOIDC(t *testing.T) {
+	request := httptest.NewRequest("GET", "/api/v1/kolide/sso/callback?code=abc&state=state1234", nil)
+	r, err := decodeCallbackSSORequest(context.Background(), request)
+	assert.Nil(t, err)
+	auth, ok := r.(*sso.OIDCAuthResponse)
+	assert.True(t, ok)
+	assert.Equal(t, "state1234", auth.RequestID())
+
+	request = httptest.NewRequest("GET", "/api/v1/kolide/sso/callback?error=access_denied&state=state1234", nil)
+	_, err = decodeCallbackSSORequest(context.Background(), request)
+	assert.NotNil(t, err)
+}
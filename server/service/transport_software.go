@@ -0,0 +1,26 @@
+package service
+
+import (
+	"context"
+	"net/http"
+)
+
+func decodeListHostSoftwareRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	return listHostSoftwareRequest{ID: id}, nil
+}
+
+func decodeListSoftwareRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	opt, err := listOptionsFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	return listSoftwareRequest{
+		Name:        r.URL.Query().Get("name"),
+		Version:     r.URL.Query().Get("version"),
+		ListOptions: opt,
+	}, nil
+}
@@ -34,6 +34,27 @@ func decodeAdminUserRequest(ctx context.Context, r *http.Request) (interface{},
 	return req, nil
 }
 
+func decodeChangeUserRoleRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := idFromRequest(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	var req changeUserRoleRequest
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	req.ID = id
+	return req, nil
+}
+
+func decodeApplyUserRolesSpecRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req applyUserRolesSpecRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
 func decodeCreateUserRequest(ctx context.Context, r *http.Request) (interface{}, error) {
 	var req createUserRequest
 	if err := json.NewDecoder(r.Body).Decode(&req.payload); err != nil {
@@ -111,3 +111,7 @@ func stringPtr(s string) *string {
 func boolPtr(b bool) *bool {
 	return &b
 }
+
+func rolePtr(r kolide.Role) *kolide.Role {
+	return &r
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+func (mw validationMiddleware) NewServiceAccount(ctx context.Context, p kolide.ServiceAccountPayload) (*kolide.User, error) {
+	invalid := &invalidArgumentError{}
+	if p.Username == "" {
+		invalid.Append("username", "cannot be empty")
+	}
+	if strings.Contains(p.Username, "@") {
+		invalid.Append("username", "'@' character not allowed in usernames")
+	}
+	if p.Email == "" {
+		invalid.Append("email", "cannot be empty")
+	}
+	if invalid.HasErrors() {
+		return nil, invalid
+	}
+	return mw.Service.NewServiceAccount(ctx, p)
+}
+
+func (mw validationMiddleware) NewAPIToken(ctx context.Context, userID uint, p kolide.APITokenPayload) (string, *kolide.APIToken, error) {
+	if p.Name == "" {
+		return "", nil, newInvalidArgumentError("name", "cannot be empty")
+	}
+	return mw.Service.NewAPIToken(ctx, userID, p)
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"net/url"
 
 	"github.com/kolide/fleet/server/kolide"
 	"github.com/pkg/errors"
@@ -28,24 +29,24 @@ func isSet(val *string) bool {
 }
 
 func validateSSOSettings(p kolide.AppConfigPayload, existing *kolide.AppConfig, invalid *invalidArgumentError) {
-	if p.SSOSettings != nil && p.SSOSettings.EnableSSO != nil {
+	if p.SSOSettings == nil {
+		return
+	}
+	provider := existing.SSOProvider
+	if p.SSOSettings.Provider != nil {
+		provider = *p.SSOSettings.Provider
+		if provider != kolide.SSOProviderSAML && provider != kolide.SSOProviderOIDC {
+			invalid.Append("provider", "must be saml or oidc")
+			return
+		}
+	}
+	validateJITSettings(p, existing, provider, invalid)
+	if p.SSOSettings.EnableSSO != nil {
 		if *p.SSOSettings.EnableSSO {
-			if !isSet(p.SSOSettings.Metadata) && !isSet(p.SSOSettings.MetadataURL) {
-				if existing.Metadata == "" && existing.MetadataURL == "" {
-					invalid.Append("metadata", "either metadata or metadata_url must be defined")
-				}
-			}
-			if isSet(p.SSOSettings.Metadata) && isSet(p.SSOSettings.MetadataURL) {
-				invalid.Append("metadata", "both metadata and metadata_url are defined, only one is allowed")
-			}
-			if !isSet(p.SSOSettings.EntityID) {
-				if existing.EntityID == "" {
-					invalid.Append("entity_id", "required")
-				}
+			if provider == kolide.SSOProviderOIDC {
+				validateOIDCSettings(p, existing, invalid)
 			} else {
-				if len(*p.SSOSettings.EntityID) < 5 {
-					invalid.Append("entity_id", "must be 5 or more characters")
-				}
+				validateSAMLSettings(p, existing, invalid)
 			}
 			if !isSet(p.SSOSettings.IDPName) {
 				if existing.IDPName == "" {
@@ -59,3 +60,69 @@ func validateSSOSettings(p kolide.AppConfigPayload, existing *kolide.AppConfig,
 		}
 	}
 }
+
+func validateSAMLSettings(p kolide.AppConfigPayload, existing *kolide.AppConfig, invalid *invalidArgumentError) {
+	if !isSet(p.SSOSettings.Metadata) && !isSet(p.SSOSettings.MetadataURL) {
+		if existing.Metadata == "" && existing.MetadataURL == "" {
+			invalid.Append("metadata", "either metadata or metadata_url must be defined")
+		}
+	}
+	if isSet(p.SSOSettings.Metadata) && isSet(p.SSOSettings.MetadataURL) {
+		invalid.Append("metadata", "both metadata and metadata_url are defined, only one is allowed")
+	}
+	if !isSet(p.SSOSettings.EntityID) {
+		if existing.EntityID == "" {
+			invalid.Append("entity_id", "required")
+		}
+	} else {
+		if len(*p.SSOSettings.EntityID) < 5 {
+			invalid.Append("entity_id", "must be 5 or more characters")
+		}
+	}
+}
+
+func validateOIDCSettings(p kolide.AppConfigPayload, existing *kolide.AppConfig, invalid *invalidArgumentError) {
+	if !isSet(p.SSOSettings.OIDCIssuerURL) {
+		if existing.OIDCIssuerURL == "" {
+			invalid.Append("oidc_issuer_url", "required")
+		}
+	} else {
+		u, err := url.Parse(*p.SSOSettings.OIDCIssuerURL)
+		if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+			invalid.Append("oidc_issuer_url", "must be an http or https URL")
+		}
+	}
+	if !isSet(p.SSOSettings.OIDCClientID) && existing.OIDCClientID == "" {
+		invalid.Append("oidc_client_id", "required")
+	}
+	if !isSet(p.SSOSettings.OIDCClientSecret) && existing.OIDCClientSecret == "" {
+		invalid.Append("oidc_client_secret", "required")
+	}
+}
+
+func validateJITSettings(p kolide.AppConfigPayload, existing *kolide.AppConfig, provider string, invalid *invalidArgumentError) {
+	enabled := existing.EnableJITProvisioning
+	if p.SSOSettings.EnableJITProvisioning != nil {
+		enabled = *p.SSOSettings.EnableJITProvisioning
+	}
+	if !enabled {
+		return
+	}
+	if provider == kolide.SSOProviderOIDC {
+		invalid.Append("enable_jit_provisioning", "only available with the saml provider")
+	}
+	groupAttribute := existing.JITGroupAttribute
+	if p.SSOSettings.JITGroupAttribute != nil {
+		groupAttribute = *p.SSOSettings.JITGroupAttribute
+	}
+	adminGroups, userGroups := []string(existing.JITAdminGroups), []string(existing.JITUserGroups)
+	if p.SSOSettings.JITAdminGroups != nil {
+		adminGroups = *p.SSOSettings.JITAdminGroups
+	}
+	if p.SSOSettings.JITUserGroups != nil {
+		userGroups = *p.SSOSettings.JITUserGroups
+	}
+	if len(adminGroups)+len(userGroups) > 0 && groupAttribute == "" {
+		invalid.Append("jit_group_attribute", "required to map groups")
+	}
+}
@@ -45,3 +45,95 @@ func TestMissingMetadata(t *testing.T) {
 	assert.Equal(t, "metadata", invalid[0].name)
 	assert.Equal(t, "either metadata or metadata_url must be defined", invalid[0].reason)
 }
+
+func TestOIDCFieldsPresent(t *testing.T) {
+	invalid := &invalidArgumentError{}
+	p := kolide.AppConfigPayload{
+		SSOSettings: &kolide.SSOSettingsPayload{
+			EnableSSO:        boolPtr(true),
+			Provider:         stringPtr(kolide.SSOProviderOIDC),
+			IDPName:          stringPtr("okta"),
+			OIDCIssuerURL:    stringPtr("https://idp.example.com"),
+			OIDCClientID:     stringPtr("fleet"),
+			OIDCClientSecret: stringPtr("secret"),
+		},
+	}
+	validateSSOSettings(p, &kolide.AppConfig{}, invalid)
+	assert.False(t, invalid.HasErrors())
+}
+
+func TestOIDCMissingFields(t *testing.T) {
+	invalid := invalidArgumentError{}
+	p := kolide.AppConfigPayload{
+		SSOSettings: &kolide.SSOSettingsPayload{
+			EnableSSO:     boolPtr(true),
+			Provider:      stringPtr(kolide.SSOProviderOIDC),
+			IDPName:       stringPtr("okta"),
+			OIDCIssuerURL: stringPtr("idp.example.com"),
+		},
+	}
+	validateSSOSettings(p, &kolide.AppConfig{}, &invalid)
+	require.Len(t, invalid, 3)
+	assert.Equal(t, "oidc_issuer_url", invalid[0].name)
+	assert.Equal(t, "oidc_client_id", invalid[1].name)
+	assert.Equal(t, "oidc_client_secret", invalid[2].name)
+
+	// The existing settings are used when the payload omits them
+	invalid = invalidArgumentError{}
+	p.SSOSettings.OIDCIssuerURL = nil
+	validateSSOSettings(p, &kolide.AppConfig{
+		OIDCIssuerURL:    "https://idp.example.com",
+		OIDCClientID:     "fleet",
+		OIDCClientSecret: "secret",
+	}, &invalid)
+	assert.False(t, invalid.HasErrors())
+}
+
+func TestUnknownSSOProvider(t *testing.T) {
+	invalid := invalidArgumentError{}
+	p := kolide.AppConfigPayload{
+		SSOSettings: &kolide.SSOSettingsPayload{Provider: stringPtr("cas")},
+	}
+	validateSSOSettings(p, &kolide.AppConfig{}, &invalid)
+	require.Len(t, invalid, 1)
+	assert.Equal(t, "provider", invalid[0].name)
+}
+
+func TestJITSettings(t *testing.T) {
+	invalid := invalidArgumentError{}
+	p := kolide.AppConfigPayload{
+		SSOSettings: &kolide.SSOSettingsPayload{
+			EnableSSO:             boolPtr(true),
+			Provider:              stringPtr(kolide.SSOProviderOIDC),
+			IDPName:               stringPtr("okta"),
+			OIDCIssuerURL:         stringPtr("https://idp.example.com"),
+			OIDCClientID:          stringPtr("fleet"),
+			OIDCClientSecret:      stringPtr("secret"),
+			EnableJITProvisioning: boolPtr(true),
+		},
+	}
+	validateSSOSettings(p, &kolide.AppConfig{}, &invalid)
+	require.Len(t, invalid, 1)
+	assert.Equal(t, "enable_jit_provisioning", invalid[0].name)
+
+	// Groups can only be mapped from an attribute
+	invalid = invalidArgumentError{}
+	p = kolide.AppConfigPayload{
+		SSOSettings: &kolide.SSOSettingsPayload{
+			EnableJITProvisioning: boolPtr(true),
+			JITGroupAttribute:     stringPtr(""),
+		},
+	}
+	validateSSOSettings(p, &kolide.AppConfig{
+		JITGroupAttribute: "groups",
+		JITAdminGroups:    kolide.StringList{"fleet-admins"},
+	}, &invalid)
+	require.Len(t, invalid, 1)
+	assert.Equal(t, "jit_group_attribute", invalid[0].name)
+
+	invalid = invalidArgumentError{}
+	p.SSOSettings.JITGroupAttribute = stringPtr("groups")
+	p.SSOSettings.JITUserGroups = &[]string{"fleet-users"}
+	validateSSOSettings(p, &kolide.AppConfig{}, &invalid)
+	assert.False(t, invalid.HasErrors())
+}
@@ -9,6 +9,11 @@ import (
 )
 
 func (mw validationMiddleware) CallbackSSO(ctx context.Context, auth kolide.Auth) (*kolide.SSOSession, error) {
+	// OIDC responses are validated when their code is redeemed for an ID
+	// token, which is done by the service.
+	if _, ok := auth.(*sso.OIDCAuthResponse); ok {
+		return mw.Service.CallbackSSO(ctx, auth)
+	}
 	invalid := &invalidArgumentError{}
 	session, err := mw.ssoSessionStore.Get(auth.RequestID())
 	if err != nil {
@@ -19,17 +24,11 @@ func (mw validationMiddleware) CallbackSSO(ctx context.Context, auth kolide.Auth
 	if err != nil {
 		return nil, errors.Wrap(err, "creating validator from metadata")
 	}
-	// make sure the response hasn't been tampered with
-	auth, err = validator.ValidateSignature(auth)
+	auth, err = sso.ValidateAuth(validator, auth)
 	if err != nil {
-		invalid.Appendf("sso response", "signature validation failed %s", err.Error())
+		invalid.Append("sso response", err.Error())
 		return nil, invalid
 	}
-	// make sure the response isn't stale
-	err = validator.ValidateResponse(auth)
-	if err != nil {
-		invalid.Appendf("sso response", "response validation failed %s", err.Error())
-	}
 
 	return mw.Service.CallbackSSO(ctx, auth)
 }
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/base64"
 	"encoding/xml"
+	"html"
+	"strings"
 
 	"github.com/kolide/fleet/server/kolide"
 	"github.com/pkg/errors"
@@ -85,6 +87,25 @@ func (r resp) UserID() string {
 	return ""
 }
 
+// AttributeValues returns the values of the assertion attribute with the
+// given name or friendly name.
+func (r resp) AttributeValues(name string) []string {
+	if r.response == nil {
+		return nil
+	}
+	var values []string
+	for _, attr := range r.response.Assertion.AttributeStatement.Attributes {
+		if attr.Name != name && attr.FriendlyName != name {
+			continue
+		}
+		for _, value := range attr.AttributeValues {
+			// Values are read as inner XML, so entities are still escaped.
+			values = append(values, strings.TrimSpace(html.UnescapeString(value.Value)))
+		}
+	}
+	return values
+}
+
 func (r resp) status() (int, error) {
 	if r.response != nil {
 		statusURI := r.response.Status.StatusCode.Value
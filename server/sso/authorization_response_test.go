@@ -18,6 +18,9 @@ func TestDecodeSuccessfulSalesforceResponse(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, Success, status)
 	assert.Equal(t, "john@kolide.co", auth.UserID())
+	assert.Equal(t, []string{"john@kolide.co"}, auth.AttributeValues("email"))
+	assert.Equal(t, []string{"false"}, auth.AttributeValues("is_portal_user"))
+	assert.Nil(t, auth.AttributeValues("groups"))
 }
 
 func TestDecodeWithCommentInName(t *testing.T) {
@@ -0,0 +1,245 @@
+package sso
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
+)
+
+// JSONWebKeySet holds the public keys used by a provider to sign ID tokens.
+// See https://tools.ietf.org/html/rfc7517 Section 5
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JSONWebKey is an RSA or elliptic curve public key.
+// See https://tools.ietf.org/html/rfc7518 Section 6
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// RSA parameters
+	N string `json:"n"`
+	E string `json:"e"`
+	// Elliptic curve parameters
+	Curve string `json:"crv"`
+	X     string `json:"x"`
+	Y     string `json:"y"`
+}
+
+// GetJSONWebKeySet retrieves the key set published at jwksURL.
+func GetJSONWebKeySet(jwksURL string, client *http.Client) (*JSONWebKeySet, error) {
+	var keys JSONWebKeySet
+	if err := getJSON(jwksURL, client, &keys); err != nil {
+		return nil, errors.Wrap(err, "getting OIDC signing keys")
+	}
+	return &keys, nil
+}
+
+// PublicKey returns the *rsa.PublicKey or *ecdsa.PublicKey of the key.
+func (k JSONWebKey) PublicKey() (interface{}, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := decodeKeyParameter(k.N)
+		if err != nil {
+			return nil, errors.Wrap(err, "decoding RSA modulus")
+		}
+		e, err := decodeKeyParameter(k.E)
+		if err != nil {
+			return nil, errors.Wrap(err, "decoding RSA exponent")
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("RSA exponent is too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.Errorf("unsupported curve %q", k.Curve)
+		}
+		x, err := decodeKeyParameter(k.X)
+		if err != nil {
+			return nil, errors.Wrap(err, "decoding EC x coordinate")
+		}
+		y, err := decodeKeyParameter(k.Y)
+		if err != nil {
+			return nil, errors.Wrap(err, "decoding EC y coordinate")
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("EC point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, errors.Errorf("unsupported key type %q", k.KeyType)
+	}
+}
+
+func decodeKeyParameter(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty key parameter")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// signingKey returns the key of the set that signed the token. When the token
+// has no key ID the set must contain a single signing key.
+func (s *JSONWebKeySet) signingKey(keyID string) (*JSONWebKey, error) {
+	var found *JSONWebKey
+	for i, key := range s.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		if keyID != "" && key.KeyID != keyID {
+			continue
+		}
+		if found != nil {
+			return nil, errors.New("ambiguous signing key")
+		}
+		found = &s.Keys[i]
+	}
+	if found == nil {
+		return nil, errors.Errorf("no signing key with id %q", keyID)
+	}
+	return found, nil
+}
+
+// audience is the aud claim, which may be a string or an array of strings.
+type audience []string
+
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(b, &multiple); err != nil {
+		return err
+	}
+	*a = audience(multiple)
+	return nil
+}
+
+func (a audience) contains(s string) bool {
+	for _, v := range a {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// idTokenClaims are the claims of an ID token used by Fleet.
+// See https://openid.net/specs/openid-connect-core-1_0.html Section 2
+type idTokenClaims struct {
+	Issuer          string   `json:"iss"`
+	Subject         string   `json:"sub"`
+	Audience        audience `json:"aud"`
+	AuthorizedParty string   `json:"azp"`
+	ExpiresAt       int64    `json:"exp"`
+	IssuedAt        int64    `json:"iat"`
+	Nonce           string   `json:"nonce"`
+	Email           string   `json:"email"`
+	EmailVerified   *bool    `json:"email_verified"`
+	// all holds every claim of the token, so that users can be identified
+	// by a configured claim
+	all map[string]interface{}
+}
+
+func (c *idTokenClaims) UnmarshalJSON(b []byte) error {
+	type claims idTokenClaims
+	if err := json.Unmarshal(b, (*claims)(c)); err != nil {
+		return err
+	}
+	return json.Unmarshal(b, &c.all)
+}
+
+// Valid is a no-op, the claims are checked by validateIDToken.
+func (c *idTokenClaims) Valid() error {
+	return nil
+}
+
+// userID returns the identifier used to find the Fleet user: the value of
+// userClaim, or the email when userClaim is empty. Other claims such as
+// preferred_username are not used as they are not guaranteed to be unique
+// or stable.
+// See https://openid.net/specs/openid-connect-core-1_0.html Section 5.7
+func (c *idTokenClaims) userID(userClaim string) (string, error) {
+	if userClaim == "" || userClaim == "email" {
+		if c.Email == "" {
+			return "", errors.New("OIDC id token is missing email")
+		}
+		if c.EmailVerified == nil || !*c.EmailVerified {
+			return "", errors.New("OIDC email is not verified")
+		}
+		return c.Email, nil
+	}
+	value, _ := c.all[userClaim].(string)
+	if value == "" {
+		return "", errors.Errorf("OIDC id token is missing %s", userClaim)
+	}
+	return value, nil
+}
+
+// validateIDToken checks the signature and the claims of an ID token.
+// See https://openid.net/specs/openid-connect-core-1_0.html Section 3.1.3.7
+func validateIDToken(rawIDToken string, keys *JSONWebKeySet, issuer, clientID, nonce string, now time.Time) (*idTokenClaims, error) {
+	parser := &jwt.Parser{
+		// Only asymmetric algorithms are accepted, which excludes "none" and
+		// tokens signed with a public key used as an HMAC secret.
+		ValidMethods:         []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"},
+		SkipClaimsValidation: true,
+	}
+	var claims idTokenClaims
+	_, err := parser.ParseWithClaims(rawIDToken, &claims, func(token *jwt.Token) (interface{}, error) {
+		keyID, _ := token.Header["kid"].(string)
+		key, err := keys.signingKey(keyID)
+		if err != nil {
+			return nil, err
+		}
+		if key.Algorithm != "" && key.Algorithm != token.Method.Alg() {
+			return nil, errors.Errorf("key %q is not used with %s", key.KeyID, token.Method.Alg())
+		}
+		return key.PublicKey()
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "verifying signature")
+	}
+
+	if claims.Issuer != issuer {
+		return nil, errors.Errorf("unexpected issuer %q", claims.Issuer)
+	}
+	if !claims.Audience.contains(clientID) {
+		return nil, errors.New("token was not issued for this client")
+	}
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != clientID {
+		return nil, errors.New("token was not authorized for this client")
+	}
+	if claims.ExpiresAt == 0 || !now.Before(time.Unix(claims.ExpiresAt, 0)) {
+		return nil, errors.New("token expired")
+	}
+	if claims.Nonce != nonce {
+		return nil, errors.New("nonce mismatch")
+	}
+	return &claims, nil
+}
@@ -0,0 +1,216 @@
+package sso
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/kolide/fleet/server/kolide"
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+)
+
+// OIDCConfiguration is the part of the OpenID Connect discovery document
+// used to sign on with a provider.
+// See https://openid.net/specs/openid-connect-discovery-1_0.html Section 3
+type OIDCConfiguration struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// GetOIDCConfiguration retrieves the discovery document of the OpenID Connect
+// provider identified by issuerURL.
+func GetOIDCConfiguration(issuerURL string, client *http.Client) (*OIDCConfiguration, error) {
+	issuerURL = strings.TrimSuffix(issuerURL, "/")
+	discoveryURL := issuerURL + "/.well-known/openid-configuration"
+	var configuration OIDCConfiguration
+	if err := getJSON(discoveryURL, client, &configuration); err != nil {
+		return nil, errors.Wrap(err, "getting OIDC discovery document")
+	}
+	// The issuer must match exactly, or the ID tokens it signs could be
+	// accepted for another issuer.
+	if strings.TrimSuffix(configuration.Issuer, "/") != issuerURL {
+		return nil, errors.Errorf("OIDC discovery document is for issuer %q, expected %q", configuration.Issuer, issuerURL)
+	}
+	if configuration.AuthorizationEndpoint == "" || configuration.TokenEndpoint == "" || configuration.JWKSURI == "" {
+		return nil, errors.New("OIDC discovery document is missing endpoints")
+	}
+	return &configuration, nil
+}
+
+// OIDCSettings configures sign on with an OpenID Connect provider.
+type OIDCSettings struct {
+	Configuration *OIDCConfiguration
+	ClientID      string
+	ClientSecret  string
+	// UserClaim is the ID token claim identifying the user. When empty,
+	// users are identified by their verified email.
+	UserClaim string
+	// RedirectURL is the call back on Fleet which receives the authorization
+	// code from the provider
+	RedirectURL  string
+	SessionStore SessionStore
+	OriginalURL  string
+	// Client is used to redeem the authorization code and to retrieve the
+	// signing keys of the provider
+	Client *http.Client
+}
+
+func (s *OIDCSettings) oauth2Config() *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     s.ClientID,
+		ClientSecret: s.ClientSecret,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  s.Configuration.AuthorizationEndpoint,
+			TokenURL: s.Configuration.TokenEndpoint,
+		},
+		RedirectURL: s.RedirectURL,
+		Scopes:      []string{"openid", "email", "profile"},
+	}
+}
+
+// CreateOIDCAuthorizationRequest creates a url that starts the authorization
+// code flow with PKCE at the provider. The state, nonce and code verifier are
+// kept in the session store until the provider calls back.
+// See https://openid.net/specs/openid-connect-core-1_0.html Section 3.1 and
+// https://tools.ietf.org/html/rfc7636
+func CreateOIDCAuthorizationRequest(settings *OIDCSettings) (string, error) {
+	if settings.Configuration == nil {
+		return "", errors.New("missing OIDC configuration")
+	}
+	state, err := randomURLSafeString()
+	if err != nil {
+		return "", errors.Wrap(err, "creating OIDC state")
+	}
+	nonce, err := randomURLSafeString()
+	if err != nil {
+		return "", errors.Wrap(err, "creating OIDC nonce")
+	}
+	verifier, err := randomURLSafeString()
+	if err != nil {
+		return "", errors.Wrap(err, "creating OIDC code verifier")
+	}
+	sess := &Session{
+		OriginalURL:  settings.OriginalURL,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+	}
+	if err := settings.SessionStore.save(state, sess, cacheLifetime); err != nil {
+		return "", errors.Wrap(err, "caching OIDC state")
+	}
+
+	challenge := sha256.Sum256([]byte(verifier))
+	return settings.oauth2Config().AuthCodeURL(state,
+		oauth2.SetAuthURLParam("nonce", nonce),
+		oauth2.SetAuthURLParam("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:])),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	), nil
+}
+
+// OIDCAuthResponse is the authorization code sent by the provider to the
+// redirect URL. The user is only known once the code has been exchanged with
+// ExchangeOIDCCode.
+type OIDCAuthResponse struct {
+	code   string
+	state  string
+	userID string
+}
+
+// DecodeOIDCAuthResponse reads the authorization response from the query of
+// the redirect.
+func DecodeOIDCAuthResponse(query url.Values) (*OIDCAuthResponse, error) {
+	if errCode := query.Get("error"); errCode != "" {
+		return nil, errors.Errorf("OIDC provider returned %s: %s", errCode, query.Get("error_description"))
+	}
+	resp := &OIDCAuthResponse{
+		code:  query.Get("code"),
+		state: query.Get("state"),
+	}
+	if resp.code == "" || resp.state == "" {
+		return nil, errors.New("missing code or state in OIDC response")
+	}
+	return resp, nil
+}
+
+// RequestID is the state of the authorization request.
+func (r *OIDCAuthResponse) RequestID() string {
+	return r.state
+}
+
+// UserID is the verified email of the user from the ID token, or the value
+// of the configured user claim.
+func (r *OIDCAuthResponse) UserID() string {
+	return r.userID
+}
+
+// AttributeValues returns nil, OIDC responses do not assert attributes.
+func (r *OIDCAuthResponse) AttributeValues(name string) []string {
+	return nil
+}
+
+// ExchangeOIDCCode redeems the authorization code of the response for an ID
+// token, and validates the token against the keys of the provider. The
+// returned auth identifies the user in the token.
+func ExchangeOIDCCode(ctx context.Context, settings *OIDCSettings, sess *Session, auth *OIDCAuthResponse) (kolide.Auth, error) {
+	if sess.CodeVerifier == "" || sess.Nonce == "" {
+		return nil, errors.New("session was not created for OIDC")
+	}
+	if settings.Client != nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, settings.Client)
+	}
+	token, err := settings.oauth2Config().Exchange(ctx, auth.code,
+		oauth2.SetAuthURLParam("code_verifier", sess.CodeVerifier),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "exchanging OIDC code")
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, errors.New("OIDC token response is missing id_token")
+	}
+
+	keys, err := GetJSONWebKeySet(settings.Configuration.JWKSURI, settings.Client)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := validateIDToken(rawIDToken, keys, settings.Configuration.Issuer, settings.ClientID, sess.Nonce, time.Now())
+	if err != nil {
+		return nil, errors.Wrap(err, "validating OIDC id token")
+	}
+	userID, err := claims.userID(settings.UserClaim)
+	if err != nil {
+		return nil, err
+	}
+	return &OIDCAuthResponse{code: auth.code, state: auth.state, userID: userID}, nil
+}
+
+func randomURLSafeString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func getJSON(url string, client *http.Client, v interface{}) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("%s returned %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,415 @@
+package sso
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memStore is a SessionStore kept in memory, so that the OIDC flow can be
+// tested without Redis.
+type memStore struct {
+	sessions map[string]Session
+}
+
+func newMemStore() *memStore {
+	return &memStore{sessions: map[string]Session{}}
+}
+
+func (s *memStore) create(requestID, originalURL, metadata string, lifetimeSecs uint) error {
+	return s.save(requestID, &Session{OriginalURL: originalURL, Metadata: metadata}, lifetimeSecs)
+}
+
+func (s *memStore) save(requestID string, sess *Session, lifetimeSecs uint) error {
+	s.sessions[requestID] = *sess
+	return nil
+}
+
+func (s *memStore) Get(requestID string) (*Session, error) {
+	sess, ok := s.sessions[requestID]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	return &sess, nil
+}
+
+func (s *memStore) Expire(requestID string) error {
+	delete(s.sessions, requestID)
+	return nil
+}
+
+// mockOIDCProvider is a minimal OpenID Connect provider implementing the
+// authorization code flow with PKCE.
+type mockOIDCProvider struct {
+	server       *httptest.Server
+	key          *rsa.PrivateKey
+	clientID     string
+	clientSecret string
+	// claims are added to the ID tokens issued by the provider
+	claims jwt.MapClaims
+
+	mu    sync.Mutex
+	codes map[string]mockAuthorization
+}
+
+type mockAuthorization struct {
+	challenge string
+	nonce     string
+}
+
+func newMockOIDCProvider(t *testing.T) *mockOIDCProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err)
+	p := &mockOIDCProvider{
+		key:          key,
+		clientID:     "fleet",
+		clientSecret: "secret",
+		claims:       jwt.MapClaims{"email": "user@example.com", "email_verified": true},
+		codes:        map[string]mockAuthorization{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(OIDCConfiguration{
+			Issuer:                p.server.URL,
+			AuthorizationEndpoint: p.server.URL + "/authorize",
+			TokenEndpoint:         p.server.URL + "/token",
+			JWKSURI:               p.server.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(JSONWebKeySet{Keys: []JSONWebKey{{
+			KeyType:   "RSA",
+			KeyID:     "key1",
+			Use:       "sig",
+			Algorithm: "RS256",
+			N:         base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		require.Nil(t, r.ParseForm())
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok {
+			clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+		}
+		p.mu.Lock()
+		authorization, found := p.codes[r.PostForm.Get("code")]
+		delete(p.codes, r.PostForm.Get("code"))
+		p.mu.Unlock()
+
+		verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if clientID != p.clientID || clientSecret != p.clientSecret || !found ||
+			r.PostForm.Get("grant_type") != "authorization_code" ||
+			base64.RawURLEncoding.EncodeToString(verifier[:]) != authorization.challenge {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		claims := jwt.MapClaims{
+			"iss":   p.server.URL,
+			"sub":   "1234",
+			"aud":   p.clientID,
+			"iat":   time.Now().Unix(),
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"nonce": authorization.nonce,
+		}
+		for k, v := range p.claims {
+			claims[k] = v
+		}
+		idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		idToken.Header["kid"] = "key1"
+		signed, err := idToken.SignedString(key)
+		require.Nil(t, err)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     signed,
+		})
+	})
+	p.server = httptest.NewServer(mux)
+	return p
+}
+
+// authorize stands in for the user signing in at the provider: it returns
+// the query the provider redirects to for the authorization request.
+func (p *mockOIDCProvider) authorize(t *testing.T, authURL string) url.Values {
+	u, err := url.Parse(authURL)
+	require.Nil(t, err)
+	query := u.Query()
+	require.Equal(t, p.server.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+	require.Equal(t, "code", query.Get("response_type"))
+	require.Equal(t, "S256", query.Get("code_challenge_method"))
+	require.Equal(t, p.clientID, query.Get("client_id"))
+
+	code, err := randomURLSafeString()
+	require.Nil(t, err)
+	p.mu.Lock()
+	p.codes[code] = mockAuthorization{challenge: query.Get("code_challenge"), nonce: query.Get("nonce")}
+	p.mu.Unlock()
+	return url.Values{"code": {code}, "state": {query.Get("state")}}
+}
+
+func (p *mockOIDCProvider) settings(t *testing.T, store SessionStore) *OIDCSettings {
+	configuration, err := GetOIDCConfiguration(p.server.URL, p.server.Client())
+	require.Nil(t, err)
+	return &OIDCSettings{
+		Configuration: configuration,
+		ClientID:      p.clientID,
+		ClientSecret:  p.clientSecret,
+		RedirectURL:   "https://fleet.example.com/api/v1/kolide/sso/callback",
+		SessionStore:  store,
+		OriginalURL:   "/hosts/manage",
+		Client:        p.server.Client(),
+	}
+}
+
+func TestOIDCFlow(t *testing.T) {
+	provider := newMockOIDCProvider(t)
+	defer provider.server.Close()
+	store := newMemStore()
+	settings := provider.settings(t, store)
+
+	authURL, err := CreateOIDCAuthorizationRequest(settings)
+	require.Nil(t, err)
+	redirect := provider.authorize(t, authURL)
+
+	auth, err := DecodeOIDCAuthResponse(redirect)
+	require.Nil(t, err)
+	sess, err := store.Get(auth.RequestID())
+	require.Nil(t, err)
+	assert.Equal(t, "/hosts/manage", sess.OriginalURL)
+	assert.NotEmpty(t, sess.Nonce)
+	assert.NotEmpty(t, sess.CodeVerifier)
+
+	validated, err := ExchangeOIDCCode(context.Background(), settings, sess, auth)
+	require.Nil(t, err)
+	assert.Equal(t, "user@example.com", validated.UserID())
+	assert.Equal(t, auth.RequestID(), validated.RequestID())
+
+	// Codes can only be redeemed once
+	_, err = ExchangeOIDCCode(context.Background(), settings, sess, auth)
+	assert.NotNil(t, err)
+}
+
+func TestOIDCFlowRejectsWrongSession(t *testing.T) {
+	provider := newMockOIDCProvider(t)
+	defer provider.server.Close()
+	store := newMemStore()
+	settings := provider.settings(t, store)
+
+	// The code verifier and nonce of another authorization request are not
+	// accepted
+	authURL, err := CreateOIDCAuthorizationRequest(settings)
+	require.Nil(t, err)
+	auth, err := DecodeOIDCAuthResponse(provider.authorize(t, authURL))
+	require.Nil(t, err)
+	_, err = CreateOIDCAuthorizationRequest(settings)
+	require.Nil(t, err)
+	for state, sess := range store.sessions {
+		if state != auth.RequestID() {
+			sess := sess
+			_, err = ExchangeOIDCCode(context.Background(), settings, &sess, auth)
+			assert.NotNil(t, err)
+		}
+	}
+
+	// SAML sessions cannot be used
+	_, err = ExchangeOIDCCode(context.Background(), settings, &Session{Metadata: "<xml/>"}, auth)
+	assert.NotNil(t, err)
+}
+
+func TestOIDCFlowUnverifiedEmail(t *testing.T) {
+	provider := newMockOIDCProvider(t)
+	defer provider.server.Close()
+	provider.claims["email_verified"] = false
+	store := newMemStore()
+	settings := provider.settings(t, store)
+
+	authURL, err := CreateOIDCAuthorizationRequest(settings)
+	require.Nil(t, err)
+	auth, err := DecodeOIDCAuthResponse(provider.authorize(t, authURL))
+	require.Nil(t, err)
+	sess, err := store.Get(auth.RequestID())
+	require.Nil(t, err)
+	_, err = ExchangeOIDCCode(context.Background(), settings, sess, auth)
+	assert.NotNil(t, err)
+}
+
+func TestOIDCFlowUserClaim(t *testing.T) {
+	provider := newMockOIDCProvider(t)
+	defer provider.server.Close()
+	store := newMemStore()
+	settings := provider.settings(t, store)
+	settings.UserClaim = "sub"
+
+	authURL, err := CreateOIDCAuthorizationRequest(settings)
+	require.Nil(t, err)
+	auth, err := DecodeOIDCAuthResponse(provider.authorize(t, authURL))
+	require.Nil(t, err)
+	sess, err := store.Get(auth.RequestID())
+	require.Nil(t, err)
+	validated, err := ExchangeOIDCCode(context.Background(), settings, sess, auth)
+	require.Nil(t, err)
+	assert.Equal(t, "1234", validated.UserID())
+}
+
+func TestIDTokenClaimsUserID(t *testing.T) {
+	parse := func(claims string) *idTokenClaims {
+		var c idTokenClaims
+		require.Nil(t, json.Unmarshal([]byte(claims), &c))
+		return &c
+	}
+
+	userID, err := parse(`{"sub":"1234","email":"user@example.com","email_verified":true}`).userID("")
+	require.Nil(t, err)
+	assert.Equal(t, "user@example.com", userID)
+	userID, err = parse(`{"sub":"1234","email":"user@example.com","email_verified":true}`).userID("sub")
+	require.Nil(t, err)
+	assert.Equal(t, "1234", userID)
+
+	// The email must be verified, and other claims are only used when
+	// configured
+	invalid := map[string]string{
+		"unverified email":   `{"sub":"1234","email":"user@example.com","email_verified":false}`,
+		"unknown verified":   `{"sub":"1234","email":"user@example.com"}`,
+		"preferred username": `{"sub":"1234","preferred_username":"user"}`,
+		"no claims":          `{}`,
+	}
+	for name, claims := range invalid {
+		_, err := parse(claims).userID("")
+		assert.NotNil(t, err, name)
+	}
+	_, err = parse(`{"email":"user@example.com","email_verified":true}`).userID("sub")
+	assert.NotNil(t, err)
+	_, err = parse(`{"sub":"1234","admin":true}`).userID("admin")
+	assert.NotNil(t, err)
+}
+
+func TestGetOIDCConfigurationIssuerMismatch(t *testing.T) {
+	provider := newMockOIDCProvider(t)
+	defer provider.server.Close()
+
+	_, err := GetOIDCConfiguration(provider.server.URL+"/", provider.server.Client())
+	assert.Nil(t, err)
+	_, err = GetOIDCConfiguration(provider.server.URL+"/other", provider.server.Client())
+	assert.NotNil(t, err)
+}
+
+func TestDecodeOIDCAuthResponse(t *testing.T) {
+	_, err := DecodeOIDCAuthResponse(url.Values{"error": {"access_denied"}, "state": {"abcdefgh"}})
+	assert.NotNil(t, err)
+	_, err = DecodeOIDCAuthResponse(url.Values{"state": {"abcdefgh"}})
+	assert.NotNil(t, err)
+
+	auth, err := DecodeOIDCAuthResponse(url.Values{"code": {"code"}, "state": {"abcdefgh"}})
+	require.Nil(t, err)
+	assert.Equal(t, "abcdefgh", auth.RequestID())
+	assert.Equal(t, "", auth.UserID())
+}
+
+func TestValidateIDToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err)
+	keys := &JSONWebKeySet{Keys: []JSONWebKey{{
+		KeyType: "RSA",
+		KeyID:   "key1",
+		N:       base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:       base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}}
+	now := time.Now()
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":   "https://idp.example.com",
+			"aud":   []string{"fleet"},
+			"exp":   now.Add(time.Minute).Unix(),
+			"nonce": "nonce",
+			"email": "user@example.com",
+		}
+	}
+	sign := func(method jwt.SigningMethod, claims jwt.MapClaims, signingKey interface{}) string {
+		token := jwt.NewWithClaims(method, claims)
+		token.Header["kid"] = "key1"
+		signed, err := token.SignedString(signingKey)
+		require.Nil(t, err)
+		return signed
+	}
+
+	claims, err := validateIDToken(sign(jwt.SigningMethodRS256, validClaims(), key), keys, "https://idp.example.com", "fleet", "nonce", now)
+	require.Nil(t, err)
+	assert.Equal(t, "user@example.com", claims.Email)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err)
+	publicKeyAsSecret := []byte(keys.Keys[0].N)
+
+	invalid := map[string]string{
+		"wrong key": sign(jwt.SigningMethodRS256, validClaims(), otherKey),
+		"hmac":      sign(jwt.SigningMethodHS256, validClaims(), publicKeyAsSecret),
+		"none":      sign(jwt.SigningMethodNone, validClaims(), jwt.UnsafeAllowNoneSignatureType),
+	}
+	modified := map[string]func(jwt.MapClaims){
+		"issuer":    func(c jwt.MapClaims) { c["iss"] = "https://other.example.com" },
+		"audience":  func(c jwt.MapClaims) { c["aud"] = "other" },
+		"expired":   func(c jwt.MapClaims) { c["exp"] = now.Add(-time.Minute).Unix() },
+		"no expiry": func(c jwt.MapClaims) { delete(c, "exp") },
+		"nonce":     func(c jwt.MapClaims) { c["nonce"] = "replayed" },
+	}
+	for name, modify := range modified {
+		claims := validClaims()
+		modify(claims)
+		invalid[name] = sign(jwt.SigningMethodRS256, claims, key)
+	}
+	for name, token := range invalid {
+		_, err := validateIDToken(token, keys, "https://idp.example.com", "fleet", "nonce", now)
+		assert.NotNil(t, err, name)
+	}
+}
+
+func TestJSONWebKeyEC(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	jwk := JSONWebKey{
+		KeyType: "EC",
+		Curve:   "P-256",
+		X:       base64.RawURLEncoding.EncodeToString(key.X.Bytes()),
+		Y:       base64.RawURLEncoding.EncodeToString(key.Y.Bytes()),
+	}
+	keys := &JSONWebKeySet{Keys: []JSONWebKey{jwk}}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss":   "https://idp.example.com",
+		"aud":   "fleet",
+		"exp":   time.Now().Add(time.Minute).Unix(),
+		"nonce": "nonce",
+	})
+	signed, err := token.SignedString(key)
+	require.Nil(t, err)
+	_, err = validateIDToken(signed, keys, "https://idp.example.com", "fleet", "nonce", time.Now())
+	assert.Nil(t, err)
+
+	jwk.Y = jwk.X
+	_, err = jwk.PublicKey()
+	assert.NotNil(t, err)
+}
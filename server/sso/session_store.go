@@ -19,6 +19,11 @@ type Session struct {
 	// ExpiresAt session will be removed after this time.
 	ExpiresAt time.Time `json:"expires_at"`
 	Metadata  string    `json:"metadata"`
+	// Nonce is the OIDC nonce that must be present in the ID token.
+	Nonce string `json:"nonce,omitempty"`
+	// CodeVerifier is the OIDC PKCE secret sent when redeeming the
+	// authorization code.
+	CodeVerifier string `json:"code_verifier,omitempty"`
 }
 
 // SessionStore persists state of a sso session across process boundries and
@@ -28,6 +33,7 @@ type Session struct {
 // a reasonable amount of time, it automatically expires and is removed.
 type SessionStore interface {
 	create(requestID, originalURL, x509Cert string, lifetimeSecs uint) error
+	save(requestID string, sess *Session, lifetimeSecs uint) error
 	Get(requestID string) (*Session, error)
 	Expire(requestID string) error
 }
@@ -42,12 +48,15 @@ type store struct {
 }
 
 func (s *store) create(requestID, originalURL, metadata string, lifetimeSecs uint) error {
+	return s.save(requestID, &Session{OriginalURL: originalURL, Metadata: metadata}, lifetimeSecs)
+}
+
+func (s *store) save(requestID string, sess *Session, lifetimeSecs uint) error {
 	if len(requestID) < 8 {
 		return errors.New("request id must be 8 or more characters in length")
 	}
 	conn := s.pool.Get()
 	defer conn.Close()
-	sess := Session{OriginalURL: originalURL, Metadata: metadata}
 	var writer bytes.Buffer
 	err := json.NewEncoder(&writer).Encode(sess)
 	if err != nil {
@@ -92,6 +92,22 @@ func (v *validator) ValidateResponse(auth kolide.Auth) error {
 	return nil
 }
 
+// ValidateAuth checks that the response was signed by the identity provider
+// and is not stale, returning the response with its signed content. The
+// response must not be used if an error is returned.
+func ValidateAuth(v Validator, auth kolide.Auth) (kolide.Auth, error) {
+	// make sure the response hasn't been tampered with
+	signed, err := v.ValidateSignature(auth)
+	if err != nil {
+		return nil, errors.Wrap(err, "signature validation failed")
+	}
+	// make sure the response isn't stale
+	if err := v.ValidateResponse(signed); err != nil {
+		return nil, errors.Wrap(err, "response validation failed")
+	}
+	return signed, nil
+}
+
 func (v *validator) ValidateSignature(auth kolide.Auth) (kolide.Auth, error) {
 	info := auth.(*resp)
 	status, err := info.status()
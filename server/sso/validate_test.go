@@ -118,6 +118,38 @@ func TestVerfiyStaleMessageFails(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+func TestValidateAuth(t *testing.T) {
+	auth, err := DecodeAuthResponse(testResponse)
+	require.Nil(t, err)
+
+	tm, err := time.Parse(time.UnixDate, "Sun Apr 30 22:10:00 UTC 2017")
+	require.Nil(t, err)
+	validator, err := NewValidator(testMetadata, Clock(dsig.NewFakeClockAt(tm)))
+	require.Nil(t, err)
+	signed, err := ValidateAuth(validator, auth)
+	require.Nil(t, err)
+	assert.Equal(t, "john@kolide.co", signed.UserID())
+
+	// Stale responses are rejected, even though their signature is valid
+	tm, err = time.Parse(time.UnixDate, "Sun Apr 30 22:14:00 UTC 2017")
+	require.Nil(t, err)
+	validator, err = NewValidator(testMetadata, Clock(dsig.NewFakeClockAt(tm)))
+	require.Nil(t, err)
+	signed, err = ValidateAuth(validator, auth)
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "response validation failed")
+	assert.Nil(t, signed)
+
+	tampered, err := tamperedResponse(testResponse)
+	require.Nil(t, err)
+	auth, err = DecodeAuthResponse(tampered)
+	require.Nil(t, err)
+	signed, err = ValidateAuth(validator, auth)
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "signature validation failed")
+	assert.Nil(t, signed)
+}
+
 var testGoogleMetadata = `
 <?xml version="1.0" encoding="UTF-8" standalone="no"?>
 <md:EntityDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" entityID="https://accounts.google.com/o/saml2?idpid=C0171bstf" validUntil="2022-07-16T20:07:43.000Z">
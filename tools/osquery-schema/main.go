@@ -0,0 +1,127 @@
+// Command osquery-schema generates the osquery schema used by Fleet to
+// validate queries (assets/osquery/schema.json) from the table definitions
+// used by the frontend (frontend/osquery_tables.json).
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/kolide/fleet/server/kolide"
+)
+
+// groupPlatforms maps the groups of the frontend table definitions, which
+// follow the layout of the osquery specs, to the platforms they are built
+// for.
+var groupPlatforms = map[string][]string{
+	"specs":     {"darwin", "freebsd", "linux", "windows"},
+	"utility":   {"darwin", "freebsd", "linux", "windows"},
+	"posix":     {"darwin", "freebsd", "linux"},
+	"yara":      {"darwin", "freebsd", "linux"},
+	"sleuthkit": {"darwin", "freebsd", "linux"},
+	"smart":     {"darwin", "freebsd", "linux"},
+	"lldpd":     {"linux"},
+	"macwin":    {"darwin", "windows"},
+	"linwin":    {"linux", "windows"},
+	"windows":   {"windows"},
+	"freebsd":   {"freebsd"},
+	"linux":     {"linux"},
+	"darwin":    {"darwin"},
+}
+
+type frontendTables struct {
+	Tables []struct {
+		Key    string `json:"key"`
+		Tables []struct {
+			Name    string `json:"name"`
+			Columns []struct {
+				Name string `json:"name"`
+			} `json:"columns"`
+		} `json:"tables"`
+	} `json:"tables"`
+}
+
+func appendMissing(list []string, s string) []string {
+	for _, e := range list {
+		if e == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+func main() {
+	var (
+		flInput   = flag.String("input", "frontend/osquery_tables.json", "frontend table definitions")
+		flOutput  = flag.String("output", kolide.OsquerySchemaAsset, "generated schema")
+		flVersion = flag.String("version", "3.3", "osquery version of the table definitions")
+	)
+	flag.Parse()
+
+	if err := generate(*flInput, *flOutput, *flVersion); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func generate(input, output, version string) error {
+	data, err := ioutil.ReadFile(input)
+	if err != nil {
+		return err
+	}
+	var frontend frontendTables
+	if err := json.Unmarshal(data, &frontend); err != nil {
+		return err
+	}
+
+	tables := map[string]kolide.OsquerySchemaTable{}
+	for _, group := range frontend.Tables {
+		platforms, ok := groupPlatforms[group.Key]
+		if !ok {
+			return fmt.Errorf("unknown table group %q", group.Key)
+		}
+		for _, t := range group.Tables {
+			table := tables[t.Name]
+			for _, p := range platforms {
+				table.Platforms = appendMissing(table.Platforms, p)
+			}
+			for _, c := range t.Columns {
+				table.Columns = appendMissing(table.Columns, c.Name)
+			}
+			sort.Strings(table.Platforms)
+			tables[t.Name] = table
+		}
+	}
+
+	var names []string
+	for name := range tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Write a table per line to keep changes to the schema reviewable
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "{\n  \"version\": %q,\n  \"tables\": {\n", version)
+	for i, name := range names {
+		table, err := json.Marshal(tables[name])
+		if err != nil {
+			return err
+		}
+		table = bytes.Replace(table, []byte(`","`), []byte(`", "`), -1)
+		table = bytes.Replace(table, []byte(`":`), []byte(`": `), -1)
+		table = bytes.Replace(table, []byte(`],"`), []byte(`], "`), -1)
+		separator := ","
+		if i == len(names)-1 {
+			separator = ""
+		}
+		fmt.Fprintf(&buf, "    %q: %s%s\n", name, table, separator)
+	}
+	buf.WriteString("  }\n}\n")
+
+	return ioutil.WriteFile(output, buf.Bytes(), 0644)
+}